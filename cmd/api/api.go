@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -11,11 +14,14 @@ import (
 	"github.com/michaelhoman/ShotSeek/docs" // This is required to run Swagger Docs
 	"github.com/michaelhoman/ShotSeek/internal/auth"
 	"github.com/michaelhoman/ShotSeek/internal/config"
-	"github.com/michaelhoman/ShotSeek/internal/env"
+	"github.com/michaelhoman/ShotSeek/internal/location"
 	"github.com/michaelhoman/ShotSeek/internal/mailer"
+	"github.com/michaelhoman/ShotSeek/internal/metrics"
 	int_middleware "github.com/michaelhoman/ShotSeek/internal/middleware"
+	"github.com/michaelhoman/ShotSeek/internal/ratelimit"
 	"github.com/michaelhoman/ShotSeek/internal/service"
 	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/tracing"
 	"github.com/michaelhoman/ShotSeek/internal/utils"
 
 	// store "github.com/michaelhoman/ShotSeek/internal/store/postgres"
@@ -30,6 +36,10 @@ type application struct {
 	jwtAuth         *auth.JWTAuth
 	auth            *auth.AuthHandler
 	locationService *service.LocationService
+	geocoder        location.Geocoder
+	rateLimiter     *ratelimit.Limiter
+	trustedProxies  []*net.IPNet
+	cors            func(http.Handler) http.Handler
 }
 
 //	type config struct {
@@ -67,33 +77,47 @@ type dbConfig struct {
 	maxIdleTime  string
 }
 
+// swaggerContentSecurityPolicy lets the bundled Swagger UI run its inline bootstrap script
+const swaggerContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
+
 func (app *application) mount() http.Handler {
-	authHandler := auth.NewAuthHandler(app.store, app.config, app.jwtService, app.jwtAuth)
+	authHandler := auth.NewAuthHandler(app.store, app.config, app.jwtService, app.jwtAuth, app.mailer)
 	r := chi.NewRouter()
 
 	// A good base middleware stack
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	r.Use(tracing.Middleware)
+	r.Use(metrics.Middleware)
+	r.Use(int_middleware.ClientIP(app.trustedProxies))
+	r.Use(int_middleware.RequestLogger)
+	r.Use(int_middleware.Recoverer)
+	r.Use(int_middleware.SecurityHeaders(app.config.HttpsEnabled))
+	r.Use(app.cors)
 
 	// Set a timeout value on the request context (ctx), that will signal
 	// through ctx.Done() that the request has timed out and further
 	// processing should be stopped.
 	r.Use(middleware.Timeout(60 * time.Second))
 
+	// Unknown routes and methods get problem details like every other error
+	r.NotFound(utils.NotFoundHandler)
+	r.MethodNotAllowed(utils.MethodNotAllowedHandler)
+
 	// Initialize JWT service
 	// jwtService := auth.NewJWTService(app.config.auth.token.secret, app.config.auth.token.exp)
 
 	r.Route("/v1", func(r chi.Router) {
+		r.Use(app.rateLimiter.Middleware(rateLimitDefault, ratelimit.ByIP))
 		r.Get("/health", app.healthCheckHandler)
 
 		docsURL := fmt.Sprintf("%s/swagger/doc.json", app.config.Addr)
-		r.Get("/swagger/*", httpSwagger.Handler(
+		r.With(int_middleware.ContentSecurityPolicy(swaggerContentSecurityPolicy)).Get("/swagger/*", httpSwagger.Handler(
 			httpSwagger.URL(docsURL), //The url pointing to API definition
 		))
 		r.Route("/posts", func(r chi.Router) {
-			r.Post("/", app.createPostsHandler)
+			r.Get("/", app.listPostsHandler)
+			r.Get("/search", app.searchPostsHandler)
+			r.With(int_middleware.JwtMiddleware(authHandler)).Post("/", app.createPostsHandler)
 
 			// Comments
 			r.Route("/comments/{commentID}", func(r chi.Router) {
@@ -105,15 +129,94 @@ func (app *application) mount() http.Handler {
 
 			r.Route("/{postID}", func(r chi.Router) {
 				r.Use(app.postsContextMiddleware)
-				r.Post("/comments", app.createCommentHandler)
+				r.Get("/comments", app.listCommentsHandler)
+				r.With(int_middleware.JwtMiddleware(authHandler)).Post("/comments", app.createCommentHandler)
 				r.Get("/", app.getPostHandler)
 				r.Get("/", app.getPostHandler)
-				r.Patch("/", app.updatePostHandler)
-				r.Delete("/", app.deletePostHandler)
+				r.With(int_middleware.JwtMiddleware(authHandler)).Patch("/", app.updatePostHandler)
+				r.With(int_middleware.JwtMiddleware(authHandler)).Delete("/", app.deletePostHandler)
+
+			})
+		})
+
+		r.Route("/gigs", func(r chi.Router) {
+			r.Get("/", app.listGigsHandler)
+			r.With(int_middleware.JwtMiddleware(authHandler)).Post("/", app.createGigHandler)
+
+			r.Route("/{gigID}", func(r chi.Router) {
+				r.Use(app.gigsContextMiddleware)
+				r.Get("/", app.getGigHandler)
+				r.With(int_middleware.JwtMiddleware(authHandler)).Patch("/", app.updateGigHandler)
+				r.With(int_middleware.JwtMiddleware(authHandler)).Put("/status", app.updateGigStatusHandler)
+				r.With(int_middleware.JwtMiddleware(authHandler)).Get("/applications", app.listGigApplicationsHandler)
+				r.With(int_middleware.JwtMiddleware(authHandler)).Post("/applications", app.createApplicationHandler)
+			})
+		})
+
+		r.Route("/applications", func(r chi.Router) {
+			r.Use(int_middleware.JwtMiddleware(authHandler))
+			r.Get("/", app.listMyApplicationsHandler)
+			r.Route("/{applicationID}", func(r chi.Router) {
+				r.Use(app.applicationsContextMiddleware)
+				r.Get("/", app.getApplicationHandler)
+				r.Put("/status", app.updateApplicationStatusHandler)
+			})
+		})
+
+		r.Route("/notifications", func(r chi.Router) {
+			r.Use(int_middleware.JwtMiddleware(authHandler))
+			r.Get("/", app.listNotificationsHandler)
+			r.Post("/{notificationID}/read", app.markNotificationReadHandler)
+		})
 
+		r.Route("/availability", func(r chi.Router) {
+			r.Use(int_middleware.JwtMiddleware(authHandler))
+			r.Get("/", app.listAvailabilityHandler)
+			r.Post("/", app.createAvailabilityHandler)
+			r.Post("/import", app.importAvailabilityHandler)
+			r.Post("/feed", app.createCalendarFeedHandler)
+			r.Delete("/feed", app.deleteCalendarFeedHandler)
+			r.Route("/{blockID}", func(r chi.Router) {
+				r.Use(app.availabilityContextMiddleware)
+				r.Get("/", app.getAvailabilityHandler)
+				r.Patch("/", app.updateAvailabilityHandler)
+				r.Delete("/", app.deleteAvailabilityHandler)
+			})
+		})
+
+		r.Get("/calendars/{token}.ics", app.getCalendarFeedHandler)
+
+		r.Route("/bookings", func(r chi.Router) {
+			r.Use(int_middleware.JwtMiddleware(authHandler))
+			r.Get("/", app.listBookingsHandler)
+			r.Post("/", app.createBookingHandler)
+			r.Route("/{bookingID}", func(r chi.Router) {
+				r.Use(app.bookingContextMiddleware)
+				r.Get("/", app.getBookingHandler)
+				r.Post("/actions", app.bookingActionHandler)
+				r.Get("/events", app.listBookingEventsHandler)
+				r.Get("/reviews", app.listBookingReviewsHandler)
+				r.Post("/reviews", app.createReviewHandler)
 			})
 		})
 
+		r.Route("/reviews/{reviewID}", func(r chi.Router) {
+			r.Use(int_middleware.JwtMiddleware(authHandler))
+			r.Use(app.reviewContextMiddleware)
+			r.Post("/response", app.respondToReviewHandler)
+		})
+
+		r.Get("/cinematographers/search", app.searchCinematographersHandler)
+
+		r.Route("/profiles", func(r chi.Router) {
+			r.With(int_middleware.JwtMiddleware(authHandler)).Get("/me", app.getMyProfileHandler)
+			r.With(int_middleware.JwtMiddleware(authHandler)).Put("/me", app.putMyProfileHandler)
+			r.With(int_middleware.JwtMiddleware(authHandler)).Get("/me/service-area", app.getMyServiceAreaHandler)
+			r.With(int_middleware.JwtMiddleware(authHandler)).Put("/me/service-area", app.putMyServiceAreaHandler)
+			r.Get("/{userID}", app.getProfileHandler)
+			r.Get("/{userID}/reviews", app.listUserReviewsHandler)
+		})
+
 		r.Route("/users", func(r chi.Router) {
 			// r.Post("/", app.createUserHandler)
 			r.Use(int_middleware.JwtMiddleware(authHandler))
@@ -131,17 +234,19 @@ func (app *application) mount() http.Handler {
 		})
 		r.Route("/locations", func(r chi.Router) {
 			r.Use(int_middleware.JwtMiddleware(authHandler))
+			// Lookups can fall through to Nominatim, which allows about one request per second
+			r.Use(app.rateLimiter.Middleware(rateLimitGeocode, ratelimit.FirstOf(ratelimit.ByUser(int_middleware.UserIDFromRequest), ratelimit.ByIP)))
 			r.Get("/zip/{ZIPCode}", app.zipLookupHandler)
-			r.Get("/zip/nearby/{ZIPCode}/{miles}", app.getNearbyByZipHandler) // Debugging - remove
 		})
 
 		//public
 		r.Route("/authentication", func(r chi.Router) {
-			r.Post("/register", authHandler.RegisterUserHandler)
+			authLimit := app.rateLimiter.Middleware(rateLimitAuth, ratelimit.ByIP)
+			r.With(authLimit).Post("/register", authHandler.RegisterUserHandler)
 			r.Put("/activate/{token}", app.activateUserHandler)
-			r.Post("/login", authHandler.LoginHandler)
+			r.With(authLimit).Post("/login", authHandler.LoginHandler)
 			r.Post("/logout", authHandler.LogoutHandler)
-			r.Post("/refresh", authHandler.RefreshHandler)
+			r.With(authLimit).Post("/refresh", authHandler.RefreshHandler)
 
 			//r.Post("/logout", app.logoutHandler)
 		})
@@ -153,7 +258,12 @@ func (app *application) mount() http.Handler {
 	return r
 }
 
-func (app *application) run(mux http.Handler) error {
+// shutdownTimeout is how long in-flight requests get to finish once the server is stopping
+const shutdownTimeout = 20 * time.Second
+
+// run serves the API until ctx is done, then stops taking connections and waits for the
+// requests in flight
+func (app *application) run(ctx context.Context, mux http.Handler) error {
 	//docs
 	docs.SwaggerInfo.Version = version
 	docs.SwaggerInfo.Host = app.config.ApiURL
@@ -167,23 +277,51 @@ func (app *application) run(mux http.Handler) error {
 		IdleTimeout:  time.Minute,
 	}
 
-	// Use the proper certificate and key paths for your server
-	httpsEnabled := env.GetBool("HTTPS_ENABLED", false)
-
-	certFile := env.GetString("HTTPS_CERT_PATH", ".keys/https/localhost.crt") // Your certificate file
-	keyFile := env.GetString("HTTPS_KEY_PATH", ".keys/https/localhost.key")   // Your private key file
+	shutdown := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		utils.Logger.Info("Server is shutting down")
 
-	utils.Logger.Info("Cert file path:", certFile)
-	utils.Logger.Info("Key file path:", keyFile)
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		shutdown <- srv.Shutdown(ctx)
+	}()
 
-	if httpsEnabled {
-		utils.Logger.Info("Server has started at ", "ADDR", app.config.Addr, "ENV", app.config.Env, "HTTPS enabled")
-		return srv.ListenAndServeTLS(certFile, keyFile)
+	var err error
+	if app.config.HttpsEnabled {
+		utils.Logger.Infow("Server has started", "addr", app.config.Addr, "env", app.config.Env, "https", true, "cert_file", app.config.HttpsCertFile)
+		err = srv.ListenAndServeTLS(app.config.HttpsCertFile, app.config.HttpsKeyFile)
 	} else {
-		utils.Logger.Info("Server has started at ", "ADDR: ", app.config.Addr, " ENV: ", app.config.Env, " HTTPS disabled")
-		return srv.ListenAndServe()
+		utils.Logger.Infow("Server has started", "addr", app.config.Addr, "env", app.config.Env, "https", false)
+		err = srv.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+	return <-shutdown
 
 	// utils.Logger.Info("Server has started at ", "ADDR", app.config.Addr, "ENV", app.config.Env)
 	// return srv.ListenAndServe()
 }
+
+// runAdmin serves operational endpoints (metrics) on their own listener so they are never exposed on the public API address
+func (app *application) runAdmin() error {
+	if app.config.Admin.Addr == "" {
+		utils.Logger.Info("Admin listener disabled")
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	srv := &http.Server{
+		Addr:         app.config.Admin.Addr,
+		Handler:      mux,
+		WriteTimeout: time.Second * 15,
+		ReadTimeout:  time.Second * 10,
+		IdleTimeout:  time.Minute,
+	}
+
+	utils.Logger.Info("Admin listener has started at ", "ADDR: ", app.config.Admin.Addr)
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+type applicationKey string
+
+const applicationCtx applicationKey = "application"
+
+type CreateApplicationPayload struct {
+	Message        string   `json:"message" validate:"required,max=2000"`
+	PortfolioLinks []string `json:"portfolio_links" validate:"max=10,dive,required,http_url,max=500"`
+}
+
+// CreateApplication godoc
+//
+//	@Summary		Applies to a gig
+//	@Description	Applies to an open gig with a message and portfolio links. Each user can apply to a gig once, and not to their own. The poster is notified.
+//	@Tags			applications
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"Gig (post) ID"
+//	@Param			payload	body		CreateApplicationPayload	true	"Application payload"
+//	@Success		201		{object}	store.Application
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		403		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		409		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/gigs/{id}/applications [post]
+func (app *application) createApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	gig := getGigFromCtx(r)
+
+	var payload CreateApplicationPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.StructCtx(r.Context(), payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	if userID == gig.UserID {
+		utils.WriteProblem(w, r, utils.NewAppError(http.StatusForbidden, utils.CodeForbidden, "You cannot apply to your own gig"))
+		return
+	}
+
+	var created *store.Application
+	err = app.store.WithTx(r.Context(), func(tx store.Storage) error {
+		// Read the status again in the transaction, so an application cannot slip in after
+		// the gig was filled or cancelled and stay pending on a closed gig
+		current, err := tx.Gigs.GetByID(r.Context(), gig.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != store.GigOpen {
+			return utils.NewAppError(http.StatusConflict, utils.CodeConflict, "This gig is no longer taking applications")
+		}
+
+		application := store.Application{
+			PostID:         gig.ID,
+			UserID:         userID,
+			Message:        payload.Message,
+			PortfolioLinks: payload.PortfolioLinks,
+		}
+		if err := tx.Applications.Create(r.Context(), &application); err != nil {
+			return err
+		}
+		if created, err = tx.Applications.GetByID(r.Context(), application.ID); err != nil {
+			return err
+		}
+		return tx.Notifications.Create(r.Context(), &store.Notification{
+			UserID:  gig.UserID,
+			Kind:    store.NotificationApplicationReceived,
+			Message: fmt.Sprintf("%s %s applied to %q", created.User.FirstName, created.User.LastName, gig.Title),
+			Link:    applicationLink(created.ID),
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			utils.WriteProblem(w, r, utils.WrapAppError(http.StatusConflict, utils.CodeConflict, "You have already applied to this gig", err))
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		case errors.As(err, new(*utils.AppError)):
+			utils.WriteProblem(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusCreated, created); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// ListGigApplications godoc
+//
+//	@Summary		Lists a gig's applicants
+//	@Description	Lists applications to the poster's own gig, ranked hired, shortlisted, submitted, closed, rejected, then withdrawn, and oldest first within each. Follow next_cursor (also sent as a Link header) for the next page, keeping the same status filter.
+//	@Tags			applications
+//	@Produce		json
+//	@Param			id		path		int		true	"Gig (post) ID"
+//	@Param			status	query		string	false	"Only applications in this status"	Enums(submitted, shortlisted, rejected, hired, withdrawn, closed)
+//	@Param			limit	query		int		false	"Page size, at most 100"			default(20)
+//	@Param			cursor	query		string	false	"next_cursor from the previous page"
+//	@Success		200		{array}		store.Application
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		403		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/gigs/{id}/applications [get]
+func (app *application) listGigApplicationsHandler(w http.ResponseWriter, r *http.Request) {
+	gig := getGigFromCtx(r)
+
+	if err := requireGigOwner(r, gig); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	filter := store.ApplicationFilter{Page: page}
+	switch status := store.ApplicationStatus(r.URL.Query().Get("status")); status {
+	case "", store.ApplicationSubmitted, store.ApplicationShortlisted, store.ApplicationRejected,
+		store.ApplicationHired, store.ApplicationWithdrawn, store.ApplicationClosed:
+		filter.Status = status
+	default:
+		utils.WriteProblem(w, r, utils.InvalidQueryParam("status", "oneof", "must be one of: submitted shortlisted rejected hired withdrawn closed"))
+		return
+	}
+
+	applications, err := app.store.Applications.ListByPost(r.Context(), gig.ID, filter)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := pagination.Write(w, r, applications); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// ListMyApplications godoc
+//
+//	@Summary		Lists your applications
+//	@Description	Lists the signed in user's applications with their current status, newest first. Follow next_cursor (also sent as a Link header) for the next page.
+//	@Tags			applications
+//	@Produce		json
+//	@Param			limit	query		int		false	"Page size, at most 100"	default(20)
+//	@Param			cursor	query		string	false	"next_cursor from the previous page"
+//	@Success		200		{array}		store.Application
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/applications [get]
+func (app *application) listMyApplicationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	applications, err := app.store.Applications.ListByUser(r.Context(), userID, page)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := pagination.Write(w, r, applications); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// GetApplication godoc
+//
+//	@Summary		Fetches an application
+//	@Description	Fetches an application. Only the applicant and the gig's poster can see it.
+//	@Tags			applications
+//	@Produce		json
+//	@Param			id	path		int	true	"Application ID"
+//	@Success		200	{object}	store.Application
+//	@Failure		400	{object}	utils.Problem
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		403	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/applications/{id} [get]
+func (app *application) getApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	if err := utils.JsonResponse(w, http.StatusOK, getApplicationFromCtx(r)); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+type UpdateApplicationStatusPayload struct {
+	Status store.ApplicationStatus `json:"status" validate:"required,oneof=shortlisted rejected hired withdrawn"`
+}
+
+// UpdateApplicationStatus godoc
+//
+//	@Summary		Changes an application's status
+//	@Description	The gig's poster can shortlist, reject or hire; the applicant can withdraw. Rejected applicants can be shortlisted again, and hires can withdraw. A change the lifecycle does not allow gets 409 invalid_transition, and a change that races another gets 409 edit_conflict with the application as it is now. The other party is notified.
+//	@Tags			applications
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int								true	"Application ID"
+//	@Param			payload	body		UpdateApplicationStatusPayload	true	"New status"
+//	@Success		200		{object}	store.Application
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		403		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		409		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/applications/{id}/status [put]
+func (app *application) updateApplicationStatusHandler(w http.ResponseWriter, r *http.Request) {
+	application := getApplicationFromCtx(r)
+
+	var payload UpdateApplicationStatusPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	// Decisions belong to the poster and withdrawing to the applicant
+	notification := store.Notification{Link: applicationLink(application.ID)}
+	switch {
+	case payload.Status == store.ApplicationWithdrawn && userID == application.UserID:
+		notification.UserID = application.PosterID
+		notification.Kind = store.NotificationApplicationWithdrawn
+		notification.Message = fmt.Sprintf("%s %s withdrew from %q", application.User.FirstName, application.User.LastName, application.PostTitle)
+	case payload.Status != store.ApplicationWithdrawn && userID == application.PosterID:
+		notification.UserID = application.UserID
+		notification.Kind = store.NotificationApplicationStatus
+		notification.Message = fmt.Sprintf("Your application to %q was %s", application.PostTitle, payload.Status)
+	default:
+		utils.WriteProblem(w, r, utils.NewAppError(http.StatusForbidden, utils.CodeForbidden, "Only the poster can shortlist, reject or hire, and only the applicant can withdraw"))
+		return
+	}
+
+	updated := *application
+	err = app.store.WithTx(r.Context(), func(tx store.Storage) error {
+		updated = *application
+		if err := tx.Applications.UpdateStatus(r.Context(), &updated, payload.Status); err != nil {
+			return err
+		}
+		return tx.Notifications.Create(r.Context(), &notification)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidTransition):
+			utils.WriteProblem(w, r, utils.WrapAppError(http.StatusConflict, utils.CodeInvalidTransition,
+				fmt.Sprintf("A %s application cannot become %s", application.Status, payload.Status), err))
+		case errors.Is(err, store.ErrEditConflict):
+			app.applicationConflictResponse(w, r, application.ID)
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, updated); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// closeApplications closes the gig's undecided applications and tells each applicant why
+func closeApplications(ctx context.Context, tx store.Storage, gig *store.Gig) error {
+	closed, err := tx.Applications.CloseByPostID(ctx, gig.ID)
+	if err != nil {
+		return err
+	}
+	for _, a := range closed {
+		err := tx.Notifications.Create(ctx, &store.Notification{
+			UserID:  a.UserID,
+			Kind:    store.NotificationApplicationStatus,
+			Message: fmt.Sprintf("%q was %s, so your application was closed", gig.Title, gig.Status),
+			Link:    applicationLink(a.ID),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applicationLink(id int64) string {
+	return fmt.Sprintf("/v1/applications/%d", id)
+}
+
+// applicationConflictResponse reports a status change that lost a race, with the application as it is now
+func (app *application) applicationConflictResponse(w http.ResponseWriter, r *http.Request, applicationID int64) {
+	current, err := app.store.Applications.GetByID(r.Context(), applicationID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.NotFoundResponse(w, r, err)
+			return
+		}
+		utils.InternalServerError(w, r, err)
+		return
+	}
+	utils.WriteProblem(w, r, editConflict(current))
+}
+
+// applicationsContextMiddleware loads the application and only lets the applicant and the
+// gig's poster through
+func (app *application) applicationsContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "applicationID"), 10, 64)
+		if err != nil {
+			utils.BadRequestResponse(w, r, err)
+			return
+		}
+
+		userID, err := authenticatedUserID(r)
+		if err != nil {
+			utils.WriteProblem(w, r, err)
+			return
+		}
+
+		application, err := app.store.Applications.GetByID(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				utils.NotFoundResponse(w, r, err)
+			default:
+				utils.InternalServerError(w, r, err)
+			}
+			return
+		}
+		if userID != application.UserID && userID != application.PosterID {
+			utils.ForbiddenResponse(w, r, errors.New("not the applicant or the poster"))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), applicationCtx, application)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getApplicationFromCtx(r *http.Request) *store.Application {
+	application, _ := r.Context().Value(applicationCtx).(*store.Application)
+	return application
+}
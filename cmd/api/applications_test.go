@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplications(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+
+	poster := srv.newClient(t)
+	poster.signUp("producer@example.com")
+	alice := srv.newClient(t)
+	alice.signUp("alice@example.com")
+	bob := srv.newClient(t)
+	bob.signUp("bob@example.com")
+	stranger := srv.newClient(t)
+	stranger.signUp("stranger@example.com")
+
+	resp := poster.do(http.MethodPost, "/v1/gigs/", gigPayload("steadicam_operator"))
+	require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+	var gig store.Gig
+	resp.decode(t, &gig)
+	applicationsPath := fmt.Sprintf("/v1/gigs/%d/applications", gig.ID)
+
+	apply := func(c *testClient) store.Application {
+		t.Helper()
+		resp := c.do(http.MethodPost, applicationsPath, map[string]any{
+			"message":         "Ten years on the rig",
+			"portfolio_links": []string{"https://vimeo.com/reel"},
+		})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		var a store.Application
+		resp.decode(t, &a)
+		return a
+	}
+	notifications := func(c *testClient, query string) []store.Notification {
+		t.Helper()
+		resp := c.do(http.MethodGet, "/v1/notifications/?"+query, nil)
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var n []store.Notification
+		resp.decode(t, &n)
+		return n
+	}
+	setStatus := func(c *testClient, a store.Application, status string) testResponse {
+		t.Helper()
+		return c.do(http.MethodPut, fmt.Sprintf("/v1/applications/%d/status", a.ID), map[string]any{"status": status})
+	}
+
+	aliceApp := apply(alice)
+	bobApp := apply(bob)
+	assert.Equal(t, store.ApplicationSubmitted, aliceApp.Status)
+	assert.Equal(t, gig.Title, aliceApp.PostTitle)
+
+	t.Run("rejects bad applications", func(t *testing.T) {
+		resp := alice.do(http.MethodPost, applicationsPath, map[string]any{"message": "Me again"})
+		assert.Equal(t, http.StatusConflict, resp.status)
+
+		resp = poster.do(http.MethodPost, applicationsPath, map[string]any{"message": "Hire me"})
+		assert.Equal(t, http.StatusForbidden, resp.status)
+
+		resp = stranger.do(http.MethodPost, applicationsPath, map[string]any{"message": "Hi", "portfolio_links": []string{"not a url"}})
+		assert.Equal(t, http.StatusBadRequest, resp.status)
+
+		resp = stranger.do(http.MethodPost, "/v1/gigs/999999/applications", map[string]any{"message": "Hi"})
+		assert.Equal(t, http.StatusNotFound, resp.status)
+	})
+
+	t.Run("poster is notified", func(t *testing.T) {
+		n := notifications(poster, "unread=true")
+		require.Len(t, n, 2)
+		assert.Equal(t, store.NotificationApplicationReceived, n[0].Kind)
+		assert.Equal(t, fmt.Sprintf("/v1/applications/%d", bobApp.ID), n[0].Link)
+
+		resp := poster.do(http.MethodPost, fmt.Sprintf("/v1/notifications/%d/read", n[0].ID), nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		assert.Len(t, notifications(poster, "unread=true"), 1)
+		assert.Len(t, notifications(poster, ""), 2)
+
+		resp = alice.do(http.MethodPost, fmt.Sprintf("/v1/notifications/%d/read", n[1].ID), nil)
+		assert.Equal(t, http.StatusNotFound, resp.status)
+	})
+
+	t.Run("only the poster sees the applicants", func(t *testing.T) {
+		resp := alice.do(http.MethodGet, applicationsPath, nil)
+		assert.Equal(t, http.StatusForbidden, resp.status)
+
+		resp = stranger.do(http.MethodGet, fmt.Sprintf("/v1/applications/%d/", aliceApp.ID), nil)
+		assert.Equal(t, http.StatusForbidden, resp.status)
+		resp = alice.do(http.MethodGet, fmt.Sprintf("/v1/applications/%d/", aliceApp.ID), nil)
+		assert.Equal(t, http.StatusOK, resp.status)
+	})
+
+	t.Run("decisions", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, setStatus(alice, aliceApp, "hired").status)
+		assert.Equal(t, http.StatusForbidden, setStatus(poster, aliceApp, "withdrawn").status)
+
+		resp := setStatus(poster, bobApp, "shortlisted")
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+
+		resp = poster.do(http.MethodGet, applicationsPath, nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		var ranked []store.Application
+		resp.decode(t, &ranked)
+		require.Len(t, ranked, 2)
+		assert.Equal(t, []int64{bobApp.ID, aliceApp.ID}, []int64{ranked[0].ID, ranked[1].ID}, "shortlisted first")
+
+		n := notifications(bob, "")
+		require.Len(t, n, 1)
+		assert.Contains(t, n[0].Message, "shortlisted")
+
+		resp = poster.do(http.MethodGet, applicationsPath+"?status=bogus", nil)
+		assert.Equal(t, http.StatusBadRequest, resp.status)
+	})
+
+	t.Run("filling the gig closes the rest", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, setStatus(poster, bobApp, "hired").status)
+
+		resp := poster.do(http.MethodPut, fmt.Sprintf("/v1/gigs/%d/status", gig.ID), map[string]any{"status": "filled"})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+
+		resp = alice.do(http.MethodGet, "/v1/applications/", nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		var mine []store.Application
+		resp.decode(t, &mine)
+		require.Len(t, mine, 1)
+		assert.Equal(t, store.ApplicationClosed, mine[0].Status)
+		assert.Contains(t, notifications(alice, "")[0].Message, "closed")
+
+		resp = setStatus(poster, aliceApp, "shortlisted")
+		assert.Equal(t, http.StatusConflict, resp.status)
+		assert.Equal(t, "invalid_transition", resp.problem(t)["code"])
+
+		resp = stranger.do(http.MethodPost, applicationsPath, map[string]any{"message": "Too late?"})
+		assert.Equal(t, http.StatusConflict, resp.status)
+		assert.Equal(t, "conflict", resp.problem(t)["code"])
+
+		var hired store.Application
+		resp = bob.do(http.MethodGet, fmt.Sprintf("/v1/applications/%d/", bobApp.ID), nil)
+		resp.decode(t, &hired)
+		assert.Equal(t, store.ApplicationHired, hired.Status)
+	})
+
+	t.Run("withdrawing notifies the poster", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, setStatus(bob, bobApp, "withdrawn").status)
+		n := notifications(poster, "unread=true")
+		require.NotEmpty(t, n)
+		assert.Equal(t, store.NotificationApplicationWithdrawn, n[0].Kind)
+	})
+}
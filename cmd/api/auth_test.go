@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticationFlow(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	c := srv.newClient(t)
+	email := "roger@example.com"
+
+	resp := c.register(email)
+	require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+
+	token := srv.mailer.activationToken(t, email)
+	require.NotEmpty(t, token)
+
+	user, err := srv.app.store.Users.GetByEmail(context.Background(), email)
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+
+	resp = c.activate(token)
+	require.Equal(t, http.StatusNoContent, resp.status, string(resp.body))
+
+	user, err = srv.app.store.Users.GetByEmail(context.Background(), email)
+	require.NoError(t, err)
+	assert.True(t, user.IsActive)
+
+	// The invitation is single use
+	resp = c.activate(token)
+	assert.Equal(t, http.StatusBadRequest, resp.status)
+
+	resp = c.login(email, testPassword)
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	authToken := c.cookie("auth_token")
+	refreshToken := c.cookie("refresh_token")
+	require.NotEmpty(t, authToken)
+	require.NotEmpty(t, refreshToken)
+
+	var me store.User
+	resp = c.do(http.MethodGet, "/v1/users/", nil)
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	resp.decode(t, &me)
+	assert.Equal(t, user.ID, me.ID)
+	assert.Equal(t, "Roger", me.FirstName)
+
+	resp = c.do(http.MethodPost, "/v1/authentication/refresh", nil)
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	assert.NotEqual(t, refreshToken, c.cookie("refresh_token"))
+	assert.NotEmpty(t, c.cookie("auth_token"))
+
+	// Rotation retires the old refresh token
+	stale := srv.newClient(t)
+	stale.client.Jar.SetCookies(mustParseURL(t, srv.URL), []*http.Cookie{{Name: "refresh_token", Value: refreshToken, Path: "/"}})
+	resp = stale.do(http.MethodPost, "/v1/authentication/refresh", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.status)
+
+	resp = c.do(http.MethodPost, "/v1/authentication/logout", nil)
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	assert.Empty(t, c.cookie("auth_token"))
+	assert.Empty(t, c.cookie("refresh_token"))
+
+	resp = c.do(http.MethodGet, "/v1/users/", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.status)
+}
+
+func TestRegisterErrors(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	c := srv.newClient(t)
+
+	resp := c.register("taken@example.com")
+	require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+
+	tests := []struct {
+		name    string
+		payload map[string]any
+		status  int
+		code    string
+	}{
+		{name: "duplicate email", payload: registrationPayload("taken@example.com"), status: http.StatusConflict, code: "duplicate_email"},
+		{name: "invalid email", payload: registrationPayload("not-an-email"), status: http.StatusBadRequest, code: "validation_failed"},
+		{name: "short password", payload: func() map[string]any {
+			p := registrationPayload("short@example.com")
+			p["password"] = "short"
+			return p
+		}(), status: http.StatusBadRequest, code: "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := c.do(http.MethodPost, "/v1/authentication/register", tt.payload)
+			assert.Equal(t, tt.status, resp.status, string(resp.body))
+			assert.Equal(t, tt.code, resp.problem(t)["code"])
+		})
+	}
+}
+
+func TestLoginErrors(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	c := srv.newClient(t)
+	c.signUp("known@example.com")
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		status   int
+	}{
+		{name: "wrong password", email: "known@example.com", password: "wrong-password", status: http.StatusUnauthorized},
+		{name: "unknown user", email: "nobody@example.com", password: testPassword, status: http.StatusUnauthorized},
+		{name: "invalid payload", email: "known", password: testPassword, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := srv.newClient(t).login(tt.email, tt.password)
+			assert.Equal(t, tt.status, resp.status, string(resp.body))
+		})
+	}
+}
@@ -0,0 +1,590 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/michaelhoman/ShotSeek/internal/ical"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+type availabilityKey string
+
+const availabilityCtx availabilityKey = "availability"
+
+// maxCalendarUpload caps an imported .ics file, the same 1MB ReadJSON allows a JSON body
+const maxCalendarUpload = 1 << 20
+
+type CreateAvailabilityPayload struct {
+	Status   store.AvailabilityStatus `json:"status" validate:"required,oneof=available tentative booked"`
+	StartsAt time.Time                `json:"starts_at" validate:"required"`
+	EndsAt   time.Time                `json:"ends_at" validate:"required"`
+	TimeZone string                   `json:"time_zone" validate:"omitempty,timezone,max=64" example:"America/Chicago"` // UTC when left out
+	Note     string                   `json:"note" validate:"max=500"`
+}
+
+type UpdateAvailabilityPayload struct {
+	Status   *store.AvailabilityStatus `json:"status" validate:"omitempty,oneof=available tentative booked"`
+	StartsAt *time.Time                `json:"starts_at"`
+	EndsAt   *time.Time                `json:"ends_at"`
+	TimeZone *string                   `json:"time_zone" validate:"omitempty,timezone,max=64"`
+	Note     *string                   `json:"note" validate:"omitempty,max=500"`
+}
+
+// AvailabilityImport is the outcome of importing a calendar
+type AvailabilityImport struct {
+	Imported []store.AvailabilityBlock `json:"imported"`
+	Skipped  int                       `json:"skipped"` // free, cancelled, empty or already past events
+}
+
+// CalendarFeed is the secret address calendar apps subscribe to
+type CalendarFeed struct {
+	URL string `json:"url" example:"https://api.shotseek.com/v1/calendars/3q2-7wEAAAA.ics"`
+}
+
+// ListAvailability godoc
+//
+//	@Summary		Lists your availability
+//	@Description	Lists the signed in user's availability blocks that overlap from..to, earliest first. Either bound may be left out.
+//	@Tags			availability
+//	@Produce		json
+//	@Param			from	query		string	false	"RFC 3339 timestamp or YYYY-MM-DD date (UTC)"
+//	@Param			to		query		string	false	"RFC 3339 timestamp or YYYY-MM-DD date (UTC), inclusive for a date"
+//	@Success		200		{array}		store.AvailabilityBlock
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/availability [get]
+func (app *application) listAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	var from, to time.Time
+	query := r.URL.Query()
+	if raw := query.Get("from"); raw != "" {
+		if from, err = parseDateParam(raw, false); err != nil {
+			utils.WriteProblem(w, r, utils.InvalidQueryParam("from", "datetime", "must be an RFC 3339 timestamp or a YYYY-MM-DD date"))
+			return
+		}
+	}
+	if raw := query.Get("to"); raw != "" {
+		if to, err = parseDateParam(raw, true); err != nil {
+			utils.WriteProblem(w, r, utils.InvalidQueryParam("to", "datetime", "must be an RFC 3339 timestamp or a YYYY-MM-DD date"))
+			return
+		}
+	}
+
+	blocks, err := app.store.Availability.ListByUser(r.Context(), userID, from, to)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, blocks); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// CreateAvailability godoc
+//
+//	@Summary		Adds an availability block
+//	@Description	Marks a stretch of the signed in user's time as available, tentative or booked. ends_at must be after starts_at; time_zone is the IANA zone the block was entered in.
+//	@Tags			availability
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateAvailabilityPayload	true	"Availability payload"
+//	@Success		201		{object}	store.AvailabilityBlock
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/availability [post]
+func (app *application) createAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateAvailabilityPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.StructCtx(r.Context(), payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	block := store.AvailabilityBlock{
+		UserID:   userID,
+		Status:   payload.Status,
+		StartsAt: payload.StartsAt,
+		EndsAt:   payload.EndsAt,
+		TimeZone: payload.TimeZone,
+		Note:     payload.Note,
+	}
+	if err := normalizeBlock(&block); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	if err := app.store.Availability.Create(r.Context(), &block); err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusCreated, block); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// normalizeBlock checks the block's times and stores them in UTC to the second, the
+// precision of the columns
+func normalizeBlock(block *store.AvailabilityBlock) error {
+	if block.TimeZone == "" {
+		block.TimeZone = "UTC"
+	}
+	block.StartsAt = block.StartsAt.UTC().Truncate(time.Second)
+	block.EndsAt = block.EndsAt.UTC().Truncate(time.Second)
+	if !block.EndsAt.After(block.StartsAt) {
+		return utils.InvalidField("ends_at", "gtfield", "must be after starts_at")
+	}
+	return nil
+}
+
+// GetAvailability godoc
+//
+//	@Summary		Fetches an availability block
+//	@Description	Fetches one of the signed in user's availability blocks.
+//	@Tags			availability
+//	@Produce		json
+//	@Param			id	path		int	true	"Block ID"
+//	@Success		200	{object}	store.AvailabilityBlock
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		403	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/availability/{id} [get]
+func (app *application) getAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	if err := utils.JsonResponse(w, http.StatusOK, getAvailabilityFromCtx(r)); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// UpdateAvailability godoc
+//
+//	@Summary		Updates an availability block
+//	@Description	Changes the fields given of one of the signed in user's availability blocks. An imported block becomes a manual one, so the next import keeps it.
+//	@Tags			availability
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"Block ID"
+//	@Param			payload	body		UpdateAvailabilityPayload	true	"Availability payload"
+//	@Success		200		{object}	store.AvailabilityBlock
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		403		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/availability/{id} [patch]
+func (app *application) updateAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	block := getAvailabilityFromCtx(r)
+
+	var payload UpdateAvailabilityPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.StructCtx(r.Context(), payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.Status != nil {
+		block.Status = *payload.Status
+	}
+	if payload.StartsAt != nil {
+		block.StartsAt = *payload.StartsAt
+	}
+	if payload.EndsAt != nil {
+		block.EndsAt = *payload.EndsAt
+	}
+	if payload.TimeZone != nil {
+		block.TimeZone = *payload.TimeZone
+	}
+	if payload.Note != nil {
+		block.Note = *payload.Note
+	}
+	if err := normalizeBlock(block); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	if err := app.store.Availability.Update(r.Context(), block); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, block); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// DeleteAvailability godoc
+//
+//	@Summary		Deletes an availability block
+//	@Description	Deletes one of the signed in user's availability blocks.
+//	@Tags			availability
+//	@Param			id	path		int	true	"Block ID"
+//	@Success		204	{object}	nil
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		403	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/availability/{id} [delete]
+func (app *application) deleteAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	block := getAvailabilityFromCtx(r)
+
+	if err := app.store.Availability.Delete(r.Context(), block.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportAvailability godoc
+//
+//	@Summary		Imports busy time from a calendar
+//	@Description	Reads an iCalendar (.ics) file, sent as the file field of a multipart form or as a text/calendar body, and marks each of its events as a booked block, or a tentative one for tentative events. Free (transparent), cancelled, empty and past events are skipped, and recurring events only count for their first occurrence. Blocks from the previous import are replaced; blocks added or edited by hand are kept. Times without a zone are read in time_zone.
+//	@Tags			availability
+//	@Accept			mpfd
+//	@Accept			text/calendar
+//	@Produce		json
+//	@Param			file		formData	file	false	"The .ics file"
+//	@Param			time_zone	query		string	false	"IANA zone for times without one"	default(UTC)
+//	@Success		200			{object}	AvailabilityImport
+//	@Failure		400			{object}	utils.Problem
+//	@Failure		401			{object}	utils.Problem
+//	@Failure		413			{object}	utils.Problem
+//	@Failure		415			{object}	utils.Problem
+//	@Failure		500			{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/availability/import [post]
+func (app *application) importAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	loc := time.UTC
+	if raw := r.URL.Query().Get("time_zone"); raw != "" {
+		if loc, err = time.LoadLocation(raw); err != nil || raw == "Local" {
+			utils.WriteProblem(w, r, utils.InvalidQueryParam("time_zone", "timezone", "must be an IANA time zone"))
+			return
+		}
+	}
+
+	file, err := calendarUpload(w, r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	cal, err := ical.Parse(file, loc)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.WriteProblem(w, r, utils.NewAppError(http.StatusRequestEntityTooLarge, utils.CodeBadRequest, "The calendar must be at most 1MB"))
+			return
+		}
+		utils.WriteProblem(w, r, utils.WrapAppError(http.StatusBadRequest, utils.CodeBadRequest, "The file is not a valid iCalendar file", err))
+		return
+	}
+
+	result := AvailabilityImport{Imported: []store.AvailabilityBlock{}}
+	now := time.Now()
+	for _, e := range cal.Events {
+		if e.Transparent || e.Status == ical.StatusCancelled || !e.End.After(e.Start) || !e.End.After(now) {
+			result.Skipped++
+			continue
+		}
+
+		status := store.AvailabilityBooked
+		if e.Status == ical.StatusTentative {
+			status = store.AvailabilityTentative
+		}
+		block := store.AvailabilityBlock{
+			Status:   status,
+			StartsAt: e.Start,
+			EndsAt:   e.End,
+			TimeZone: e.Start.Location().String(),
+			Note:     truncate(e.Summary, 500),
+		}
+		if err := normalizeBlock(&block); err != nil {
+			// Shorter than the columns' one second precision
+			result.Skipped++
+			continue
+		}
+		result.Imported = append(result.Imported, block)
+	}
+
+	if err := app.store.Availability.ReplaceImported(r.Context(), userID, result.Imported); err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, result); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// calendarUpload returns the uploaded calendar, from the file field of a multipart form or
+// from a text/calendar body
+func calendarUpload(w http.ResponseWriter, r *http.Request) (io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		// Leave room for the multipart framing around the file itself
+		r.Body = http.MaxBytesReader(w, r.Body, maxCalendarUpload+64<<10)
+		if err := r.ParseMultipartForm(maxCalendarUpload); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, utils.NewAppError(http.StatusRequestEntityTooLarge, utils.CodeBadRequest, "The calendar must be at most 1MB")
+			}
+			return nil, utils.WrapAppError(http.StatusBadRequest, utils.CodeBadRequest, "The multipart form could not be read", err)
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, utils.InvalidField("file", "required", "is required")
+		}
+		return file, nil
+	case "text/calendar":
+		return http.MaxBytesReader(w, r.Body, maxCalendarUpload), nil
+	default:
+		return nil, utils.NewAppError(http.StatusUnsupportedMediaType, utils.CodeBadRequest, "Send the calendar as multipart/form-data or text/calendar")
+	}
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// CreateCalendarFeed godoc
+//
+//	@Summary		Turns on your calendar feed
+//	@Description	Creates a secret .ics address that calendar apps can subscribe to for the signed in user's availability. The address is only shown now; calling this again replaces it and stops the old one from working. Notes are left out of the feed.
+//	@Tags			availability
+//	@Produce		json
+//	@Success		201	{object}	CalendarFeed
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/availability/feed [post]
+func (app *application) createCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	if err := app.store.Availability.SetFeedToken(r.Context(), userID, hashFeedToken(token)); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	feed := CalendarFeed{URL: fmt.Sprintf("%s://%s/v1/calendars/%s.ics", scheme, r.Host, token)}
+	if err := utils.JsonResponse(w, http.StatusCreated, feed); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// DeleteCalendarFeed godoc
+//
+//	@Summary		Turns off your calendar feed
+//	@Description	Stops the signed in user's secret .ics address from working.
+//	@Tags			availability
+//	@Success		204	{object}	nil
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/availability/feed [delete]
+func (app *application) deleteCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	if err := app.store.Availability.DeleteFeedToken(r.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetCalendarFeed godoc
+//
+//	@Summary		Serves a calendar feed
+//	@Description	Serves a user's availability as iCalendar for calendar apps to subscribe to. The token in the address is the secret; anyone holding it can read the feed until it is replaced or turned off. Tentative blocks are tentative events and available blocks are free (transparent) events.
+//	@Tags			availability
+//	@Produce		text/calendar
+//	@Param			token	path		string	true	"Feed secret"
+//	@Success		200		{string}	string
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Router			/calendars/{token}.ics [get]
+func (app *application) getCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := app.store.Availability.UserIDByFeedToken(r.Context(), hashFeedToken(chi.URLParam(r, "token")))
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	blocks, err := app.store.Availability.ListByUser(r.Context(), userID, time.Time{}, time.Time{})
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	cal := ical.Calendar{ProdID: "-//ShotSeek//Availability//EN", Name: "ShotSeek availability"}
+	for _, b := range blocks {
+		cal.Events = append(cal.Events, blockEvent(b, r.Host))
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if err := ical.Encode(w, cal); err != nil {
+		utils.Logger.Errorw("writing calendar feed", "error", err)
+	}
+}
+
+// blockEvent turns a block into a feed event whose UID stays the same across edits
+func blockEvent(b store.AvailabilityBlock, host string) ical.Event {
+	e := ical.Event{
+		UID:     fmt.Sprintf("availability-%d@%s", b.ID, host),
+		Summary: "Booked",
+		Start:   b.StartsAt,
+		End:     b.EndsAt,
+		Status:  ical.StatusConfirmed,
+		Stamp:   b.UpdatedAt,
+	}
+	switch b.Status {
+	case store.AvailabilityTentative:
+		e.Summary, e.Status = "Tentative", ical.StatusTentative
+	case store.AvailabilityAvailable:
+		e.Summary, e.Transparent = "Available", true
+	}
+	return e
+}
+
+func hashFeedToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// availabilityContextMiddleware loads the block and only lets its owner through
+func (app *application) availabilityContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "blockID"), 10, 64)
+		if err != nil {
+			utils.BadRequestResponse(w, r, err)
+			return
+		}
+
+		userID, err := authenticatedUserID(r)
+		if err != nil {
+			utils.WriteProblem(w, r, err)
+			return
+		}
+
+		block, err := app.store.Availability.GetByID(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				utils.NotFoundResponse(w, r, err)
+			default:
+				utils.InternalServerError(w, r, err)
+			}
+			return
+		}
+		if block.UserID != userID {
+			utils.ForbiddenResponse(w, r, errors.New("not the block's owner"))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), availabilityCtx, block)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getAvailabilityFromCtx(r *http.Request) *store.AvailabilityBlock {
+	block, _ := r.Context().Value(availabilityCtx).(*store.AvailabilityBlock)
+	return block
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/ical"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvailability(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+
+	owner := srv.newClient(t)
+	owner.signUp("owner@example.com")
+	other := srv.newClient(t)
+	other.signUp("other@example.com")
+
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 1, 0)
+	create := func(status string, from, to time.Time) store.AvailabilityBlock {
+		t.Helper()
+		resp := owner.do(http.MethodPost, "/v1/availability", map[string]any{
+			"status": status, "starts_at": from, "ends_at": to, "time_zone": "America/Chicago", "note": "Client shoot",
+		})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		var block store.AvailabilityBlock
+		resp.decode(t, &block)
+		return block
+	}
+
+	var booked store.AvailabilityBlock
+	t.Run("create and list", func(t *testing.T) {
+		booked = create("booked", day.Add(9*time.Hour), day.Add(17*time.Hour))
+		assert.Equal(t, store.AvailabilityManual, booked.Source)
+		assert.Equal(t, "America/Chicago", booked.TimeZone)
+		create("tentative", day.AddDate(0, 0, 3), day.AddDate(0, 0, 4))
+
+		resp := owner.do(http.MethodGet, "/v1/availability", nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		var blocks []store.AvailabilityBlock
+		resp.decode(t, &blocks)
+		require.Len(t, blocks, 2)
+		assert.Equal(t, booked.ID, blocks[0].ID)
+
+		resp = owner.do(http.MethodGet, "/v1/availability?from="+day.AddDate(0, 0, 1).Format(time.DateOnly), nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		resp.decode(t, &blocks)
+		assert.Len(t, blocks, 1)
+	})
+
+	t.Run("rejects bad blocks", func(t *testing.T) {
+		for _, body := range []map[string]any{
+			{"status": "busy", "starts_at": day, "ends_at": day.Add(time.Hour)},
+			{"status": "booked", "starts_at": day, "ends_at": day},
+			{"status": "booked", "starts_at": day, "ends_at": day.Add(-time.Hour)},
+			{"status": "booked", "starts_at": day, "ends_at": day.Add(time.Hour), "time_zone": "Mars/Olympus"},
+		} {
+			resp := owner.do(http.MethodPost, "/v1/availability", body)
+			assert.Equal(t, http.StatusBadRequest, resp.status, body)
+		}
+	})
+
+	t.Run("only the owner sees and edits a block", func(t *testing.T) {
+		path := fmt.Sprintf("/v1/availability/%d", booked.ID)
+		assert.Equal(t, http.StatusForbidden, other.do(http.MethodGet, path, nil).status)
+		assert.Equal(t, http.StatusForbidden, other.do(http.MethodDelete, path, nil).status)
+
+		resp := owner.do(http.MethodPatch, path, map[string]any{"status": "tentative", "note": "On hold"})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var updated store.AvailabilityBlock
+		resp.decode(t, &updated)
+		assert.Equal(t, store.AvailabilityTentative, updated.Status)
+		assert.Equal(t, "On hold", updated.Note)
+		assert.True(t, booked.StartsAt.Equal(updated.StartsAt))
+
+		resp = owner.do(http.MethodPatch, path, map[string]any{"ends_at": booked.StartsAt.Add(-time.Hour)})
+		assert.Equal(t, http.StatusBadRequest, resp.status)
+
+		resp = owner.do(http.MethodPatch, path, map[string]any{"status": "booked"})
+		require.Equal(t, http.StatusOK, resp.status)
+	})
+
+	t.Run("import replaces the previous import", func(t *testing.T) {
+		calendar := func(events ...string) string {
+			return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+		}
+		event := func(uid string, start time.Time, extra string) string {
+			return "BEGIN:VEVENT\r\nUID:" + uid + "\r\nDTSTART:" + start.Format("20060102T150405Z") +
+				"\r\nDURATION:PT4H\r\nSUMMARY:" + uid + "\r\n" + extra + "END:VEVENT\r\n"
+		}
+		first := calendar(
+			event("shoot", day.AddDate(0, 0, 7), ""),
+			event("maybe", day.AddDate(0, 0, 8), "STATUS:TENTATIVE\r\n"),
+			event("lunch", day.AddDate(0, 0, 9), "TRANSP:TRANSPARENT\r\n"),
+			event("called off", day.AddDate(0, 0, 10), "STATUS:CANCELLED\r\n"),
+			event("last year", day.AddDate(-1, 0, 0), ""),
+		)
+
+		resp := owner.doWithHeader(http.MethodPost, "/v1/availability/import", strings.NewReader(first), http.Header{"Content-Type": {"text/calendar"}})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var result AvailabilityImport
+		resp.decode(t, &result)
+		require.Len(t, result.Imported, 2)
+		assert.Equal(t, 3, result.Skipped)
+		assert.Equal(t, store.AvailabilityBooked, result.Imported[0].Status)
+		assert.Equal(t, store.AvailabilityTentative, result.Imported[1].Status)
+		assert.Equal(t, store.AvailabilityImported, result.Imported[0].Source)
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		file, err := form.CreateFormFile("file", "calendar.ics")
+		require.NoError(t, err)
+		file.Write([]byte(calendar(event("reshoot", day.AddDate(0, 0, 12), ""))))
+		require.NoError(t, form.Close())
+
+		resp = owner.doWithHeader(http.MethodPost, "/v1/availability/import", &body, http.Header{"Content-Type": {form.FormDataContentType()}})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+
+		resp = owner.do(http.MethodGet, "/v1/availability", nil)
+		var blocks []store.AvailabilityBlock
+		resp.decode(t, &blocks)
+		notes := []string{}
+		for _, b := range blocks {
+			notes = append(notes, b.Note)
+		}
+		assert.Equal(t, []string{"On hold", "Client shoot", "reshoot"}, notes, "manual blocks are kept")
+
+		resp = owner.doWithHeader(http.MethodPost, "/v1/availability/import", strings.NewReader("not a calendar"), http.Header{"Content-Type": {"text/calendar"}})
+		assert.Equal(t, http.StatusBadRequest, resp.status)
+		resp = owner.doWithHeader(http.MethodPost, "/v1/availability/import", strings.NewReader(first), http.Header{"Content-Type": {"text/plain"}})
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.status)
+	})
+
+	t.Run("feed", func(t *testing.T) {
+		resp := owner.do(http.MethodPost, "/v1/availability/feed", nil)
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		var feed CalendarFeed
+		resp.decode(t, &feed)
+		path := mustParseURL(t, feed.URL).Path
+
+		resp = srv.newClient(t).do(http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		assert.Equal(t, "text/calendar; charset=utf-8", resp.header.Get("Content-Type"))
+		assert.NotContains(t, string(resp.body), "Client shoot", "notes stay private")
+
+		cal, err := ical.Parse(bytes.NewReader(resp.body), time.UTC)
+		require.NoError(t, err)
+		require.Len(t, cal.Events, 3)
+		assert.True(t, cal.Events[0].Start.Equal(booked.StartsAt))
+		assert.Equal(t, ical.StatusTentative, cal.Events[1].Status)
+
+		// Rotating the secret retires the old address
+		resp = owner.do(http.MethodPost, "/v1/availability/feed", nil)
+		require.Equal(t, http.StatusCreated, resp.status)
+		assert.Equal(t, http.StatusNotFound, owner.do(http.MethodGet, path, nil).status)
+
+		resp.decode(t, &feed)
+		require.Equal(t, http.StatusNoContent, owner.do(http.MethodDelete, "/v1/availability/feed", nil).status)
+		assert.Equal(t, http.StatusNotFound, owner.do(http.MethodGet, mustParseURL(t, feed.URL).Path, nil).status)
+		assert.Equal(t, http.StatusNotFound, owner.do(http.MethodDelete, "/v1/availability/feed", nil).status)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		path := fmt.Sprintf("/v1/availability/%d", booked.ID)
+		require.Equal(t, http.StatusNoContent, owner.do(http.MethodDelete, path, nil).status)
+		assert.Equal(t, http.StatusNotFound, owner.do(http.MethodGet, path, nil).status)
+	})
+}
+
+func TestSearchCinematographersByAvailability(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+
+	signUp := func(email, headline string) *testClient {
+		c := srv.newClient(t)
+		payload := registrationPayload(email)
+		payload["city"], payload["state"], payload["zip_code"] = "Kansas City", "MO", "64105"
+		payload["latitude"], payload["longitude"] = 39.1, -94.58
+		c.signUpWith(payload)
+		resp := c.do(http.MethodPut, "/v1/profiles/me", map[string]any{"headline": headline})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		return c
+	}
+	free := signUp("free@example.com", "Free")
+	busy := signUp("busy@example.com", "Busy")
+	held := signUp("held@example.com", "Held")
+
+	day := time.Date(2031, 6, 10, 0, 0, 0, 0, time.UTC)
+	block := func(c *testClient, status string, from, to time.Time) {
+		t.Helper()
+		resp := c.do(http.MethodPost, "/v1/availability", map[string]any{"status": status, "starts_at": from, "ends_at": to})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+	}
+	block(busy, "booked", day.Add(14*time.Hour), day.Add(20*time.Hour))
+	block(held, "tentative", day, day.AddDate(0, 0, 1))
+	block(free, "available", day, day.AddDate(0, 0, 1))
+
+	search := func(from, to string) []string {
+		t.Helper()
+		query := url.Values{"zip": {"64105"}, "available_from": {from}, "available_to": {to}}
+		resp := free.do(http.MethodGet, "/v1/cinematographers/search?"+query.Encode(), nil)
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var found []Cinematographer
+		resp.decode(t, &found)
+		headlines := []string{}
+		for _, c := range found {
+			headlines = append(headlines, c.Headline)
+		}
+		return headlines
+	}
+
+	assert.ElementsMatch(t, []string{"Free", "Held"}, search("2031-06-10", "2031-06-10"))
+	assert.ElementsMatch(t, []string{"Free", "Held"}, search("2031-06-08", "2031-06-12"))
+	assert.ElementsMatch(t, []string{"Free", "Busy", "Held"}, search("2031-06-11", "2031-06-12"))
+
+	for _, query := range []string{
+		"available_from=2031-06-10",
+		"available_from=2031-06-10&available_to=2031-06-09",
+		"available_from=2031-06-10&available_to=2033-06-10",
+		"available_from=June&available_to=2031-06-10",
+	} {
+		resp := free.do(http.MethodGet, "/v1/cinematographers/search?zip=64105&"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.status, query)
+	}
+}
@@ -0,0 +1,746 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+type bookingKey string
+
+const bookingCtx bookingKey = "booking"
+
+const (
+	// defaultHoldTTL is how long a hold lasts when the producer does not say
+	defaultHoldTTL = 7 * 24 * time.Hour
+	// maxHoldTTL keeps a hold from tying up a cinematographer's dates indefinitely
+	maxHoldTTL = 30 * 24 * time.Hour
+)
+
+type CreateBookingPayload struct {
+	CinematographerID uuid.UUID         `json:"cinematographer_id" validate:"required"`
+	Kind              store.BookingKind `json:"kind" validate:"required,oneof=request hold"`
+	StartsAt          time.Time         `json:"starts_at" validate:"required"`
+	EndsAt            time.Time         `json:"ends_at" validate:"required"`
+	TimeZone          string            `json:"time_zone" validate:"omitempty,timezone,max=64" example:"America/Chicago"` // UTC when left out
+	Rate              int               `json:"rate" validate:"gte=0"`
+	Currency          string            `json:"currency" validate:"omitempty,iso4217" example:"USD"` // USD when left out
+	ZIPCode           string            `json:"zip_code" validate:"required,max=10"`
+	Message           string            `json:"message" validate:"max=2000"`
+	ExpiresAt         *time.Time        `json:"expires_at"` // holds only; a week out when left out, at most 30 days
+}
+
+type BookingActionPayload struct {
+	Action   string     `json:"action" validate:"required,oneof=accept decline counter confirm cancel"`
+	StartsAt *time.Time `json:"starts_at"`                       // counter only
+	EndsAt   *time.Time `json:"ends_at"`                         // counter only
+	Rate     *int       `json:"rate" validate:"omitempty,gte=0"` // counter only
+	Note     string     `json:"note" validate:"max=2000"`
+}
+
+// bookingActions gives the audit trail entry and the notification verb for each action
+var bookingActions = map[string]string{
+	"accept":  "accepted",
+	"decline": "declined",
+	"counter": "countered",
+	"confirm": "confirmed",
+	"cancel":  "cancelled",
+}
+
+// CreateBooking godoc
+//
+//	@Summary		Requests a booking or places a hold
+//	@Description	Asks a cinematographer for their time at a rate and general location. A request books the dates once accepted. A hold pencils them in once accepted and lasts until the producer confirms it, either side cancels it, or expires_at passes. Holds on the same dates are ranked in the order they were placed (hold_rank). The cinematographer is notified.
+//	@Tags			bookings
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateBookingPayload	true	"Booking payload"
+//	@Success		201		{object}	store.Booking
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		403		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Failure		502		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/bookings [post]
+func (app *application) createBookingHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateBookingPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.StructCtx(r.Context(), payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	if userID == payload.CinematographerID {
+		utils.WriteProblem(w, r, utils.NewAppError(http.StatusForbidden, utils.CodeForbidden, "You cannot book yourself"))
+		return
+	}
+
+	booking := store.Booking{
+		ProducerID:        userID,
+		CinematographerID: payload.CinematographerID,
+		Kind:              payload.Kind,
+		StartsAt:          payload.StartsAt,
+		EndsAt:            payload.EndsAt,
+		TimeZone:          payload.TimeZone,
+		Rate:              payload.Rate,
+		Currency:          payload.Currency,
+		Message:           payload.Message,
+	}
+	if booking.Currency == "" {
+		booking.Currency = "USD"
+	}
+	now := time.Now()
+	if err := normalizeBooking(&booking, now); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	if err := setHoldExpiry(&booking, payload.ExpiresAt, now); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	if _, err := app.store.Profiles.GetByUserID(r.Context(), payload.CinematographerID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.WriteProblem(w, r, utils.NewAppError(http.StatusNotFound, utils.CodeNotFound, "No cinematographer with that ID"))
+			return
+		}
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if booking.Location, err = app.lookupByZip(r.Context(), payload.ZIPCode); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	var created *store.Booking
+	err = app.store.WithTx(r.Context(), func(tx store.Storage) error {
+		if err := tx.Bookings.Create(r.Context(), &booking); err != nil {
+			return err
+		}
+		if created, err = tx.Bookings.GetByID(r.Context(), booking.ID); err != nil {
+			return err
+		}
+		return recordBookingEvent(r.Context(), tx, created, "", &userID, "requested", payload.Message)
+	})
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusCreated, created); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// normalizeBooking checks the booking's times and stores them in UTC to the second, the
+// precision of the columns. Bookings are for time still to come.
+func normalizeBooking(b *store.Booking, now time.Time) error {
+	if b.TimeZone == "" {
+		b.TimeZone = "UTC"
+	}
+	b.StartsAt = b.StartsAt.UTC().Truncate(time.Second)
+	b.EndsAt = b.EndsAt.UTC().Truncate(time.Second)
+	if !b.StartsAt.After(now) {
+		return utils.InvalidField("starts_at", "gt", "must be in the future")
+	}
+	if !b.EndsAt.After(b.StartsAt) {
+		return utils.InvalidField("ends_at", "gtfield", "must be after starts_at")
+	}
+	return nil
+}
+
+// setHoldExpiry gives a hold its expiry, a week out unless the producer picked one
+func setHoldExpiry(b *store.Booking, expiresAt *time.Time, now time.Time) error {
+	if b.Kind != store.BookingHold {
+		if expiresAt != nil {
+			return utils.InvalidField("expires_at", "excluded_unless", "only applies to holds")
+		}
+		return nil
+	}
+
+	expiry := now.Add(defaultHoldTTL)
+	if expiresAt != nil {
+		expiry = *expiresAt
+	}
+	expiry = expiry.UTC().Truncate(time.Second)
+	switch {
+	case !expiry.After(now):
+		return utils.InvalidField("expires_at", "gt", "must be in the future")
+	case expiry.After(now.Add(maxHoldTTL)):
+		return utils.InvalidField("expires_at", "lte", "must be at most 30 days away")
+	}
+	b.ExpiresAt = &expiry
+	return nil
+}
+
+// ListBookings godoc
+//
+//	@Summary		Lists your bookings
+//	@Description	Lists bookings the signed in user sent as a producer or received as a cinematographer, newest first. role keeps one side; status keeps one status. Follow next_cursor (also sent as a Link header) for the next page, keeping the same filters.
+//	@Tags			bookings
+//	@Produce		json
+//	@Param			role	query		string	false	"Only bookings you sent (producer) or received (cinematographer)"	Enums(producer, cinematographer)
+//	@Param			status	query		string	false	"Only bookings in this status"										Enums(pending, countered, held, accepted, declined, cancelled, expired)
+//	@Param			limit	query		int		false	"Page size, at most 100"											default(20)
+//	@Param			cursor	query		string	false	"next_cursor from the previous page"
+//	@Success		200		{array}		store.Booking
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/bookings [get]
+func (app *application) listBookingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	filter := store.BookingFilter{Page: page}
+	query := r.URL.Query()
+	switch role := store.BookingRole(query.Get("role")); role {
+	case "", store.BookingAsProducer, store.BookingAsCinematographer:
+		filter.Role = role
+	default:
+		utils.WriteProblem(w, r, utils.InvalidQueryParam("role", "oneof", "must be one of: producer cinematographer"))
+		return
+	}
+	switch status := store.BookingStatus(query.Get("status")); status {
+	case "", store.BookingPending, store.BookingCountered, store.BookingHeld, store.BookingAccepted,
+		store.BookingDeclined, store.BookingCancelled, store.BookingExpired:
+		filter.Status = status
+	default:
+		utils.WriteProblem(w, r, utils.InvalidQueryParam("status", "oneof", "must be one of: pending countered held accepted declined cancelled expired"))
+		return
+	}
+
+	bookings, err := app.store.Bookings.ListByUser(r.Context(), userID, filter)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := pagination.Write(w, r, bookings); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// GetBooking godoc
+//
+//	@Summary		Fetches a booking
+//	@Description	Fetches a booking with its current terms and, for an open hold, its rank. Only the producer and the cinematographer can see it.
+//	@Tags			bookings
+//	@Produce		json
+//	@Param			id	path		int	true	"Booking ID"
+//	@Success		200	{object}	store.Booking
+//	@Failure		400	{object}	utils.Problem
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		403	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{id} [get]
+func (app *application) getBookingHandler(w http.ResponseWriter, r *http.Request) {
+	if err := utils.JsonResponse(w, http.StatusOK, getBookingFromCtx(r)); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// ListBookingEvents godoc
+//
+//	@Summary		Lists a booking's history
+//	@Description	Lists every change to a booking, oldest first: who made it, the status it moved between and the terms it left. actor_id is null for holds that expired.
+//	@Tags			bookings
+//	@Produce		json
+//	@Param			id	path		int	true	"Booking ID"
+//	@Success		200	{array}		store.BookingEvent
+//	@Failure		400	{object}	utils.Problem
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		403	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{id}/events [get]
+func (app *application) listBookingEventsHandler(w http.ResponseWriter, r *http.Request) {
+	events, err := app.store.Bookings.ListEvents(r.Context(), getBookingFromCtx(r).ID)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, events); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// BookingAction godoc
+//
+//	@Summary		Responds to a booking
+//	@Description	Moves a booking along. While it is pending the cinematographer can accept, decline or counter with new starts_at, ends_at or rate; a counter hands the same choice to the producer, and so on. Accepting a request books the dates; accepting a hold marks them tentative until the producer confirms it, which only the first hold on those dates can do. The producer can cancel at any point; the cinematographer declines a pending booking and can cancel it after that. Acting on someone else's turn gets 403, an action the booking's status does not allow gets 409 invalid_transition, and a change that races another gets 409 edit_conflict with the booking as it is now. The other party is notified.
+//	@Tags			bookings
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Booking ID"
+//	@Param			payload	body		BookingActionPayload	true	"Action"
+//	@Success		200		{object}	store.Booking
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		403		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		409		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{id}/actions [post]
+func (app *application) bookingActionHandler(w http.ResponseWriter, r *http.Request) {
+	booking := getBookingFromCtx(r)
+
+	var payload BookingActionPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.StructCtx(r.Context(), payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	if booking.DueToExpire(now) {
+		if err := app.expireBooking(r.Context(), booking); err != nil && !errors.Is(err, store.ErrEditConflict) {
+			utils.InternalServerError(w, r, err)
+			return
+		}
+		utils.WriteProblem(w, r, utils.NewAppError(http.StatusConflict, utils.CodeInvalidTransition, "This hold has expired"))
+		return
+	}
+
+	updated := *booking
+	if err := applyBookingAction(&updated, userID, payload, now); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	var saved *store.Booking
+	err = app.store.WithTx(r.Context(), func(tx store.Storage) error {
+		if updated.Status == store.BookingAccepted {
+			if err := checkNotDoubleBooked(r.Context(), tx, &updated); err != nil {
+				return err
+			}
+		}
+		changed := updated
+		if err := saveBookingChange(r.Context(), tx, booking.Status, &changed, &userID, bookingActions[payload.Action], payload.Note); err != nil {
+			return err
+		}
+		saved, err = tx.Bookings.GetByID(r.Context(), booking.ID)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrEditConflict):
+			app.bookingConflictResponse(w, r, booking.ID)
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		case errors.As(err, new(*utils.AppError)):
+			utils.WriteProblem(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, saved); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// applyBookingAction moves b to the status the action leads to, with a counter's new terms,
+// after checking it is userID's move to make
+func applyBookingAction(b *store.Booking, userID uuid.UUID, payload BookingActionPayload, now time.Time) error {
+	if payload.Action != "counter" {
+		switch {
+		case payload.StartsAt != nil:
+			return utils.InvalidField("starts_at", "excluded_unless", "only applies to a counter")
+		case payload.EndsAt != nil:
+			return utils.InvalidField("ends_at", "excluded_unless", "only applies to a counter")
+		case payload.Rate != nil:
+			return utils.InvalidField("rate", "excluded_unless", "only applies to a counter")
+		}
+	}
+
+	isProducer := userID == b.ProducerID
+	var next store.BookingStatus
+	switch payload.Action {
+	case "accept", "decline", "counter":
+		var awaiting uuid.UUID
+		switch b.Status {
+		case store.BookingPending:
+			awaiting = b.CinematographerID
+		case store.BookingCountered:
+			awaiting = b.ProducerID
+		default:
+			return invalidBookingTransition(b, payload.Action)
+		}
+		if userID != awaiting {
+			return utils.NewAppError(http.StatusForbidden, utils.CodeForbidden, "It is the other party's turn to respond")
+		}
+
+		switch payload.Action {
+		case "accept":
+			next = store.BookingAccepted
+			if b.Kind == store.BookingHold {
+				next = store.BookingHeld
+			}
+		case "decline":
+			next = store.BookingDeclined
+		case "counter":
+			next = store.BookingPending
+			if b.Status == store.BookingPending {
+				next = store.BookingCountered
+			}
+			if err := applyCounter(b, payload, now); err != nil {
+				return err
+			}
+		}
+	case "confirm":
+		if !isProducer {
+			return utils.NewAppError(http.StatusForbidden, utils.CodeForbidden, "Only the producer can confirm a hold")
+		}
+		if b.Status != store.BookingHeld {
+			return invalidBookingTransition(b, payload.Action)
+		}
+		if b.HoldRank > 1 {
+			return utils.NewAppError(http.StatusConflict, utils.CodeConflict,
+				fmt.Sprintf("This is hold #%d on these dates; only the first hold can be confirmed", b.HoldRank))
+		}
+		next = store.BookingAccepted
+	case "cancel":
+		// The cinematographer answers a request by declining it rather than cancelling
+		if !isProducer && b.Status == store.BookingPending {
+			return utils.NewAppError(http.StatusConflict, utils.CodeInvalidTransition, "Decline a pending booking instead of cancelling it")
+		}
+		next = store.BookingCancelled
+	}
+
+	if !b.Status.CanBecome(next) {
+		return invalidBookingTransition(b, payload.Action)
+	}
+	b.Status = next
+	return nil
+}
+
+// applyCounter swaps in the counter's terms, which must change at least one of them
+func applyCounter(b *store.Booking, payload BookingActionPayload, now time.Time) error {
+	if payload.StartsAt == nil && payload.EndsAt == nil && payload.Rate == nil {
+		return utils.InvalidField("action", "counter", "a counter needs a new starts_at, ends_at or rate")
+	}
+	if payload.StartsAt != nil {
+		b.StartsAt = *payload.StartsAt
+	}
+	if payload.EndsAt != nil {
+		b.EndsAt = *payload.EndsAt
+	}
+	if payload.Rate != nil {
+		b.Rate = *payload.Rate
+	}
+	return normalizeBooking(b, now)
+}
+
+func invalidBookingTransition(b *store.Booking, action string) error {
+	return utils.NewAppError(http.StatusConflict, utils.CodeInvalidTransition,
+		fmt.Sprintf("A %s %s cannot be %s", b.Status, bookingNoun(b), bookingActions[action]))
+}
+
+// checkNotDoubleBooked refuses to book dates the cinematographer already has booked, other
+// than by this booking. It locks the cinematographer's calendar first, so it must run in the
+// transaction that books the dates.
+func checkNotDoubleBooked(ctx context.Context, tx store.Storage, b *store.Booking) error {
+	if err := tx.Availability.LockCalendar(ctx, b.CinematographerID); err != nil {
+		return err
+	}
+	blocks, err := tx.Availability.ListByUser(ctx, b.CinematographerID, b.StartsAt, b.EndsAt)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		if block.Status == store.AvailabilityBooked && (b.AvailabilityBlockID == nil || block.ID != *b.AvailabilityBlockID) {
+			return utils.NewAppError(http.StatusConflict, utils.CodeConflict, "The cinematographer is already booked during these dates")
+		}
+	}
+	return nil
+}
+
+// saveBookingChange saves a booking that moved from one status to another: it keeps the
+// cinematographer's calendar in step, adds to the audit trail and notifies the parties
+func saveBookingChange(ctx context.Context, tx store.Storage, from store.BookingStatus, b *store.Booking, actorID *uuid.UUID, action, note string) error {
+	if err := syncBookingBlock(ctx, tx, b); err != nil {
+		return err
+	}
+	if err := tx.Bookings.Update(ctx, b); err != nil {
+		return err
+	}
+	return recordBookingEvent(ctx, tx, b, from, actorID, action, note)
+}
+
+// syncBookingBlock puts a held booking on the cinematographer's calendar as tentative and an
+// accepted one as booked, and takes it off again once it falls through
+func syncBookingBlock(ctx context.Context, tx store.Storage, b *store.Booking) error {
+	var status store.AvailabilityStatus
+	switch b.Status {
+	case store.BookingHeld:
+		status = store.AvailabilityTentative
+	case store.BookingAccepted:
+		status = store.AvailabilityBooked
+	case store.BookingDeclined, store.BookingCancelled, store.BookingExpired:
+		if b.AvailabilityBlockID != nil {
+			if err := tx.Availability.Delete(ctx, *b.AvailabilityBlockID); err != nil && !errors.Is(err, store.ErrNotFound) {
+				return err
+			}
+			b.AvailabilityBlockID = nil
+		}
+		return nil
+	default:
+		return nil
+	}
+
+	block := store.AvailabilityBlock{
+		UserID:   b.CinematographerID,
+		Status:   status,
+		StartsAt: b.StartsAt,
+		EndsAt:   b.EndsAt,
+		TimeZone: b.TimeZone,
+		Note:     fmt.Sprintf("%s for %s %s", bookingNoun(b), b.Producer.FirstName, b.Producer.LastName),
+		Source:   store.AvailabilityBooking,
+	}
+	if b.AvailabilityBlockID != nil {
+		block.ID = *b.AvailabilityBlockID
+		err := tx.Availability.Update(ctx, &block)
+		if !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+		// The cinematographer deleted the block; put it back
+	}
+	if err := tx.Availability.Create(ctx, &block); err != nil {
+		return err
+	}
+	b.AvailabilityBlockID = &block.ID
+	return nil
+}
+
+// recordBookingEvent adds to the booking's audit trail and notifies whoever did not act, or
+// both parties when the system acted
+func recordBookingEvent(ctx context.Context, tx store.Storage, b *store.Booking, from store.BookingStatus, actorID *uuid.UUID, action, note string) error {
+	event := store.BookingEvent{
+		BookingID:  b.ID,
+		ActorID:    actorID,
+		Action:     action,
+		FromStatus: from,
+		ToStatus:   b.Status,
+		StartsAt:   b.StartsAt,
+		EndsAt:     b.EndsAt,
+		Rate:       b.Rate,
+		Note:       note,
+	}
+	if err := tx.Bookings.AddEvent(ctx, &event); err != nil {
+		return err
+	}
+
+	for _, n := range bookingNotifications(b, actorID, action) {
+		if err := tx.Notifications.Create(ctx, &n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bookingNotifications(b *store.Booking, actorID *uuid.UUID, action string) []store.Notification {
+	link := bookingLink(b.ID)
+	if actorID == nil {
+		message := fmt.Sprintf("The hold %s placed with %s for %s expired",
+			fullName(b.Producer), fullName(b.Cinematographer), bookingDates(b))
+		return []store.Notification{
+			{UserID: b.ProducerID, Kind: store.NotificationBookingExpired, Message: message, Link: link},
+			{UserID: b.CinematographerID, Kind: store.NotificationBookingExpired, Message: message, Link: link},
+		}
+	}
+
+	actor, recipient := b.Producer, b.CinematographerID
+	if *actorID == b.CinematographerID {
+		actor, recipient = b.Cinematographer, b.ProducerID
+	}
+	if action == "requested" {
+		message := fmt.Sprintf("%s sent you a booking request for %s", fullName(actor), bookingDates(b))
+		if b.Kind == store.BookingHold {
+			message = fmt.Sprintf("%s placed a hold on %s", fullName(actor), bookingDates(b))
+		}
+		return []store.Notification{{UserID: recipient, Kind: store.NotificationBookingRequested, Message: message, Link: link}}
+	}
+	return []store.Notification{{
+		UserID:  recipient,
+		Kind:    store.NotificationBookingUpdated,
+		Message: fmt.Sprintf("%s %s the %s for %s", fullName(actor), action, bookingNoun(b), bookingDates(b)),
+		Link:    link,
+	}}
+}
+
+func fullName(u store.User) string {
+	return u.FirstName + " " + u.LastName
+}
+
+func bookingNoun(b *store.Booking) string {
+	if b.Kind == store.BookingHold {
+		return "hold"
+	}
+	return "booking request"
+}
+
+// bookingDates says when a booking runs, in its own time zone
+func bookingDates(b *store.Booking) string {
+	loc, err := time.LoadLocation(b.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start, last := b.StartsAt.In(loc), b.EndsAt.Add(-time.Second).In(loc)
+	if start.Format(time.DateOnly) == last.Format(time.DateOnly) {
+		return start.Format("Jan 2, 2006")
+	}
+	return start.Format("Jan 2, 2006") + " to " + last.Format("Jan 2, 2006")
+}
+
+func bookingLink(id int64) string {
+	return fmt.Sprintf("/v1/bookings/%d", id)
+}
+
+// expireBooking expires a hold whose time ran out and tells both parties
+func (app *application) expireBooking(ctx context.Context, b *store.Booking) error {
+	return app.store.WithTx(ctx, func(tx store.Storage) error {
+		expired := *b
+		expired.Status = store.BookingExpired
+		return saveBookingChange(ctx, tx, b.Status, &expired, nil, "expired", "")
+	})
+}
+
+// expireHolds expires every hold whose time ran out by now, returning how many it expired.
+// A hold that changed since it was read is left for the next sweep.
+func (app *application) expireHolds(ctx context.Context, now time.Time) (int, error) {
+	due, err := app.store.Bookings.ListDueToExpire(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range due {
+		if err := app.expireBooking(ctx, &due[i]); err != nil {
+			if errors.Is(err, store.ErrEditConflict) {
+				continue
+			}
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// expireHoldsEvery sweeps up lapsed holds on every tick until ctx is done
+func (app *application) expireHoldsEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := app.expireHolds(ctx, now); err != nil && ctx.Err() == nil {
+				utils.Logger.Warnw("expiring booking holds failed", "error", err)
+			}
+		}
+	}
+}
+
+// bookingConflictResponse reports a change that lost a race, with the booking as it is now
+func (app *application) bookingConflictResponse(w http.ResponseWriter, r *http.Request, bookingID int64) {
+	current, err := app.store.Bookings.GetByID(r.Context(), bookingID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.NotFoundResponse(w, r, err)
+			return
+		}
+		utils.InternalServerError(w, r, err)
+		return
+	}
+	utils.WriteProblem(w, r, editConflict(current))
+}
+
+// bookingContextMiddleware loads the booking and only lets the producer and the
+// cinematographer through
+func (app *application) bookingContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "bookingID"), 10, 64)
+		if err != nil {
+			utils.BadRequestResponse(w, r, err)
+			return
+		}
+
+		userID, err := authenticatedUserID(r)
+		if err != nil {
+			utils.WriteProblem(w, r, err)
+			return
+		}
+
+		booking, err := app.store.Bookings.GetByID(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				utils.NotFoundResponse(w, r, err)
+			default:
+				utils.InternalServerError(w, r, err)
+			}
+			return
+		}
+		if userID != booking.ProducerID && userID != booking.CinematographerID {
+			utils.ForbiddenResponse(w, r, errors.New("not the producer or the cinematographer"))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), bookingCtx, booking)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getBookingFromCtx(r *http.Request) *store.Booking {
+	booking, _ := r.Context().Value(bookingCtx).(*store.Booking)
+	return booking
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookings(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	srv.app.geocoder.(*fakeGeocoder).locations["90028"] = store.Location{
+		City: "Los Angeles", State: "CA", ZIPCode: "90028", CountryCode: "US", Latitude: 34.1, Longitude: -118.33,
+	}
+
+	dp := srv.newClient(t)
+	dpID := dp.signUp("dp@example.com")
+	resp := dp.do(http.MethodPut, "/v1/profiles/me", map[string]any{"headline": "DP"})
+	require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+	producer := srv.newClient(t)
+	producer.signUp("producer@example.com")
+	rival := srv.newClient(t)
+	rival.signUp("rival@example.com")
+	stranger := srv.newClient(t)
+	strangerID := stranger.signUp("stranger@example.com")
+
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 2, 0)
+	book := func(c *testClient, kind string, from, to time.Time) store.Booking {
+		t.Helper()
+		resp := c.do(http.MethodPost, "/v1/bookings", map[string]any{
+			"cinematographer_id": dpID, "kind": kind, "starts_at": from, "ends_at": to,
+			"time_zone": "America/Los_Angeles", "rate": 1500, "zip_code": "90028", "message": "Music video",
+		})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		var b store.Booking
+		resp.decode(t, &b)
+		return b
+	}
+	act := func(c *testClient, b store.Booking, body map[string]any) testResponse {
+		t.Helper()
+		return c.do(http.MethodPost, fmt.Sprintf("/v1/bookings/%d/actions", b.ID), body)
+	}
+	get := func(c *testClient, b store.Booking) store.Booking {
+		t.Helper()
+		resp := c.do(http.MethodGet, fmt.Sprintf("/v1/bookings/%d", b.ID), nil)
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var got store.Booking
+		resp.decode(t, &got)
+		return got
+	}
+	calendar := func() []store.AvailabilityBlock {
+		t.Helper()
+		var blocks []store.AvailabilityBlock
+		dp.do(http.MethodGet, "/v1/availability", nil).decode(t, &blocks)
+		return blocks
+	}
+	notifications := func(c *testClient) []store.Notification {
+		t.Helper()
+		var n []store.Notification
+		c.do(http.MethodGet, "/v1/notifications/", nil).decode(t, &n)
+		return n
+	}
+
+	t.Run("rejects bad bookings", func(t *testing.T) {
+		valid := func() map[string]any {
+			return map[string]any{
+				"cinematographer_id": dpID, "kind": "request", "starts_at": day, "ends_at": day.Add(10 * time.Hour),
+				"rate": 1000, "zip_code": "90028",
+			}
+		}
+		for name, change := range map[string]func(map[string]any){
+			"bad kind":         func(p map[string]any) { p["kind"] = "maybe" },
+			"ends first":       func(p map[string]any) { p["ends_at"] = day.Add(-time.Hour) },
+			"in the past":      func(p map[string]any) { p["starts_at"], p["ends_at"] = day.AddDate(-1, 0, 0), day },
+			"negative rate":    func(p map[string]any) { p["rate"] = -1 },
+			"request expiry":   func(p map[string]any) { p["expires_at"] = day },
+			"distant expiry":   func(p map[string]any) { p["kind"], p["expires_at"] = "hold", time.Now().AddDate(0, 2, 0) },
+			"missing zip code": func(p map[string]any) { delete(p, "zip_code") },
+		} {
+			p := valid()
+			change(p)
+			resp := producer.do(http.MethodPost, "/v1/bookings", p)
+			assert.Equal(t, http.StatusBadRequest, resp.status, name)
+		}
+
+		p := valid()
+		p["cinematographer_id"] = strangerID
+		assert.Equal(t, http.StatusNotFound, producer.do(http.MethodPost, "/v1/bookings", p).status, "no profile")
+		assert.Equal(t, http.StatusForbidden, dp.do(http.MethodPost, "/v1/bookings", valid()).status, "booking yourself")
+	})
+
+	t.Run("request with a counter", func(t *testing.T) {
+		b := book(producer, "request", day.Add(16*time.Hour), day.Add(26*time.Hour))
+		assert.Equal(t, store.BookingPending, b.Status)
+		assert.Equal(t, "USD", b.Currency)
+		assert.Equal(t, "90028", b.Location.ZIPCode)
+		assert.Zero(t, b.HoldRank)
+		assert.Equal(t, store.NotificationBookingRequested, notifications(dp)[0].Kind)
+
+		assert.Equal(t, http.StatusForbidden, stranger.do(http.MethodGet, fmt.Sprintf("/v1/bookings/%d", b.ID), nil).status)
+		assert.Equal(t, http.StatusForbidden, act(producer, b, map[string]any{"action": "accept"}).status, "not the producer's turn")
+		assert.Equal(t, http.StatusBadRequest, act(dp, b, map[string]any{"action": "counter"}).status, "a counter needs terms")
+		assert.Equal(t, http.StatusBadRequest, act(dp, b, map[string]any{"action": "accept", "rate": 2000}).status)
+
+		resp := act(dp, b, map[string]any{"action": "counter", "rate": 2000, "note": "Day rate went up"})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		resp.decode(t, &b)
+		assert.Equal(t, store.BookingCountered, b.Status)
+		assert.Equal(t, 2000, b.Rate)
+		assert.Equal(t, store.NotificationBookingUpdated, notifications(producer)[0].Kind)
+
+		assert.Equal(t, http.StatusForbidden, act(dp, b, map[string]any{"action": "accept"}).status, "not the cinematographer's turn")
+		resp = act(producer, b, map[string]any{"action": "accept"})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		resp.decode(t, &b)
+		assert.Equal(t, store.BookingAccepted, b.Status)
+		require.NotNil(t, b.AvailabilityBlockID)
+
+		blocks := calendar()
+		require.Len(t, blocks, 1)
+		assert.Equal(t, store.AvailabilityBooked, blocks[0].Status)
+		assert.Equal(t, store.AvailabilityBooking, blocks[0].Source)
+
+		resp = act(producer, b, map[string]any{"action": "decline"})
+		assert.Equal(t, http.StatusConflict, resp.status)
+		assert.Equal(t, "invalid_transition", resp.problem(t)["code"])
+
+		resp = dp.do(http.MethodGet, fmt.Sprintf("/v1/bookings/%d/events", b.ID), nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		var events []store.BookingEvent
+		resp.decode(t, &events)
+		actions := []string{}
+		for _, e := range events {
+			actions = append(actions, e.Action)
+		}
+		assert.Equal(t, []string{"requested", "countered", "accepted"}, actions)
+		assert.Equal(t, "Day rate went up", events[1].Note)
+		assert.Equal(t, 2000, events[1].Rate)
+
+		// Cancelling frees the dates again
+		require.Equal(t, http.StatusOK, act(dp, b, map[string]any{"action": "cancel"}).status)
+		assert.Empty(t, calendar())
+	})
+
+	t.Run("holds are ranked and only the first can be confirmed", func(t *testing.T) {
+		from, to := day.AddDate(0, 0, 7), day.AddDate(0, 0, 9)
+		first := book(producer, "hold", from, to)
+		second := book(rival, "hold", from.AddDate(0, 0, 1), to.AddDate(0, 0, 1))
+		assert.Equal(t, 1, first.HoldRank)
+		assert.Equal(t, 2, second.HoldRank)
+		require.NotNil(t, first.ExpiresAt)
+
+		assert.Equal(t, http.StatusConflict, act(producer, first, map[string]any{"action": "confirm"}).status, "not held yet")
+		require.Equal(t, http.StatusOK, act(dp, first, map[string]any{"action": "accept"}).status)
+		require.Equal(t, http.StatusOK, act(dp, second, map[string]any{"action": "accept"}).status)
+		assert.Equal(t, store.BookingHeld, get(rival, second).Status)
+		assert.Len(t, calendar(), 2)
+
+		resp := act(rival, second, map[string]any{"action": "confirm"})
+		assert.Equal(t, http.StatusConflict, resp.status, "second hold")
+		assert.Equal(t, http.StatusForbidden, act(dp, first, map[string]any{"action": "confirm"}).status)
+
+		resp = act(producer, first, map[string]any{"action": "confirm"})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		resp.decode(t, &first)
+		assert.Equal(t, store.BookingAccepted, first.Status)
+		assert.Zero(t, first.HoldRank)
+		assert.Equal(t, 1, get(rival, second).HoldRank, "the second hold moves up")
+
+		// The dates are booked now, so the second hold cannot be confirmed
+		resp = act(rival, second, map[string]any{"action": "confirm"})
+		assert.Equal(t, http.StatusConflict, resp.status)
+		assert.Equal(t, "conflict", resp.problem(t)["code"])
+	})
+
+	t.Run("holds expire", func(t *testing.T) {
+		b := book(producer, "hold", day.AddDate(0, 0, 20), day.AddDate(0, 0, 21))
+		require.Equal(t, http.StatusOK, act(dp, b, map[string]any{"action": "accept"}).status)
+		b = get(producer, b)
+		require.NotNil(t, b.AvailabilityBlockID)
+
+		n, err := srv.app.expireHolds(context.Background(), time.Now())
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		n, err = srv.app.expireHolds(context.Background(), b.ExpiresAt.Add(time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, 2, n, "this hold and the one still held from the last test")
+
+		b = get(producer, b)
+		assert.Equal(t, store.BookingExpired, b.Status)
+		assert.Nil(t, b.AvailabilityBlockID)
+		assert.Equal(t, store.NotificationBookingExpired, notifications(producer)[0].Kind)
+		assert.Equal(t, store.NotificationBookingExpired, notifications(dp)[0].Kind)
+		for _, block := range calendar() {
+			assert.Equal(t, store.AvailabilityBooked, block.Status, "only the confirmed hold is left")
+		}
+
+		var events []store.BookingEvent
+		dp.do(http.MethodGet, fmt.Sprintf("/v1/bookings/%d/events", b.ID), nil).decode(t, &events)
+		last := events[len(events)-1]
+		assert.Equal(t, "expired", last.Action)
+		assert.Nil(t, last.ActorID)
+	})
+
+	t.Run("acting on a lapsed hold expires it", func(t *testing.T) {
+		b := book(producer, "hold", day.AddDate(0, 0, 30), day.AddDate(0, 0, 31))
+		lapsed := time.Now().Add(-time.Minute)
+		b.ExpiresAt = &lapsed
+		require.NoError(t, srv.app.store.Bookings.Update(context.Background(), &b))
+
+		resp := act(dp, b, map[string]any{"action": "accept"})
+		assert.Equal(t, http.StatusConflict, resp.status)
+		assert.Equal(t, store.BookingExpired, get(dp, b).Status)
+	})
+
+	t.Run("lists by role and status", func(t *testing.T) {
+		list := func(c *testClient, query string) []store.Booking {
+			t.Helper()
+			resp := c.do(http.MethodGet, "/v1/bookings?"+query, nil)
+			require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+			var bookings []store.Booking
+			resp.decode(t, &bookings)
+			return bookings
+		}
+		assert.Len(t, list(dp, ""), 5)
+		assert.Len(t, list(dp, "role=cinematographer"), 5)
+		assert.Empty(t, list(dp, "role=producer"))
+		assert.Len(t, list(producer, "role=producer&status=expired"), 2)
+		assert.Len(t, list(rival, ""), 1)
+
+		assert.Equal(t, http.StatusBadRequest, dp.do(http.MethodGet, "/v1/bookings?role=director", nil).status)
+		assert.Equal(t, http.StatusBadRequest, dp.do(http.MethodGet, "/v1/bookings?status=done", nil).status)
+	})
+
+	t.Run("concurrent accepts cannot double book", func(t *testing.T) {
+		from, to := day.AddDate(0, 0, 40), day.AddDate(0, 0, 41)
+		requests := []store.Booking{book(producer, "request", from, to), book(rival, "request", from, to)}
+
+		statuses := make(chan int, len(requests))
+		for _, b := range requests {
+			go func() { statuses <- act(dp, b, map[string]any{"action": "accept"}).status }()
+		}
+		got := []int{<-statuses, <-statuses}
+		assert.ElementsMatch(t, []int{http.StatusOK, http.StatusConflict}, got)
+
+		booked := 0
+		for _, block := range calendar() {
+			if block.StartsAt.Equal(from) {
+				booked++
+			}
+		}
+		assert.Equal(t, 1, booked)
+	})
+}
+
+func TestExpireHoldsEveryStopsOnShutdown(t *testing.T) {
+	app := newTestApplication(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stopped := make(chan struct{})
+	go func() {
+		app.expireHoldsEvery(ctx, time.Millisecond)
+		close(stopped)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the sweep kept running after shutdown")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+// Cinematographer is a public profile found by a nearby search, with how far the user's
+// location is from the searched ZIP code
+type Cinematographer struct {
+	store.Profile
+	Distance     float64 `json:"distance" example:"12.4"`
+	DistanceUnit string  `json:"distance_unit" example:"mi"`
+	MatchedBy    string  `json:"matched_by" enums:"nearby,travel_radius,service_area"`
+}
+
+// cinematographerSearch is a parsed nearby search; the store measures in kilometers and
+// distances are only converted to unit for the response
+type cinematographerSearch struct {
+	filter store.ProfileFilter
+	unit   string
+}
+
+// SearchCinematographers godoc
+//
+//	@Summary		Searches for cinematographers nearby
+//	@Description	Finds active users' public profiles near a ZIP code, nearest first, measured from each user's own location. A user matches when they live within miles of it, when it is within their travel radius, or when one of their service places covers it; matched_by says which. role keeps those who take that crew role; max_rate keeps those whose day rate starts at or below it, in currency (USD unless given), and leaves out profiles without a rate. available_from and available_to (both or neither) keep those with nothing booked on any day from one through the other; tentative holds do not count. sort=rating lists the highest rated first by their average revealed review score, with unrated profiles last. distance is given in unit. Follow next_cursor (also sent as a Link header) for the next page, keeping the same filters.
+//	@Tags			profiles
+//	@Produce		json
+//	@Param			zip			query		string	true	"Search around this ZIP code"
+//	@Param			miles		query		number	false	"Radius around zip, at most 500"	default(25)
+//	@Param			role		query		string	false	"Crew role"	Enums(director_of_photography, camera_operator, steadicam_operator, drone_pilot, first_ac, second_ac, dit, gaffer, best_boy_electric, electrician, key_grip, grip)
+//	@Param			max_rate	query		int		false	"Highest day rate, whole units of currency"
+//	@Param			currency	query		string	false	"ISO 4217 currency code"
+//	@Param			available_from	query		string	false	"First day they must be free, YYYY-MM-DD (UTC)"
+//	@Param			available_to	query		string	false	"Last day they must be free, YYYY-MM-DD (UTC)"
+//	@Param			unit		query		string	false	"Unit for distance"	Enums(mi, km)	default(mi)
+//	@Param			sort		query		string	false	"Order of the results"	Enums(distance, rating)	default(distance)
+//	@Param			limit		query		int		false	"Page size, at most 100"	default(20)
+//	@Param			cursor		query		string	false	"next_cursor from the previous page"
+//	@Success		200			{array}		Cinematographer
+//	@Failure		400			{object}	utils.Problem
+//	@Failure		404			{object}	utils.Problem
+//	@Failure		500			{object}	utils.Problem
+//	@Failure		502			{object}	utils.Problem
+//	@Router			/cinematographers/search [get]
+func (app *application) searchCinematographersHandler(w http.ResponseWriter, r *http.Request) {
+	search, err := app.parseCinematographerSearch(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	found, err := app.store.Profiles.Search(r.Context(), search.filter)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	results := make([]Cinematographer, len(found.Items))
+	for i, m := range found.Items {
+		distance := m.DistanceKm
+		if search.unit == "mi" {
+			distance /= store.KmPerMile
+		}
+		results[i] = Cinematographer{
+			Profile:      m.Profile,
+			Distance:     math.Round(distance*10) / 10,
+			DistanceUnit: search.unit,
+			MatchedBy:    m.MatchedBy,
+		}
+	}
+	if err := pagination.Write(w, r, pagination.Page[Cinematographer]{Items: results, Next: found.Next}); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+func (app *application) parseCinematographerSearch(r *http.Request) (cinematographerSearch, error) {
+	query := r.URL.Query()
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		return cinematographerSearch{}, err
+	}
+	search := cinematographerSearch{filter: store.ProfileFilter{Sort: store.ProfileSortDistance, Page: page}, unit: "mi"}
+
+	zipRequired := utils.InvalidQueryParam("zip", "required", "is required")
+	if query.Get("zip") == "" {
+		return cinematographerSearch{}, zipRequired
+	}
+	if raw := query.Get("role"); raw != "" {
+		if search.filter.Role = store.CrewRole(raw); !search.filter.Role.Valid() {
+			return cinematographerSearch{}, utils.InvalidQueryParam("role", "oneof", "must be a crew role")
+		}
+	}
+	if raw := query.Get("max_rate"); raw != "" {
+		if search.filter.MaxRate, err = strconv.Atoi(raw); err != nil || search.filter.MaxRate < 1 {
+			return cinematographerSearch{}, utils.InvalidQueryParam("max_rate", "min", "must be a whole number of at least 1")
+		}
+		search.filter.Currency = "USD"
+	}
+	if raw := query.Get("currency"); raw != "" {
+		search.filter.Currency = strings.ToUpper(raw)
+		if err := utils.Validate.Var(search.filter.Currency, "iso4217"); err != nil {
+			return cinematographerSearch{}, utils.InvalidQueryParam("currency", "iso4217", "must be an ISO 4217 currency code")
+		}
+	}
+	if err := parseAvailableRange(query, &search.filter); err != nil {
+		return cinematographerSearch{}, err
+	}
+	switch unit := query.Get("unit"); unit {
+	case "", "mi":
+	case "km":
+		search.unit = unit
+	default:
+		return cinematographerSearch{}, utils.InvalidQueryParam("unit", "oneof", "must be one of: mi km")
+	}
+	switch sort := query.Get("sort"); sort {
+	case "", "distance":
+	case "rating":
+		search.filter.Sort = store.ProfileSortRating
+	default:
+		return cinematographerSearch{}, utils.InvalidQueryParam("sort", "oneof", "must be one of: distance rating")
+	}
+
+	center, miles, err := app.radiusFromQuery(r)
+	if err != nil {
+		return cinematographerSearch{}, err
+	}
+	if center == nil {
+		return cinematographerSearch{}, zipRequired
+	}
+	search.filter.Latitude, search.filter.Longitude = center.Latitude, center.Longitude
+	search.filter.RadiusMiles = miles
+	return search, nil
+}
+
+// maxAvailableDays caps the range a search can require someone to be free over
+const maxAvailableDays = 366
+
+// parseAvailableRange reads available_from and available_to as whole UTC days, the second inclusive
+func parseAvailableRange(query url.Values, filter *store.ProfileFilter) error {
+	rawFrom, rawTo := query.Get("available_from"), query.Get("available_to")
+	if rawFrom == "" && rawTo == "" {
+		return nil
+	}
+
+	from, err := time.Parse(time.DateOnly, rawFrom)
+	if err != nil {
+		return utils.InvalidQueryParam("available_from", "datetime", "must be a YYYY-MM-DD date, given along with available_to")
+	}
+	to, err := time.Parse(time.DateOnly, rawTo)
+	if err != nil {
+		return utils.InvalidQueryParam("available_to", "datetime", "must be a YYYY-MM-DD date, given along with available_from")
+	}
+	to = to.AddDate(0, 0, 1)
+	switch {
+	case !to.After(from):
+		return utils.InvalidQueryParam("available_to", "gtefield", "must be on or after available_from")
+	case to.Sub(from) > maxAvailableDays*24*time.Hour:
+		return utils.InvalidQueryParam("available_to", "max", fmt.Sprintf("must be at most %d days after available_from", maxAvailableDays-1))
+	}
+	filter.FreeFrom, filter.FreeTo = from, to
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchCinematographers(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+
+	crew := []struct {
+		email, city, zip string
+		lat, lon         float64
+		roles            []string
+		rate             int
+	}{
+		{"kc@example.com", "Kansas City", "64105", 39.1, -94.58, []string{"director_of_photography"}, 900},
+		{"op@example.com", "Overland Park", "66210", 38.93, -94.70, []string{"camera_operator", "drone_pilot"}, 500},
+		{"lawrence@example.com", "Lawrence", "66044", 38.97, -95.24, []string{"director_of_photography", "drone_pilot"}, 0},
+		// Inside the 50 mile bounding box but about 57 miles away
+		{"corner@example.com", "Maysville", "64469", 39.7, -93.83, []string{"director_of_photography"}, 700},
+	}
+	for _, c := range crew {
+		client := srv.newClient(t)
+		payload := registrationPayload(c.email)
+		payload["city"], payload["state"], payload["zip_code"] = c.city, "MO", c.zip
+		payload["latitude"], payload["longitude"] = c.lat, c.lon
+		client.signUpWith(payload)
+
+		resp := client.do(http.MethodPut, "/v1/profiles/me", map[string]any{
+			"headline":     c.city + " crew",
+			"roles":        c.roles,
+			"day_rate_min": c.rate,
+			"day_rate_max": c.rate,
+		})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+	}
+	visitor := srv.newClient(t)
+
+	search := func(query string) ([]Cinematographer, testResponse) {
+		t.Helper()
+		resp := visitor.do(http.MethodGet, "/v1/cinematographers/search?"+query, nil)
+		var found []Cinematographer
+		if resp.status == http.StatusOK {
+			resp.decode(t, &found)
+		}
+		return found, resp
+	}
+	headlines := func(found []Cinematographer) []string {
+		headlines := []string{}
+		for _, c := range found {
+			headlines = append(headlines, c.Headline)
+		}
+		return headlines
+	}
+
+	t.Run("nearest first within the radius", func(t *testing.T) {
+		found, resp := search("zip=64105&miles=50")
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		assert.Equal(t, []string{"Kansas City crew", "Overland Park crew", "Lawrence crew"}, headlines(found))
+		assert.Equal(t, 0.0, found[0].Distance)
+		assert.InDelta(t, 13, found[1].Distance, 1)
+		assert.Equal(t, "mi", found[1].DistanceUnit)
+		assert.NotContains(t, string(resp.body), "kc@example.com")
+	})
+
+	t.Run("kilometers", func(t *testing.T) {
+		found, resp := search("zip=64105&miles=50&unit=km")
+		require.Equal(t, http.StatusOK, resp.status)
+		assert.InDelta(t, 21, found[1].Distance, 1.5)
+		assert.Equal(t, "km", found[1].DistanceUnit)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		found, _ := search("zip=64105&miles=50&role=drone_pilot")
+		assert.Equal(t, []string{"Overland Park crew", "Lawrence crew"}, headlines(found))
+
+		found, _ = search("zip=64105&miles=50&max_rate=600")
+		assert.Equal(t, []string{"Overland Park crew"}, headlines(found), "profiles without a rate are left out")
+
+		found, _ = search("zip=64105&miles=60")
+		assert.Contains(t, headlines(found), "Maysville crew")
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		found, resp := search("zip=64105&miles=50&limit=2")
+		require.Len(t, found, 2)
+		link := resp.header.Get("Link")
+		require.NotEmpty(t, link)
+
+		resp = visitor.do(http.MethodGet, strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`), nil)
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		resp.decode(t, &found)
+		assert.Equal(t, []string{"Lawrence crew"}, headlines(found))
+		assert.Empty(t, resp.header.Get("Link"))
+	})
+
+	t.Run("rejects bad searches", func(t *testing.T) {
+		for _, query := range []string{"", "zip=", "miles=10", "zip=64105&role=boom_operator", "zip=64105&max_rate=0", "zip=64105&unit=furlongs", "zip=64105&miles=1000"} {
+			_, resp := search(query)
+			assert.Equal(t, http.StatusBadRequest, resp.status, query)
+		}
+	})
+}
@@ -3,11 +3,11 @@ package main
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
 	// store "github.com/michaelhoman/ShotSeek/internal/store/postgres"
 	"github.com/michaelhoman/ShotSeek/internal/store"
 	"github.com/michaelhoman/ShotSeek/internal/utils"
@@ -21,6 +21,40 @@ type CreateCommentPayload struct {
 	Content string `json:"content" validate:"required,max=1000"`
 }
 
+// ListComments godoc
+//
+//	@Summary		Lists a post's comments
+//	@Description	Lists the comments on a post newest first, a page at a time. Follow next_cursor (also sent as a Link header) for the next page.
+//	@Tags			comments
+//	@Produce		json
+//	@Param			id		path		int		true	"Post ID"
+//	@Param			limit	query		int		false	"Page size, at most 100"	default(20)
+//	@Param			cursor	query		string	false	"next_cursor from the previous page"
+//	@Success		200		{array}		store.Comment
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Router			/posts/{id}/comments [get]
+func (app *application) listCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	params, err := pagination.FromRequest(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	page, err := app.store.Comments.GetByPostID(r.Context(), post.ID, params)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := pagination.Write(w, r, page); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
 // CreateComment godoc
 //
 //	@Summary		Creates a comment
@@ -30,10 +64,10 @@ type CreateCommentPayload struct {
 //	@Produce		json
 //	@Param			id		path		int						true	"Post ID"
 //	@Param			payload	body		CreateCommentPayload	true	"Comment payload"
-//	@Success		200		{object}	store.Comment
-//	@Failure		400		{object}	error
-//	@Failure		404		{object}	error
-//	@Failure		500		{object}	error
+//	@Success		201		{object}	store.Comment
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id}/comments [post]
 func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Request) {
@@ -54,9 +88,15 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
 	comment := store.Comment{
 		PostID:  postID,
-		UserID:  1,
+		UserID:  userID,
 		Content: payload.Content,
 	}
 
@@ -68,8 +108,9 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-
+	if err := utils.JsonResponse(w, http.StatusCreated, comment); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
 }
 
 // GetComment godoc
@@ -81,15 +122,13 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 //	@Produce		json
 //	@Param			id	path		int	true	"Comment ID"
 //	@Success		200	{object}	store.Comment
-//	@Failure		400	{object}	error
-//	@Failure		404	{object}	error
-//	@Failure		500	{object}	error
+//	@Failure		400	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/posts/comments/{id} [get]
 func (app *application) getCommentHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("getCommentHandler")
 	comment := getCommentFromCtx(r)
-	fmt.Println("comment:", comment)
 
 	if err := utils.JsonResponse(w, http.StatusOK, comment); err != nil {
 		utils.InternalServerError(w, r, err)
@@ -107,9 +146,9 @@ func (app *application) getCommentHandler(w http.ResponseWriter, r *http.Request
 //	@Param			id		path		int						true	"Comment ID"
 //	@Param			payload	body		CreateCommentPayload	true	"Comment payload"
 //	@Success		200		{object}	store.Comment
-//	@Failure		400		{object}	error
-//	@Failure		404		{object}	error
-//	@Failure		500		{object}	error
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/posts/comments/{id} [patch]
 func (app *application) updateCommentHandler(w http.ResponseWriter, r *http.Request) {
@@ -150,9 +189,9 @@ func (app *application) updateCommentHandler(w http.ResponseWriter, r *http.Requ
 //	@Produce		json
 //	@Param			id	path		int	true	"Comment ID"
 //	@Success		204	{object}	nil
-//	@Failure		400	{object}	error
-//	@Failure		404	{object}	error
-//	@Failure		500	{object}	error
+//	@Failure		400	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/posts/comments/{id} [delete]
 func (app *application) DeleteByCommentIDHandler(w http.ResponseWriter, r *http.Request) {
@@ -205,12 +244,10 @@ func (app *application) DeleteByPostID(w http.ResponseWriter, r *http.Request) {
 
 func (app *application) commentsContextMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Println("commentsContextMiddleware")
 		idParam := chi.URLParam(r, "commentID")
 		id, err := strconv.ParseInt(idParam, 10, 64)
 
 		if err != nil {
-			fmt.Println("Error in commentsContextMiddleware")
 			utils.InternalServerError(w, r, err)
 			return
 		}
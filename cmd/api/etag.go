@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+// postETag is derived from the post's version plus its embedded comments, so a new or
+// edited comment changes the tag of the representation that includes it
+func postETag(post *store.Post) string {
+	h := fnv.New64a()
+	for _, c := range post.Comments {
+		fmt.Fprintf(h, "%d:%d;", c.ID, c.UpdatedAt.UnixNano())
+	}
+	return fmt.Sprintf(`"%d-%x"`, post.Version, h.Sum64())
+}
+
+func userETag(user *store.User) string {
+	return fmt.Sprintf(`"%d"`, user.Version)
+}
+
+// gigETag follows the post version, which gig edits and status changes both bump
+func gigETag(gig *store.Gig) string {
+	return fmt.Sprintf(`"%d"`, gig.Version)
+}
+
+// profileETag is derived from the profile's version plus its rating, which moves whenever
+// a review is revealed without the profile itself changing
+func profileETag(profile *store.Profile) string {
+	h := fnv.New64a()
+	rating := profile.Rating
+	for _, avg := range []*float64{rating.Average, rating.Professionalism, rating.TechnicalSkill, rating.Communication} {
+		if avg != nil {
+			fmt.Fprintf(h, "%g", *avg)
+		}
+		fmt.Fprint(h, ";")
+	}
+	return fmt.Sprintf(`"%d-%d-%x"`, profile.Version, rating.Count, h.Sum64())
+}
+
+// matchesETag reports whether an If-Match or If-None-Match header lists etag. weak allows
+// W/ tags to match, which RFC 9110 permits for If-None-Match only.
+func matchesETag(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified answers 304 when the client already holds the current representation
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" || !matchesETag(header, etag, true) {
+		return false
+	}
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// checkPrecondition makes sure a write is based on the representation the client last read.
+// If-Match is compared with etag; without it, a version from the request body is compared
+// with the stored version. Requests carrying neither are rejected with 428.
+func checkPrecondition(r *http.Request, etag string, version *int, current int, representation any) error {
+	if header := r.Header.Get("If-Match"); header != "" {
+		if matchesETag(header, etag, false) {
+			return nil
+		}
+		return preconditionFailed(representation)
+	}
+
+	if version == nil {
+		return utils.NewAppError(http.StatusPreconditionRequired, utils.CodePreconditionRequired, "Send an If-Match header with the ETag you last received, or the version field")
+	}
+	if *version != current {
+		return editConflict(representation)
+	}
+	return nil
+}
+
+func preconditionFailed(current any) error {
+	err := utils.NewAppError(http.StatusPreconditionFailed, utils.CodePreconditionFailed, "The resource has changed since it was last read")
+	err.Current = current
+	return err
+}
+
+func editConflict(current any) error {
+	err := utils.NewAppError(http.StatusConflict, utils.CodeEditConflict, "The resource was modified by another request")
+	err.Current = current
+	return err
+}
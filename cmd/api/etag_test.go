@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesETag(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		weak   bool
+		want   bool
+	}{
+		{name: "exact", header: `"3-a"`, want: true},
+		{name: "list", header: `"1-a", "3-a"`, want: true},
+		{name: "wildcard", header: "*", want: true},
+		{name: "different", header: `"2-a"`, want: false},
+		{name: "weak rejected for If-Match", header: `W/"3-a"`, want: false},
+		{name: "weak accepted for If-None-Match", header: `W/"3-a"`, weak: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchesETag(tt.header, `"3-a"`, tt.weak))
+		})
+	}
+}
+
+func TestPostConditionalRequests(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	c := srv.newClient(t)
+	c.signUp("etag@example.com")
+
+	var post store.Post
+	c.do(http.MethodPost, "/v1/posts/", map[string]any{"title": "Framing", "content": "2.39 or 1.85?"}).decode(t, &post)
+	postPath := fmt.Sprintf("/v1/posts/%d/", post.ID)
+
+	resp := c.do(http.MethodGet, postPath, nil)
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	etag := resp.header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	resp = c.doWithHeader(http.MethodGet, postPath, nil, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, resp.status)
+	assert.Empty(t, resp.body)
+
+	t.Run("precondition required", func(t *testing.T) {
+		resp := c.do(http.MethodPatch, postPath, map[string]any{"title": "Aspect ratios"})
+		require.Equal(t, http.StatusPreconditionRequired, resp.status, string(resp.body))
+		assert.Equal(t, "precondition_required", resp.problem(t)["code"])
+	})
+
+	resp = c.doWithHeader(http.MethodPatch, postPath, map[string]any{"title": "Aspect ratios"}, http.Header{"If-Match": {etag}})
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	fresh := resp.header.Get("ETag")
+	assert.NotEqual(t, etag, fresh)
+
+	t.Run("stale If-Match", func(t *testing.T) {
+		resp := c.doWithHeader(http.MethodPatch, postPath, map[string]any{"title": "Lost update"}, http.Header{"If-Match": {etag}})
+		require.Equal(t, http.StatusPreconditionFailed, resp.status, string(resp.body))
+		problem := resp.problem(t)
+		assert.Equal(t, "precondition_failed", problem["code"])
+		current, ok := problem["current"].(map[string]any)
+		require.True(t, ok, string(resp.body))
+		assert.Equal(t, "Aspect ratios", current["title"])
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		resp := c.do(http.MethodPatch, postPath, map[string]any{"title": "Lost update", "version": post.Version})
+		require.Equal(t, http.StatusConflict, resp.status, string(resp.body))
+		assert.Equal(t, "edit_conflict", resp.problem(t)["code"])
+	})
+
+	t.Run("new comment changes the tag", func(t *testing.T) {
+		resp := c.do(http.MethodPost, postPath+"comments", map[string]any{"content": "1.85 for interiors"})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+
+		resp = c.doWithHeader(http.MethodGet, postPath, nil, http.Header{"If-None-Match": {fresh}})
+		require.Equal(t, http.StatusOK, resp.status)
+		fresh = resp.header.Get("ETag")
+	})
+
+	resp = c.do(http.MethodDelete, postPath, nil)
+	assert.Equal(t, http.StatusPreconditionRequired, resp.status, string(resp.body))
+	resp = c.doWithHeader(http.MethodDelete, postPath, nil, http.Header{"If-Match": {etag}})
+	assert.Equal(t, http.StatusPreconditionFailed, resp.status, string(resp.body))
+	resp = c.doWithHeader(http.MethodDelete, postPath, nil, http.Header{"If-Match": {fresh}})
+	assert.Equal(t, http.StatusNoContent, resp.status, string(resp.body))
+}
+
+func TestUserConditionalRequests(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	c := srv.newClient(t)
+	userID := c.signUp("conditional@example.com")
+	userPath := "/v1/users/" + userID + "/"
+
+	resp := c.do(http.MethodGet, userPath, nil)
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	etag := resp.header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	resp = c.doWithHeader(http.MethodGet, "/v1/users/", nil, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, resp.status)
+
+	resp = c.doWithHeader(http.MethodPatch, userPath, map[string]any{"first_name": "Greig"}, http.Header{"If-Match": {etag}})
+	require.Equal(t, http.StatusNoContent, resp.status, string(resp.body))
+	assert.NotEqual(t, etag, resp.header.Get("ETag"))
+
+	resp = c.doWithHeader(http.MethodPatch, userPath, map[string]any{"first_name": "Hoyte"}, http.Header{"If-Match": {etag}})
+	require.Equal(t, http.StatusPreconditionFailed, resp.status, string(resp.body))
+	current, ok := resp.problem(t)["current"].(map[string]any)
+	require.True(t, ok, string(resp.body))
+	assert.Equal(t, "Greig", current["first_name"])
+
+	resp = c.do(http.MethodDelete, userPath, nil)
+	assert.Equal(t, http.StatusPreconditionRequired, resp.status, string(resp.body))
+}
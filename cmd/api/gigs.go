@@ -0,0 +1,564 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+type gigKey string
+
+const gigCtx gigKey = "gig"
+
+// GigLocationPayload is where the shoot happens. Coordinates are looked up from the ZIP
+// code when both are left out.
+type GigLocationPayload struct {
+	Street    string  `json:"street" validate:"max=255"`
+	City      string  `json:"city" validate:"required,max=100"`
+	State     string  `json:"state" validate:"required,max=100"`
+	Zipcode   string  `json:"zip_code" validate:"required,max=12"`
+	Country   string  `json:"country" validate:"required,max=100"`
+	Latitude  float64 `json:"latitude" validate:"gte=-90,lte=90"`
+	Longitude float64 `json:"longitude" validate:"gte=-180,lte=180"`
+}
+
+type CreateGigPayload struct {
+	Title         string             `json:"title" validate:"required,max=100"`
+	Content       string             `json:"content" validate:"required,max=1000"`
+	Tags          []string           `json:"tags" validate:"max=100"`
+	Role          store.CrewRole     `json:"role" validate:"required,oneof=director_of_photography camera_operator steadicam_operator drone_pilot first_ac second_ac dit gaffer best_boy_electric electrician key_grip grip"`
+	ShootStart    store.Date         `json:"shoot_start" validate:"required" swaggertype:"string" format:"date" example:"2025-06-02"`
+	ShootEnd      store.Date         `json:"shoot_end" validate:"required" swaggertype:"string" format:"date" example:"2025-06-04"`
+	CallTime      string             `json:"call_time" validate:"required,datetime=15:04" example:"07:00"`
+	DurationHours int                `json:"duration_hours" validate:"required,min=1,max=24"`
+	DayRateMin    int                `json:"day_rate_min" validate:"min=0"`
+	DayRateMax    int                `json:"day_rate_max" validate:"required,min=0"`
+	Currency      string             `json:"currency" validate:"required,iso4217" example:"USD"`
+	Gear          []string           `json:"gear" validate:"max=50,dive,required,max=100"`
+	UnionStatus   store.UnionStatus  `json:"union_status" validate:"required,oneof=union non_union either"`
+	Location      GigLocationPayload `json:"location" validate:"required"`
+}
+
+// CreateGig godoc
+//
+//	@Summary		Creates a gig
+//	@Description	Posts a crew call: a post with the role, shoot dates, call time, day rate range, gear and location. New gigs are open. Rates are whole units of currency.
+//	@Tags			gigs
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateGigPayload	true	"Gig payload"
+//	@Success		201		{object}	store.Gig
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Failure		502		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/gigs [post]
+func (app *application) createGigHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateGigPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.StructCtx(r.Context(), payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	gig := store.Gig{
+		Post: store.Post{
+			Title:   payload.Title,
+			Content: payload.Content,
+			Tags:    payload.Tags,
+			UserID:  userID,
+		},
+		Role:          payload.Role,
+		ShootStart:    payload.ShootStart,
+		ShootEnd:      payload.ShootEnd,
+		CallTime:      payload.CallTime,
+		DurationHours: payload.DurationHours,
+		DayRateMin:    payload.DayRateMin,
+		DayRateMax:    payload.DayRateMax,
+		Currency:      payload.Currency,
+		Gear:          payload.Gear,
+		UnionStatus:   payload.UnionStatus,
+		Location:      payload.Location.toLocation(),
+	}
+
+	if err := validateGig(&gig); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	if err := app.locateGig(r.Context(), &gig); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	if err := app.store.Gigs.Create(r.Context(), &gig); err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", gigETag(&gig))
+	if err := utils.JsonResponse(w, http.StatusCreated, gig); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+func (p GigLocationPayload) toLocation() *store.Location {
+	return &store.Location{
+		Street:    p.Street,
+		City:      p.City,
+		State:     p.State,
+		ZIPCode:   p.Zipcode,
+		Country:   p.Country,
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+	}
+}
+
+// validateGig checks the rules that span more than one field
+func validateGig(gig *store.Gig) error {
+	if gig.ShootEnd.Before(gig.ShootStart.Time) {
+		return utils.InvalidField("shoot_end", "gtefield", "must be on or after shoot_start")
+	}
+	if gig.DayRateMax < gig.DayRateMin {
+		return utils.InvalidField("day_rate_max", "gtefield", "must be at least day_rate_min")
+	}
+	return nil
+}
+
+// locateGig fills in the coordinates of a gig location given without them, so gigs can be
+// found by distance
+func (app *application) locateGig(ctx context.Context, gig *store.Gig) error {
+	loc := gig.Location
+	if loc.Latitude != 0 || loc.Longitude != 0 {
+		return nil
+	}
+	found, err := app.lookupByZip(ctx, loc.ZIPCode)
+	if err != nil {
+		return err
+	}
+	loc.Latitude, loc.Longitude = found.Latitude, found.Longitude
+	if loc.County == "" {
+		loc.County = found.County
+	}
+	if loc.CountryCode == "" {
+		loc.CountryCode = found.CountryCode
+	}
+	return nil
+}
+
+// ListGigs godoc
+//
+//	@Summary		Lists gigs
+//	@Description	Lists gigs a page at a time, most recently posted first. Only open gigs are listed unless status says otherwise. from and to match gigs shooting on any day in that range. Pass zip (and optionally miles) to only list gigs near that ZIP code. Follow next_cursor (also sent as a Link header) for the next page, keeping the same filters.
+//	@Tags			gigs
+//	@Produce		json
+//	@Param			role			query		string		false	"Crew role"	Enums(director_of_photography, camera_operator, steadicam_operator, drone_pilot, first_ac, second_ac, dit, gaffer, best_boy_electric, electrician, key_grip, grip)
+//	@Param			status			query		string		false	"Gig status, or any"	Enums(open, filled, cancelled, any)	default(open)
+//	@Param			union_status	query		string		false	"Union status"	Enums(union, non_union, either)
+//	@Param			from			query		string		false	"Shooting on or after, YYYY-MM-DD"
+//	@Param			to				query		string		false	"Shooting on or before, YYYY-MM-DD"
+//	@Param			min_rate		query		int			false	"Day rate range reaches at least this"
+//	@Param			currency		query		string		false	"ISO 4217 currency code"
+//	@Param			gear			query		[]string	false	"Only gigs asking for every one of these"	collectionFormat(multi)
+//	@Param			zip				query		string		false	"Only gigs near this ZIP code"
+//	@Param			miles			query		number		false	"Radius around zip, at most 500"	default(25)
+//	@Param			limit			query		int			false	"Page size, at most 100"			default(20)
+//	@Param			cursor			query		string		false	"next_cursor from the previous page"
+//	@Success		200				{array}		store.Gig
+//	@Failure		400				{object}	utils.Problem
+//	@Failure		404				{object}	utils.Problem
+//	@Failure		500				{object}	utils.Problem
+//	@Failure		502				{object}	utils.Problem
+//	@Router			/gigs [get]
+func (app *application) listGigsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := app.parseGigFilter(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	page, err := app.store.Gigs.List(r.Context(), filter)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := pagination.Write(w, r, page); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// parseGigFilter reads the list gigs query parameters
+func (app *application) parseGigFilter(r *http.Request) (store.GigFilter, error) {
+	query := r.URL.Query()
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		return store.GigFilter{}, err
+	}
+	filter := store.GigFilter{Status: store.GigOpen, Gear: query["gear"], Page: page}
+
+	if raw := query.Get("role"); raw != "" {
+		if filter.Role = store.CrewRole(raw); !filter.Role.Valid() {
+			return store.GigFilter{}, utils.InvalidQueryParam("role", "oneof", "must be a crew role")
+		}
+	}
+	switch status := store.GigStatus(query.Get("status")); status {
+	case "":
+	case "any":
+		filter.Status = ""
+	case store.GigOpen, store.GigFilled, store.GigCancelled:
+		filter.Status = status
+	default:
+		return store.GigFilter{}, utils.InvalidQueryParam("status", "oneof", "must be one of: open filled cancelled any")
+	}
+	switch union := store.UnionStatus(query.Get("union_status")); union {
+	case "", store.UnionOnly, store.UnionNonUnion, store.UnionEither:
+		filter.UnionStatus = union
+	default:
+		return store.GigFilter{}, utils.InvalidQueryParam("union_status", "oneof", "must be one of: union non_union either")
+	}
+	if raw := query.Get("from"); raw != "" {
+		if filter.From, err = store.ParseDate(raw); err != nil {
+			return store.GigFilter{}, utils.InvalidQueryParam("from", "datetime", "must be a YYYY-MM-DD date")
+		}
+	}
+	if raw := query.Get("to"); raw != "" {
+		if filter.To, err = store.ParseDate(raw); err != nil {
+			return store.GigFilter{}, utils.InvalidQueryParam("to", "datetime", "must be a YYYY-MM-DD date")
+		}
+	}
+	if raw := query.Get("min_rate"); raw != "" {
+		if filter.MinRate, err = strconv.Atoi(raw); err != nil || filter.MinRate < 0 {
+			return store.GigFilter{}, utils.InvalidQueryParam("min_rate", "min", "must be a whole number of at least 0")
+		}
+	}
+	if raw := query.Get("currency"); raw != "" {
+		filter.Currency = strings.ToUpper(raw)
+		if err := utils.Validate.Var(filter.Currency, "iso4217"); err != nil {
+			return store.GigFilter{}, utils.InvalidQueryParam("currency", "iso4217", "must be an ISO 4217 currency code")
+		}
+	}
+
+	if filter.Within, err = app.boundingBoxFromQuery(r); err != nil {
+		return store.GigFilter{}, err
+	}
+	return filter, nil
+}
+
+// GetGig godoc
+//
+//	@Summary		Fetches a gig
+//	@Description	Fetches a gig by its post ID. Send the ETag back in If-None-Match to get 304 Not Modified.
+//	@Tags			gigs
+//	@Produce		json
+//	@Param			id				path		int		true	"Gig (post) ID"
+//	@Param			If-None-Match	header		string	false	"ETag from a previous response"
+//	@Success		200				{object}	store.Gig
+//	@Success		304				"Not modified"
+//	@Failure		400				{object}	utils.Problem
+//	@Failure		404				{object}	utils.Problem
+//	@Failure		500				{object}	utils.Problem
+//	@Router			/gigs/{id} [get]
+func (app *application) getGigHandler(w http.ResponseWriter, r *http.Request) {
+	gig := getGigFromCtx(r)
+
+	etag := gigETag(gig)
+	if notModified(w, r, etag) {
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	if err := utils.JsonResponse(w, http.StatusOK, gig); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+type UpdateGigPayload struct {
+	Title         *string             `json:"title" validate:"omitempty,max=100"`
+	Content       *string             `json:"content" validate:"omitempty,max=1000"`
+	Tags          *[]string           `json:"tags" validate:"omitempty,max=100"`
+	Role          *store.CrewRole     `json:"role" validate:"omitempty,oneof=director_of_photography camera_operator steadicam_operator drone_pilot first_ac second_ac dit gaffer best_boy_electric electrician key_grip grip"`
+	ShootStart    *store.Date         `json:"shoot_start" swaggertype:"string" format:"date"`
+	ShootEnd      *store.Date         `json:"shoot_end" swaggertype:"string" format:"date"`
+	CallTime      *string             `json:"call_time" validate:"omitempty,datetime=15:04"`
+	DurationHours *int                `json:"duration_hours" validate:"omitempty,min=1,max=24"`
+	DayRateMin    *int                `json:"day_rate_min" validate:"omitempty,min=0"`
+	DayRateMax    *int                `json:"day_rate_max" validate:"omitempty,min=0"`
+	Currency      *string             `json:"currency" validate:"omitempty,iso4217"`
+	Gear          *[]string           `json:"gear" validate:"omitempty,max=50,dive,required,max=100"`
+	UnionStatus   *store.UnionStatus  `json:"union_status" validate:"omitempty,oneof=union non_union either"`
+	Location      *GigLocationPayload `json:"location"`
+	Version       *int                `json:"version" validate:"omitempty,min=0"` // used when If-Match is absent
+}
+
+// UpdateGig godoc
+//
+//	@Summary		Updates a gig
+//	@Description	Updates the poster's own gig. A new location replaces the old one entirely. Send the ETag from GET in If-Match (or the version field); a stale ETag gets 412 and a stale version 409, both with the current gig. The status is changed with PUT /gigs/{id}/status.
+//	@Tags			gigs
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int					true	"Gig (post) ID"
+//	@Param			If-Match	header		string				false	"ETag of the gig being edited"
+//	@Param			payload		body		UpdateGigPayload	true	"Gig payload"
+//	@Success		200			{object}	store.Gig
+//	@Failure		400			{object}	utils.Problem
+//	@Failure		401			{object}	utils.Problem
+//	@Failure		403			{object}	utils.Problem
+//	@Failure		404			{object}	utils.Problem
+//	@Failure		409			{object}	utils.Problem
+//	@Failure		412			{object}	utils.Problem
+//	@Failure		428			{object}	utils.Problem
+//	@Failure		500			{object}	utils.Problem
+//	@Failure		502			{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/gigs/{id} [patch]
+func (app *application) updateGigHandler(w http.ResponseWriter, r *http.Request) {
+	gig := getGigFromCtx(r)
+
+	var payload UpdateGigPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := requireGigOwner(r, gig); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	if err := checkPrecondition(r, gigETag(gig), payload.Version, gig.Version, gig); err != nil {
+		w.Header().Set("ETag", gigETag(gig))
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	payload.apply(gig)
+	if err := validateGig(gig); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	if err := app.locateGig(r.Context(), gig); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	if err := app.store.Gigs.Update(r.Context(), gig); err != nil {
+		switch {
+		case errors.Is(err, store.ErrEditConflict):
+			app.gigConflictResponse(w, r, gig.ID)
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", gigETag(gig))
+	if err := utils.JsonResponse(w, http.StatusOK, gig); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// apply copies the fields present in the payload onto gig
+func (p UpdateGigPayload) apply(gig *store.Gig) {
+	if p.Title != nil {
+		gig.Title = *p.Title
+	}
+	if p.Content != nil {
+		gig.Content = *p.Content
+	}
+	if p.Tags != nil {
+		gig.Tags = *p.Tags
+	}
+	if p.Role != nil {
+		gig.Role = *p.Role
+	}
+	if p.ShootStart != nil {
+		gig.ShootStart = *p.ShootStart
+	}
+	if p.ShootEnd != nil {
+		gig.ShootEnd = *p.ShootEnd
+	}
+	if p.CallTime != nil {
+		gig.CallTime = *p.CallTime
+	}
+	if p.DurationHours != nil {
+		gig.DurationHours = *p.DurationHours
+	}
+	if p.DayRateMin != nil {
+		gig.DayRateMin = *p.DayRateMin
+	}
+	if p.DayRateMax != nil {
+		gig.DayRateMax = *p.DayRateMax
+	}
+	if p.Currency != nil {
+		gig.Currency = *p.Currency
+	}
+	if p.Gear != nil {
+		gig.Gear = *p.Gear
+	}
+	if p.UnionStatus != nil {
+		gig.UnionStatus = *p.UnionStatus
+	}
+	if p.Location != nil {
+		gig.Location = p.Location.toLocation()
+	}
+}
+
+type UpdateGigStatusPayload struct {
+	Status store.GigStatus `json:"status" validate:"required,oneof=open filled cancelled"`
+}
+
+// UpdateGigStatus godoc
+//
+//	@Summary		Changes a gig's status
+//	@Description	Marks the poster's own gig filled or cancelled, or reopens a filled gig. Cancelled gigs cannot change again; a change that is not allowed gets 409 invalid_transition. Filling or cancelling a gig closes the applications still awaiting a decision and notifies those applicants.
+//	@Tags			gigs
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Gig (post) ID"
+//	@Param			payload	body		UpdateGigStatusPayload	true	"New status"
+//	@Success		200		{object}	store.Gig
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		403		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		409		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/gigs/{id}/status [put]
+func (app *application) updateGigStatusHandler(w http.ResponseWriter, r *http.Request) {
+	gig := getGigFromCtx(r)
+
+	var payload UpdateGigStatusPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := requireGigOwner(r, gig); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	// The status change and closing the applications commit together; a rerun starts from the gig as read
+	current := *gig
+	err := app.store.WithTx(r.Context(), func(tx store.Storage) error {
+		*gig = current
+		if err := tx.Gigs.UpdateStatus(r.Context(), gig, payload.Status); err != nil {
+			return err
+		}
+		if gig.Status == store.GigOpen {
+			return nil
+		}
+		return closeApplications(r.Context(), tx, gig)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidTransition):
+			utils.WriteProblem(w, r, utils.WrapAppError(http.StatusConflict, utils.CodeInvalidTransition,
+				fmt.Sprintf("A %s gig cannot become %s", current.Status, payload.Status), err))
+		case errors.Is(err, store.ErrEditConflict):
+			app.gigConflictResponse(w, r, gig.ID)
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", gigETag(gig))
+	if err := utils.JsonResponse(w, http.StatusOK, gig); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// requireGigOwner only lets the user who posted a gig change it
+func requireGigOwner(r *http.Request, gig *store.Gig) error {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		return err
+	}
+	if userID != gig.UserID {
+		return utils.NewAppError(http.StatusForbidden, utils.CodeForbidden, "Only the poster can change this gig")
+	}
+	return nil
+}
+
+// gigConflictResponse reports a write that lost a race, with the gig as it is now
+func (app *application) gigConflictResponse(w http.ResponseWriter, r *http.Request, gigID int64) {
+	current, err := app.store.Gigs.GetByID(r.Context(), gigID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.NotFoundResponse(w, r, err)
+			return
+		}
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", gigETag(current))
+	utils.WriteProblem(w, r, editConflict(current))
+}
+
+func (app *application) gigsContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "gigID"), 10, 64)
+		if err != nil {
+			utils.BadRequestResponse(w, r, err)
+			return
+		}
+
+		gig, err := app.store.Gigs.GetByID(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				utils.NotFoundResponse(w, r, err)
+			default:
+				utils.InternalServerError(w, r, err)
+			}
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), gigCtx, gig)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getGigFromCtx(r *http.Request) *store.Gig {
+	gig, _ := r.Context().Value(gigCtx).(*store.Gig)
+	return gig
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gigPayload(role string) map[string]any {
+	return map[string]any{
+		"title":          "Crew call",
+		"content":        "Two day car commercial",
+		"role":           role,
+		"shoot_start":    "2031-06-02",
+		"shoot_end":      "2031-06-03",
+		"call_time":      "06:30",
+		"duration_hours": 12,
+		"day_rate_min":   600,
+		"day_rate_max":   900,
+		"currency":       "USD",
+		"gear":           []string{"Alexa Mini"},
+		"union_status":   "either",
+		"location": map[string]any{
+			"city": "Kansas City", "state": "MO", "zip_code": "64105", "country": "USA",
+			"latitude": 39.1, "longitude": -94.58,
+		},
+	}
+}
+
+func TestGigs(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	srv.app.geocoder.(*fakeGeocoder).locations["59718"] = store.Location{
+		City: "Bozeman", State: "MT", ZIPCode: "59718", Country: "USA", Latitude: 45.68, Longitude: -111.04,
+	}
+
+	poster := srv.newClient(t)
+	poster.signUp("producer@example.com")
+	crew := srv.newClient(t)
+	crew.signUp("crew@example.com")
+
+	create := func(payload map[string]any) store.Gig {
+		t.Helper()
+		resp := poster.do(http.MethodPost, "/v1/gigs/", payload)
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		var gig store.Gig
+		resp.decode(t, &gig)
+		return gig
+	}
+
+	dp := create(gigPayload("director_of_photography"))
+	assert.Equal(t, store.GigOpen, dp.Status)
+	assert.Equal(t, "2031-06-02", dp.ShootStart.String())
+	assert.Equal(t, "06:30", dp.CallTime)
+
+	montana := gigPayload("first_ac")
+	montana["location"] = map[string]any{"city": "Bozeman", "state": "MT", "zip_code": "59718", "country": "USA"}
+	ac := create(montana)
+	assert.InDelta(t, 45.68, ac.Location.Latitude, 1e-9, "coordinates come from the ZIP code")
+
+	t.Run("rejects invalid gigs", func(t *testing.T) {
+		for field, value := range map[string]any{
+			"role":         "producer",
+			"shoot_end":    "2031-06-01",
+			"call_time":    "6:30pm",
+			"day_rate_max": 500,
+			"currency":     "dollars",
+			"union_status": "maybe",
+			"shoot_start":  "June 2nd",
+		} {
+			payload := gigPayload("gaffer")
+			payload[field] = value
+			resp := poster.do(http.MethodPost, "/v1/gigs/", payload)
+			assert.Equal(t, http.StatusBadRequest, resp.status, field)
+		}
+
+		resp := poster.do(http.MethodPost, "/v1/gigs/", gigPayload("gaffer"))
+		require.Equal(t, http.StatusCreated, resp.status)
+		var gig store.Gig
+		resp.decode(t, &gig)
+		assert.Equal(t, store.RoleGaffer, gig.Role)
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		anonymous := srv.newClient(t)
+		resp := anonymous.do(http.MethodPost, "/v1/gigs/", gigPayload("grip"))
+		assert.Equal(t, http.StatusUnauthorized, resp.status)
+	})
+
+	list := func(query string) (int, []store.Gig) {
+		t.Helper()
+		resp := crew.do(http.MethodGet, "/v1/gigs/?"+query, nil)
+		var gigs []store.Gig
+		if resp.status == http.StatusOK {
+			resp.decode(t, &gigs)
+		}
+		return resp.status, gigs
+	}
+	ids := func(gigs []store.Gig) []int64 {
+		ids := []int64{}
+		for _, g := range gigs {
+			ids = append(ids, g.ID)
+		}
+		return ids
+	}
+
+	t.Run("list filters", func(t *testing.T) {
+		status, gigs := list("role=first_ac")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, []int64{ac.ID}, ids(gigs))
+
+		status, gigs = list("role=director_of_photography&zip=64105&miles=10")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, []int64{dp.ID}, ids(gigs))
+
+		status, gigs = list("zip=59718&from=2031-06-03&to=2031-06-30&gear=Alexa+Mini&min_rate=900&currency=usd")
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, []int64{ac.ID}, ids(gigs))
+
+		status, gigs = list("from=2031-07-01")
+		require.Equal(t, http.StatusOK, status)
+		assert.Empty(t, gigs)
+
+		for _, query := range []string{"role=producer", "status=closed", "from=tomorrow", "min_rate=-1", "currency=dollars", "miles=5"} {
+			status, _ := list(query)
+			assert.Equal(t, http.StatusBadRequest, status, query)
+		}
+	})
+
+	t.Run("get with etag", func(t *testing.T) {
+		resp := crew.do(http.MethodGet, fmt.Sprintf("/v1/gigs/%d/", dp.ID), nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		etag := resp.header.Get("ETag")
+		require.NotEmpty(t, etag)
+
+		resp = crew.doWithHeader(http.MethodGet, fmt.Sprintf("/v1/gigs/%d/", dp.ID), nil, http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, resp.status)
+
+		resp = crew.do(http.MethodGet, "/v1/gigs/999999/", nil)
+		assert.Equal(t, http.StatusNotFound, resp.status)
+	})
+
+	t.Run("only the poster can edit", func(t *testing.T) {
+		path := fmt.Sprintf("/v1/gigs/%d/", dp.ID)
+		resp := crew.do(http.MethodPatch, path, map[string]any{"day_rate_max": 1000, "version": dp.Version})
+		assert.Equal(t, http.StatusForbidden, resp.status)
+		resp = crew.do(http.MethodPut, path+"status", map[string]any{"status": "filled"})
+		assert.Equal(t, http.StatusForbidden, resp.status)
+
+		resp = poster.do(http.MethodPatch, path, map[string]any{"day_rate_max": 1000})
+		assert.Equal(t, http.StatusPreconditionRequired, resp.status)
+
+		resp = poster.do(http.MethodPatch, path, map[string]any{"day_rate_min": 1200, "version": dp.Version})
+		assert.Equal(t, http.StatusBadRequest, resp.status, "min above the current max")
+
+		resp = poster.do(http.MethodPatch, path, map[string]any{"day_rate_max": 1000, "version": dp.Version})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var updated store.Gig
+		resp.decode(t, &updated)
+		assert.Equal(t, 1000, updated.DayRateMax)
+		assert.Equal(t, dp.Version+1, updated.Version)
+
+		resp = poster.do(http.MethodPatch, path, map[string]any{"day_rate_max": 1100, "version": dp.Version})
+		assert.Equal(t, http.StatusConflict, resp.status)
+		assert.Equal(t, "edit_conflict", resp.problem(t)["code"])
+	})
+
+	t.Run("not changed through posts", func(t *testing.T) {
+		path := fmt.Sprintf("/v1/posts/%d/", dp.ID)
+		anonymous := srv.newClient(t)
+		assert.Equal(t, http.StatusUnauthorized, anonymous.do(http.MethodPatch, path, map[string]any{"title": "Mine now"}).status)
+		assert.Equal(t, http.StatusUnauthorized, anonymous.do(http.MethodDelete, path, nil).status)
+
+		for _, c := range []*testClient{crew, poster} {
+			resp := c.do(http.MethodPatch, path, map[string]any{"title": "Mine now", "version": dp.Version})
+			assert.Equal(t, http.StatusForbidden, resp.status)
+			assert.Equal(t, http.StatusForbidden, c.do(http.MethodDelete, path, nil).status)
+		}
+		resp := crew.do(http.MethodGet, fmt.Sprintf("/v1/gigs/%d/", dp.ID), nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		var gig store.Gig
+		resp.decode(t, &gig)
+		assert.Equal(t, "Crew call", gig.Title)
+	})
+
+	t.Run("status lifecycle", func(t *testing.T) {
+		path := fmt.Sprintf("/v1/gigs/%d/status", ac.ID)
+		setStatus := func(status string) testResponse {
+			t.Helper()
+			return poster.do(http.MethodPut, path, map[string]any{"status": status})
+		}
+
+		resp := setStatus("filled")
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		_, gigs := list("role=first_ac")
+		assert.Empty(t, gigs, "filled gigs leave the default listing")
+		_, gigs = list("role=first_ac&status=filled")
+		assert.Equal(t, []int64{ac.ID}, ids(gigs))
+
+		assert.Equal(t, http.StatusOK, setStatus("open").status)
+		assert.Equal(t, http.StatusOK, setStatus("cancelled").status)
+
+		resp = setStatus("open")
+		assert.Equal(t, http.StatusConflict, resp.status)
+		assert.Equal(t, "invalid_transition", resp.problem(t)["code"])
+
+		assert.Equal(t, http.StatusBadRequest, setStatus("closed").status)
+
+		_, gigs = list("role=first_ac&status=any")
+		assert.Equal(t, []int64{ac.ID}, ids(gigs))
+	})
+}
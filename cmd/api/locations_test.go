@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZipLookup(t *testing.T) {
+	app := newTestApplication(t)
+	geocoder := &fakeGeocoder{locations: map[string]store.Location{
+		"90028": {City: "Los Angeles", State: "California", ZIPCode: "90028", Country: "United States", Latitude: 34.1, Longitude: -118.33},
+	}}
+	app.geocoder = geocoder
+
+	srv := newTestServer(t, app)
+	c := srv.newClient(t)
+	c.signUp("scout@example.com")
+
+	for range 2 {
+		var loc store.Location
+		resp := c.do(http.MethodGet, "/v1/locations/zip/90028", nil)
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		resp.decode(t, &loc)
+		assert.True(t, strings.EqualFold("Los Angeles", loc.City), loc.City)
+	}
+	assert.Equal(t, 1, geocoder.lookups, "second lookup should be served from the store")
+
+	resp := c.do(http.MethodGet, "/v1/locations/zip/00000", nil)
+	assert.Equal(t, http.StatusNotFound, resp.status, string(resp.body))
+}
@@ -1,16 +1,29 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"database/sql"
+	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/michaelhoman/ShotSeek/internal/auth"
 	"github.com/michaelhoman/ShotSeek/internal/config"
+	"github.com/michaelhoman/ShotSeek/internal/location"
+	"github.com/michaelhoman/ShotSeek/internal/mailer"
+	"github.com/michaelhoman/ShotSeek/internal/metrics"
+	int_middleware "github.com/michaelhoman/ShotSeek/internal/middleware"
 	"github.com/michaelhoman/ShotSeek/internal/postgres_db"
+	"github.com/michaelhoman/ShotSeek/internal/secrets"
+	"github.com/michaelhoman/ShotSeek/internal/tracing"
 	"github.com/michaelhoman/ShotSeek/internal/utils"
 
 	// postgres_store "github.com/michaelhoman/ShotSeek/internal/store/postgres"
 	"github.com/michaelhoman/ShotSeek/internal/store"
+	"gopkg.in/yaml.v3"
 )
 
 const version = "0.0.1"
@@ -60,52 +73,149 @@ func main() {
 	// 	},
 	// }
 
-	// Load configuration
-	cfg := config.Load()
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file; environment variables override it")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	migrateOnStart := flag.Bool("migrate-on-start", false, "apply pending database migrations before serving")
+	flag.Parse()
+
+	// Secret references in the config (file://, env://, enc://) are resolved through this provider
+	secretsProvider, err := secrets.NewResolverFromEnv()
+	if err != nil {
+		log.Fatalf("Error initializing secrets: %v", err)
+	}
+
+	// Load configuration: defaults, then the config file, then environment variables
+	cfg, err := config.Load(context.Background(), *configFile, secretsProvider)
+	if *printConfig {
+		if out, marshalErr := yaml.Marshal(cfg.Redacted()); marshalErr == nil {
+			os.Stdout.Write(out)
+		}
+		if err != nil {
+			log.Fatalf("Invalid configuration:\n%v", err)
+		}
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
 
 	// Initialize logger
-	utils.InitLogger()
+	if err := utils.InitLogger(cfg.Log, cfg.Env); err != nil {
+		log.Fatalf("Error initializing logger: %v", err)
+	}
 	defer utils.CleanupLogger()
 
 	// Access logger
 	logger := utils.Logger
-	// Database
-	db, err := postgres_db.New(
-		cfg.Db.Addr,
-		cfg.Db.MaxOpenConns,
-		cfg.Db.MaxIdleConns,
-		cfg.Db.MaxIdleTime,
-	)
+
+	// Tracing
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, version)
 	if err != nil {
 		logger.Fatal(err)
 	}
-
-	defer db.Close()
-	logger.Info("Database connection pool established")
-
-	store := store.NewPostgresStorage(db)
+	defer shutdownTracing(context.Background())
+	// Storage: Postgres, or the in-memory store for demos and tests
+	var db *sql.DB
+	var connector *postgres_db.Connector
+	var storage store.Storage
+	switch cfg.Db.Driver {
+	case "memory":
+		logger.Warn("Using the in-memory store, all data is lost on restart")
+		storage = store.NewMemoryStorage()
+	default:
+		db, connector, err = postgres_db.NewRotatable(
+			cfg.Db.Addr,
+			cfg.Db.MaxOpenConns,
+			cfg.Db.MaxIdleConns,
+			cfg.Db.MaxIdleTime,
+		)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		defer db.Close()
+		logger.Info("Database connection pool established")
+
+		if err := metrics.RegisterDB(db, "shotseek"); err != nil {
+			logger.Fatal(err)
+		}
+
+		// Never serve against an outdated schema
+		if err := prepareSchema(context.Background(), db, *migrateOnStart); err != nil {
+			logger.Fatal(err)
+		}
+
+		storage = store.NewPostgresStorage(db)
+	}
 
 	jwtService := auth.NewJWTService(cfg.Auth.Token.Secret, cfg.Auth.Token.Exp)
 
-	// authHandler := auth.NewAuthHandler(store, cfg, jwtService)
+	// authHandler := auth.NewAuthHandler(storage, cfg, jwtService)
 	// Initialize JWTAuth with the ECDSA keys
-	jwtAuth, err := auth.NewJWTAuth()
+	jwtAuth, err := auth.NewJWTAuth(context.Background(), secretsProvider, cfg.Auth.Keys)
 	if err != nil {
 		log.Fatalf("Error initializing JWTAuth: %v", err)
 	}
 
-	// Example usage of the jwtAuth instance
-	fmt.Println("JWT Auth initialized:", jwtAuth)
+	rateLimiter, err := newRateLimiter(cfg.RateLimit, db)
+	if err != nil {
+		logger.Fatal(err)
+	}
 
-	app := &application{
-		config:     cfg,
-		store:      store,
+	trustedProxies, err := int_middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	cors, err := int_middleware.CORS(cfg.CORS)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	mail := mailer.NewSwapper(newMailer(cfg.Mail))
+
+	// SIGHUP rotates the database, mail and JWT credentials in place
+	reloader := &secretsReloader{
+		provider:   secretsProvider,
+		configFile: *configFile,
+		cfg:        cfg,
+		db:         db,
+		connector:  connector,
+		mail:       mail,
 		jwtService: jwtService,
 		jwtAuth:    jwtAuth,
-		auth:       auth.NewAuthHandler(store, cfg, jwtService, jwtAuth),
+	}
+	go reloader.reloadOnSIGHUP()
+
+	app := &application{
+		config:         cfg,
+		store:          storage,
+		mailer:         mail,
+		jwtService:     jwtService,
+		jwtAuth:        jwtAuth,
+		auth:           auth.NewAuthHandler(storage, cfg, jwtService, jwtAuth, mail),
+		geocoder:       location.NewNominatimGeocoder(),
+		rateLimiter:    rateLimiter,
+		trustedProxies: trustedProxies,
+		cors:           cors,
 	}
 
 	mux := app.mount()
 
-	logger.Fatal(app.run(mux))
+	// Stop on Ctrl-C or when the platform asks the process to terminate
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Holds lapse on their own; sweep them up once a minute
+	go app.expireHoldsEvery(ctx, time.Minute)
+
+	go func() {
+		if err := app.runAdmin(); err != nil {
+			logger.Fatal(err)
+		}
+	}()
+
+	if err := app.run(ctx, mux); err != nil {
+		logger.Fatal(err)
+	}
+	logger.Info("Server stopped")
 }
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/michaelhoman/ShotSeek/internal/migrate"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+// prepareSchema applies pending migrations when migrateOnStart is set, then refuses to
+// continue if the schema is still behind the migrations embedded in this binary
+func prepareSchema(ctx context.Context, db *sql.DB, migrateOnStart bool) error {
+	runner, err := migrate.NewRunner(db)
+	if err != nil {
+		return err
+	}
+
+	if migrateOnStart {
+		results, err := runner.Up(ctx)
+		for _, result := range results {
+			utils.Logger.Infow("migration applied", "version", result.Source.Version, "file", result.Source.Path, "duration", result.Duration)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := runner.EnsureCurrent(ctx); err != nil {
+		return err
+	}
+
+	current, _, err := runner.Versions(ctx)
+	if err != nil {
+		return err
+	}
+	utils.Logger.Infow("Database schema is current", "version", current)
+	return nil
+}
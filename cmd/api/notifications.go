@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+// ListNotifications godoc
+//
+//	@Summary		Lists your notifications
+//	@Description	Lists the signed in user's notifications, newest first. link is the API path of the resource each one is about. Follow next_cursor (also sent as a Link header) for the next page, keeping the same unread filter.
+//	@Tags			notifications
+//	@Produce		json
+//	@Param			unread	query		bool	false	"Only notifications not yet marked read"
+//	@Param			limit	query		int		false	"Page size, at most 100"	default(20)
+//	@Param			cursor	query		string	false	"next_cursor from the previous page"
+//	@Success		200		{array}		store.Notification
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/notifications [get]
+func (app *application) listNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	filter := store.NotificationFilter{Page: page}
+	if raw := r.URL.Query().Get("unread"); raw != "" {
+		if filter.UnreadOnly, err = strconv.ParseBool(raw); err != nil {
+			utils.WriteProblem(w, r, utils.InvalidQueryParam("unread", "boolean", "must be true or false"))
+			return
+		}
+	}
+
+	notifications, err := app.store.Notifications.ListByUser(r.Context(), userID, filter)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := pagination.Write(w, r, notifications); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// MarkNotificationRead godoc
+//
+//	@Summary		Marks a notification read
+//	@Description	Marks one of the signed in user's notifications read. Marking it again keeps the first read time.
+//	@Tags			notifications
+//	@Produce		json
+//	@Param			id	path		int	true	"Notification ID"
+//	@Success		200	{object}	store.Notification
+//	@Failure		400	{object}	utils.Problem
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/notifications/{id}/read [post]
+func (app *application) markNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "notificationID"), 10, 64)
+	if err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	notification, err := app.store.Notifications.MarkRead(r.Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, notification); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
@@ -6,8 +6,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	location_package "github.com/michaelhoman/ShotSeek/internal/location"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
 	// store "github.com/michaelhoman/ShotSeek/internal/store/postgres"
 	"github.com/michaelhoman/ShotSeek/internal/store"
 	"github.com/michaelhoman/ShotSeek/internal/utils"
@@ -32,13 +37,12 @@ type CreatePostPayload struct {
 //	@Produce		json
 //	@Param			payload	body		CreatePostPayload	true	"Post payload"
 //	@Success		200		{object}	store.Post
-//	@Failure		400		{object}	error
-//	@Failure		404		{object}	error
-//	@Failure		500		{object}	error
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/posts [post]
 func (app *application) createPostsHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("Create post handler")
 
 	var payload CreatePostPayload
 	if err := utils.ReadJSON(w, r, &payload); err != nil {
@@ -51,15 +55,19 @@ func (app *application) createPostsHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
 	post := store.Post{
 		Title:   payload.Title,
 		Content: payload.Content,
 		Tags:    payload.Tags,
-		UserID:  1,
+		UserID:  userID,
 	}
 
-	fmt.Println("Tags data:", post.Tags)
-
 	ctx := r.Context()
 
 	if err := app.store.Posts.Create(ctx, &post); err != nil {
@@ -73,64 +81,275 @@ func (app *application) createPostsHandler(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// ListPosts godoc
+//
+//	@Summary		Lists posts
+//	@Description	Lists posts a page at a time, without their comments. Follow next_cursor (also sent as a Link header) for the next page, keeping the same filters and sort.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			tag		query		[]string	false	"Only posts carrying every one of these tags"	collectionFormat(multi)
+//	@Param			author	query		string		false	"Author user ID"
+//	@Param			from	query		string		false	"Created at or after, RFC 3339 or YYYY-MM-DD"
+//	@Param			to		query		string		false	"Created before, RFC 3339, or through the end of YYYY-MM-DD"
+//	@Param			sort	query		string		false	"Newest first by default"	Enums(-created_at, created_at)
+//	@Param			limit	query		int			false	"Page size, at most 100"	default(20)
+//	@Param			cursor	query		string		false	"next_cursor from the previous page"
+//	@Success		200		{array}		store.Post
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Router			/posts [get]
+func (app *application) listPostsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parsePostFilter(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	page, err := app.store.Posts.List(r.Context(), filter)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := pagination.Write(w, r, page); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// parsePostFilter reads the list posts query parameters
+func parsePostFilter(r *http.Request) (store.PostFilter, error) {
+	query := r.URL.Query()
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		return store.PostFilter{}, err
+	}
+	filter := store.PostFilter{Tags: query["tag"], Page: page}
+
+	if raw := query.Get("author"); raw != "" {
+		if filter.AuthorID, err = uuid.Parse(raw); err != nil {
+			return store.PostFilter{}, utils.InvalidQueryParam("author", "uuid", "must be a valid UUID")
+		}
+	}
+	if raw := query.Get("from"); raw != "" {
+		if filter.From, err = parseDateParam(raw, false); err != nil {
+			return store.PostFilter{}, utils.InvalidQueryParam("from", "datetime", "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+	}
+	if raw := query.Get("to"); raw != "" {
+		if filter.To, err = parseDateParam(raw, true); err != nil {
+			return store.PostFilter{}, utils.InvalidQueryParam("to", "datetime", "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+	}
+
+	switch sort := store.PostSort(query.Get("sort")); sort {
+	case "", store.PostSortNewest, store.PostSortOldest:
+		filter.Sort = sort
+	default:
+		return store.PostFilter{}, utils.InvalidQueryParam("sort", "oneof", "must be one of: -created_at created_at")
+	}
+	return filter, nil
+}
+
+// parseDateParam accepts an RFC 3339 timestamp or a bare date in UTC. A bare date used as
+// an exclusive upper bound means the end of that day, so to=2025-01-31 includes the 31st.
+func parseDateParam(raw string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+const (
+	defaultSearchMiles = 25
+	maxSearchMiles     = 500
+	maxSearchQuery     = 200
+)
+
+type postSearchResponse struct {
+	Results []store.PostSearchResult `json:"results"`
+	Tags    []store.TagFacet         `json:"tags"`
+}
+
+// SearchPosts godoc
+//
+//	@Summary		Searches posts
+//	@Description	Full-text search over post titles, tags and content, best match first. q takes web search syntax: words, "quoted phrases", or, and -excluded words. Each result has an HTML highlight with matches in <mark>, and tags counts every matching post's tags. Pass zip (and optionally miles) to only match authors near that ZIP code. Follow next_cursor (also sent as a Link header) for the next page.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			q		query		string		true	"Search terms"
+//	@Param			tag		query		[]string	false	"Only posts carrying every one of these tags"	collectionFormat(multi)
+//	@Param			zip		query		string		false	"Only authors near this ZIP code"
+//	@Param			miles	query		number		false	"Radius around zip, at most 500"	default(25)
+//	@Param			limit	query		int			false	"Page size, at most 100"			default(20)
+//	@Param			cursor	query		string		false	"next_cursor from the previous page"
+//	@Success		200		{object}	postSearchResponse
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Failure		502		{object}	utils.Problem
+//	@Router			/posts/search [get]
+func (app *application) searchPostsHandler(w http.ResponseWriter, r *http.Request) {
+	search, err := app.parsePostSearch(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	results, err := app.store.Posts.Search(r.Context(), search)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	next := pagination.SetLink(w, r, results.Next)
+	response := postSearchResponse{Results: results.Items, Tags: results.Tags}
+	if err := utils.JsonPageResponse(w, http.StatusOK, response, next); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// parsePostSearch reads the search query parameters
+func (app *application) parsePostSearch(r *http.Request) (store.PostSearch, error) {
+	query := r.URL.Query()
+
+	q := strings.TrimSpace(query.Get("q"))
+	switch {
+	case q == "":
+		return store.PostSearch{}, utils.InvalidQueryParam("q", "required", "is required")
+	case len(q) > maxSearchQuery:
+		return store.PostSearch{}, utils.InvalidQueryParam("q", "max", fmt.Sprintf("must be at most %d characters", maxSearchQuery))
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		return store.PostSearch{}, err
+	}
+	search := store.PostSearch{Query: q, Tags: query["tag"], Page: page}
+
+	if search.Within, err = app.boundingBoxFromQuery(r); err != nil {
+		return store.PostSearch{}, err
+	}
+	return search, nil
+}
+
+// boundingBoxFromQuery resolves the zip and miles query parameters to a bounding box the
+// same way the nearby locations lookup does. It returns nil when zip is absent.
+func (app *application) boundingBoxFromQuery(r *http.Request) (*store.BoundingBox, error) {
+	center, miles, err := app.radiusFromQuery(r)
+	if err != nil || center == nil {
+		return nil, err
+	}
+	box := boundingBox(center, miles)
+	return &box, nil
+}
+
+// radiusFromQuery resolves the zip query parameter to its location and reads the miles
+// around it. The location is nil when zip is absent.
+func (app *application) radiusFromQuery(r *http.Request) (*store.Location, float64, error) {
+	query := r.URL.Query()
+
+	zip := query.Get("zip")
+	if zip == "" {
+		if query.Has("miles") {
+			return nil, 0, utils.InvalidQueryParam("zip", "required_with", "is required with miles")
+		}
+		return nil, 0, nil
+	}
+
+	miles := float64(defaultSearchMiles)
+	if raw := query.Get("miles"); raw != "" {
+		var err error
+		miles, err = strconv.ParseFloat(raw, 64)
+		if err != nil || miles <= 0 || miles > maxSearchMiles {
+			return nil, 0, utils.InvalidQueryParam("miles", "range", fmt.Sprintf("must be a number greater than 0 and at most %d", maxSearchMiles))
+		}
+	}
+
+	center, err := app.lookupByZip(r.Context(), zip)
+	if err != nil {
+		return nil, 0, err
+	}
+	return center, miles, nil
+}
+
+func boundingBox(center *store.Location, miles float64) store.BoundingBox {
+	minLat, maxLat, minLon, maxLon := location_package.GetBoundingBox(center.Latitude, center.Longitude, miles)
+	return store.BoundingBox{MinLat: minLat, MaxLat: maxLat, MinLon: minLon, MaxLon: maxLon}
+}
+
 // GetPost godoc
 //
 //	@Summary		Fetches a post
-//	@Description	Fetches a post by ID
+//	@Description	Fetches a post by ID with the first page of its comments, newest first; comments_next_cursor continues through GET /posts/{id}/comments. The ETag header changes whenever the post or one of its comments does; send it back in If-None-Match to get 304 Not Modified.
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		int	true	"Post ID"
-//	@Success		200	{object}	store.Post
-//	@Failure		400	{object}	error
-//	@Failure		404	{object}	error
-//	@Failure		500	{object}	error
+//	@Param			id				path		int		true	"Post ID"
+//	@Param			If-None-Match	header		string	false	"ETag from a previous response"
+//	@Success		200				{object}	store.Post
+//	@Success		304				"Not modified"
+//	@Failure		400				{object}	utils.Problem
+//	@Failure		404				{object}	utils.Problem
+//	@Failure		500				{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id} [get]
 func (app *application) getPostHandler(w http.ResponseWriter, r *http.Request) {
 	post := getPostFromCtx(r)
-	// ctx := r.Context()
 
-	comments, err := app.store.Comments.GetByPostID(r.Context(), post.ID)
-
-	if err != nil {
-		utils.InternalServerError(w, r, err)
+	etag := postETag(post)
+	if notModified(w, r, etag) {
 		return
 	}
 
-	post.Comments = comments
-
+	w.Header().Set("ETag", etag)
 	if err := utils.JsonResponse(w, http.StatusOK, post); err != nil {
 		utils.InternalServerError(w, r, err)
 		return
 	}
-
 }
 
 type UpdatePostPayload struct {
 	Title   *string   `json:"title" validate:"omitempty,max=100"`
 	Content *string   `json:"content" validate:"omitempty,max=1000"`
 	Tags    *[]string `json:"tags" validate:"omitempty,max=100"`
-	// Version int       `json:"version" validate:"required"`
+	Version *int      `json:"version" validate:"omitempty,min=0"` // used when If-Match is absent
 }
 
 // UpdatePost godoc
 //
 //	@Summary		Updates a post
-//	@Description	Updates an existing post from payload
+//	@Description	Updates an existing post from payload. Send the ETag from GET in If-Match (or the version field); a stale ETag gets 412 and a stale version 409, both with the current post.
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		int					true	"Post ID"
-//	@Param			payload	body		UpdatePostPayload	true	"Post payload"
-//	@Success		200		{object}	store.Post
-//	@Failure		400		{object}	error
-//	@Failure		404		{object}	error
-//	@Failure		500		{object}	error
+//	@Param			id			path		int					true	"Post ID"
+//	@Param			If-Match	header		string				false	"ETag of the post being edited"
+//	@Param			payload		body		UpdatePostPayload	true	"Post payload"
+//	@Success		200			{object}	store.Post
+//	@Failure		400			{object}	utils.Problem
+//	@Failure		401			{object}	utils.Problem
+//	@Failure		403			{object}	utils.Problem
+//	@Failure		404			{object}	utils.Problem
+//	@Failure		409			{object}	utils.Problem
+//	@Failure		412			{object}	utils.Problem
+//	@Failure		428			{object}	utils.Problem
+//	@Failure		500			{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id} [patch]
 func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request) {
 	post := getPostFromCtx(r)
+	if err := app.requirePostAuthor(r, post); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
 
 	var payload UpdatePostPayload
 	if err := utils.ReadJSON(w, r, &payload); err != nil {
@@ -143,6 +362,12 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if err := checkPrecondition(r, postETag(post), payload.Version, post.Version, post); err != nil {
+		w.Header().Set("ETag", postETag(post))
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
 	if payload.Content != nil {
 		post.Content = *payload.Content
 	}
@@ -156,9 +381,18 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 	ctx := r.Context()
 
 	if err := app.store.Posts.Update(ctx, post); err != nil {
-		utils.InternalServerError(w, r, err)
+		switch {
+		case errors.Is(err, store.ErrEditConflict):
+			app.postConflictResponse(w, r, post.ID)
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
 	}
 
+	w.Header().Set("ETag", postETag(post))
 	if err := utils.JsonResponse(w, http.StatusOK, post); err != nil {
 		utils.InternalServerError(w, r, err)
 	}
@@ -167,49 +401,127 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 // DeletePost godoc
 //
 //	@Summary		Deletes a post
-//	@Description	Deletes a post by ID
+//	@Description	Deletes a post by ID. Requires If-Match with the post's current ETag.
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		int	true	"Post ID"
-//	@Success		204	{object}	nil
-//	@Failure		400	{object}	error
-//	@Failure		404	{object}	error
-//	@Failure		500	{object}	error
+//	@Param			id			path		int		true	"Post ID"
+//	@Param			If-Match	header		string	true	"ETag of the post being deleted"
+//	@Success		204			{object}	nil
+//	@Failure		400			{object}	utils.Problem
+//	@Failure		401			{object}	utils.Problem
+//	@Failure		403			{object}	utils.Problem
+//	@Failure		404			{object}	utils.Problem
+//	@Failure		409			{object}	utils.Problem
+//	@Failure		412			{object}	utils.Problem
+//	@Failure		428			{object}	utils.Problem
+//	@Failure		500			{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id} [delete]
 func (app *application) deletePostHandler(w http.ResponseWriter, r *http.Request) {
-	postIDStr := chi.URLParam(r, "postID")
-	postID, err := strconv.ParseInt(postIDStr, 10, 64)
-	if err != nil {
-		utils.InternalServerError(w, r, err)
+	post := getPostFromCtx(r)
+	if err := app.requirePostAuthor(r, post); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
 	}
 
-	ctx := r.Context()
-	if err := app.store.Comments.DeleteByPostID(ctx, postID); err != nil {
-		utils.InternalServerError(w, r, err)
+	if err := checkPrecondition(r, postETag(post), nil, post.Version, post); err != nil {
+		w.Header().Set("ETag", postETag(post))
+		utils.WriteProblem(w, r, err)
 		return
 	}
-	if err := app.store.Posts.Delete(ctx, postID); err != nil {
-		utils.InternalServerError(w, r, err)
+
+	// Remove the comments and the post together so a failure never leaves a half deleted thread
+	err := app.store.WithTx(r.Context(), func(tx store.Storage) error {
+		if err := tx.Comments.DeleteByPostID(r.Context(), post.ID); err != nil {
+			return err
+		}
+		return tx.Posts.Delete(r.Context(), post.ID, post.Version)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrEditConflict):
+			app.postConflictResponse(w, r, post.ID)
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// requirePostAuthor only lets the author change a post. Gigs are posts too, but they are changed
+// through /gigs so their version and applications stay consistent.
+func (app *application) requirePostAuthor(r *http.Request, post *store.Post) error {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		return err
+	}
+	if userID != post.UserID {
+		return utils.NewAppError(http.StatusForbidden, utils.CodeForbidden, "Only the author can change this post")
+	}
+
+	_, err = app.store.Gigs.GetByID(r.Context(), post.ID)
+	switch {
+	case err == nil:
+		return utils.NewAppError(http.StatusForbidden, utils.CodeForbidden, "Gigs are changed through /v1/gigs")
+	case errors.Is(err, store.ErrNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+// postConflictResponse reports a write that lost a race, with the post as it is now
+func (app *application) postConflictResponse(w http.ResponseWriter, r *http.Request, postID int64) {
+	current, err := app.loadPost(r.Context(), postID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.NotFoundResponse(w, r, err)
+			return
+		}
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", postETag(current))
+	utils.WriteProblem(w, r, editConflict(current))
+}
+
+// loadPost fetches a post with the first page of its comments
+func (app *application) loadPost(ctx context.Context, postID int64) (*store.Post, error) {
+	post, err := app.store.Posts.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := app.store.Comments.GetByPostID(ctx, postID, pagination.Params{Limit: pagination.DefaultLimit})
+	if err != nil {
+		return nil, err
+	}
+	post.Comments = comments.Items
+	if comments.Next != nil {
+		next := comments.Next.Encode()
+		post.CommentsNextCursor = &next
+	}
+	return post, nil
+}
+
 func (app *application) postsContextMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		idParam := chi.URLParam(r, "postID")
 		id, err := strconv.ParseInt(idParam, 10, 64)
 
 		if err != nil {
-			utils.InternalServerError(w, r, err)
+			utils.BadRequestResponse(w, r, err)
 			return
 		}
 
 		ctx := r.Context()
 
-		post, err := app.store.Posts.GetByID(ctx, id)
+		post, err := app.loadPost(ctx, id)
 		if err != nil {
 			switch {
 			case errors.Is(err, store.ErrNotFound):
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostsAndComments(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	c := srv.newClient(t)
+	userID := c.signUp("poster@example.com")
+
+	var post store.Post
+	resp := c.do(http.MethodPost, "/v1/posts/", map[string]any{
+		"title":   "Lighting a night exterior",
+		"content": "Sodium vapour or LED?",
+		"tags":    []string{"lighting", "night"},
+	})
+	require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+	resp.decode(t, &post)
+	assert.NotZero(t, post.ID)
+	assert.Equal(t, userID, post.UserID.String())
+	postPath := fmt.Sprintf("/v1/posts/%d/", post.ID)
+
+	var fetched store.Post
+	resp = c.do(http.MethodGet, postPath, nil)
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	resp.decode(t, &fetched)
+	assert.Equal(t, "Lighting a night exterior", fetched.Title)
+	assert.Equal(t, []string{"lighting", "night"}, fetched.Tags)
+
+	var updated store.Post
+	resp = c.do(http.MethodPatch, postPath, map[string]any{"title": "Lighting a rainy night exterior", "version": fetched.Version})
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	resp.decode(t, &updated)
+	assert.Equal(t, "Lighting a rainy night exterior", updated.Title)
+	assert.Equal(t, "Sodium vapour or LED?", updated.Content)
+
+	stranger := srv.newClient(t)
+	stranger.signUp("stranger@example.com")
+	resp = stranger.do(http.MethodPatch, postPath, map[string]any{"title": "Not mine", "version": updated.Version})
+	assert.Equal(t, http.StatusForbidden, resp.status)
+	assert.Equal(t, http.StatusUnauthorized, srv.newClient(t).do(http.MethodPatch, postPath, map[string]any{"title": "Not mine"}).status)
+
+	var comment store.Comment
+	resp = c.do(http.MethodPost, postPath+"comments", map[string]any{"content": "LED, you can dial the colour"})
+	require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+	resp.decode(t, &comment)
+	assert.NotZero(t, comment.ID)
+	assert.Equal(t, post.ID, comment.PostID)
+	commentPath := fmt.Sprintf("/v1/posts/comments/%d/", comment.ID)
+
+	resp = c.do(http.MethodGet, postPath, nil)
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	resp.decode(t, &fetched)
+	require.Len(t, fetched.Comments, 1)
+	assert.Equal(t, "LED, you can dial the colour", fetched.Comments[0].Content)
+
+	resp = c.do(http.MethodPatch, commentPath, map[string]any{"content": "LED, unless it is a period piece"})
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+
+	var fetchedComment store.Comment
+	resp = c.do(http.MethodGet, commentPath, nil)
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	resp.decode(t, &fetchedComment)
+	assert.Equal(t, "LED, unless it is a period piece", fetchedComment.Content)
+
+	resp = c.do(http.MethodDelete, commentPath, nil)
+	require.Equal(t, http.StatusNoContent, resp.status, string(resp.body))
+	resp = c.do(http.MethodGet, commentPath, nil)
+	assert.Equal(t, http.StatusNotFound, resp.status)
+
+	resp = c.do(http.MethodGet, postPath, nil)
+	resp = c.doWithHeader(http.MethodDelete, postPath, nil, http.Header{"If-Match": {resp.header.Get("ETag")}})
+	require.Equal(t, http.StatusNoContent, resp.status, string(resp.body))
+	resp = c.do(http.MethodGet, postPath, nil)
+	assert.Equal(t, http.StatusNotFound, resp.status)
+}
+
+func TestPostsRequireAuthentication(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	anonymous := srv.newClient(t)
+
+	resp := anonymous.do(http.MethodPost, "/v1/posts/", map[string]any{"title": "t", "content": "c"})
+	assert.Equal(t, http.StatusUnauthorized, resp.status)
+
+	owner := srv.newClient(t)
+	owner.signUp("owner@example.com")
+	var post store.Post
+	owner.do(http.MethodPost, "/v1/posts/", map[string]any{"title": "t", "content": "c"}).decode(t, &post)
+
+	resp = anonymous.do(http.MethodPost, fmt.Sprintf("/v1/posts/%d/comments", post.ID), map[string]any{"content": "c"})
+	assert.Equal(t, http.StatusUnauthorized, resp.status)
+}
+
+func TestPostValidation(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	c := srv.newClient(t)
+	c.signUp("validator@example.com")
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		payload any
+		status  int
+	}{
+		{name: "missing title", method: http.MethodPost, path: "/v1/posts/", payload: map[string]any{"content": "c"}, status: http.StatusBadRequest},
+		{name: "unknown field", method: http.MethodPost, path: "/v1/posts/", payload: map[string]any{"title": "t", "content": "c", "extra": true}, status: http.StatusBadRequest},
+		{name: "unknown post", method: http.MethodGet, path: "/v1/posts/999/", status: http.StatusNotFound},
+		{name: "malformed post id", method: http.MethodGet, path: "/v1/posts/abc/", status: http.StatusBadRequest},
+		{name: "comment on unknown post", method: http.MethodPost, path: "/v1/posts/999/comments", payload: map[string]any{"content": "c"}, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := c.do(tt.method, tt.path, tt.payload)
+			assert.Equal(t, tt.status, resp.status, string(resp.body))
+		})
+	}
+}
+
+func TestListPosts(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	c := srv.newClient(t)
+	authorID := c.signUp("lister@example.com")
+	other := srv.newClient(t)
+	other.signUp("other@example.com")
+
+	for i := range 3 {
+		resp := c.do(http.MethodPost, "/v1/posts/", map[string]any{"title": fmt.Sprintf("Post %d", i), "content": "c", "tags": []string{"gear"}})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+	}
+	resp := other.do(http.MethodPost, "/v1/posts/", map[string]any{"title": "Elsewhere", "content": "c", "tags": []string{"hiring"}})
+	require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+
+	// Walk the author's posts two at a time by following the Link header
+	var titles []string
+	path := "/v1/posts?limit=2&author=" + authorID
+	for path != "" {
+		resp := c.do(http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+
+		var page struct {
+			Data       []store.Post `json:"data"`
+			NextCursor *string      `json:"next_cursor"`
+		}
+		require.NoError(t, json.Unmarshal(resp.body, &page))
+		for _, p := range page.Data {
+			titles = append(titles, p.Title)
+		}
+
+		path = ""
+		if link := resp.header.Get("Link"); link != "" {
+			require.NotNil(t, page.NextCursor)
+			path = strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+			assert.Contains(t, path, "cursor="+*page.NextCursor)
+		} else {
+			assert.Nil(t, page.NextCursor)
+		}
+	}
+	assert.Equal(t, []string{"Post 2", "Post 1", "Post 0"}, titles)
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   []string
+	}{
+		{name: "tag", query: "tag=hiring", status: http.StatusOK, want: []string{"Elsewhere"}},
+		{name: "oldest first", query: "sort=created_at&limit=1", status: http.StatusOK, want: []string{"Post 0"}},
+		{name: "date range", query: "from=2000-01-01&to=2000-12-31", status: http.StatusOK, want: []string{}},
+		{name: "bad author", query: "author=nobody", status: http.StatusBadRequest},
+		{name: "bad sort", query: "sort=title", status: http.StatusBadRequest},
+		{name: "bad date", query: "from=yesterday", status: http.StatusBadRequest},
+		{name: "bad limit", query: "limit=-1", status: http.StatusBadRequest},
+		{name: "bad cursor", query: "cursor=xyz", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := c.do(http.MethodGet, "/v1/posts?"+tt.query, nil)
+			require.Equal(t, tt.status, resp.status, string(resp.body))
+			if tt.status != http.StatusOK {
+				assert.Equal(t, "validation_failed", resp.problem(t)["code"])
+				return
+			}
+			var posts []store.Post
+			resp.decode(t, &posts)
+			titles := []string{}
+			for _, p := range posts {
+				titles = append(titles, p.Title)
+			}
+			assert.Equal(t, tt.want, titles)
+		})
+	}
+}
+
+func TestListComments(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	c := srv.newClient(t)
+	c.signUp("commenter@example.com")
+
+	var post store.Post
+	c.do(http.MethodPost, "/v1/posts/", map[string]any{"title": "t", "content": "c"}).decode(t, &post)
+	postPath := fmt.Sprintf("/v1/posts/%d/", post.ID)
+
+	for i := range 25 {
+		resp := c.do(http.MethodPost, postPath+"comments", map[string]any{"content": fmt.Sprintf("Comment %d", i)})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+	}
+
+	// The post embeds only the first page, newest first
+	var fetched store.Post
+	c.do(http.MethodGet, postPath, nil).decode(t, &fetched)
+	require.Len(t, fetched.Comments, 20)
+	assert.Equal(t, "Comment 24", fetched.Comments[0].Content)
+	require.NotNil(t, fetched.CommentsNextCursor)
+
+	var rest []store.Comment
+	resp := c.do(http.MethodGet, postPath+"comments?cursor="+*fetched.CommentsNextCursor, nil)
+	require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+	resp.decode(t, &rest)
+	require.Len(t, rest, 5)
+	assert.Equal(t, "Comment 4", rest[0].Content)
+	assert.Equal(t, "Comment 0", rest[4].Content)
+	assert.Empty(t, resp.header.Get("Link"))
+
+	resp = c.do(http.MethodGet, "/v1/posts/999/comments", nil)
+	assert.Equal(t, http.StatusNotFound, resp.status)
+}
+
+func TestSearchPosts(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+
+	kc := srv.newClient(t)
+	payload := registrationPayload("kc@example.com")
+	payload["city"], payload["state"], payload["zip_code"] = "Kansas City", "MO", "64105"
+	payload["latitude"], payload["longitude"] = 39.1, -94.58
+	kc.signUpWith(payload)
+
+	bozeman := srv.newClient(t)
+	bozeman.signUp("bozeman@example.com")
+
+	for _, post := range []struct {
+		c     *testClient
+		title string
+		tags  []string
+	}{
+		{kc, "RED Komodo with Steadicam", []string{"camera", "steadicam"}},
+		{kc, "Need a gaffer", []string{"lighting"}},
+		{bozeman, "Komodo for rent", []string{"camera"}},
+	} {
+		resp := post.c.do(http.MethodPost, "/v1/posts/", map[string]any{"title": post.title, "content": "Shoot <next> week", "tags": post.tags})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+	}
+
+	search := func(query string) (int, postSearchResponse) {
+		t.Helper()
+		resp := kc.do(http.MethodGet, "/v1/posts/search?"+query, nil)
+		var results postSearchResponse
+		if resp.status == http.StatusOK {
+			resp.decode(t, &results)
+		}
+		return resp.status, results
+	}
+	titles := func(results postSearchResponse) []string {
+		titles := []string{}
+		for _, r := range results.Results {
+			titles = append(titles, r.Title)
+		}
+		return titles
+	}
+
+	status, results := search("q=komodo+steadicam")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"RED Komodo with Steadicam"}, titles(results))
+
+	status, results = search("q=komodo")
+	require.Equal(t, http.StatusOK, status)
+	assert.ElementsMatch(t, []string{"RED Komodo with Steadicam", "Komodo for rent"}, titles(results))
+	assert.Equal(t, []store.TagFacet{{Tag: "camera", Count: 2}, {Tag: "steadicam", Count: 1}}, results.Tags)
+
+	status, results = search("q=komodo&zip=64105&miles=50")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"RED Komodo with Steadicam"}, titles(results))
+
+	status, results = search("q=shoot&limit=1")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, results.Results, 1)
+	assert.Contains(t, results.Results[0].Highlight, "<mark>Shoot</mark>")
+	assert.Contains(t, results.Results[0].Highlight, "&lt;next&gt;")
+
+	for _, query := range []string{"", "q=", "q=komodo&miles=5", "q=komodo&zip=64105&miles=-1", "q=" + strings.Repeat("a", 201)} {
+		status, _ := search(query)
+		assert.Equal(t, http.StatusBadRequest, status, query)
+	}
+	status, _ = search("q=komodo&zip=00000")
+	assert.Equal(t, http.StatusNotFound, status)
+}
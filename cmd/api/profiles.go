@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+type ProfileLinkPayload struct {
+	Kind store.LinkKind `json:"kind" validate:"required,oneof=reel website instagram vimeo youtube imdb linkedin other"`
+	URL  string         `json:"url" validate:"required,http_url,max=500" example:"https://vimeo.com/reel"`
+}
+
+// ProfilePayload is the whole profile; fields left out are cleared
+type ProfilePayload struct {
+	Headline        string               `json:"headline" validate:"max=120"`
+	Bio             string               `json:"bio" validate:"max=5000"`
+	Roles           []store.CrewRole     `json:"roles" validate:"max=12,dive,oneof=director_of_photography camera_operator steadicam_operator drone_pilot first_ac second_ac dit gaffer best_boy_electric electrician key_grip grip"`
+	Specialties     []store.Specialty    `json:"specialties" validate:"max=6,dive,oneof=documentary commercial narrative music_video corporate event"`
+	YearsExperience int                  `json:"years_experience" validate:"min=0,max=80"`
+	DayRateMin      int                  `json:"day_rate_min" validate:"min=0"`
+	DayRateMax      int                  `json:"day_rate_max" validate:"min=0"`
+	Currency        string               `json:"currency" validate:"omitempty,iso4217" example:"USD"` // USD when left out
+	Languages       []string             `json:"languages" validate:"max=20,dive,required,max=50" example:"English,Spanish"`
+	Unions          []string             `json:"unions" validate:"max=10,dive,required,max=100" example:"IATSE Local 600"`
+	Links           []ProfileLinkPayload `json:"links" validate:"max=20,dive"`
+	Version         *int                 `json:"version" validate:"omitempty,min=0"` // used when If-Match is absent
+}
+
+// GetMyProfile godoc
+//
+//	@Summary		Fetches your profile
+//	@Description	Fetches the signed in user's cinematographer profile.
+//	@Tags			profiles
+//	@Produce		json
+//	@Success		200	{object}	store.Profile
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/profiles/me [get]
+func (app *application) getMyProfileHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	app.writeProfile(w, r, userID, http.StatusOK)
+}
+
+// GetProfile godoc
+//
+//	@Summary		Fetches a public profile
+//	@Description	Fetches a cinematographer's public profile. It carries their name and city but never their email or street address. Send the ETag back in If-None-Match to get 304 when it has not changed.
+//	@Tags			profiles
+//	@Produce		json
+//	@Param			userID			path		string	true	"User ID"
+//	@Param			If-None-Match	header		string	false	"ETag from an earlier response"
+//	@Success		200				{object}	store.Profile
+//	@Success		304
+//	@Failure		400	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Router			/profiles/{userID} [get]
+func (app *application) getProfileHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+	app.writeProfile(w, r, userID, http.StatusOK)
+}
+
+// writeProfile reads the profile back with the name and city joined from the user
+func (app *application) writeProfile(w http.ResponseWriter, r *http.Request, userID uuid.UUID, status int) {
+	profile, err := app.store.Profiles.GetByUserID(r.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	etag := profileETag(profile)
+	if r.Method == http.MethodGet && notModified(w, r, etag) {
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	if err := utils.JsonResponse(w, status, profile); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// PutMyProfile godoc
+//
+//	@Summary		Creates or replaces your profile
+//	@Description	Creates the signed in user's profile, or replaces it entirely if they have one. Replacing needs the ETag from GET in If-Match (or the version field); a stale ETag gets 412 and a stale version 409, both with the current profile. Rates are whole units of currency, 0 when not given.
+//	@Tags			profiles
+//	@Accept			json
+//	@Produce		json
+//	@Param			If-Match	header		string			false	"ETag of the profile being replaced"
+//	@Param			payload		body		ProfilePayload	true	"Profile payload"
+//	@Success		200			{object}	store.Profile
+//	@Success		201			{object}	store.Profile
+//	@Failure		400			{object}	utils.Problem
+//	@Failure		401			{object}	utils.Problem
+//	@Failure		409			{object}	utils.Problem
+//	@Failure		412			{object}	utils.Problem
+//	@Failure		428			{object}	utils.Problem
+//	@Failure		500			{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/profiles/me [put]
+func (app *application) putMyProfileHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ProfilePayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.StructCtx(r.Context(), payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+	if payload.DayRateMax < payload.DayRateMin {
+		utils.WriteProblem(w, r, utils.InvalidField("day_rate_max", "gtefield", "must be at least day_rate_min"))
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	profile := payload.toProfile(userID)
+	current, err := app.store.Profiles.GetByUserID(r.Context(), userID)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		if err := app.store.Profiles.Create(r.Context(), profile); err != nil {
+			if errors.Is(err, store.ErrConflict) {
+				app.profileConflictResponse(w, r, userID)
+				return
+			}
+			utils.InternalServerError(w, r, err)
+			return
+		}
+		app.writeProfile(w, r, userID, http.StatusCreated)
+		return
+	case err != nil:
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := checkPrecondition(r, profileETag(current), payload.Version, current.Version, current); err != nil {
+		w.Header().Set("ETag", profileETag(current))
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	profile.Version = current.Version
+	if err := app.store.Profiles.Update(r.Context(), profile); err != nil {
+		switch {
+		case errors.Is(err, store.ErrEditConflict):
+			app.profileConflictResponse(w, r, userID)
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+	app.writeProfile(w, r, userID, http.StatusOK)
+}
+
+func (p ProfilePayload) toProfile(userID uuid.UUID) *store.Profile {
+	profile := &store.Profile{
+		UserID:          userID,
+		Headline:        p.Headline,
+		Bio:             p.Bio,
+		Roles:           p.Roles,
+		Specialties:     p.Specialties,
+		YearsExperience: p.YearsExperience,
+		DayRateMin:      p.DayRateMin,
+		DayRateMax:      p.DayRateMax,
+		Currency:        p.Currency,
+		Languages:       p.Languages,
+		Unions:          p.Unions,
+		Links:           make(store.ProfileLinks, len(p.Links)),
+	}
+	if profile.Currency == "" {
+		profile.Currency = "USD"
+	}
+	for i, link := range p.Links {
+		profile.Links[i] = store.ProfileLink{Kind: link.Kind, URL: link.URL}
+	}
+	return profile
+}
+
+// profileConflictResponse reports a write that lost a race, with the profile as it is now
+func (app *application) profileConflictResponse(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	current, err := app.store.Profiles.GetByUserID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.NotFoundResponse(w, r, err)
+			return
+		}
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", profileETag(current))
+	utils.WriteProblem(w, r, editConflict(current))
+}
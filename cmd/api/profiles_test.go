@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiles(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+
+	dp := srv.newClient(t)
+	payload := registrationPayload("dp@example.com")
+	payload["street"] = "12 Main St"
+	userID := dp.signUpWith(payload)
+	visitor := srv.newClient(t)
+
+	profilePayload := map[string]any{
+		"headline":         "Documentary DP and drone pilot",
+		"roles":            []string{"director_of_photography", "drone_pilot"},
+		"specialties":      []string{"documentary", "commercial"},
+		"years_experience": 12,
+		"day_rate_min":     800,
+		"day_rate_max":     1200,
+		"languages":        []string{"English", "Spanish"},
+		"unions":           []string{"IATSE Local 600"},
+		"links":            []map[string]string{{"kind": "reel", "url": "https://vimeo.com/reel"}},
+	}
+
+	t.Run("no profile yet", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, dp.do(http.MethodGet, "/v1/profiles/me", nil).status)
+		assert.Equal(t, http.StatusNotFound, visitor.do(http.MethodGet, "/v1/profiles/"+userID, nil).status)
+	})
+
+	t.Run("rejects bad profiles", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			field string
+			value any
+		}{
+			{"unknown role", "roles", []string{"boom_operator"}},
+			{"unknown specialty", "specialties", []string{"wedding"}},
+			{"bad link", "links", []map[string]string{{"kind": "reel", "url": "not a url"}}},
+			{"rate range backwards", "day_rate_min", 2000},
+			{"bad currency", "currency", "XYZ"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				body := map[string]any{}
+				for k, v := range profilePayload {
+					body[k] = v
+				}
+				body[tt.field] = tt.value
+				resp := dp.do(http.MethodPut, "/v1/profiles/me", body)
+				assert.Equal(t, http.StatusBadRequest, resp.status, string(resp.body))
+			})
+		}
+	})
+
+	resp := dp.do(http.MethodPut, "/v1/profiles/me", profilePayload)
+	require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+	var created store.Profile
+	resp.decode(t, &created)
+	assert.Equal(t, "USD", created.Currency)
+	assert.Equal(t, "Roger", created.FirstName)
+	etag := resp.header.Get("ETag")
+
+	t.Run("public profile hides private data", func(t *testing.T) {
+		resp := visitor.do(http.MethodGet, "/v1/profiles/"+userID, nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		assert.NotContains(t, string(resp.body), "dp@example.com")
+		assert.NotContains(t, string(resp.body), "12 Main St")
+		assert.NotContains(t, string(resp.body), `"email"`)
+
+		var public store.Profile
+		resp.decode(t, &public)
+		assert.Equal(t, []store.CrewRole{store.RoleDirectorOfPhotography, store.RoleDronePilot}, public.Roles)
+		assert.Equal(t, "BOZEMAN", public.City)
+
+		resp = visitor.doWithHeader(http.MethodGet, "/v1/profiles/"+userID, nil, http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, resp.status)
+
+		assert.Equal(t, http.StatusBadRequest, visitor.do(http.MethodGet, "/v1/profiles/not-a-uuid", nil).status)
+		assert.Equal(t, http.StatusUnauthorized, visitor.do(http.MethodGet, "/v1/profiles/me", nil).status)
+	})
+
+	t.Run("replacing needs the current version", func(t *testing.T) {
+		profilePayload["headline"] = "Commercial DP"
+
+		resp := dp.do(http.MethodPut, "/v1/profiles/me", profilePayload)
+		assert.Equal(t, http.StatusPreconditionRequired, resp.status)
+
+		resp = dp.doWithHeader(http.MethodPut, "/v1/profiles/me", profilePayload, http.Header{"If-Match": {etag}})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var updated store.Profile
+		resp.decode(t, &updated)
+		assert.Equal(t, "Commercial DP", updated.Headline)
+		assert.Equal(t, created.Version+1, updated.Version)
+
+		resp = dp.doWithHeader(http.MethodPut, "/v1/profiles/me", profilePayload, http.Header{"If-Match": {etag}})
+		assert.Equal(t, http.StatusPreconditionFailed, resp.status)
+
+		profilePayload["version"] = created.Version
+		resp = dp.do(http.MethodPut, "/v1/profiles/me", profilePayload)
+		assert.Equal(t, http.StatusConflict, resp.status)
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/config"
+	"github.com/michaelhoman/ShotSeek/internal/ratelimit"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+// Rate limit policy names used when mounting routes
+const (
+	rateLimitDefault = "default"
+	rateLimitAuth    = "auth"
+	rateLimitGeocode = "geocode"
+)
+
+// newRateLimiter builds the limiter for cfg. When rate limiting is disabled the limiter has no store
+// and its middleware lets every request through.
+func newRateLimiter(cfg config.RateLimitConfig, db *sql.DB) (*ratelimit.Limiter, error) {
+	specs := map[string]string{
+		rateLimitDefault: cfg.Default,
+		rateLimitAuth:    cfg.Auth,
+		rateLimitGeocode: cfg.Geocode,
+	}
+
+	var policies []ratelimit.Policy
+	var longest time.Duration
+	for name, spec := range specs {
+		policy, err := ratelimit.ParsePolicy(name, spec)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+		longest = max(longest, policy.Period)
+	}
+
+	if !cfg.Enabled {
+		return ratelimit.NewLimiter(nil, policies...), nil
+	}
+
+	switch cfg.Store {
+	case ratelimit.StoreMemory:
+		return ratelimit.NewLimiter(ratelimit.NewMemoryStore(), policies...), nil
+	case ratelimit.StorePostgres:
+		store := ratelimit.NewPostgresStore(db)
+		go pruneRateLimits(store, longest)
+		return ratelimit.NewLimiter(store, policies...), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.Store)
+	}
+}
+
+// pruneRateLimits periodically removes buckets idle for longer than idle
+func pruneRateLimits(store *ratelimit.PostgresStore, idle time.Duration) {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := store.Prune(context.Background(), idle); err != nil {
+			utils.Logger.Warnw("pruning rate limit buckets failed", "error", err)
+		}
+	}
+}
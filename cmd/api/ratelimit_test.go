@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRateLimit(t *testing.T) {
+	app := newTestApplication(t)
+	cfg := app.config.RateLimit
+	cfg.Enabled, cfg.Store, cfg.Default = true, ratelimit.StoreMemory, "1/1h"
+	var err error
+	app.rateLimiter, err = newRateLimiter(cfg, nil)
+	require.NoError(t, err)
+	c := newTestServer(t, app).newClient(t)
+
+	t.Run("an unchecked API key header does not reset the bucket", func(t *testing.T) {
+		statuses := []int{}
+		for i := range 3 {
+			header := http.Header{"X-API-Key": {fmt.Sprintf("key-%d", i)}}
+			statuses = append(statuses, c.doWithHeader(http.MethodGet, "/v1/health", nil, header).status)
+		}
+		assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests}, statuses)
+	})
+}
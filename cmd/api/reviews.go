@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+type reviewKey string
+
+const reviewCtx reviewKey = "review"
+
+// reviewWindow is how long after a booking ends its parties can review each other. Reviews
+// nobody answered are revealed when it closes.
+const reviewWindow = 14 * 24 * time.Hour
+
+type CreateReviewPayload struct {
+	Rating          int    `json:"rating" validate:"required,min=1,max=5"`
+	Professionalism *int   `json:"professionalism" validate:"omitempty,min=1,max=5"`
+	TechnicalSkill  *int   `json:"technical_skill" validate:"omitempty,min=1,max=5"` // cinematographers only
+	Communication   *int   `json:"communication" validate:"omitempty,min=1,max=5"`
+	Body            string `json:"body" validate:"max=5000"`
+}
+
+type ReviewResponsePayload struct {
+	Response string `json:"response" validate:"required,max=2000"`
+}
+
+// CreateReview godoc
+//
+//	@Summary		Reviews the other party to a booking
+//	@Description	Rates the other party to an accepted booking from 1 to 5, with optional scores for professionalism, communication and, for a cinematographer, technical_skill. Reviews open once the booking ends and close 14 days later. They are double blind: neither party sees the other's review until both have reviewed or the period closes, whichever comes first (visible_at). Each party reviews a booking once; a second review gets 409. The other party is notified.
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int					true	"Booking ID"
+//	@Param			payload	body		CreateReviewPayload	true	"Review"
+//	@Success		201		{object}	store.Review
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		403		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		409		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{id}/reviews [post]
+func (app *application) createReviewHandler(w http.ResponseWriter, r *http.Request) {
+	booking := getBookingFromCtx(r)
+
+	var payload CreateReviewPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.StructCtx(r.Context(), payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	review := store.Review{
+		BookingID:       booking.ID,
+		ReviewerID:      userID,
+		RevieweeID:      booking.CinematographerID,
+		RevieweeRole:    store.BookingAsCinematographer,
+		Rating:          payload.Rating,
+		Professionalism: payload.Professionalism,
+		TechnicalSkill:  payload.TechnicalSkill,
+		Communication:   payload.Communication,
+		Body:            payload.Body,
+		VisibleAt:       booking.EndsAt.Add(reviewWindow),
+	}
+	if userID == booking.CinematographerID {
+		review.RevieweeID, review.RevieweeRole = booking.ProducerID, store.BookingAsProducer
+		if payload.TechnicalSkill != nil {
+			utils.WriteProblem(w, r, utils.InvalidField("technical_skill", "excluded_unless", "only applies to cinematographers"))
+			return
+		}
+	}
+	if err := checkReviewable(booking, time.Now()); err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	var created *store.Review
+	err = app.store.WithTx(r.Context(), func(tx store.Storage) error {
+		if err := tx.Reviews.Create(r.Context(), &review); err != nil {
+			return err
+		}
+		for _, n := range reviewNotifications(booking, &review) {
+			if err := tx.Notifications.Create(r.Context(), &n); err != nil {
+				return err
+			}
+		}
+		created, err = tx.Reviews.GetByID(r.Context(), review.ID)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			utils.WriteProblem(w, r, utils.NewAppError(http.StatusConflict, utils.CodeConflict, "You already reviewed this booking"))
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusCreated, created); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// checkReviewable refuses reviews of bookings that never went ahead, have not ended yet or
+// ended longer ago than the review period
+func checkReviewable(b *store.Booking, now time.Time) error {
+	switch {
+	case b.Status != store.BookingAccepted:
+		return utils.NewAppError(http.StatusConflict, utils.CodeConflict,
+			fmt.Sprintf("Only accepted bookings can be reviewed; this one is %s", b.Status))
+	case b.EndsAt.After(now):
+		return utils.NewAppError(http.StatusConflict, utils.CodeConflict, "A booking can be reviewed once it has ended")
+	case now.After(b.EndsAt.Add(reviewWindow)):
+		return utils.NewAppError(http.StatusConflict, utils.CodeConflict, "The review period for this booking has closed")
+	}
+	return nil
+}
+
+// reviewNotifications tells both parties when the review revealed theirs, which moved it up
+// from the end of the review period, and otherwise tells the reviewee a review is waiting
+func reviewNotifications(b *store.Booking, review *store.Review) []store.Notification {
+	link := bookingLink(b.ID) + "/reviews"
+	reviewer := b.Producer
+	if review.ReviewerID == b.CinematographerID {
+		reviewer = b.Cinematographer
+	}
+
+	if review.VisibleAt.Before(b.EndsAt.Add(reviewWindow)) {
+		message := fmt.Sprintf("Reviews for the booking on %s are now visible", bookingDates(b))
+		return []store.Notification{
+			{UserID: review.ReviewerID, Kind: store.NotificationReviewsRevealed, Message: message, Link: link},
+			{UserID: review.RevieweeID, Kind: store.NotificationReviewsRevealed, Message: message, Link: link},
+		}
+	}
+	return []store.Notification{{
+		UserID: review.RevieweeID,
+		Kind:   store.NotificationReviewReceived,
+		Message: fmt.Sprintf("%s reviewed the booking on %s. Review them by %s to see it sooner.",
+			fullName(reviewer), bookingDates(b), review.VisibleAt.Format("Jan 2, 2006")),
+		Link: link,
+	}}
+}
+
+// ListBookingReviews godoc
+//
+//	@Summary		Lists a booking's reviews
+//	@Description	Lists the reviews of a booking, oldest first: your own, and the other party's once it is visible.
+//	@Tags			reviews
+//	@Produce		json
+//	@Param			id	path		int	true	"Booking ID"
+//	@Success		200	{array}		store.Review
+//	@Failure		400	{object}	utils.Problem
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		403	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/bookings/{id}/reviews [get]
+func (app *application) listBookingReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	reviews, err := app.store.Reviews.ListByBooking(r.Context(), getBookingFromCtx(r).ID)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	shown := []store.Review{}
+	for _, review := range reviews {
+		if review.ReviewerID == userID || review.Visible(now) {
+			shown = append(shown, review)
+		}
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, shown); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// ListUserReviews godoc
+//
+//	@Summary		Lists the reviews a user received
+//	@Description	Lists the visible reviews a user received, newest first. role keeps those they received as the booking's producer or as its cinematographer. Follow next_cursor (also sent as a Link header) for the next page, keeping the same filters.
+//	@Tags			reviews
+//	@Produce		json
+//	@Param			userID	path		string	true	"User ID"
+//	@Param			role	query		string	false	"Only reviews received in this role"	Enums(producer, cinematographer)
+//	@Param			limit	query		int		false	"Page size, at most 100"				default(20)
+//	@Param			cursor	query		string	false	"next_cursor from the previous page"
+//	@Success		200		{array}		store.Review
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Router			/profiles/{userID}/reviews [get]
+func (app *application) listUserReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	filter := store.ReviewFilter{Page: page}
+	switch role := store.BookingRole(r.URL.Query().Get("role")); role {
+	case "", store.BookingAsProducer, store.BookingAsCinematographer:
+		filter.Role = role
+	default:
+		utils.WriteProblem(w, r, utils.InvalidQueryParam("role", "oneof", "must be one of: producer cinematographer"))
+		return
+	}
+
+	reviews, err := app.store.Reviews.ListReceived(r.Context(), userID, filter)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := pagination.Write(w, r, reviews); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// RespondToReview godoc
+//
+//	@Summary		Responds to a review
+//	@Description	Adds the reviewed party's public reply to a visible review. Each review takes one response; a second gets 409. The reviewer is notified.
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Review ID"
+//	@Param			payload	body		ReviewResponsePayload	true	"Response"
+//	@Success		200		{object}	store.Review
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		403		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		409		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/reviews/{id}/response [post]
+func (app *application) respondToReviewHandler(w http.ResponseWriter, r *http.Request) {
+	review := getReviewFromCtx(r)
+
+	var payload ReviewResponsePayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.StructCtx(r.Context(), payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+	if userID != review.RevieweeID {
+		utils.WriteProblem(w, r, utils.NewAppError(http.StatusForbidden, utils.CodeForbidden, "Only the reviewed party can respond to a review"))
+		return
+	}
+	if !review.Visible(time.Now()) {
+		utils.WriteProblem(w, r, utils.NewAppError(http.StatusConflict, utils.CodeConflict, "This review is not visible yet"))
+		return
+	}
+
+	review.Response = payload.Response
+	err = app.store.WithTx(r.Context(), func(tx store.Storage) error {
+		if err := tx.Reviews.Respond(r.Context(), review); err != nil {
+			return err
+		}
+		return tx.Notifications.Create(r.Context(), &store.Notification{
+			UserID:  review.ReviewerID,
+			Kind:    store.NotificationReviewResponse,
+			Message: "Your review received a response",
+			Link:    bookingLink(review.BookingID) + "/reviews",
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			utils.WriteProblem(w, r, utils.NewAppError(http.StatusConflict, utils.CodeConflict, "This review already has a response"))
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, review); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// reviewContextMiddleware loads the review; the handlers decide who may act on it
+func (app *application) reviewContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "reviewID"), 10, 64)
+		if err != nil {
+			utils.BadRequestResponse(w, r, err)
+			return
+		}
+
+		review, err := app.store.Reviews.GetByID(r.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				utils.NotFoundResponse(w, r, err)
+			default:
+				utils.InternalServerError(w, r, err)
+			}
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), reviewCtx, review)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getReviewFromCtx(r *http.Request) *store.Review {
+	review, _ := r.Context().Value(reviewCtx).(*store.Review)
+	return review
+}
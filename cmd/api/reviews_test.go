@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviews(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+
+	signUpCrew := func(email, city, zip string, lat, lon float64) (*testClient, string) {
+		t.Helper()
+		c := srv.newClient(t)
+		payload := registrationPayload(email)
+		payload["city"], payload["state"], payload["zip_code"] = city, "MO", zip
+		payload["latitude"], payload["longitude"] = lat, lon
+		id := c.signUpWith(payload)
+		resp := c.do(http.MethodPut, "/v1/profiles/me", map[string]any{"headline": city + " crew"})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		return c, id
+	}
+	// The reviewed cinematographer lives further out than the unrated one
+	dp, dpID := signUpCrew("dp@example.com", "Overland Park", "66210", 38.93, -94.70)
+	signUpCrew("unrated@example.com", "Kansas City", "64105", 39.1, -94.58)
+	producer := srv.newClient(t)
+	producer.signUp("producer@example.com")
+	stranger := srv.newClient(t)
+	stranger.signUp("stranger@example.com")
+
+	// booking is an accepted booking that ended the given time ago
+	booked := 0
+	booking := func(endedAgo time.Duration) store.Booking {
+		t.Helper()
+		booked++
+		day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 1, booked)
+		resp := producer.do(http.MethodPost, "/v1/bookings", map[string]any{
+			"cinematographer_id": dpID, "kind": "request", "starts_at": day, "ends_at": day.Add(10 * time.Hour),
+			"rate": 1500, "zip_code": "64105",
+		})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		var b store.Booking
+		resp.decode(t, &b)
+		resp = dp.do(http.MethodPost, fmt.Sprintf("/v1/bookings/%d/actions", b.ID), map[string]any{"action": "accept"})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		resp.decode(t, &b)
+
+		b.EndsAt = time.Now().Add(-endedAgo).UTC().Truncate(time.Second)
+		b.StartsAt = b.EndsAt.Add(-10 * time.Hour)
+		require.NoError(t, srv.app.store.Bookings.Update(context.Background(), &b))
+		return b
+	}
+	review := func(c *testClient, b store.Booking, body map[string]any) testResponse {
+		t.Helper()
+		return c.do(http.MethodPost, fmt.Sprintf("/v1/bookings/%d/reviews", b.ID), body)
+	}
+	reviews := func(c *testClient, path string) []store.Review {
+		t.Helper()
+		resp := c.do(http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var r []store.Review
+		resp.decode(t, &r)
+		return r
+	}
+	notifications := func(c *testClient) []store.Notification {
+		t.Helper()
+		var n []store.Notification
+		c.do(http.MethodGet, "/v1/notifications/", nil).decode(t, &n)
+		return n
+	}
+
+	done := booking(48 * time.Hour)
+	bookingReviews := fmt.Sprintf("/v1/bookings/%d/reviews", done.ID)
+	var ofDP store.Review
+
+	t.Run("only completed bookings within the review period", func(t *testing.T) {
+		upcoming := booking(-24 * time.Hour)
+		resp := review(producer, upcoming, map[string]any{"rating": 5})
+		assert.Equal(t, http.StatusConflict, resp.status)
+
+		closed := booking(reviewWindow + time.Hour)
+		resp = review(producer, closed, map[string]any{"rating": 5})
+		assert.Equal(t, http.StatusConflict, resp.status)
+
+		assert.Equal(t, http.StatusForbidden, review(stranger, done, map[string]any{"rating": 5}).status)
+		assert.Equal(t, http.StatusBadRequest, review(producer, done, map[string]any{"rating": 6}).status)
+		assert.Equal(t, http.StatusBadRequest, review(producer, done, map[string]any{"rating": 4, "communication": 0}).status)
+		resp = review(dp, done, map[string]any{"rating": 4, "technical_skill": 5})
+		assert.Equal(t, http.StatusBadRequest, resp.status, "producers are not rated on technical skill")
+	})
+
+	t.Run("hidden from the reviewee until they review", func(t *testing.T) {
+		resp := review(producer, done, map[string]any{"rating": 5, "technical_skill": 4, "body": "Beautiful work"})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		resp.decode(t, &ofDP)
+		assert.Equal(t, store.BookingAsCinematographer, ofDP.RevieweeRole)
+		assert.Equal(t, done.EndsAt.Add(reviewWindow), ofDP.VisibleAt.UTC())
+		assert.Equal(t, store.NotificationReviewReceived, notifications(dp)[0].Kind)
+
+		assert.Len(t, reviews(producer, bookingReviews), 1)
+		assert.Empty(t, reviews(dp, bookingReviews))
+		assert.Empty(t, reviews(stranger, "/v1/profiles/"+dpID+"/reviews"))
+		assert.Equal(t, http.StatusConflict, review(producer, done, map[string]any{"rating": 1}).status)
+
+		resp = dp.do(http.MethodPost, fmt.Sprintf("/v1/reviews/%d/response", ofDP.ID), map[string]any{"response": "Thanks"})
+		assert.Equal(t, http.StatusConflict, resp.status, "not visible yet")
+	})
+
+	t.Run("the second review reveals both", func(t *testing.T) {
+		resp := review(dp, done, map[string]any{"rating": 4, "communication": 5})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		assert.Equal(t, store.NotificationReviewsRevealed, notifications(dp)[0].Kind)
+		assert.Equal(t, store.NotificationReviewsRevealed, notifications(producer)[0].Kind)
+
+		assert.Len(t, reviews(dp, bookingReviews), 2)
+		public := reviews(stranger, "/v1/profiles/"+dpID+"/reviews")
+		require.Len(t, public, 1)
+		assert.Equal(t, "Beautiful work", public[0].Body)
+		assert.Empty(t, reviews(stranger, "/v1/profiles/"+dpID+"/reviews?role=producer"))
+
+		var profile store.Profile
+		stranger.do(http.MethodGet, "/v1/profiles/"+dpID, nil).decode(t, &profile)
+		assert.Equal(t, 1, profile.Rating.Count)
+		require.NotNil(t, profile.Rating.Average)
+		assert.Equal(t, 5.0, *profile.Rating.Average)
+		assert.Equal(t, 4.0, *profile.Rating.TechnicalSkill)
+	})
+
+	t.Run("the reviewee responds once", func(t *testing.T) {
+		path := fmt.Sprintf("/v1/reviews/%d/response", ofDP.ID)
+		assert.Equal(t, http.StatusForbidden, producer.do(http.MethodPost, path, map[string]any{"response": "Me too"}).status)
+		assert.Equal(t, http.StatusBadRequest, dp.do(http.MethodPost, path, map[string]any{}).status)
+
+		resp := dp.do(http.MethodPost, path, map[string]any{"response": "Thanks, great shoot"})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var got store.Review
+		resp.decode(t, &got)
+		assert.Equal(t, "Thanks, great shoot", got.Response)
+		assert.NotNil(t, got.RespondedAt)
+		assert.Equal(t, store.NotificationReviewResponse, notifications(producer)[0].Kind)
+
+		assert.Equal(t, http.StatusConflict, dp.do(http.MethodPost, path, map[string]any{"response": "Again"}).status)
+		assert.Equal(t, http.StatusNotFound, dp.do(http.MethodPost, "/v1/reviews/999999/response", map[string]any{"response": "Hi"}).status)
+	})
+
+	t.Run("search sorts by rating", func(t *testing.T) {
+		search := func(query string) []string {
+			t.Helper()
+			resp := stranger.do(http.MethodGet, "/v1/cinematographers/search?zip=64105&miles=50"+query, nil)
+			require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+			var found []Cinematographer
+			resp.decode(t, &found)
+			headlines := []string{}
+			for _, c := range found {
+				headlines = append(headlines, c.Headline)
+			}
+			return headlines
+		}
+		assert.Equal(t, []string{"Kansas City crew", "Overland Park crew"}, search(""))
+		assert.Equal(t, []string{"Overland Park crew", "Kansas City crew"}, search("&sort=rating"))
+		assert.Equal(t, []string{"Overland Park crew"}, search("&sort=rating&limit=1"))
+
+		resp := stranger.do(http.MethodGet, "/v1/cinematographers/search?zip=64105&sort=price", nil)
+		assert.Equal(t, http.StatusBadRequest, resp.status)
+	})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/michaelhoman/ShotSeek/internal/auth"
+	"github.com/michaelhoman/ShotSeek/internal/config"
+	"github.com/michaelhoman/ShotSeek/internal/mailer"
+	"github.com/michaelhoman/ShotSeek/internal/postgres_db"
+	"github.com/michaelhoman/ShotSeek/internal/secrets"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+// secretsReloader swaps rotated credentials into the running server. db and connector are nil
+// with the in-memory store.
+type secretsReloader struct {
+	provider   *secrets.Resolver
+	configFile string
+	cfg        config.Config
+
+	db         *sql.DB
+	connector  *postgres_db.Connector
+	mail       *mailer.Swapper
+	jwtService *auth.JWTService
+	jwtAuth    *auth.JWTAuth
+}
+
+// reloadOnSIGHUP reloads the secrets whenever the process receives SIGHUP, so credentials can
+// be rotated without a restart. A failed reload is logged and the previous secrets stay in use.
+func (r *secretsReloader) reloadOnSIGHUP() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	for range hup {
+		logger := utils.Logger
+		logger.Info("SIGHUP received, reloading secrets")
+
+		if err := r.reload(context.Background()); err != nil {
+			logger.Errorw("reloading secrets failed", "error", err)
+			continue
+		}
+		logger.Info("Secrets reloaded")
+	}
+}
+
+// reload re-reads the encrypted secrets file, resolves the config again and swaps in the
+// JWT signing keys, the database address, the JWT secret and the SendGrid key. A config that
+// fails to load changes nothing, and a database address that refuses connections leaves the
+// pool on its current credentials.
+func (r *secretsReloader) reload(ctx context.Context) error {
+	if err := r.provider.Reload(); err != nil {
+		return err
+	}
+	cfg, err := config.Load(ctx, r.configFile, r.provider)
+	if err != nil {
+		return err
+	}
+
+	if err := r.jwtAuth.Reload(ctx); err != nil {
+		return err
+	}
+	if r.connector != nil {
+		rotated, err := r.connector.Rotate(ctx, r.db, cfg.Db.Addr)
+		if err != nil {
+			return err
+		}
+		if rotated {
+			utils.Logger.Info("Database credentials rotated")
+		}
+	}
+	r.jwtService.SetSecret(cfg.Auth.Token.Secret)
+	if cfg.Mail != r.cfg.Mail {
+		r.mail.Swap(newMailer(cfg.Mail))
+	}
+
+	r.cfg = cfg
+	return nil
+}
+
+// newMailer sends through SendGrid, or writes emails to the log without an API key so
+// registration still works locally
+func newMailer(cfg config.MailConfig) mailer.Client {
+	if cfg.APIKey == "" {
+		utils.Logger.Warn("SENDGRID_API_KEY is not set, emails are logged instead of sent")
+		return mailer.NewLogMailer(utils.Logger)
+	}
+	return mailer.NewSendgrid(cfg.APIKey, cfg.FromEmail)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/auth"
+	"github.com/michaelhoman/ShotSeek/internal/config"
+	"github.com/michaelhoman/ShotSeek/internal/mailer"
+	"github.com/michaelhoman/ShotSeek/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsReload(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "jwt_secret")
+	require.NoError(t, os.WriteFile(secretPath, []byte("first-secret-that-is-long-enough-1234"), 0o600))
+
+	keys := writeTestKeys(t)
+	configPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(`
+env: test
+db:
+  driver: memory
+auth:
+  token:
+    secret: %s%s
+  keys:
+    private_key: %s
+    public_key: %s
+`, secrets.SchemeFile, secretPath, keys.PrivateKey, keys.PublicKey)), 0o600))
+
+	provider, err := secrets.NewResolver("", nil)
+	require.NoError(t, err)
+	cfg, err := config.Load(context.Background(), configPath, provider)
+	require.NoError(t, err)
+	jwtAuth, err := auth.NewJWTAuth(context.Background(), provider, cfg.Auth.Keys)
+	require.NoError(t, err)
+
+	reloader := &secretsReloader{
+		provider:   provider,
+		configFile: configPath,
+		cfg:        cfg,
+		mail:       mailer.NewSwapper(&fakeMailer{}),
+		jwtService: auth.NewJWTService(cfg.Auth.Token.Secret, cfg.Auth.Token.Exp),
+		jwtAuth:    jwtAuth,
+	}
+
+	require.NoError(t, os.WriteFile(secretPath, []byte("second-secret-that-is-long-enough-123"), 0o600))
+	require.NoError(t, reloader.reload(context.Background()))
+	assert.Equal(t, "second-secret-that-is-long-enough-123", reloader.jwtService.Secret())
+
+	require.NoError(t, os.Remove(secretPath))
+	assert.Error(t, reloader.reload(context.Background()))
+	assert.Equal(t, "second-secret-that-is-long-enough-123", reloader.jwtService.Secret(), "a failed reload keeps the current secret")
+}
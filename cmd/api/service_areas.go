@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+// ServicePlacePayload is a further area the user works in, given by a ZIP code in it
+type ServicePlacePayload struct {
+	Name    string `json:"name" validate:"max=100" example:"Kansas City metro"`
+	ZIPCode string `json:"zip_code" validate:"required,max=12" example:"64105"`
+	Miles   int    `json:"miles" validate:"min=0,max=500"` // around the ZIP code, 0 for the ZIP code alone
+}
+
+// ServiceAreaPayload is the whole service area; places left out are removed
+type ServiceAreaPayload struct {
+	TravelMiles int                   `json:"travel_miles" validate:"min=0,max=500"`
+	Places      []ServicePlacePayload `json:"places" validate:"max=20,dive"`
+}
+
+// GetMyServiceArea godoc
+//
+//	@Summary		Fetches your service area
+//	@Description	Fetches how far the signed in user travels from home and the further places they work in.
+//	@Tags			profiles
+//	@Produce		json
+//	@Success		200	{object}	store.ServiceArea
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/profiles/me/service-area [get]
+func (app *application) getMyServiceAreaHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	area, err := app.store.ServiceAreas.Get(r.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, area); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// PutMyServiceArea godoc
+//
+//	@Summary		Replaces your service area
+//	@Description	Sets how many miles the signed in user travels from their home location and replaces their further places, each a ZIP code with the miles around it; a city or metro area is given by a ZIP code in it. Nearby cinematographer searches match a shoot location against all of these.
+//	@Tags			profiles
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ServiceAreaPayload	true	"Service area payload"
+//	@Success		200		{object}	store.ServiceArea
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
+//	@Failure		502		{object}	utils.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/profiles/me/service-area [put]
+func (app *application) putMyServiceAreaHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ServiceAreaPayload
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := utils.Validate.StructCtx(r.Context(), payload); err != nil {
+		utils.BadRequestResponse(w, r, err)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	area := store.ServiceArea{UserID: userID, TravelMiles: payload.TravelMiles, Places: []store.ServicePlace{}}
+	for _, p := range payload.Places {
+		location, err := app.lookupByZip(r.Context(), p.ZIPCode)
+		if err != nil {
+			utils.WriteProblem(w, r, err)
+			return
+		}
+		area.Places = append(area.Places, store.ServicePlace{Name: p.Name, Miles: p.Miles, Location: location})
+	}
+
+	if err := app.store.ServiceAreas.Replace(r.Context(), &area); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := utils.JsonResponse(w, http.StatusOK, area); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
+}
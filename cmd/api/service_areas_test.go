@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceAreas(t *testing.T) {
+	srv := newTestServer(t, newTestApplication(t))
+	srv.app.geocoder.(*fakeGeocoder).locations["80202"] = store.Location{
+		City: "Denver", State: "CO", ZIPCode: "80202", Country: "USA", Latitude: 39.75, Longitude: -105.0,
+	}
+
+	signUp := func(email, city, zip string, lat, lon float64) (*testClient, string) {
+		c := srv.newClient(t)
+		payload := registrationPayload(email)
+		payload["city"], payload["state"], payload["zip_code"] = city, "KS", zip
+		payload["latitude"], payload["longitude"] = lat, lon
+		id := c.signUpWith(payload)
+		resp := c.do(http.MethodPut, "/v1/profiles/me", map[string]any{"headline": city + " DP", "roles": []string{"director_of_photography"}})
+		require.Equal(t, http.StatusCreated, resp.status, string(resp.body))
+		return c, id
+	}
+	local, _ := signUp("local@example.com", "Kansas City", "64105", 39.1, -94.58)
+	lawrence, _ := signUp("lawrence@example.com", "Lawrence", "66044", 38.97, -95.24)
+	wichita, wichitaID := signUp("wichita@example.com", "Wichita", "67202", 37.69, -97.34)
+
+	search := func(query string) map[string]string {
+		t.Helper()
+		resp := local.do(http.MethodGet, "/v1/cinematographers/search?"+query, nil)
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var found []Cinematographer
+		resp.decode(t, &found)
+		matched := map[string]string{}
+		for _, c := range found {
+			matched[c.Headline] = c.MatchedBy
+		}
+		return matched
+	}
+
+	t.Run("home only by default", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"Kansas City DP": store.MatchedNearby}, search("zip=64105&miles=10"))
+
+		resp := lawrence.do(http.MethodGet, "/v1/profiles/me/service-area", nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		var area store.ServiceArea
+		resp.decode(t, &area)
+		assert.Zero(t, area.TravelMiles)
+		assert.Empty(t, area.Places)
+	})
+
+	t.Run("rejects bad service areas", func(t *testing.T) {
+		for _, body := range []map[string]any{
+			{"travel_miles": 501},
+			{"travel_miles": -1},
+			{"places": []map[string]any{{"name": "Nowhere"}}},
+			{"places": []map[string]any{{"zip_code": "64105", "miles": 1000}}},
+		} {
+			resp := lawrence.do(http.MethodPut, "/v1/profiles/me/service-area", body)
+			assert.Equal(t, http.StatusBadRequest, resp.status, body)
+		}
+
+		resp := lawrence.do(http.MethodPut, "/v1/profiles/me/service-area", map[string]any{"places": []map[string]any{{"zip_code": "00000"}}})
+		assert.Equal(t, http.StatusNotFound, resp.status)
+	})
+
+	t.Run("travel radius and places", func(t *testing.T) {
+		resp := lawrence.do(http.MethodPut, "/v1/profiles/me/service-area", map[string]any{"travel_miles": 150})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+
+		resp = wichita.do(http.MethodPut, "/v1/profiles/me/service-area", map[string]any{
+			"places": []map[string]any{{"name": "Denver metro", "zip_code": "80202", "miles": 30}},
+		})
+		require.Equal(t, http.StatusOK, resp.status, string(resp.body))
+		var area store.ServiceArea
+		resp.decode(t, &area)
+		require.Len(t, area.Places, 1)
+		assert.Equal(t, "DENVER", area.Places[0].Location.City)
+
+		assert.Equal(t, map[string]string{
+			"Kansas City DP": store.MatchedNearby,
+			"Lawrence DP":    store.MatchedTravelRadius,
+		}, search("zip=64105&miles=10"))
+		assert.Equal(t, map[string]string{"Wichita DP": store.MatchedServiceArea}, search("zip=80202&miles=10"))
+
+		resp = local.do(http.MethodGet, "/v1/profiles/"+wichitaID, nil)
+		require.Equal(t, http.StatusOK, resp.status)
+		var profile store.Profile
+		resp.decode(t, &profile)
+		assert.Len(t, profile.ServicePlaces, 1)
+	})
+}
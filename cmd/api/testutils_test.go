@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/michaelhoman/ShotSeek/internal/auth"
+	"github.com/michaelhoman/ShotSeek/internal/config"
+	"github.com/michaelhoman/ShotSeek/internal/location"
+	"github.com/michaelhoman/ShotSeek/internal/mailer"
+	"github.com/michaelhoman/ShotSeek/internal/secrets"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+const testPassword = "correct-horse-battery"
+
+// fakeMailer records every email instead of sending it
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []sentEmail
+}
+
+type sentEmail struct {
+	template string
+	email    string
+	data     any
+}
+
+func (m *fakeMailer) Send(templateFile, first_name, last_name, email string, data any, isSandbox bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, sentEmail{template: templateFile, email: email, data: data})
+	return nil
+}
+
+// activationToken returns the plain token from the last invitation sent to email
+func (m *fakeMailer) activationToken(t *testing.T, email string) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		sent := m.sent[i]
+		if sent.email != email || sent.template != mailer.UserInvitationTemplate {
+			continue
+		}
+
+		var vars struct{ ActivationURL string }
+		raw, err := json.Marshal(sent.data)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(raw, &vars))
+
+		u, err := url.Parse(vars.ActivationURL)
+		require.NoError(t, err)
+		return u.Query().Get("token")
+	}
+
+	t.Fatalf("no invitation sent to %s", email)
+	return ""
+}
+
+// fakeGeocoder answers from a fixed table and counts lookups
+type fakeGeocoder struct {
+	mu        sync.Mutex
+	locations map[string]store.Location
+	lookups   int
+}
+
+func (g *fakeGeocoder) LookupZip(ctx context.Context, zip string) (*store.Location, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lookups++
+
+	loc, ok := g.locations[zip]
+	if !ok {
+		return nil, location.ErrNoResults
+	}
+	return &loc, nil
+}
+
+type testOption func(*application)
+
+// withStorage replaces the default in-memory storage
+func withStorage(s store.Storage) testOption {
+	return func(app *application) { app.store = s }
+}
+
+// newTestApplication wires an application the way main does, with an in-memory store,
+// freshly generated signing keys, no rate limiting and fake external services
+func newTestApplication(t *testing.T, opts ...testOption) *application {
+	t.Helper()
+
+	cfg := config.Defaults()
+	cfg.Env = "test"
+	cfg.Db.Driver = "memory"
+	cfg.RateLimit.Enabled = false
+	cfg.Auth.Keys = writeTestKeys(t)
+
+	provider, err := secrets.NewResolver("", nil)
+	require.NoError(t, err)
+	jwtAuth, err := auth.NewJWTAuth(context.Background(), provider, cfg.Auth.Keys)
+	require.NoError(t, err)
+
+	rateLimiter, err := newRateLimiter(cfg.RateLimit, nil)
+	require.NoError(t, err)
+
+	app := &application{
+		config:      cfg,
+		store:       store.NewMemoryStorage(),
+		mailer:      &fakeMailer{},
+		jwtService:  auth.NewJWTService(cfg.Auth.Token.Secret, cfg.Auth.Token.Exp),
+		jwtAuth:     jwtAuth,
+		geocoder:    &fakeGeocoder{locations: map[string]store.Location{}},
+		rateLimiter: rateLimiter,
+		cors:        func(next http.Handler) http.Handler { return next },
+	}
+	for _, opt := range opts {
+		opt(app)
+	}
+
+	app.auth = auth.NewAuthHandler(app.store, app.config, app.jwtService, app.jwtAuth, app.mailer)
+	return app
+}
+
+// writeTestKeys generates an ECDSA key pair and returns file references to it
+func writeTestKeys(t *testing.T) config.KeysConfig {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	privateDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "private_key.pem")
+	publicPath := filepath.Join(dir, "public_key.pem")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateDER}), 0o600))
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600))
+
+	return config.KeysConfig{
+		PrivateKey: secrets.SchemeFile + privatePath,
+		PublicKey:  secrets.SchemeFile + publicPath,
+	}
+}
+
+// testServer serves the application over TLS, since the auth cookies are Secure
+type testServer struct {
+	*httptest.Server
+	app    *application
+	mailer *fakeMailer
+}
+
+func newTestServer(t *testing.T, app *application) *testServer {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(app.mount())
+	t.Cleanup(srv.Close)
+
+	mail, _ := app.mailer.(*fakeMailer)
+	return &testServer{Server: srv, app: app, mailer: mail}
+}
+
+// testClient is one browser: it keeps its own cookies across requests
+type testClient struct {
+	t      *testing.T
+	srv    *testServer
+	client *http.Client
+}
+
+func (ts *testServer) newClient(t *testing.T) *testClient {
+	t.Helper()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	// ts.Client is shared, so copy its TLS transport rather than attaching the jar to it
+	client := &http.Client{Transport: ts.Client().Transport, Jar: jar}
+	return &testClient{t: t, srv: ts, client: client}
+}
+
+type testResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// decode unwraps the {"data": ...} envelope into dst
+func (r testResponse) decode(t *testing.T, dst any) {
+	t.Helper()
+	envelope := struct {
+		Data any `json:"data"`
+	}{Data: dst}
+	require.NoError(t, json.Unmarshal(r.body, &envelope), string(r.body))
+}
+
+// problem decodes an error response
+func (r testResponse) problem(t *testing.T) map[string]any {
+	t.Helper()
+	var p map[string]any
+	require.NoError(t, json.Unmarshal(r.body, &p), string(r.body))
+	return p
+}
+
+// do sends a request with body encoded as JSON when it is not nil
+func (c *testClient) do(method, path string, body any) testResponse {
+	c.t.Helper()
+	return c.doWithHeader(method, path, body, nil)
+}
+
+// doWithHeader is do with extra request headers, such as If-Match
+func (c *testClient) doWithHeader(method, path string, body any, header http.Header) testResponse {
+	c.t.Helper()
+
+	var reader io.Reader
+	_, isReader := body.(io.Reader)
+	switch {
+	case isReader:
+		// Sent as is, with the Content-Type given in header
+		reader = body.(io.Reader)
+	case body != nil:
+		raw, err := json.Marshal(body)
+		require.NoError(c.t, err)
+		reader = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequest(method, c.srv.URL+path, reader)
+	require.NoError(c.t, err)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil && !isReader {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	require.NoError(c.t, err)
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(c.t, err)
+	return testResponse{status: resp.StatusCode, header: resp.Header, body: raw}
+}
+
+// cookie returns the value the client would send for name, or "" when it has none
+func (c *testClient) cookie(name string) string {
+	u, _ := url.Parse(c.srv.URL)
+	for _, cookie := range c.client.Jar.Cookies(u) {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+func registrationPayload(email string) map[string]any {
+	return map[string]any{
+		"email":      email,
+		"password":   testPassword,
+		"first_name": "Roger",
+		"last_name":  "Deakins",
+		"city":       "Bozeman",
+		"state":      "MT",
+		"zip_code":   "59715",
+		"country":    "USA",
+	}
+}
+
+func (c *testClient) register(email string) testResponse {
+	c.t.Helper()
+	return c.do(http.MethodPost, "/v1/authentication/register", registrationPayload(email))
+}
+
+func (c *testClient) activate(token string) testResponse {
+	c.t.Helper()
+	return c.do(http.MethodPut, "/v1/authentication/activate/"+token, nil)
+}
+
+func (c *testClient) login(email, password string) testResponse {
+	c.t.Helper()
+	return c.do(http.MethodPost, "/v1/authentication/login", map[string]string{"email": email, "password": password})
+}
+
+// signUp registers, activates and logs in a new user, returning their ID
+func (c *testClient) signUp(email string) string {
+	c.t.Helper()
+	return c.signUpWith(registrationPayload(email))
+}
+
+// signUpWith is signUp with a custom registration payload, e.g. to place the user elsewhere
+func (c *testClient) signUpWith(payload map[string]any) string {
+	c.t.Helper()
+	email, _ := payload["email"].(string)
+
+	resp := c.do(http.MethodPost, "/v1/authentication/register", payload)
+	require.Equal(c.t, http.StatusCreated, resp.status, string(resp.body))
+	resp = c.activate(c.srv.mailer.activationToken(c.t, email))
+	require.Equal(c.t, http.StatusNoContent, resp.status, string(resp.body))
+	resp = c.login(email, testPassword)
+	require.Equal(c.t, http.StatusOK, resp.status, string(resp.body))
+
+	var user store.User
+	c.do(http.MethodGet, "/v1/users/", nil).decode(c.t, &user)
+	require.Equal(c.t, strings.ToLower(email), strings.ToLower(user.Email))
+	return user.ID.String()
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
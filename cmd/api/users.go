@@ -11,6 +11,7 @@ import (
 
 	// store "github.com/michaelhoman/ShotSeek/internal/store/postgres"
 
+	int_middleware "github.com/michaelhoman/ShotSeek/internal/middleware"
 	"github.com/michaelhoman/ShotSeek/internal/store"
 	"github.com/michaelhoman/ShotSeek/internal/utils"
 )
@@ -40,9 +41,9 @@ type CreateUserPayload struct {
 //	@Produce		json
 //	@Param			payload	body		CreateUserPayload	true	"User payload"
 //	@Success		200		{object}	store.User
-//	@Failure		400		{object}	error
-//	@Failure		404		{object}	error
-//	@Failure		500		{object}	error
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/users [post]
 // func (app *application) createUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -92,6 +93,7 @@ type UpdateUserPayload struct {
 	Zipcode   *string `json:"zip_code" validate:"omitempty"`
 	City      *string `json:"city" validate:"omitempty"`
 	State     *string `json:"state" validate:"omitempty"`
+	Version   *int    `json:"version" validate:"omitempty,min=0"` // used when If-Match is absent
 }
 
 // GetUser godoc
@@ -103,14 +105,20 @@ type UpdateUserPayload struct {
 //	@Produce		json
 //	@Param			id	path		string	true	"User ID"
 //	@Success		200	{object}	store.User
-//	@Failure		400	{object}	error
-//	@Failure		404	{object}	error
-//	@Failure		500	{object}	error
+//	@Failure		400	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/users/{id} [get]
 func (app *application) getUserByIDHandler(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromCtx(r)
 
+	etag := userETag(user)
+	if notModified(w, r, etag) {
+		return
+	}
+
+	w.Header().Set("ETag", etag)
 	if err := utils.JsonResponse(w, http.StatusOK, user); err != nil {
 		utils.InternalServerError(w, r, err)
 		return
@@ -125,13 +133,35 @@ func (app *application) getUserByIDHandler(w http.ResponseWriter, r *http.Reques
 //	@Accept			json
 //	@Produce		json
 //	@Success		200	{object}	store.User
-//	@Failure		400	{object}	error
-//	@Failure		404	{object}	error
-//	@Failure		500	{object}	error
+//	@Failure		400	{object}	utils.Problem
+//	@Failure		404	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/users/ [get]
 func (app *application) getCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
-	user := getUserFromCtx(r)
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
+	user, err := app.store.Users.GetByID(r.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	etag := userETag(user)
+	if notModified(w, r, etag) {
+		return
+	}
+
+	w.Header().Set("ETag", etag)
 	if err := utils.JsonResponse(w, http.StatusOK, user); err != nil {
 		utils.InternalServerError(w, r, err)
 		return
@@ -141,16 +171,20 @@ func (app *application) getCurrentUserHandler(w http.ResponseWriter, r *http.Req
 // UpdateUser godoc
 //
 //	@Summary		Updates a user
-//	@Description	Updates a user by ID
+//	@Description	Updates a user by ID. Send the ETag from GET in If-Match (or the version field); a stale ETag gets 412 and a stale version 409, both with the current user.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		int					true	"User ID"
-//	@Param			payload	body		UpdateUserPayload	true	"User payload"
-//	@Success		200		{object}	store.User
-//	@Failure		400		{object}	error
-//	@Failure		404		{object}	error
-//	@Failure		500		{object}	error
+//	@Param			id			path		int					true	"User ID"
+//	@Param			If-Match	header		string				false	"ETag of the user being edited"
+//	@Param			payload		body		UpdateUserPayload	true	"User payload"
+//	@Success		204			{object}	nil
+//	@Failure		400			{object}	utils.Problem
+//	@Failure		404			{object}	utils.Problem
+//	@Failure		409			{object}	utils.Problem
+//	@Failure		412			{object}	utils.Problem
+//	@Failure		428			{object}	utils.Problem
+//	@Failure		500			{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/users/{id} [patch]
 func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -167,6 +201,12 @@ func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if err := checkPrecondition(r, userETag(user), payload.Version, user.Version, user); err != nil {
+		w.Header().Set("ETag", userETag(user))
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
 	if payload.Email != nil {
 		user.Email = *payload.Email
 	}
@@ -195,10 +235,20 @@ func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request
 
 	usersStore := app.store.Users
 	if err := usersStore.Update(ctx, user, location); err != nil {
-		utils.InternalServerError(w, r, err)
+		switch {
+		case errors.Is(err, store.ErrEditConflict):
+			app.userConflictResponse(w, r, user.ID)
+		case errors.Is(err, store.ErrDuplicateEmail):
+			utils.WriteProblem(w, r, utils.WrapAppError(http.StatusConflict, utils.CodeDuplicateEmail, "A user with that email already exists", err))
+		case errors.Is(err, store.ErrNotFound):
+			utils.NotFoundResponse(w, r, err)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
 		return
 	}
 
+	w.Header().Set("ETag", userETag(user))
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -209,14 +259,23 @@ func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		int	true	"User ID"
-//	@Success		204	{object}	nil
-//	@Failure		400	{object}	error
+//	@Param			id			path		int		true	"User ID"
+//	@Param			If-Match	header		string	true	"ETag of the user being deleted"
+//	@Success		204			{object}	nil
+//	@Failure		400			{object}	utils.Problem
+//	@Failure		412			{object}	utils.Problem
+//	@Failure		428			{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/users/{id} [delete]
 func (app *application) deleteUserHandler(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromCtx(r)
 
+	if err := checkPrecondition(r, userETag(user), nil, user.Version, user); err != nil {
+		w.Header().Set("ETag", userETag(user))
+		utils.WriteProblem(w, r, err)
+		return
+	}
+
 	ctx := r.Context()
 
 	usersStore := app.store.Users
@@ -237,9 +296,9 @@ func (app *application) deleteUserHandler(w http.ResponseWriter, r *http.Request
 //	@Produce		json
 //	@Param			token	path		string	true	"Invitation token"
 //	@Success		204		{string}	string	"User activated"
-//	@Failure		400		{object}	error
-//	@Failure		404		{object}	error
-//	@Failure		500		{object}	error
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		404		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/authentication/activate/{token} [put]
 func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -274,12 +333,6 @@ func (app *application) usersContextMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		idParam := chi.URLParam(r, "userID")
 		id, err := convertToUUID(idParam)
-		if err != nil {
-			fmt.Println("Error parsing UUID:", err)
-		} else {
-			fmt.Println("Parsed UUID:", id)
-		}
-
 		if err != nil {
 			utils.InternalServerError(w, r, err)
 			return
@@ -308,27 +361,27 @@ func (app *application) currentUserContextMiddleware(next http.Handler) http.Han
 		// Step 1: Extract token from Authorization header
 		tokenStr, err := utils.ExtractBearerToken(r)
 		if err != nil {
-			utils.BadRequestResponse(w, r, fmt.Errorf("missing or malformed Authorization header"))
+			utils.BadRequestResponse(w, r, utils.WrapAppError(http.StatusBadRequest, utils.CodeBadRequest, "Missing or malformed Authorization header", err))
 			return
 		}
 
 		// Step 2: Decode and validate token
-		claims, err := utils.DecodeJWT(tokenStr, app.jwtAuth.PublicKey)
+		claims, err := utils.DecodeJWT(tokenStr, app.jwtAuth.PublicKey())
 		if err != nil {
-			utils.BadRequestResponse(w, r, fmt.Errorf("invalid token: %w", err))
+			utils.BadRequestResponse(w, r, utils.WrapAppError(http.StatusBadRequest, utils.CodeBadRequest, "Invalid token", err))
 			return
 		}
 
 		// Step 3: Extract user ID from `sub` claim
 		sub, ok := claims["sub"].(string)
 		if !ok || sub == "" {
-			utils.BadRequestResponse(w, r, errors.New("missing or invalid subject claim"))
+			utils.BadRequestResponse(w, r, utils.NewAppError(http.StatusBadRequest, utils.CodeBadRequest, "Missing or invalid subject claim"))
 			return
 		}
 
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			utils.BadRequestResponse(w, r, errors.New("invalid user ID format in token"))
+			utils.BadRequestResponse(w, r, utils.WrapAppError(http.StatusBadRequest, utils.CodeBadRequest, "Invalid user ID format in token", err))
 			return
 		}
 
@@ -345,6 +398,31 @@ func (app *application) currentUserContextMiddleware(next http.Handler) http.Han
 	})
 }
 
+// authenticatedUserID returns the ID of the user whose token passed the JWT middleware
+func authenticatedUserID(r *http.Request) (uuid.UUID, error) {
+	userID, err := uuid.Parse(int_middleware.UserIDFromRequest(r))
+	if err != nil {
+		return uuid.Nil, utils.WrapAppError(http.StatusUnauthorized, utils.CodeUnauthorized, "Authentication required", err)
+	}
+	return userID, nil
+}
+
+// userConflictResponse reports an update that lost a race, with the user as they are now
+func (app *application) userConflictResponse(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	current, err := app.store.Users.GetByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			utils.NotFoundResponse(w, r, err)
+			return
+		}
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", userETag(current))
+	utils.WriteProblem(w, r, editConflict(current))
+}
+
 func getUserFromCtx(r *http.Request) *store.User {
 	user, _ := r.Context().Value(userCtx).(*store.User)
 	return user
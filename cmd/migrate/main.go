@@ -0,0 +1,111 @@
+// Command migrate applies the embedded SQL migrations to the configured database.
+//
+//	go run ./cmd/migrate [-config config.yaml] up|down|redo|status|to <version>
+//
+// The database address comes from the same configuration as the API (DB_ADDR, config file, secrets).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/config"
+	"github.com/michaelhoman/ShotSeek/internal/migrate"
+	"github.com/michaelhoman/ShotSeek/internal/postgres_db"
+	"github.com/michaelhoman/ShotSeek/internal/secrets"
+	"github.com/pressly/goose/v3"
+
+	_ "github.com/lib/pq"
+)
+
+const usage = "usage: migrate [-config file] up | down | redo | status | to <version>"
+
+func main() {
+	log.SetFlags(0)
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file; environment variables override it")
+	flag.Usage = func() { fmt.Fprintln(flag.CommandLine.Output(), usage); flag.PrintDefaults() }
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal(usage)
+	}
+
+	ctx := context.Background()
+
+	provider, err := secrets.NewResolverFromEnv()
+	if err != nil {
+		log.Fatalf("Error initializing secrets: %v", err)
+	}
+	cfg, err := config.Load(ctx, *configFile, provider)
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+
+	db, err := postgres_db.New(cfg.Db.Addr, 1, 1, time.Minute)
+	if err != nil {
+		log.Fatalf("Connecting to database: %v", err)
+	}
+	defer db.Close()
+
+	runner, err := migrate.NewRunner(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var results []*goose.MigrationResult
+	switch cmd := flag.Arg(0); cmd {
+	case "up":
+		results, err = runner.Up(ctx)
+	case "down":
+		var result *goose.MigrationResult
+		result, err = runner.Down(ctx)
+		if result != nil {
+			results = append(results, result)
+		}
+	case "redo":
+		results, err = runner.Redo(ctx)
+	case "to":
+		if flag.NArg() != 2 {
+			log.Fatal(usage)
+		}
+		version, parseErr := strconv.ParseInt(flag.Arg(1), 10, 64)
+		if parseErr != nil {
+			log.Fatalf("invalid version %q", flag.Arg(1))
+		}
+		results, err = runner.To(ctx, version)
+	case "status":
+		err = printStatus(ctx, runner)
+	default:
+		log.Fatalf("unknown command %q\n%s", cmd, usage)
+	}
+
+	for _, result := range results {
+		log.Println(result)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func printStatus(ctx context.Context, runner *migrate.Runner) error {
+	statuses, err := runner.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+	for _, s := range statuses {
+		appliedAt := "-"
+		if !s.AppliedAt.IsZero() {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Source.Version, s.State, appliedAt, s.Source.Path)
+	}
+	return w.Flush()
+}
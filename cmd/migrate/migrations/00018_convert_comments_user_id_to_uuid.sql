@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+-- comments.user_id was created as BIGINT while users.id is a uuid, so an existing comment's
+-- author cannot be mapped to a user. Those rows are moved to archived_comments, keeping the
+-- old id in legacy_user_id, instead of being deleted.
+CREATE TABLE IF NOT EXISTS archived_comments (
+    id BIGINT PRIMARY KEY,
+    post_id BIGINT NOT NULL,
+    legacy_user_id BIGINT,
+    user_id uuid,
+    content TEXT NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL,
+    updated_at timestamp(0) with time zone NOT NULL
+);
+
+INSERT INTO archived_comments (id, post_id, legacy_user_id, content, created_at, updated_at)
+SELECT id, post_id, user_id, content, created_at, updated_at FROM comments;
+DELETE FROM comments;
+
+ALTER TABLE comments
+    ALTER COLUMN user_id TYPE uuid USING NULL;
+
+-- Comments archived by a rollback go back once their author still exists
+INSERT INTO comments (id, post_id, user_id, content, created_at, updated_at)
+SELECT a.id, a.post_id, a.user_id, a.content, a.created_at, a.updated_at
+FROM archived_comments a
+WHERE a.user_id IS NOT NULL AND EXISTS (SELECT 1 FROM users u WHERE u.id = a.user_id);
+DELETE FROM archived_comments a WHERE EXISTS (SELECT 1 FROM comments c WHERE c.id = a.id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The reverse: comments by uuid authors are archived, and those archived by Up are restored
+INSERT INTO archived_comments (id, post_id, user_id, content, created_at, updated_at)
+SELECT id, post_id, user_id, content, created_at, updated_at FROM comments;
+DELETE FROM comments;
+
+ALTER TABLE comments
+    ALTER COLUMN user_id TYPE BIGINT USING NULL;
+
+INSERT INTO comments (id, post_id, user_id, content, created_at, updated_at)
+SELECT id, post_id, legacy_user_id, content, created_at, updated_at
+FROM archived_comments
+WHERE legacy_user_id IS NOT NULL;
+DELETE FROM archived_comments WHERE legacy_user_id IS NOT NULL;
+-- +goose StatementEnd
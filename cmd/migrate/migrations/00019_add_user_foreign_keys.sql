@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Rows pointing at users or posts that no longer exist would block the constraints below
+DELETE FROM user_invitations ui WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = ui.user_id);
+UPDATE users u SET location_id = NULL
+WHERE location_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM locations l WHERE l.id = u.location_id);
+
+-- Deleting a user removes everything they own
+ALTER TABLE posts
+    DROP CONSTRAINT fk_user,
+    ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE comments
+    DROP CONSTRAINT fk_post,
+    ADD CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE refresh_tokens
+    DROP CONSTRAINT refresh_tokens_user_id_fkey,
+    ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE user_invitations
+    ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+-- Locations are shared between users, so deleting one only clears the reference
+ALTER TABLE users
+    ADD CONSTRAINT fk_location FOREIGN KEY (location_id) REFERENCES locations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_posts_user_id ON posts(user_id);
+CREATE INDEX IF NOT EXISTS idx_comments_user_id ON comments(user_id);
+CREATE INDEX IF NOT EXISTS idx_comments_post_id ON comments(post_id);
+CREATE INDEX IF NOT EXISTS idx_users_location_id ON users(location_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_location_id;
+DROP INDEX IF EXISTS idx_comments_post_id;
+DROP INDEX IF EXISTS idx_comments_user_id;
+DROP INDEX IF EXISTS idx_posts_user_id;
+
+ALTER TABLE users
+    DROP CONSTRAINT fk_location;
+
+ALTER TABLE user_invitations
+    DROP CONSTRAINT fk_user;
+
+ALTER TABLE refresh_tokens
+    DROP CONSTRAINT fk_user,
+    ADD CONSTRAINT refresh_tokens_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+ALTER TABLE comments
+    DROP CONSTRAINT fk_user,
+    DROP CONSTRAINT fk_post,
+    ADD CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES posts(id);
+
+ALTER TABLE posts
+    DROP CONSTRAINT fk_user,
+    ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id);
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- user_images duplicated images without recording an owner; images is the one table kept.
+-- Its rows are carried over unowned so no uploaded file is forgotten.
+INSERT INTO images (file_url, created_at)
+SELECT image_url, created_at FROM user_images;
+
+DROP TABLE user_images;
+
+CREATE INDEX IF NOT EXISTS idx_images_user_id ON images(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_images_user_id;
+
+CREATE TABLE IF NOT EXISTS user_images (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    image_url VARCHAR(255) NOT NULL
+);
+
+INSERT INTO user_images (image_url, created_at)
+SELECT file_url, COALESCE(created_at, NOW()) FROM images WHERE user_id IS NULL;
+
+DELETE FROM images WHERE user_id IS NULL;
+-- +goose StatementEnd
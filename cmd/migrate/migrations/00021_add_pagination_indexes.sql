@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Lists page on (created_at, id) in either direction, so a plain btree on the pair serves both
+CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_posts_tags ON posts USING GIN (tags);
+
+-- The composite index covers every lookup the single column one did
+CREATE INDEX IF NOT EXISTS idx_comments_post_id_created_at_id ON comments(post_id, created_at, id);
+DROP INDEX IF EXISTS idx_comments_post_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_comments_post_id ON comments(post_id);
+DROP INDEX IF EXISTS idx_comments_post_id_created_at_id;
+DROP INDEX IF EXISTS idx_posts_tags;
+DROP INDEX IF EXISTS idx_posts_created_at_id;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS search tsvector;
+
+-- Titles outrank tags, which outrank body text. A generated column cannot be used because
+-- array_to_string is not immutable, so a trigger keeps the vector current.
+CREATE OR REPLACE FUNCTION posts_search_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(array_to_string(NEW.tags, ' '), '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.content, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER posts_search_update
+    BEFORE INSERT OR UPDATE OF title, content, tags ON posts
+    FOR EACH ROW EXECUTE FUNCTION posts_search_update();
+
+-- Fire the trigger for existing rows
+UPDATE posts SET title = title;
+
+CREATE INDEX IF NOT EXISTS idx_posts_search ON posts USING GIN (search);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_posts_search;
+DROP TRIGGER IF EXISTS posts_search_update ON posts;
+DROP FUNCTION IF EXISTS posts_search_update();
+ALTER TABLE posts DROP COLUMN IF EXISTS search;
+-- +goose StatementEnd
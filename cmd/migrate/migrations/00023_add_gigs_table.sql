@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A gig is a post with crew call details; the post keeps the title, description, tags and version
+CREATE TABLE IF NOT EXISTS gigs (
+    post_id BIGINT PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    shoot_start DATE NOT NULL,
+    shoot_end DATE NOT NULL,
+    call_time TIME(0) NOT NULL,
+    duration_hours SMALLINT NOT NULL CHECK (duration_hours BETWEEN 1 AND 24),
+    day_rate_min INTEGER NOT NULL CHECK (day_rate_min >= 0),
+    day_rate_max INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL,
+    gear TEXT[] NOT NULL DEFAULT '{}',
+    union_status TEXT NOT NULL CHECK (union_status IN ('union', 'non_union', 'either')),
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'filled', 'cancelled')),
+    location_id BIGINT NOT NULL REFERENCES locations(id),
+    CONSTRAINT gigs_shoot_dates CHECK (shoot_end >= shoot_start),
+    CONSTRAINT gigs_day_rate CHECK (day_rate_max >= day_rate_min)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gigs_status_role ON gigs(status, role);
+CREATE INDEX IF NOT EXISTS idx_gigs_shoot_dates ON gigs(shoot_start, shoot_end);
+CREATE INDEX IF NOT EXISTS idx_gigs_location_id ON gigs(location_id);
+CREATE INDEX IF NOT EXISTS idx_gigs_gear ON gigs USING GIN (gear);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS gigs;
+-- +goose StatementEnd
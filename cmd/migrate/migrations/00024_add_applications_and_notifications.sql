@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS applications (
+    id bigserial PRIMARY KEY,
+    post_id BIGINT NOT NULL REFERENCES gigs(post_id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    portfolio_links TEXT[] NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'submitted'
+        CHECK (status IN ('submitted', 'shortlisted', 'rejected', 'hired', 'withdrawn', 'closed')),
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT applications_post_user_key UNIQUE (post_id, user_id)
+);
+
+-- The unique constraint already indexes lookups by post; applicants list their own newest first
+CREATE INDEX IF NOT EXISTS idx_applications_user_id_created_at_id ON applications(user_id, created_at, id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id bigserial PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    message TEXT NOT NULL,
+    link TEXT NOT NULL DEFAULT '',
+    read_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id_created_at_id ON notifications(user_id, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS applications;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- 00010_add_user_profiles_table only ever created images; this is the profile itself
+CREATE TABLE IF NOT EXISTS profiles (
+    user_id uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    headline VARCHAR(120) NOT NULL DEFAULT '',
+    bio TEXT NOT NULL DEFAULT '',
+    roles TEXT[] NOT NULL DEFAULT '{}',
+    specialties TEXT[] NOT NULL DEFAULT '{}',
+    years_experience SMALLINT NOT NULL DEFAULT 0 CHECK (years_experience BETWEEN 0 AND 80),
+    day_rate_min INTEGER NOT NULL DEFAULT 0 CHECK (day_rate_min >= 0),
+    day_rate_max INTEGER NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    languages TEXT[] NOT NULL DEFAULT '{}',
+    unions TEXT[] NOT NULL DEFAULT '{}',
+    links JSONB NOT NULL DEFAULT '[]',
+    version INT NOT NULL DEFAULT 0,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT profiles_day_rate CHECK (day_rate_max >= day_rate_min)
+);
+
+CREATE INDEX IF NOT EXISTS idx_profiles_roles ON profiles USING GIN (roles);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS profiles;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Profiles are keyed by user, but keyset cursors need a numeric tiebreaker
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS id BIGINT GENERATED ALWAYS AS IDENTITY;
+ALTER TABLE profiles ADD CONSTRAINT profiles_id_key UNIQUE (id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE profiles DROP COLUMN IF EXISTS id;
+-- +goose StatementEnd
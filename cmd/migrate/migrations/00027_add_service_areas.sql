@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- How far from their home location a user will travel, 0 for no further than a search radius reaches
+ALTER TABLE users ADD COLUMN IF NOT EXISTS travel_miles INT NOT NULL DEFAULT 0 CHECK (travel_miles BETWEEN 0 AND 500);
+
+-- Further places a user works, each a general location plus the miles around it
+CREATE TABLE IF NOT EXISTS service_areas (
+    id BIGSERIAL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    location_id BIGINT NOT NULL REFERENCES locations(id),
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    miles INT NOT NULL DEFAULT 0 CHECK (miles BETWEEN 0 AND 500)
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_areas_user_id ON service_areas(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS service_areas;
+ALTER TABLE users DROP COLUMN IF EXISTS travel_miles;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Stretches of time a user is free, pencilled in or booked. time_zone is the IANA zone the
+-- user entered the block in; starts_at and ends_at are instants either way.
+CREATE TABLE IF NOT EXISTS availability_blocks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('available', 'tentative', 'booked')),
+    starts_at timestamp(0) with time zone NOT NULL,
+    ends_at timestamp(0) with time zone NOT NULL,
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    note VARCHAR(500) NOT NULL DEFAULT '',
+    -- import marks blocks read from an uploaded calendar, which the next upload replaces
+    source VARCHAR(20) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'import')),
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_availability_blocks_user_id_starts_at ON availability_blocks(user_id, starts_at);
+
+-- The secret behind each user's subscribable .ics feed; only its SHA-256 is kept
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS calendar_feeds;
+DROP TABLE IF EXISTS availability_blocks;
+-- +goose StatementEnd
@@ -0,0 +1,66 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A producer's booking request or soft hold on a cinematographer's time. The terms (dates, rate,
+-- location) are the latest offer; counters replace them and booking_events keeps the history.
+CREATE TABLE IF NOT EXISTS bookings (
+    id bigserial PRIMARY KEY,
+    producer_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    cinematographer_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('request', 'hold')),
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'countered', 'held', 'accepted', 'declined', 'cancelled', 'expired')),
+    starts_at timestamp(0) with time zone NOT NULL,
+    ends_at timestamp(0) with time zone NOT NULL,
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    rate INT NOT NULL DEFAULT 0 CHECK (rate >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    location_id BIGINT NOT NULL REFERENCES locations(id),
+    message TEXT NOT NULL DEFAULT '',
+    expires_at timestamp(0) with time zone,
+    availability_block_id BIGINT REFERENCES availability_blocks(id) ON DELETE SET NULL,
+    version INT NOT NULL DEFAULT 0,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at),
+    CHECK (producer_id <> cinematographer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_producer_id_created_at_id ON bookings(producer_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_bookings_cinematographer_id_created_at_id ON bookings(cinematographer_id, created_at, id);
+-- The expiry sweep only looks at holds still open
+CREATE INDEX IF NOT EXISTS idx_bookings_open_holds_expires_at ON bookings(expires_at)
+    WHERE kind = 'hold' AND status IN ('pending', 'countered', 'held');
+
+-- Every change to a booking, who made it and the terms it left the booking with. actor_id is
+-- NULL for changes the system makes, such as expiring a hold.
+CREATE TABLE IF NOT EXISTS booking_events (
+    id bigserial PRIMARY KEY,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    actor_id uuid REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    from_status TEXT NOT NULL DEFAULT '',
+    to_status TEXT NOT NULL,
+    starts_at timestamp(0) with time zone NOT NULL,
+    ends_at timestamp(0) with time zone NOT NULL,
+    rate INT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_events_booking_id ON booking_events(booking_id, id);
+
+-- Blocks a booking puts on the cinematographer's calendar
+ALTER TABLE availability_blocks DROP CONSTRAINT IF EXISTS availability_blocks_source_check;
+ALTER TABLE availability_blocks ADD CONSTRAINT availability_blocks_source_check
+    CHECK (source IN ('manual', 'import', 'booking'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM availability_blocks WHERE source = 'booking';
+ALTER TABLE availability_blocks DROP CONSTRAINT IF EXISTS availability_blocks_source_check;
+ALTER TABLE availability_blocks ADD CONSTRAINT availability_blocks_source_check
+    CHECK (source IN ('manual', 'import'));
+DROP TABLE IF EXISTS booking_events;
+DROP TABLE IF EXISTS bookings;
+-- +goose StatementEnd
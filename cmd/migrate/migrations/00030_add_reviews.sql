@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- What each party to a completed booking thought of the other. Reviews are double blind: each
+-- stays hidden until visible_at, which is the end of the review period, or the moment the
+-- other party's review comes in.
+CREATE TABLE IF NOT EXISTS reviews (
+    id bigserial PRIMARY KEY,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    reviewer_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reviewee_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reviewee_role TEXT NOT NULL CHECK (reviewee_role IN ('producer', 'cinematographer')),
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    professionalism SMALLINT CHECK (professionalism BETWEEN 1 AND 5),
+    technical_skill SMALLINT CHECK (technical_skill BETWEEN 1 AND 5),
+    communication SMALLINT CHECK (communication BETWEEN 1 AND 5),
+    body TEXT NOT NULL DEFAULT '',
+    response TEXT NOT NULL DEFAULT '',
+    responded_at timestamp(0) with time zone,
+    visible_at timestamp(0) with time zone NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT reviews_booking_reviewer_key UNIQUE (booking_id, reviewer_id),
+    CHECK (reviewer_id <> reviewee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_reviewee_id_created_at_id ON reviews(reviewee_id, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reviews;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Cinematographer searches narrow homes and service places to a bounding box before measuring
+CREATE INDEX IF NOT EXISTS idx_locations_latitude_longitude ON locations(latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_service_areas_location_id ON service_areas(location_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_service_areas_location_id;
+DROP INDEX IF EXISTS idx_locations_latitude_longitude;
+-- +goose StatementEnd
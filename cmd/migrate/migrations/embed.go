@@ -0,0 +1,8 @@
+// Package migrations embeds the goose SQL migrations so the migrate command and the
+// API can apply them without the files being present at runtime.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,98 @@
+// Command secrets manages the encrypted secrets file read by the API through enc:// references.
+//
+//	go run ./cmd/secrets keygen                          # print a new base64 master key
+//	go run ./cmd/secrets seal -in secrets.json -out secrets.enc
+//	go run ./cmd/secrets open -in secrets.enc            # print the decrypted JSON
+//
+// seal and open read the master key from SECRETS_MASTER_KEY.
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/michaelhoman/ShotSeek/internal/secrets"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		log.Fatal("usage: secrets keygen | seal -in plain.json -out sealed.enc | open -in sealed.enc")
+	}
+
+	switch os.Args[1] {
+	case "keygen":
+		key, err := secrets.GenerateKey()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(base64.StdEncoding.EncodeToString(key))
+	case "seal":
+		seal(os.Args[2:])
+	case "open":
+		open(os.Args[2:])
+	default:
+		log.Fatalf("unknown command %q", os.Args[1])
+	}
+}
+
+func seal(args []string) {
+	fs := flag.NewFlagSet("seal", flag.ExitOnError)
+	in := fs.String("in", "", "plaintext JSON object of secret names to values")
+	out := fs.String("out", "", "encrypted output file")
+	fs.Parse(args)
+	if *in == "" || *out == "" {
+		log.Fatal("seal needs -in and -out")
+	}
+
+	key := masterKey()
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	plain := map[string]string{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		log.Fatalf("parsing %s: %v", *in, err)
+	}
+
+	sealed, err := secrets.Seal(key, plain)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, sealed, 0o600); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("sealed %d secrets into %s", len(plain), *out)
+}
+
+func open(args []string) {
+	fs := flag.NewFlagSet("open", flag.ExitOnError)
+	in := fs.String("in", "", "encrypted secrets file")
+	fs.Parse(args)
+	if *in == "" {
+		log.Fatal("open needs -in")
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	plain, err := secrets.Open(masterKey(), data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, _ := json.MarshalIndent(plain, "", "  ")
+	fmt.Println(string(out))
+}
+
+func masterKey() []byte {
+	key, err := secrets.DecodeKey(os.Getenv("SECRETS_MASTER_KEY"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return key
+}
@@ -0,0 +1,69 @@
+// Command seed fills a migrated development database with generated users, profiles, posts and comments.
+//
+//	go run ./cmd/seed [-config config.yaml] [-users 100] [-profiles 60] [-posts 200] [-comments 500] [-seed 1]
+//
+// The database address comes from the same configuration as the API (DB_ADDR, config file, secrets).
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/config"
+	"github.com/michaelhoman/ShotSeek/internal/db"
+	"github.com/michaelhoman/ShotSeek/internal/migrate"
+	"github.com/michaelhoman/ShotSeek/internal/postgres_db"
+	"github.com/michaelhoman/ShotSeek/internal/secrets"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	log.SetFlags(0)
+	seedCfg := db.DefaultConfig()
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file; environment variables override it")
+	flag.IntVar(&seedCfg.Users, "users", seedCfg.Users, "number of users to create")
+	flag.IntVar(&seedCfg.Profiles, "profiles", seedCfg.Profiles, "number of users who get a cinematographer profile")
+	flag.IntVar(&seedCfg.Posts, "posts", seedCfg.Posts, "number of posts to create")
+	flag.IntVar(&seedCfg.Comments, "comments", seedCfg.Comments, "number of comments to create")
+	flag.Int64Var(&seedCfg.Seed, "seed", seedCfg.Seed, "random seed; the same seed generates the same data")
+	flag.StringVar(&seedCfg.Password, "password", seedCfg.Password, "password shared by every seeded user")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	provider, err := secrets.NewResolverFromEnv()
+	if err != nil {
+		log.Fatalf("Error initializing secrets: %v", err)
+	}
+	cfg, err := config.Load(ctx, *configFile, provider)
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	if cfg.IsProduction() {
+		log.Fatal("Refusing to seed a production database")
+	}
+
+	conn, err := postgres_db.New(cfg.Db.Addr, cfg.Db.MaxOpenConns, cfg.Db.MaxIdleConns, time.Minute)
+	if err != nil {
+		log.Fatalf("Connecting to database: %v", err)
+	}
+	defer conn.Close()
+
+	runner, err := migrate.NewRunner(conn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := runner.EnsureCurrent(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := db.Seed(ctx, store.NewPostgresStorage(conn), conn, seedCfg); err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+	log.Println("Seeding complete")
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/michaelhoman/ShotSeek/internal/middleware"
 )
 
 // Define UI route handlers as standalone functions
@@ -23,9 +24,19 @@ func LoginPageHandler(w http.ResponseWriter, r *http.Request) {
 	tmpl.Execute(w, nil)
 }
 
+// contentSecurityPolicy covers the templates: htmx and Tailwind from their CDNs, inline
+// theme scripts and onclick handlers, and form posts back to the API
+const contentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' https://unpkg.com https://cdn.tailwindcss.com; " +
+	"style-src 'self' 'unsafe-inline'; img-src 'self' data:; connect-src 'self'; " +
+	"frame-ancestors 'none'; base-uri 'self'; form-action 'self'"
+
 // Register UI routes on an existing router
 func RegisterUIRoutes(r *chi.Mux) {
-	r.Get("/register", RegisterPageHandler)
-	r.Get("/verify", VerifyPageHandler)
-	r.Get("/login", LoginPageHandler)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.ContentSecurityPolicy(contentSecurityPolicy))
+		r.Get("/register", RegisterPageHandler)
+		r.Get("/verify", VerifyPageHandler)
+		r.Get("/login", LoginPageHandler)
+	})
 }
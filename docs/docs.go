@@ -24,6 +24,213 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/applications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the signed in user's applications with their current status, newest first. Follow next_cursor (also sent as a Link header) for the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Lists your applications",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Application"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/applications/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches an application. Only the applicant and the gig's poster can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Fetches an application",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Application"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/applications/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The gig's poster can shortlist, reject or hire; the applicant can withdraw. Rejected applicants can be shortlisted again, and hires can withdraw. A change the lifecycle does not allow gets 409 invalid_transition, and a change that races another gets 409 edit_conflict with the application as it is now. The other party is notified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Changes an application's status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateApplicationStatusPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Application"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
         "/authentication/activate/{token}": {
             "put": {
                 "security": [
@@ -60,15 +267,21 @@ const docTemplate = `{
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             }
@@ -106,15 +319,21 @@ const docTemplate = `{
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "401": {
                         "description": "Unauthorized",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             }
@@ -138,7 +357,9 @@ const docTemplate = `{
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             }
@@ -162,11 +383,15 @@ const docTemplate = `{
                     },
                     "401": {
                         "description": "Unauthorized",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             }
@@ -204,42 +429,46 @@ const docTemplate = `{
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             }
         },
-        "/locations/zip/nearby/{ZIPCode}/{miles}": {
+        "/availability": {
             "get": {
-                "description": "Get nearby locations by ZIP code",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Lists the signed in user's availability blocks that overlap from..to, earliest first. Either bound may be left out.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "locations"
+                    "availability"
                 ],
-                "summary": "Get nearby locations by ZIP code",
+                "summary": "Lists your availability",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ZIP code",
-                        "name": "ZIPCode",
-                        "in": "path",
-                        "required": true
+                        "description": "RFC 3339 timestamp or YYYY-MM-DD date (UTC)",
+                        "name": "from",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Distance in miles",
-                        "name": "miles",
-                        "in": "path",
-                        "required": true
+                        "description": "RFC 3339 timestamp or YYYY-MM-DD date (UTC), inclusive for a date",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -248,36 +477,37 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/store.Location"
+                                "$ref": "#/definitions/store.AvailabilityBlock"
                             }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
-            }
-        },
-        "/locations/zip/{ZIPCode}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
-                    },
-                    {
-                        "BearerAuth": []
                     }
                 ],
-                "description": "Lookup location by ZIP code",
+                "description": "Marks a stretch of the signed in user's time as available, tentative or booked. ends_at must be after starts_at; time_zone is the IANA zone the block was entered in.",
                 "consumes": [
                     "application/json"
                 ],
@@ -285,136 +515,87 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "locations"
+                    "availability"
                 ],
-                "summary": "Lookup location by ZIP code",
+                "summary": "Adds an availability block",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ZIP code",
-                        "name": "ZIPCode",
-                        "in": "path",
-                        "required": true
+                        "description": "Availability payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateAvailabilityPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/store.Location"
+                            "$ref": "#/definitions/store.AvailabilityBlock"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             }
         },
-        "/posts": {
+        "/availability/feed": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a new post from payload",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Creates a secret .ics address that calendar apps can subscribe to for the signed in user's availability. The address is only shown now; calling this again replaces it and stops the old one from working. Notes are left out of the feed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "posts"
-                ],
-                "summary": "Creates a post",
-                "parameters": [
-                    {
-                        "description": "Post payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.CreatePostPayload"
-                        }
-                    }
+                    "availability"
                 ],
+                "summary": "Turns on your calendar feed",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Post"
+                            "$ref": "#/definitions/api.CalendarFeed"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {}
-                    }
-                }
-            }
-        },
-        "/posts/comments/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Retrieves a comment by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "comments"
-                ],
-                "summary": "Retrieves a comment",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Comment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Comment"
+                            "$ref": "#/definitions/utils.Problem"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
                     "404": {
                         "description": "Not Found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             },
@@ -424,123 +605,129 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes a comment by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Stops the signed in user's secret .ics address from working.",
                 "tags": [
-                    "comments"
-                ],
-                "summary": "Deletes a comment",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Comment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "availability"
                 ],
+                "summary": "Turns off your calendar feed",
                 "responses": {
                     "204": {
                         "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/availability/import": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates a comment by ID",
+                "description": "Reads an iCalendar (.ics) file, sent as the file field of a multipart form or as a text/calendar body, and marks each of its events as a booked block, or a tentative one for tentative events. Free (transparent), cancelled, empty and past events are skipped, and recurring events only count for their first occurrence. Blocks from the previous import are replaced; blocks added or edited by hand are kept. Times without a zone are read in time_zone.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data",
+                    "text/calendar"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "availability"
                 ],
-                "summary": "Updates a comment",
+                "summary": "Imports busy time from a calendar",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Comment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "file",
+                        "description": "The .ics file",
+                        "name": "file",
+                        "in": "formData"
                     },
                     {
-                        "description": "Comment payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.CreateCommentPayload"
-                        }
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA zone for times without one",
+                        "name": "time_zone",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Comment"
+                            "$ref": "#/definitions/api.AvailabilityImport"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             }
         },
-        "/posts/{id}": {
+        "/availability/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches a post by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Fetches one of the signed in user's availability blocks.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "posts"
+                    "availability"
                 ],
-                "summary": "Fetches a post",
+                "summary": "Fetches an availability block",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Post ID",
+                        "description": "Block ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -550,20 +737,32 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Post"
+                            "$ref": "#/definitions/store.AvailabilityBlock"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             },
@@ -573,21 +772,15 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes a post by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Deletes one of the signed in user's availability blocks.",
                 "tags": [
-                    "posts"
+                    "availability"
                 ],
-                "summary": "Deletes a post",
+                "summary": "Deletes an availability block",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Post ID",
+                        "description": "Block ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -597,17 +790,29 @@ const docTemplate = `{
                     "204": {
                         "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             },
@@ -617,7 +822,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates an existing post from payload",
+                "description": "Changes the fields given of one of the signed in user's availability blocks. An imported block becomes a manual one, so the next import keeps it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -625,24 +830,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "posts"
+                    "availability"
                 ],
-                "summary": "Updates a post",
+                "summary": "Updates an availability block",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Post ID",
+                        "description": "Block ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Post payload",
+                        "description": "Availability payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.UpdatePostPayload"
+                            "$ref": "#/definitions/api.UpdateAvailabilityPayload"
                         }
                     }
                 ],
@@ -650,90 +855,134 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Post"
+                            "$ref": "#/definitions/store.AvailabilityBlock"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             }
         },
-        "/posts/{id}/comments": {
-            "post": {
+        "/bookings": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a new comment from payload",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists bookings the signed in user sent as a producer or received as a cinematographer, newest first. role keeps one side; status keeps one status. Follow next_cursor (also sent as a Link header) for the next page, keeping the same filters.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "bookings"
                 ],
-                "summary": "Creates a comment",
+                "summary": "Lists your bookings",
                 "parameters": [
+                    {
+                        "enum": [
+                            "producer",
+                            "cinematographer"
+                        ],
+                        "type": "string",
+                        "description": "Only bookings you sent (producer) or received (cinematographer)",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "countered",
+                            "held",
+                            "accepted",
+                            "declined",
+                            "cancelled",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Only bookings in this status",
+                        "name": "status",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Post ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "description": "Comment payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.CreateCommentPayload"
-                        }
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Comment"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Booking"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
-            }
-        },
-        "/users/": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches the current user",
+                "description": "Asks a cinematographer for their time at a rate and general location. A request books the dates once accepted. A hold pencils them in once accepted and lasts until the producer confirms it, either side cancels it, or expires_at passes. Holds on the same dates are ranked in the order they were placed (hold_rank). The cinematographer is notified.",
                 "consumes": [
                     "application/json"
                 ],
@@ -741,39 +990,138 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
+                ],
+                "summary": "Requests a booking or places a hold",
+                "parameters": [
+                    {
+                        "description": "Booking payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateBookingPayload"
+                        }
+                    }
                 ],
-                "summary": "Fetches the current user",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                            "$ref": "#/definitions/store.Booking"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             }
         },
-        "/users/{id}": {
+        "/bookings/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches a user by ID string/uuid",
+                "description": "Fetches a booking with its current terms and, for an open hold, its rank. Only the producer and the cinematographer can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Fetches a booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Booking"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/actions": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moves a booking along. While it is pending the cinematographer can accept, decline or counter with new starts_at, ends_at or rate; a counter hands the same choice to the producer, and so on. Accepting a request books the dates; accepting a hold marks them tentative until the producer confirms it, which only the first hold on those dates can do. The producer can cancel at any point; the cinematographer declines a pending booking and can cancel it after that. Acting on someone else's turn gets 403, an action the booking's status does not allow gets 409 invalid_transition, and a change that races another gets 409 edit_conflict with the booking as it is now. The other party is notified.",
                 "consumes": [
                     "application/json"
                 ],
@@ -781,82 +1129,213 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
                 ],
-                "summary": "Fetches a user by ID string/uuid",
+                "summary": "Responds to a booking",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
+                        "type": "integer",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Action",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BookingActionPayload"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                            "$ref": "#/definitions/store.Booking"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/bookings/{id}/events": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes a user by ID",
-                "consumes": [
+                "description": "Lists every change to a booking, oldest first: who made it, the status it moved between and the terms it left. actor_id is null for holds that expired.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Lists a booking's history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.BookingEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/reviews": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the reviews of a booking, oldest first: your own, and the other party's once it is visible.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "reviews"
                 ],
-                "summary": "Deletes a user",
+                "summary": "Lists a booking's reviews",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Review"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates a user by ID",
+                "description": "Rates the other party to an accepted booking from 1 to 5, with optional scores for professionalism, communication and, for a cinematographer, technical_skill. Reviews open once the booking ends and close 14 days later. They are double blind: neither party sees the other's review until both have reviewed or the period closes, whichever comes first (visible_at). Each party reviews a booking once; a second review gets 409. The other party is notified.",
                 "consumes": [
                     "application/json"
                 ],
@@ -864,88 +1343,3389 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "reviews"
                 ],
-                "summary": "Updates a user",
+                "summary": "Reviews the other party to a booking",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User payload",
+                        "description": "Review",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.UpdateUserPayload"
+                            "$ref": "#/definitions/api.CreateReviewPayload"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                            "$ref": "#/definitions/store.Review"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
-                        "schema": {}
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "api.CreateCommentPayload": {
-            "type": "object",
-            "required": [
-                "content"
-            ],
-            "properties": {
-                "content": {
-                    "type": "string",
-                    "maxLength": 1000
-                }
-            }
-        },
-        "api.CreatePostPayload": {
-            "type": "object",
-            "required": [
-                "content",
-                "title"
-            ],
-            "properties": {
-                "content": {
-                    "type": "string",
-                    "maxLength": 1000
-                },
-                "tags": {
-                    "type": "array",
-                    "maxItems": 100,
-                    "items": {
-                        "type": "string"
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
                     }
-                },
-                "title": {
-                    "type": "string",
-                    "maxLength": 100
                 }
             }
         },
-        "api.UpdatePostPayload": {
+        "/calendars/{token}.ics": {
+            "get": {
+                "description": "Serves a user's availability as iCalendar for calendar apps to subscribe to. The token in the address is the secret; anyone holding it can read the feed until it is replaced or turned off. Tentative blocks are tentative events and available blocks are free (transparent) events.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "availability"
+                ],
+                "summary": "Serves a calendar feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Feed secret",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/cinematographers/search": {
+            "get": {
+                "description": "Finds active users' public profiles near a ZIP code, nearest first, measured from each user's own location. A user matches when they live within miles of it, when it is within their travel radius, or when one of their service places covers it; matched_by says which. role keeps those who take that crew role; max_rate keeps those whose day rate starts at or below it, in currency (USD unless given), and leaves out profiles without a rate. available_from and available_to (both or neither) keep those with nothing booked on any day from one through the other; tentative holds do not count. sort=rating lists the highest rated first by their average revealed review score, with unrated profiles last. distance is given in unit. Follow next_cursor (also sent as a Link header) for the next page, keeping the same filters.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "Searches for cinematographers nearby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search around this ZIP code",
+                        "name": "zip",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "default": 25,
+                        "description": "Radius around zip, at most 500",
+                        "name": "miles",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "director_of_photography",
+                            "camera_operator",
+                            "steadicam_operator",
+                            "drone_pilot",
+                            "first_ac",
+                            "second_ac",
+                            "dit",
+                            "gaffer",
+                            "best_boy_electric",
+                            "electrician",
+                            "key_grip",
+                            "grip"
+                        ],
+                        "type": "string",
+                        "description": "Crew role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Highest day rate, whole units of currency",
+                        "name": "max_rate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency code",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day they must be free, YYYY-MM-DD (UTC)",
+                        "name": "available_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day they must be free, YYYY-MM-DD (UTC)",
+                        "name": "available_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "mi",
+                            "km"
+                        ],
+                        "type": "string",
+                        "default": "mi",
+                        "description": "Unit for distance",
+                        "name": "unit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "distance",
+                            "rating"
+                        ],
+                        "type": "string",
+                        "default": "distance",
+                        "description": "Order of the results",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Cinematographer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/gigs": {
+            "get": {
+                "description": "Lists gigs a page at a time, most recently posted first. Only open gigs are listed unless status says otherwise. from and to match gigs shooting on any day in that range. Pass zip (and optionally miles) to only list gigs near that ZIP code. Follow next_cursor (also sent as a Link header) for the next page, keeping the same filters.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gigs"
+                ],
+                "summary": "Lists gigs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "director_of_photography",
+                            "camera_operator",
+                            "steadicam_operator",
+                            "drone_pilot",
+                            "first_ac",
+                            "second_ac",
+                            "dit",
+                            "gaffer",
+                            "best_boy_electric",
+                            "electrician",
+                            "key_grip",
+                            "grip"
+                        ],
+                        "type": "string",
+                        "description": "Crew role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "filled",
+                            "cancelled",
+                            "any"
+                        ],
+                        "type": "string",
+                        "default": "open",
+                        "description": "Gig status, or any",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "union",
+                            "non_union",
+                            "either"
+                        ],
+                        "type": "string",
+                        "description": "Union status",
+                        "name": "union_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Shooting on or after, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Shooting on or before, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Day rate range reaches at least this",
+                        "name": "min_rate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency code",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only gigs asking for every one of these",
+                        "name": "gear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only gigs near this ZIP code",
+                        "name": "zip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "default": 25,
+                        "description": "Radius around zip, at most 500",
+                        "name": "miles",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Gig"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Posts a crew call: a post with the role, shoot dates, call time, day rate range, gear and location. New gigs are open. Rates are whole units of currency.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gigs"
+                ],
+                "summary": "Creates a gig",
+                "parameters": [
+                    {
+                        "description": "Gig payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateGigPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Gig"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/gigs/{id}": {
+            "get": {
+                "description": "Fetches a gig by its post ID. Send the ETag back in If-None-Match to get 304 Not Modified.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gigs"
+                ],
+                "summary": "Fetches a gig",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gig (post) ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Gig"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates the poster's own gig. A new location replaces the old one entirely. Send the ETag from GET in If-Match (or the version field); a stale ETag gets 412 and a stale version 409, both with the current gig. The status is changed with PUT /gigs/{id}/status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gigs"
+                ],
+                "summary": "Updates a gig",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gig (post) ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the gig being edited",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Gig payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateGigPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Gig"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/gigs/{id}/applications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists applications to the poster's own gig, ranked hired, shortlisted, submitted, closed, rejected, then withdrawn, and oldest first within each. Follow next_cursor (also sent as a Link header) for the next page, keeping the same status filter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Lists a gig's applicants",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gig (post) ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "submitted",
+                            "shortlisted",
+                            "rejected",
+                            "hired",
+                            "withdrawn",
+                            "closed"
+                        ],
+                        "type": "string",
+                        "description": "Only applications in this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Application"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Applies to an open gig with a message and portfolio links. Each user can apply to a gig once, and not to their own. The poster is notified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Applies to a gig",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gig (post) ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Application payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateApplicationPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Application"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/gigs/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Marks the poster's own gig filled or cancelled, or reopens a filled gig. Cancelled gigs cannot change again; a change that is not allowed gets 409 invalid_transition. Filling or cancelling a gig closes the applications still awaiting a decision and notifies those applicants.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "gigs"
+                ],
+                "summary": "Changes a gig's status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Gig (post) ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateGigStatusPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Gig"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/locations/zip/{ZIPCode}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lookup location by ZIP code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "locations"
+                ],
+                "summary": "Lookup location by ZIP code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ZIP code",
+                        "name": "ZIPCode",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Location"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the signed in user's notifications, newest first. link is the API path of the resource each one is about. Follow next_cursor (also sent as a Link header) for the next page, keeping the same unread filter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Lists your notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only notifications not yet marked read",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Notification"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Marks one of the signed in user's notifications read. Marking it again keeps the first read time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Marks a notification read",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Notification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts": {
+            "get": {
+                "description": "Lists posts a page at a time, without their comments. Follow next_cursor (also sent as a Link header) for the next page, keeping the same filters and sort.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Lists posts",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only posts carrying every one of these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Author user ID",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC 3339 or YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, RFC 3339, or through the end of YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "-created_at",
+                            "created_at"
+                        ],
+                        "type": "string",
+                        "description": "Newest first by default",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Post"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new post from payload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Creates a post",
+                "parameters": [
+                    {
+                        "description": "Post payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreatePostPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Post"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/comments/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves a comment by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Retrieves a comment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a comment by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Deletes a comment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates a comment by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Updates a comment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateCommentPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/search": {
+            "get": {
+                "description": "Full-text search over post titles, tags and content, best match first. q takes web search syntax: words, \"quoted phrases\", or, and -excluded words. Each result has an HTML highlight with matches in \u003cmark\u003e, and tags counts every matching post's tags. Pass zip (and optionally miles) to only match authors near that ZIP code. Follow next_cursor (also sent as a Link header) for the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Searches posts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search terms",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only posts carrying every one of these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only authors near this ZIP code",
+                        "name": "zip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "default": 25,
+                        "description": "Radius around zip, at most 500",
+                        "name": "miles",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.postSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches a post by ID with the first page of its comments, newest first; comments_next_cursor continues through GET /posts/{id}/comments. The ETag header changes whenever the post or one of its comments does; send it back in If-None-Match to get 304 Not Modified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Fetches a post",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Post"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a post by ID. Requires If-Match with the post's current ETag.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Deletes a post",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the post being deleted",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates an existing post from payload. Send the ETag from GET in If-Match (or the version field); a stale ETag gets 412 and a stale version 409, both with the current post.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Updates a post",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the post being edited",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Post payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdatePostPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Post"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/comments": {
+            "get": {
+                "description": "Lists the comments on a post newest first, a page at a time. Follow next_cursor (also sent as a Link header) for the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Lists a post's comments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Comment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new comment from payload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Creates a comment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateCommentPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches the signed in user's cinematographer profile.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "Fetches your profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Profile"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates the signed in user's profile, or replaces it entirely if they have one. Replacing needs the ETag from GET in If-Match (or the version field); a stale ETag gets 412 and a stale version 409, both with the current profile. Rates are whole units of currency, 0 when not given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "Creates or replaces your profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the profile being replaced",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Profile payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ProfilePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Profile"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Profile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/me/service-area": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches how far the signed in user travels from home and the further places they work in.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "Fetches your service area",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ServiceArea"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets how many miles the signed in user travels from their home location and replaces their further places, each a ZIP code with the miles around it; a city or metro area is given by a ZIP code in it. Nearby cinematographer searches match a shoot location against all of these.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "Replaces your service area",
+                "parameters": [
+                    {
+                        "description": "Service area payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ServiceAreaPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ServiceArea"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{userID}": {
+            "get": {
+                "description": "Fetches a cinematographer's public profile. It carries their name and city but never their email or street address. Send the ETag back in If-None-Match to get 304 when it has not changed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profiles"
+                ],
+                "summary": "Fetches a public profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from an earlier response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Profile"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{userID}/reviews": {
+            "get": {
+                "description": "Lists the visible reviews a user received, newest first. role keeps those they received as the booking's producer or as its cinematographer. Follow next_cursor (also sent as a Link header) for the next page, keeping the same filters.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Lists the reviews a user received",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "producer",
+                            "cinematographer"
+                        ],
+                        "type": "string",
+                        "description": "Only reviews received in this role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Review"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/{id}/response": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds the reviewed party's public reply to a visible review. Each review takes one response; a second gets 409. The reviewer is notified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Responds to a review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Response",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ReviewResponsePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Fetches the current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches a user by ID string/uuid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Fetches a user by ID string/uuid",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a user by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Deletes a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the user being deleted",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates a user by ID. Send the ETag from GET in If-Match (or the version field); a stale ETag gets 412 and a stale version 409, both with the current user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Updates a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the user being edited",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateUserPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.AvailabilityImport": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AvailabilityBlock"
+                    }
+                },
+                "skipped": {
+                    "description": "free, cancelled, empty or already past events",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.BookingActionPayload": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "accept",
+                        "decline",
+                        "counter",
+                        "confirm",
+                        "cancel"
+                    ]
+                },
+                "ends_at": {
+                    "description": "counter only",
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "rate": {
+                    "description": "counter only",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "starts_at": {
+                    "description": "counter only",
+                    "type": "string"
+                }
+            }
+        },
+        "api.CalendarFeed": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string",
+                    "example": "https://api.shotseek.com/v1/calendars/3q2-7wEAAAA.ics"
+                }
+            }
+        },
+        "api.Cinematographer": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "day_rate_max": {
+                    "type": "integer"
+                },
+                "day_rate_min": {
+                    "description": "whole units of Currency, 0 when not given",
+                    "type": "integer"
+                },
+                "distance": {
+                    "type": "number",
+                    "example": 12.4
+                },
+                "distance_unit": {
+                    "type": "string",
+                    "example": "mi"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "headline": {
+                    "type": "string",
+                    "example": "Documentary DP with a drone license"
+                },
+                "languages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "English",
+                        "Spanish"
+                    ]
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ProfileLink"
+                    }
+                },
+                "matched_by": {
+                    "type": "string",
+                    "enum": [
+                        "nearby",
+                        "travel_radius",
+                        "service_area"
+                    ]
+                },
+                "rating": {
+                    "description": "from producers' revealed reviews",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.RatingSummary"
+                        }
+                    ]
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.CrewRole"
+                    }
+                },
+                "service_places": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ServicePlace"
+                    }
+                },
+                "specialties": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Specialty"
+                    }
+                },
+                "state": {
+                    "type": "string"
+                },
+                "travel_miles": {
+                    "description": "from the user's service area",
+                    "type": "integer"
+                },
+                "unions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "IATSE Local 600"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "years_experience": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.CreateApplicationPayload": {
+            "type": "object",
+            "required": [
+                "message",
+                "portfolio_links"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "portfolio_links": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.CreateAvailabilityPayload": {
+            "type": "object",
+            "required": [
+                "ends_at",
+                "starts_at",
+                "status"
+            ],
+            "properties": {
+                "ends_at": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "available",
+                        "tentative",
+                        "booked"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.AvailabilityStatus"
+                        }
+                    ]
+                },
+                "time_zone": {
+                    "description": "UTC when left out",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "America/Chicago"
+                }
+            }
+        },
+        "api.CreateBookingPayload": {
+            "type": "object",
+            "required": [
+                "cinematographer_id",
+                "ends_at",
+                "kind",
+                "starts_at",
+                "zip_code"
+            ],
+            "properties": {
+                "cinematographer_id": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "USD when left out",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "holds only; a week out when left out, at most 30 days",
+                    "type": "string"
+                },
+                "kind": {
+                    "enum": [
+                        "request",
+                        "hold"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.BookingKind"
+                        }
+                    ]
+                },
+                "message": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "rate": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "time_zone": {
+                    "description": "UTC when left out",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "America/Chicago"
+                },
+                "zip_code": {
+                    "type": "string",
+                    "maxLength": 10
+                }
+            }
+        },
+        "api.CreateCommentPayload": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "api.CreateGigPayload": {
+            "type": "object",
+            "required": [
+                "call_time",
+                "content",
+                "currency",
+                "day_rate_max",
+                "duration_hours",
+                "gear",
+                "location",
+                "role",
+                "shoot_end",
+                "shoot_start",
+                "title",
+                "union_status"
+            ],
+            "properties": {
+                "call_time": {
+                    "type": "string",
+                    "example": "07:00"
+                },
+                "content": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "day_rate_max": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "day_rate_min": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "duration_hours": {
+                    "type": "integer",
+                    "maximum": 24,
+                    "minimum": 1
+                },
+                "gear": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "location": {
+                    "$ref": "#/definitions/api.GigLocationPayload"
+                },
+                "role": {
+                    "enum": [
+                        "director_of_photography",
+                        "camera_operator",
+                        "steadicam_operator",
+                        "drone_pilot",
+                        "first_ac",
+                        "second_ac",
+                        "dit",
+                        "gaffer",
+                        "best_boy_electric",
+                        "electrician",
+                        "key_grip",
+                        "grip"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.CrewRole"
+                        }
+                    ]
+                },
+                "shoot_end": {
+                    "type": "string",
+                    "format": "date",
+                    "example": "2025-06-04"
+                },
+                "shoot_start": {
+                    "type": "string",
+                    "format": "date",
+                    "example": "2025-06-02"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "union_status": {
+                    "enum": [
+                        "union",
+                        "non_union",
+                        "either"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.UnionStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.CreatePostPayload": {
+            "type": "object",
+            "required": [
+                "content",
+                "title"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "api.CreateReviewPayload": {
+            "type": "object",
+            "required": [
+                "rating"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 5000
+                },
+                "communication": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
+                },
+                "professionalism": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
+                },
+                "rating": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
+                },
+                "technical_skill": {
+                    "description": "cinematographers only",
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
+                }
+            }
+        },
+        "api.GigLocationPayload": {
+            "type": "object",
+            "required": [
+                "city",
+                "country",
+                "state",
+                "zip_code"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "country": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                },
+                "state": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "street": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "zip_code": {
+                    "type": "string",
+                    "maxLength": 12
+                }
+            }
+        },
+        "api.ProfileLinkPayload": {
+            "type": "object",
+            "required": [
+                "kind",
+                "url"
+            ],
+            "properties": {
+                "kind": {
+                    "enum": [
+                        "reel",
+                        "website",
+                        "instagram",
+                        "vimeo",
+                        "youtube",
+                        "imdb",
+                        "linkedin",
+                        "other"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.LinkKind"
+                        }
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "https://vimeo.com/reel"
+                }
+            }
+        },
+        "api.ProfilePayload": {
+            "type": "object",
+            "required": [
+                "languages",
+                "unions"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string",
+                    "maxLength": 5000
+                },
+                "currency": {
+                    "description": "USD when left out",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "day_rate_max": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "day_rate_min": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "headline": {
+                    "type": "string",
+                    "maxLength": 120
+                },
+                "languages": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "English",
+                        "Spanish"
+                    ]
+                },
+                "links": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/api.ProfileLinkPayload"
+                    }
+                },
+                "roles": {
+                    "type": "array",
+                    "maxItems": 12,
+                    "items": {
+                        "$ref": "#/definitions/store.CrewRole"
+                    }
+                },
+                "specialties": {
+                    "type": "array",
+                    "maxItems": 6,
+                    "items": {
+                        "$ref": "#/definitions/store.Specialty"
+                    }
+                },
+                "unions": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "IATSE Local 600"
+                    ]
+                },
+                "version": {
+                    "description": "used when If-Match is absent",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "years_experience": {
+                    "type": "integer",
+                    "maximum": 80,
+                    "minimum": 0
+                }
+            }
+        },
+        "api.ReviewResponsePayload": {
+            "type": "object",
+            "required": [
+                "response"
+            ],
+            "properties": {
+                "response": {
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "api.ServiceAreaPayload": {
+            "type": "object",
+            "properties": {
+                "places": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/api.ServicePlacePayload"
+                    }
+                },
+                "travel_miles": {
+                    "type": "integer",
+                    "maximum": 500,
+                    "minimum": 0
+                }
+            }
+        },
+        "api.ServicePlacePayload": {
+            "type": "object",
+            "required": [
+                "zip_code"
+            ],
+            "properties": {
+                "miles": {
+                    "description": "around the ZIP code, 0 for the ZIP code alone",
+                    "type": "integer",
+                    "maximum": 500,
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Kansas City metro"
+                },
+                "zip_code": {
+                    "type": "string",
+                    "maxLength": 12,
+                    "example": "64105"
+                }
+            }
+        },
+        "api.UpdateApplicationStatusPayload": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "enum": [
+                        "shortlisted",
+                        "rejected",
+                        "hired",
+                        "withdrawn"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.ApplicationStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.UpdateAvailabilityPayload": {
+            "type": "object",
+            "properties": {
+                "ends_at": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "available",
+                        "tentative",
+                        "booked"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.AvailabilityStatus"
+                        }
+                    ]
+                },
+                "time_zone": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "api.UpdateGigPayload": {
+            "type": "object",
+            "required": [
+                "gear"
+            ],
+            "properties": {
+                "call_time": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "day_rate_max": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "day_rate_min": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "duration_hours": {
+                    "type": "integer",
+                    "maximum": 24,
+                    "minimum": 1
+                },
+                "gear": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "location": {
+                    "$ref": "#/definitions/api.GigLocationPayload"
+                },
+                "role": {
+                    "enum": [
+                        "director_of_photography",
+                        "camera_operator",
+                        "steadicam_operator",
+                        "drone_pilot",
+                        "first_ac",
+                        "second_ac",
+                        "dit",
+                        "gaffer",
+                        "best_boy_electric",
+                        "electrician",
+                        "key_grip",
+                        "grip"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.CrewRole"
+                        }
+                    ]
+                },
+                "shoot_end": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "shoot_start": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "union_status": {
+                    "enum": [
+                        "union",
+                        "non_union",
+                        "either"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.UnionStatus"
+                        }
+                    ]
+                },
+                "version": {
+                    "description": "used when If-Match is absent",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "api.UpdateGigStatusPayload": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "enum": [
+                        "open",
+                        "filled",
+                        "cancelled"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.GigStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.UpdatePostPayload": {
             "type": "object",
             "properties": {
                 "content": {
@@ -954,144 +4734,776 @@ const docTemplate = `{
                 },
                 "tags": {
                     "type": "array",
-                    "maxItems": 100,
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "version": {
+                    "description": "used when If-Match is absent",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "api.UpdateUserPayload": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "description": "Password  *string ` + "`" + `json:\"password\" validate:\"omitempty,min=6\"` + "`" + `",
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "used when If-Match is absent",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "zip_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.postSearchResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.PostSearchResult"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.TagFacet"
+                    }
+                }
+            }
+        },
+        "auth.LoginPayload": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                }
+            }
+        },
+        "auth.RegisterUserPayload": {
+            "type": "object",
+            "required": [
+                "city",
+                "country",
+                "email",
+                "first_name",
+                "last_name",
+                "password",
+                "state",
+                "zip_code"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "country": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 255
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 255
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                },
+                "state": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "street": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "zip_code": {
+                    "type": "string",
+                    "maxLength": 12
+                }
+            }
+        },
+        "github_com_michaelhoman_ShotSeek_internal_store.Comment": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "post_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_michaelhoman_ShotSeek_internal_store.Post": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Comment"
+                    }
+                },
+                "comments_next_cursor": {
+                    "description": "CommentsNextCursor pages on through GET /posts/{id}/comments when Comments holds only the first page",
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
                 "title": {
-                    "type": "string",
-                    "maxLength": 100
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "api.UpdateUserPayload": {
+        "github_com_michaelhoman_ShotSeek_internal_store.User": {
             "type": "object",
             "properties": {
-                "city": {
+                "created_at": {
                     "type": "string"
                 },
                 "email": {
                     "type": "string"
                 },
                 "first_name": {
-                    "description": "Password  *string ` + "`" + `json:\"password\" validate:\"omitempty,min=6\"` + "`" + `",
                     "type": "string"
                 },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
                 "last_name": {
                     "type": "string"
                 },
-                "state": {
+                "location": {
+                    "$ref": "#/definitions/store.Location"
+                },
+                "location_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 },
-                "zip_code": {
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.Application": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "portfolio_links": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "post_id": {
+                    "type": "integer"
+                },
+                "post_title": {
+                    "type": "string"
+                },
+                "poster_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/store.ApplicationStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "auth.LoginPayload": {
+        "store.ApplicationStatus": {
+            "type": "string",
+            "enum": [
+                "submitted",
+                "shortlisted",
+                "rejected",
+                "hired",
+                "withdrawn",
+                "closed"
+            ],
+            "x-enum-varnames": [
+                "ApplicationSubmitted",
+                "ApplicationShortlisted",
+                "ApplicationRejected",
+                "ApplicationHired",
+                "ApplicationWithdrawn",
+                "ApplicationClosed"
+            ]
+        },
+        "store.AvailabilityBlock": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/store.AvailabilitySource"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/store.AvailabilityStatus"
+                },
+                "time_zone": {
+                    "type": "string",
+                    "example": "America/Chicago"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.AvailabilitySource": {
+            "type": "string",
+            "enum": [
+                "manual",
+                "import",
+                "booking"
+            ],
+            "x-enum-comments": {
+                "AvailabilityBooking": "kept in step with a held or accepted booking",
+                "AvailabilityImported": "read from an uploaded calendar, replaced by the next upload"
+            },
+            "x-enum-varnames": [
+                "AvailabilityManual",
+                "AvailabilityImported",
+                "AvailabilityBooking"
+            ]
+        },
+        "store.AvailabilityStatus": {
+            "type": "string",
+            "enum": [
+                "available",
+                "tentative",
+                "booked"
             ],
+            "x-enum-comments": {
+                "AvailabilityTentative": "pencilled in, may still fall through"
+            },
+            "x-enum-varnames": [
+                "AvailabilityAvailable",
+                "AvailabilityTentative",
+                "AvailabilityBooked"
+            ]
+        },
+        "store.Booking": {
+            "type": "object",
             "properties": {
-                "email": {
+                "availability_block_id": {
+                    "description": "on the cinematographer's calendar once held or accepted",
+                    "type": "integer"
+                },
+                "cinematographer": {
+                    "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                },
+                "cinematographer_id": {
                     "type": "string"
                 },
-                "password": {
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
                     "type": "string",
-                    "maxLength": 72,
-                    "minLength": 8
+                    "example": "USD"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "holds only",
+                    "type": "string"
+                },
+                "hold_rank": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/store.BookingKind"
+                },
+                "location": {
+                    "$ref": "#/definitions/store.Location"
+                },
+                "location_id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "producer": {
+                    "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                },
+                "producer_id": {
+                    "type": "string"
+                },
+                "rate": {
+                    "description": "whole units of Currency for the whole booking",
+                    "type": "integer"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/store.BookingStatus"
+                },
+                "time_zone": {
+                    "type": "string",
+                    "example": "America/Chicago"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.BookingEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "countered"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "$ref": "#/definitions/store.BookingStatus"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "integer"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "to_status": {
+                    "$ref": "#/definitions/store.BookingStatus"
+                }
+            }
+        },
+        "store.BookingKind": {
+            "type": "string",
+            "enum": [
+                "request",
+                "hold"
+            ],
+            "x-enum-varnames": [
+                "BookingRequest",
+                "BookingHold"
+            ]
+        },
+        "store.BookingRole": {
+            "type": "string",
+            "enum": [
+                "producer",
+                "cinematographer"
+            ],
+            "x-enum-varnames": [
+                "BookingAsProducer",
+                "BookingAsCinematographer"
+            ]
+        },
+        "store.BookingStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "countered",
+                "held",
+                "accepted",
+                "declined",
+                "cancelled",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "BookingPending",
+                "BookingCountered",
+                "BookingHeld",
+                "BookingAccepted",
+                "BookingDeclined",
+                "BookingCancelled",
+                "BookingExpired"
+            ]
+        },
+        "store.CrewRole": {
+            "type": "string",
+            "enum": [
+                "director_of_photography",
+                "camera_operator",
+                "steadicam_operator",
+                "drone_pilot",
+                "first_ac",
+                "second_ac",
+                "dit",
+                "gaffer",
+                "best_boy_electric",
+                "electrician",
+                "key_grip",
+                "grip"
+            ],
+            "x-enum-varnames": [
+                "RoleDirectorOfPhotography",
+                "RoleCameraOperator",
+                "RoleSteadicamOperator",
+                "RoleDronePilot",
+                "RoleFirstAC",
+                "RoleSecondAC",
+                "RoleDIT",
+                "RoleGaffer",
+                "RoleBestBoyElectric",
+                "RoleElectrician",
+                "RoleKeyGrip",
+                "RoleGrip"
+            ]
+        },
+        "store.Gig": {
+            "type": "object",
+            "properties": {
+                "call_time": {
+                    "description": "local to the location",
+                    "type": "string",
+                    "example": "07:00"
+                },
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Comment"
+                    }
+                },
+                "comments_next_cursor": {
+                    "description": "CommentsNextCursor pages on through GET /posts/{id}/comments when Comments holds only the first page",
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "day_rate_max": {
+                    "type": "integer"
+                },
+                "day_rate_min": {
+                    "description": "whole units of Currency",
+                    "type": "integer"
+                },
+                "duration_hours": {
+                    "description": "per shoot day",
+                    "type": "integer"
+                },
+                "gear": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "$ref": "#/definitions/store.Location"
+                },
+                "location_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/store.CrewRole"
+                },
+                "shoot_end": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "shoot_start": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "status": {
+                    "$ref": "#/definitions/store.GigStatus"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "union_status": {
+                    "$ref": "#/definitions/store.UnionStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "auth.RegisterUserPayload": {
-            "type": "object",
-            "required": [
-                "city",
-                "country",
-                "email",
-                "first_name",
-                "last_name",
-                "password",
-                "state",
-                "zip_code"
+        "store.GigStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "filled",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "GigOpen",
+                "GigFilled",
+                "GigCancelled"
+            ]
+        },
+        "store.LinkKind": {
+            "type": "string",
+            "enum": [
+                "reel",
+                "website",
+                "instagram",
+                "vimeo",
+                "youtube",
+                "imdb",
+                "linkedin",
+                "other"
             ],
+            "x-enum-varnames": [
+                "LinkReel",
+                "LinkWebsite",
+                "LinkInstagram",
+                "LinkVimeo",
+                "LinkYouTube",
+                "LinkIMDb",
+                "LinkLinkedIn",
+                "LinkOther"
+            ]
+        },
+        "store.Location": {
+            "type": "object",
             "properties": {
                 "city": {
-                    "type": "string",
-                    "maxLength": 255
+                    "type": "string"
                 },
                 "country": {
-                    "type": "string",
-                    "maxLength": 255
+                    "type": "string"
                 },
-                "email": {
+                "country_code": {
                     "type": "string"
                 },
-                "first_name": {
-                    "type": "string",
-                    "maxLength": 255
+                "county": {
+                    "type": "string"
                 },
-                "last_name": {
-                    "type": "string",
-                    "maxLength": 255
+                "id": {
+                    "type": "integer"
                 },
                 "latitude": {
-                    "type": "number",
-                    "maximum": 255
+                    "type": "number"
                 },
                 "longitude": {
-                    "type": "number",
-                    "maximum": 255
-                },
-                "password": {
-                    "type": "string",
-                    "maxLength": 72,
-                    "minLength": 8
+                    "type": "number"
                 },
                 "state": {
-                    "type": "string",
-                    "maxLength": 255
+                    "type": "string"
                 },
                 "street": {
-                    "type": "string",
-                    "maxLength": 255
+                    "type": "string"
                 },
                 "zip_code": {
-                    "type": "string",
-                    "maxLength": 12
+                    "type": "string"
                 }
             }
         },
-        "github_com_michaelhoman_ShotSeek_internal_store.Comment": {
+        "store.Notification": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string"
-                },
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "post_id": {
-                    "type": "integer"
+                "kind": {
+                    "$ref": "#/definitions/store.NotificationKind"
                 },
-                "updated_at": {
+                "link": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                "message": {
+                    "type": "string"
+                },
+                "read_at": {
+                    "type": "string"
                 },
                 "user_id": {
-                    "type": "integer"
+                    "type": "string"
                 }
             }
         },
-        "github_com_michaelhoman_ShotSeek_internal_store.Post": {
+        "store.NotificationKind": {
+            "type": "string",
+            "enum": [
+                "application_received",
+                "application_status",
+                "application_withdrawn",
+                "booking_requested",
+                "booking_updated",
+                "booking_expired",
+                "review_received",
+                "reviews_revealed",
+                "review_response"
+            ],
+            "x-enum-varnames": [
+                "NotificationApplicationReceived",
+                "NotificationApplicationStatus",
+                "NotificationApplicationWithdrawn",
+                "NotificationBookingRequested",
+                "NotificationBookingUpdated",
+                "NotificationBookingExpired",
+                "NotificationReviewReceived",
+                "NotificationReviewsRevealed",
+                "NotificationReviewResponse"
+            ]
+        },
+        "store.PostSearchResult": {
             "type": "object",
             "properties": {
                 "comments": {
@@ -1100,15 +5512,25 @@ const docTemplate = `{
                         "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.Comment"
                     }
                 },
+                "comments_next_cursor": {
+                    "description": "CommentsNextCursor pages on through GET /posts/{id}/comments when Comments holds only the first page",
+                    "type": "string"
+                },
                 "content": {
                     "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
+                "highlight": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "rank": {
+                    "type": "number"
+                },
                 "tags": {
                     "type": "array",
                     "items": {
@@ -1122,79 +5544,372 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "user_id": {
-                    "type": "integer"
+                    "type": "string"
                 },
                 "version": {
                     "type": "integer"
                 }
             }
         },
-        "github_com_michaelhoman_ShotSeek_internal_store.User": {
+        "store.Profile": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "bio": {
                     "type": "string"
                 },
-                "email": {
+                "city": {
                     "type": "string"
                 },
-                "first_name": {
+                "country": {
                     "type": "string"
                 },
-                "id": {
+                "created_at": {
                     "type": "string"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "day_rate_max": {
+                    "type": "integer"
+                },
+                "day_rate_min": {
+                    "description": "whole units of Currency, 0 when not given",
+                    "type": "integer"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "headline": {
+                    "type": "string",
+                    "example": "Documentary DP with a drone license"
+                },
+                "languages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "English",
+                        "Spanish"
+                    ]
                 },
                 "last_name": {
                     "type": "string"
                 },
-                "location": {
-                    "$ref": "#/definitions/store.Location"
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ProfileLink"
+                    }
                 },
-                "location_id": {
+                "rating": {
+                    "description": "from producers' revealed reviews",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.RatingSummary"
+                        }
+                    ]
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.CrewRole"
+                    }
+                },
+                "service_places": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ServicePlace"
+                    }
+                },
+                "specialties": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Specialty"
+                    }
+                },
+                "state": {
+                    "type": "string"
+                },
+                "travel_miles": {
+                    "description": "from the user's service area",
                     "type": "integer"
                 },
+                "unions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "IATSE Local 600"
+                    ]
+                },
                 "updated_at": {
                     "type": "string"
                 },
+                "user_id": {
+                    "type": "string"
+                },
                 "version": {
                     "type": "integer"
+                },
+                "years_experience": {
+                    "type": "integer"
                 }
             }
         },
-        "store.Location": {
+        "store.ProfileLink": {
             "type": "object",
             "properties": {
-                "city": {
+                "kind": {
+                    "$ref": "#/definitions/store.LinkKind"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://vimeo.com/reel"
+                }
+            }
+        },
+        "store.RatingSummary": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "type": "number",
+                    "example": 4.75
+                },
+                "communication": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "professionalism": {
+                    "type": "number"
+                },
+                "technical_skill": {
+                    "type": "number"
+                }
+            }
+        },
+        "store.Review": {
+            "type": "object",
+            "properties": {
+                "body": {
                     "type": "string"
                 },
-                "country": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "communication": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "country_code": {
+                "id": {
+                    "type": "integer"
+                },
+                "professionalism": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "responded_at": {
                     "type": "string"
                 },
-                "county": {
+                "response": {
+                    "description": "the reviewee's public reply",
                     "type": "string"
                 },
-                "id": {
+                "reviewee_id": {
+                    "type": "string"
+                },
+                "reviewee_role": {
+                    "description": "what the reviewee was on the booking",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.BookingRole"
+                        }
+                    ]
+                },
+                "reviewer": {
+                    "$ref": "#/definitions/github_com_michaelhoman_ShotSeek_internal_store.User"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                },
+                "technical_skill": {
                     "type": "integer"
                 },
-                "latitude": {
-                    "type": "number"
+                "updated_at": {
+                    "type": "string"
                 },
-                "longitude": {
-                    "type": "number"
+                "visible_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.ServiceArea": {
+            "type": "object",
+            "properties": {
+                "places": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ServicePlace"
+                    }
                 },
-                "state": {
+                "travel_miles": {
+                    "description": "0 when they do not travel",
+                    "type": "integer"
+                },
+                "user_id": {
                     "type": "string"
+                }
+            }
+        },
+        "store.ServicePlace": {
+            "type": "object",
+            "properties": {
+                "location": {
+                    "$ref": "#/definitions/store.Location"
                 },
-                "street": {
+                "miles": {
+                    "description": "around the location, 0 for the ZIP code alone",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Kansas City metro"
+                }
+            }
+        },
+        "store.Specialty": {
+            "type": "string",
+            "enum": [
+                "documentary",
+                "commercial",
+                "narrative",
+                "music_video",
+                "corporate",
+                "event"
+            ],
+            "x-enum-varnames": [
+                "SpecialtyDocumentary",
+                "SpecialtyCommercial",
+                "SpecialtyNarrative",
+                "SpecialtyMusicVideo",
+                "SpecialtyCorporate",
+                "SpecialtyEvent"
+            ]
+        },
+        "store.TagFacet": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "tag": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.UnionStatus": {
+            "type": "string",
+            "enum": [
+                "union",
+                "non_union",
+                "either"
+            ],
+            "x-enum-varnames": [
+                "UnionOnly",
+                "UnionNonUnion",
+                "UnionEither"
+            ]
+        },
+        "utils.ErrorCode": {
+            "type": "string",
+            "enum": [
+                "bad_request",
+                "invalid_json",
+                "validation_failed",
+                "unauthorized",
+                "forbidden",
+                "not_found",
+                "method_not_allowed",
+                "conflict",
+                "duplicate_email",
+                "edit_conflict",
+                "precondition_failed",
+                "precondition_required",
+                "invalid_transition",
+                "rate_limited",
+                "upstream_failure",
+                "internal_error"
+            ],
+            "x-enum-varnames": [
+                "CodeBadRequest",
+                "CodeInvalidJSON",
+                "CodeValidationFailed",
+                "CodeUnauthorized",
+                "CodeForbidden",
+                "CodeNotFound",
+                "CodeMethodNotAllowed",
+                "CodeConflict",
+                "CodeDuplicateEmail",
+                "CodeEditConflict",
+                "CodePreconditionFailed",
+                "CodePreconditionRequired",
+                "CodeInvalidTransition",
+                "CodeRateLimited",
+                "CodeUpstreamFailure",
+                "CodeInternal"
+            ]
+        },
+        "utils.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
                     "type": "string"
                 },
-                "zip_code": {
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "utils.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/utils.ErrorCode"
+                },
+                "current": {},
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
                     "type": "string"
                 }
             }
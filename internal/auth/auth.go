@@ -5,7 +5,6 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -13,6 +12,8 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/michaelhoman/ShotSeek/internal/config"
+	"github.com/michaelhoman/ShotSeek/internal/mailer"
+	"github.com/michaelhoman/ShotSeek/internal/metrics"
 	"github.com/michaelhoman/ShotSeek/internal/store"
 	"github.com/michaelhoman/ShotSeek/internal/utils"
 )
@@ -46,14 +47,16 @@ type AuthHandler struct {
 	Config     config.Config
 	jwtService *JWTService
 	JWTAuth    *JWTAuth
+	mailer     mailer.Client
 }
 
-func NewAuthHandler(store store.Storage, config config.Config, jwtService *JWTService, jwtAuth *JWTAuth) *AuthHandler {
+func NewAuthHandler(store store.Storage, config config.Config, jwtService *JWTService, jwtAuth *JWTAuth, mailer mailer.Client) *AuthHandler {
 	return &AuthHandler{
 		store:      store,
 		Config:     config,
 		jwtService: jwtService,
 		JWTAuth:    jwtAuth,
+		mailer:     mailer,
 	}
 }
 
@@ -71,8 +74,8 @@ func NewAuthHandler(store store.Storage, config config.Config, jwtService *JWTSe
 //	@Produce		json
 //	@Param			payload	body		RegisterUserPayload	true	"User credentials"
 //	@Success		201		{object}	store.User			"User Registered"
-//	@Failure		400		{object}	error
-//	@Failure		500		{object}	error
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
 //	@Router			/authentication/register [post]
 func (a *AuthHandler) RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
 	var payload RegisterUserPayload
@@ -108,7 +111,6 @@ func (a *AuthHandler) RegisterUserHandler(w http.ResponseWriter, r *http.Request
 		utils.InternalServerError(w, r, err)
 		return
 	}
-	fmt.Println("user.Password", user.Password)
 	// store the user
 
 	// if err := app.jsonResponse(w, http.StatusCreated, nil); err != nil {
@@ -127,7 +129,7 @@ func (a *AuthHandler) RegisterUserHandler(w http.ResponseWriter, r *http.Request
 	if err != nil {
 		switch err {
 		case store.ErrDuplicateEmail:
-			utils.BadRequestResponse(w, r, err)
+			utils.WriteProblem(w, r, utils.WrapAppError(http.StatusConflict, utils.CodeDuplicateEmail, "A user with that email already exists", err))
 		// case store.ErrDuplicateUser:
 		// 	utils.BadRequestResponse(w, r, err)
 		default:
@@ -136,28 +138,33 @@ func (a *AuthHandler) RegisterUserHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// TODO revert the userWithToken after Dev/TESTING - this is insecure and bypasses the email verification
-	// userWithToken := &userWithToken{
-	// 	User:  user,
-	// 	Token: plainToken,
-	// }
-	// if err := app.jsonResponse(w, http.StatusCreated, userWithToken); err != nil {
-	// 	utils.InternalServerError(w, r, err)
-	// }
-	// Example: Sending success message response
-	// response := map[string]string{
-	// 	"message": "Registration successful! Check your email to verify your account.",
-	// }
-	confirmationMessage := "Registration successful! Check your email to verify your account."
+	// send email to user with plainToken
+	vars := struct {
+		FirstName     string
+		ActivationURL string
+		Expiry        time.Duration
+	}{
+		FirstName:     user.FirstName,
+		ActivationURL: fmt.Sprintf("%s/verify?token=%s", a.Config.FrontendURL, plainToken),
+		Expiry:        a.Config.Mail.Exp,
+	}
 
-	fmt.Println(user.FirstName, user.LastName, " plainToken:", plainToken)
-	// Return a success message instead of the form
-	if err := utils.WriteMessagePlain(w, http.StatusCreated, confirmationMessage); err != nil {
+	isSandbox := !a.Config.IsProduction()
+	if err := a.mailer.Send(mailer.UserInvitationTemplate, user.FirstName, user.LastName, user.Email, vars, isSandbox); err != nil {
+		// Without the email the account can never be activated, so roll it back and let the user retry
+		if delErr := a.store.Users.Delete(ctx, user.ID); delErr != nil {
+			utils.LoggerFromCtx(ctx).Errorw("failed to remove user after invitation email failed", "user_id", user.ID, "error", delErr)
+		}
 		utils.InternalServerError(w, r, err)
+		return
 	}
 
-	// send email to user with plainToken
+	utils.LoggerFromCtx(ctx).Infow("user registered", "user_id", user.ID)
 
+	// Return a success message instead of the form
+	if err := utils.WriteMessagePlain(w, http.StatusCreated, "Registration successful! Check your email to verify your account."); err != nil {
+		utils.InternalServerError(w, r, err)
+	}
 }
 
 // var jwtSigningKey = []byte(os.Getenv("JWT_SIGNING_KEY")) // Replace with a secure key
@@ -174,48 +181,38 @@ func (a *AuthHandler) RegisterUserHandler(w http.ResponseWriter, r *http.Request
 //	@Produce		json
 //	@Param			payload	body		LoginPayload	true	"User credentials"
 //	@Success		200		{string}	string			"updated Login successful, JWT stored in cookie"
-//	@Failure		400		{object}	error
-//	@Failure		401		{object}	error
-//	@Failure		500		{object}	error
+//	@Failure		400		{object}	utils.Problem
+//	@Failure		401		{object}	utils.Problem
+//	@Failure		500		{object}	utils.Problem
 //	@Router			/authentication/login [post]
 func (a *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("LoginHandler LoginHandler inital call") // Debugging
 	tokenStore := a.store.Tokens
 	if tokenStore == nil {
-		log.Println("Error: tokenStore is nil")
 		utils.InternalServerError(w, r, errors.New("internal server error"))
 		return
 	}
 
 	var payload LoginPayload
 
-	fmt.Println("LoginHandler *1") // Debugging
 	if err := utils.ReadJSON(w, r, &payload); err != nil {
 		utils.BadRequestResponse(w, r, err)
 		return
 	}
 
-	fmt.Println("LoginHandler *2") // Debugging
-
 	if err := utils.Validate.Struct(payload); err != nil {
 		utils.BadRequestResponse(w, r, err)
 		return
 	}
 
-	fmt.Println("LoginHandler *3") // Debugging
-
 	// Authenticate the user (e.g., check the password against the db)
 
 	user, err := a.store.Users.GetByEmailWithPassword(r.Context(), payload.Email)
 
-	fmt.Println("LoginHandler *4")                            // Debugging
-	fmt.Println("LoginHandler payload.Email:", payload.Email) // Debugging
-
-	fmt.Println("LoginHandler user:", user) // Debugging
-
 	if err != nil {
 		switch err {
 		case store.ErrNotFound:
+			metrics.AuthEvent(metrics.AuthLoginFailure)
+			utils.LoggerFromCtx(r.Context()).Infow("login failed: unknown email", "email", payload.Email)
 			utils.UnauthorizedErrorResponse(w, r, err)
 		default:
 			utils.InternalServerError(w, r, err)
@@ -223,10 +220,10 @@ func (a *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fmt.Println("LoginHandler *5") // Debugging
 	// Compare the hashed password
-	fmt.Println(payload.Password) // Debugging
 	if err := user.Password.Compare(payload.Password); err != nil {
+		metrics.AuthEvent(metrics.AuthLoginFailure)
+		utils.LoggerFromCtx(r.Context()).Infow("login failed: password mismatch", "user_id", user.ID)
 		utils.UnauthorizedErrorResponse(w, r, err)
 		return
 	}
@@ -235,41 +232,31 @@ func (a *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	userAgent := r.UserAgent()
 	fingerprint := a.GenerateFingerprint(ip, userAgent) // Optional fingerprint
 
-	fmt.Println("LoginHandler *6") // Debugging
-
 	newRefreshToken, err := a.GenerateRefreshToken()
-	fmt.Println("LoginHandler *7 newRefreshToken:", newRefreshToken) // Debugging
 	if err != nil {
 		utils.InternalServerError(w, r, err)
 		return
 	}
 
 	newRefreshTokenHash := a.HashToken(newRefreshToken)
-	fmt.Println("LoginHandler *8 newRefreshTokenHash:", newRefreshTokenHash) // Debugging
 
-	fmt.Println("LoginHandler *9 a.store.Tokens:", a.store.Tokens) // Debugging
 	if a.store.Tokens == nil {
-		log.Println("Error: a.store.Tokens is nil")
 		utils.InternalServerError(w, r, errors.New("internal server error"))
 		return
 	}
 
-	// Store the refresh token in the databasePrintln("******") // Debugging
+	// Store the refresh token in the database
 
-	fmt.Println("LoginHandler Calling UpdateRefreshToken") // Debugging
 	err = tokenStore.UpdateRefreshToken(r.Context(), user.ID, newRefreshTokenHash, fingerprint, time.Now().Add(a.Config.Auth.RefreshToken.Exp))
 
-	fmt.Println("LoginHandler *10") // Debugging
 	if err != nil {
 		utils.InternalServerError(w, r, err)
 		return
 	}
-	fmt.Println("LoginHandler **********!!!!!!!!!!!!!!!!*********")  // Debugging
-	fmt.Println("LoginHandler Generating JWT for user.ID:", user.ID) // Debugging
-	token, err := a.GenerateJWTWithFP(user.ID, fingerprint)          // Pass fingerprint if needed
+	token, err := a.GenerateJWTWithFP(user.ID, fingerprint) // Pass fingerprint if needed
 	if err != nil {
-		fmt.Println("Error generating JWT:", err) // TODO Remove Debugging
-		http.Error(w, "Could not generate token", http.StatusInternalServerError)
+		utils.LoggerFromCtx(r.Context()).Errorw("generating JWT failed", "user_id", user.ID, "error", err)
+		utils.InternalServerError(w, r, err)
 		return
 	}
 
@@ -295,6 +282,9 @@ func (a *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		Expires:  time.Now().Add(a.Config.Auth.RefreshToken.Exp), // Set expiration based on config
 	})
 
+	metrics.AuthEvent(metrics.AuthLogin)
+	utils.LoggerFromCtx(r.Context()).Infow("user logged in", "user_id", user.ID)
+
 	// Respond to the user (no need to send the token in the body)
 	w.Write([]byte("LoginHandler Login successful, JWT stored in cookie"))
 }
@@ -306,7 +296,7 @@ func (a *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 //	@Tags			users
 //	@Produce		json
 //	@Success		200	{string}	string	"Logout successful"
-//	@Failure		500	{object}	error
+//	@Failure		500	{object}	utils.Problem
 //	@Router			/authentication/logout [post]
 func (a *AuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
 	// Clear the auth_token cookie by setting MaxAge to -1 (expires immediately)
@@ -376,7 +366,6 @@ func (a *AuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
 func ExtractJWTToken(r *http.Request) (string, error) {
 	// Check the "auth_token" cookie first
 	cookie, err := r.Cookie("auth_token")
-	fmt.Println("cookie:", cookie) // Debugging
 	if err == nil {
 		return cookie.Value, nil
 	}
@@ -392,7 +381,6 @@ func ExtractJWTToken(r *http.Request) (string, error) {
 		return "", errors.New("invalid Authorization header format")
 	}
 
-	fmt.Println("tokenParts[1]:", tokenParts[1]) // Debugging
 	return tokenParts[1], nil
 }
 
@@ -405,18 +393,16 @@ func ExtractJWTToken(r *http.Request) (string, error) {
 //	@Tags			users
 //	@Produce		json
 //	@Success		200	{string}	string	"JWT refreshed successfully"
-//	@Failure		401	{object}	error
-//	@Failure		500	{object}	error
+//	@Failure		401	{object}	utils.Problem
+//	@Failure		500	{object}	utils.Problem
 //	@Router			/authentication/refresh [post]
 func (a *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("RefreshHandler initial call") // Debugging
 
 	// Step 1: Get the refresh_token from the cookies
 	cookie, err := r.Cookie("refresh_token")
 	// fmt.Println("cookie:", cookie) // Debugging
-	fmt.Println("cookie:", cookie) // Debugging
 	if err != nil {
-		fmt.Println("Error getting refresh token cookie:", err) // Debugging
+		metrics.AuthEvent(metrics.AuthRefreshFailure)
 		utils.UnauthorizedErrorResponse(w, r, errors.New("no refresh token"))
 		return
 	}
@@ -425,24 +411,20 @@ func (a *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
 	refreshToken := cookie.Value
 	refreshTokenHash := a.HashToken(refreshToken)
 
-	fmt.Println("refreshTokenHash:", refreshTokenHash) // Debugging
-
 	// Validate the refresh token hash
-	fmt.Println("ValidateRefreshTokenByHash Called")
 	userID, err := a.ValidateRefreshTokenByHash(r, refreshTokenHash) // Validate the refresh token logic
 
 	if err != nil {
+		metrics.AuthEvent(metrics.AuthRefreshFailure)
 		utils.UnauthorizedErrorResponse(w, r, err)
 		return
 	}
 
-	fmt.Println("userID:", userID) // Debugging
 	// Step 3: Generate a new JWT (auth_token)
 	// You might want to pass a fingerprint here if you’re using one
 	ip := a.GetIPAddress(r)
 	userAgent := r.UserAgent()
 	fingerprint := a.GenerateFingerprint(ip, userAgent)           // Optional fingerprint
-	fmt.Println("fingerprint:", fingerprint)                      // Debugging
 	newAuthToken, err := a.GenerateJWTWithFP(userID, fingerprint) // Pass fingerprint if needed
 	if err != nil {
 		utils.InternalServerError(w, r, err)
@@ -463,30 +445,22 @@ func (a *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
 	// Step 5: Respond to the user with a success message
 	tokenStore := a.store.Tokens
 
-	fmt.Println("*6") // Debugging
-
 	newRefreshToken, err := a.GenerateRefreshToken()
-	fmt.Println("* 7 newRefreshToken:", newRefreshToken) // Debugging
 	if err != nil {
 		utils.InternalServerError(w, r, err)
 		return
 	}
 
 	newRefreshTokenHash := a.HashToken(newRefreshToken)
-	fmt.Println("*8 newRefreshTokenHash:", newRefreshTokenHash) // Debugging
 
-	fmt.Println("*9 a.store.Tokens:", a.store.Tokens) // Debugging
 	if a.store.Tokens == nil {
-		log.Println("Error: a.store.Tokens is nil")
 		utils.InternalServerError(w, r, errors.New("internal server error"))
 		return
 	}
 
 	// Store the refresh token in the database
-	fmt.Println("Calling UpdateRefreshToken") // Debugging
 	err = tokenStore.UpdateRefreshToken(r.Context(), userID, newRefreshTokenHash, fingerprint, time.Now().Add(a.Config.Auth.RefreshToken.Exp))
 
-	fmt.Println("*10") // Debugging
 	if err != nil {
 		utils.InternalServerError(w, r, err)
 		return
@@ -494,7 +468,6 @@ func (a *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Remove the old refresh token from cookies
 
-	fmt.Println("do i get here------------") // Debugging
 	// Set the refresh token in a secure, HTTP-only cookie
 
 	http.SetCookie(w, &http.Cookie{
@@ -517,8 +490,9 @@ func (a *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
 		Expires:  time.Now().Add(a.Config.Auth.Token.Exp), // Cookie expiration time
 	})
 
+	metrics.AuthEvent(metrics.AuthRefresh)
+
 	// Set the refresh token in a secure, HTTP-only cookie
-	fmt.Println("*11 ")
 	w.Write([]byte("JWT refreshed successfully"))
 }
 
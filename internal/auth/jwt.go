@@ -11,66 +11,117 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"net"
 	"net/http"
-	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/michaelhoman/ShotSeek/internal/config"
-	"github.com/michaelhoman/ShotSeek/internal/env"
+	"github.com/michaelhoman/ShotSeek/internal/secrets"
 )
 
+// JWTService holds the HMAC signing secret, which SetSecret can rotate while requests read it
 type JWTService struct {
 	config config.Config
-	secret string
 	expiry time.Duration
+
+	mu     sync.RWMutex
+	secret string
 }
 
+// JWTAuth holds the ECDSA key pair used to sign and verify JWTs. The keys are read through a
+// secrets.Provider and can be swapped at runtime with Reload, so access goes through a mutex.
 type JWTAuth struct {
-	PrivateKey *ecdsa.PrivateKey
-	PublicKey  *ecdsa.PublicKey
+	provider secrets.Provider
+	keys     config.KeysConfig
+
+	mu         sync.RWMutex
+	privateKey *ecdsa.PrivateKey
+	publicKey  *ecdsa.PublicKey
 }
 
 func NewJWTService(secret string, expiry time.Duration) *JWTService {
 	return &JWTService{secret: secret, expiry: expiry}
 }
 
+// Secret returns the current signing secret
+func (s *JWTService) Secret() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.secret
+}
+
+// SetSecret swaps in a rotated signing secret
+func (s *JWTService) SetSecret(secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secret = secret
+}
+
 // NewJWTAuth initializes the JWTAuth struct by reading the ECDSA keys.
-func NewJWTAuth() (*JWTAuth, error) {
-	privateKey, err := loadPrivateKey(env.GetString("JWT_ECDSA_PRIVATE_KEY_PATH", ".keys/private_key.pem"))
+func NewJWTAuth(ctx context.Context, provider secrets.Provider, keys config.KeysConfig) (*JWTAuth, error) {
+	j := &JWTAuth{provider: provider, keys: keys}
+	if err := j.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Reload re-reads both keys and swaps them in together. On error the current keys stay in use.
+// Tokens signed with a rotated-out key fail validation and are reissued through the refresh token.
+func (j *JWTAuth) Reload(ctx context.Context) error {
+	privatePEM, err := j.provider.Resolve(ctx, keyRef(j.keys.PrivateKey))
 	if err != nil {
-		return nil, fmt.Errorf("failed to load private key: %v", err)
+		return fmt.Errorf("failed to load private key: %v", err)
+	}
+	privateKey, err := parsePrivateKey(privatePEM)
+	if err != nil {
+		return err
 	}
 
-	publicKey, err := loadPublicKey(env.GetString("JWT_ECDSA_PUBLIC_KEY_PATH", ".keys/public_key.pem"))
+	publicPEM, err := j.provider.Resolve(ctx, keyRef(j.keys.PublicKey))
+	if err != nil {
+		return fmt.Errorf("failed to load public key: %v", err)
+	}
+	publicKey, err := parsePublicKey(publicPEM)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load public key: %v", err)
+		return err
 	}
 
-	return &JWTAuth{
-		PrivateKey: privateKey,
-		PublicKey:  publicKey,
-	}, nil
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.privateKey = privateKey
+	j.publicKey = publicKey
+	return nil
 }
 
-// loadPrivateKey reads and parses the private key from the file system.
-func loadPrivateKey(path string) (*ecdsa.PrivateKey, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open private key file: %v", err)
-	}
-	defer file.Close()
+// PrivateKey returns the current signing key
+func (j *JWTAuth) PrivateKey() *ecdsa.PrivateKey {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.privateKey
+}
 
-	block, err := ioutil.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read private key: %v", err)
+// PublicKey returns the current verification key
+func (j *JWTAuth) PublicKey() *ecdsa.PublicKey {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.publicKey
+}
+
+// keyRef treats a bare path as a file reference, matching the old *_PATH settings
+func keyRef(value string) string {
+	if secrets.IsRef(value) {
+		return value
 	}
+	return secrets.SchemeFile + value
+}
 
+// parsePrivateKey parses a PEM encoded EC private key.
+func parsePrivateKey(block []byte) (*ecdsa.PrivateKey, error) {
 	pemBlock, _ := pem.Decode(block)
 	if pemBlock == nil {
 		return nil, errors.New("failed to decode PEM block containing the private key")
@@ -84,19 +135,8 @@ func loadPrivateKey(path string) (*ecdsa.PrivateKey, error) {
 	return privKey, nil
 }
 
-// loadPublicKey reads and parses the public key from the file system.
-func loadPublicKey(path string) (*ecdsa.PublicKey, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open public key file: %v", err)
-	}
-	defer file.Close()
-
-	block, err := io.ReadAll(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read public key: %v", err)
-	}
-
+// parsePublicKey parses a PEM encoded PKIX ECDSA public key.
+func parsePublicKey(block []byte) (*ecdsa.PublicKey, error) {
 	pemBlock, _ := pem.Decode(block)
 	if pemBlock == nil {
 		return nil, errors.New("failed to decode PEM block containing the public key")
@@ -146,10 +186,9 @@ func loadPublicKey(path string) (*ecdsa.PublicKey, error) {
 // }
 
 func (a *AuthHandler) GenerateJWTWithFP(userID uuid.UUID, fingerprint string) (string, error) {
-	fmt.Println("321Generating JWT for user:", userID) // TODO REMOVE Debugging
 	// Load the private key for signing (this can be done using the method we defined earlier)
 
-	// privateKey, err := loadPrivateKey(a.JWTAuth.PrivateKey) // Path to your private key
+	// privateKey, err := loadPrivateKey(a.JWTAuth.PrivateKey()) // Path to your private key
 	// if err != nil {
 	// 	return "", fmt.Errorf("failed to load private key: %v", err)
 	// }
@@ -171,11 +210,9 @@ func (a *AuthHandler) GenerateJWTWithFP(userID uuid.UUID, fingerprint string) (s
 
 	// Create the token using ES256 algorithm
 	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
-	fmt.Println("Token created:", token) // TODO REMOVE Debugging
 
 	// Sign the token with your ECDSA private key
-	signedToken, err := token.SignedString(a.JWTAuth.PrivateKey)
-	fmt.Println("Signed token:", signedToken) // TODO REMOVE Debugging
+	signedToken, err := token.SignedString(a.JWTAuth.PrivateKey())
 	if err != nil {
 		return "", fmt.Errorf("could not sign the token: %v", err)
 	}
@@ -185,7 +222,7 @@ func (a *AuthHandler) GenerateJWT(userID uuid.UUID) (string, error) {
 
 	// Load the private key for signing (this can be done using the method we defined earlier)
 
-	// privateKey, err := loadPrivateKey(a.JWTAuth.PrivateKey) // Path to your private key
+	// privateKey, err := loadPrivateKey(a.JWTAuth.PrivateKey()) // Path to your private key
 	// if err != nil {
 	// 	return "", fmt.Errorf("failed to load private key: %v", err)
 	// }
@@ -207,11 +244,9 @@ func (a *AuthHandler) GenerateJWT(userID uuid.UUID) (string, error) {
 
 	// Create the token using ES256 algorithm
 	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
-	fmt.Println("Token created:", token) // TODO REMOVE Debugging
 
 	// Sign the token with your ECDSA private key
-	signedToken, err := token.SignedString(a.JWTAuth.PrivateKey)
-	fmt.Println("Signed token:", signedToken) // TODO REMOVE Debugging
+	signedToken, err := token.SignedString(a.JWTAuth.PrivateKey())
 	if err != nil {
 		return "", fmt.Errorf("could not sign the token: %v", err)
 	}
@@ -287,7 +322,6 @@ func (a *AuthHandler) ValidateFingerprint(r *http.Request, expectedHash string)
 }
 
 func (a *AuthHandler) ValidateJWT(r *http.Request, tokenString, requestFingerprint string) (*Claims, error) {
-	fmt.Println("Validating JWT Called: tokenString:", tokenString, "\nrequestFingerprint: ", requestFingerprint) // TODO REMOVE Debugging
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate Algorithm
 		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
@@ -296,7 +330,7 @@ func (a *AuthHandler) ValidateJWT(r *http.Request, tokenString, requestFingerpri
 		// // Get the secret key from the environment variable
 		// jwtSigningKey := []byte(os.Getenv("JWT_SIGNING_KEY"))
 		// return jwtSigningKey, nil
-		return a.JWTAuth.PublicKey, nil
+		return a.JWTAuth.PublicKey(), nil
 
 	},
 		jwt.WithExpirationRequired(),                                // Ensure expiration is required and checked
@@ -320,8 +354,6 @@ func (a *AuthHandler) ValidateJWT(r *http.Request, tokenString, requestFingerpri
 		return nil, fmt.Errorf("invalid token issuer")
 	}
 
-	fmt.Printf("Extracted JWT Fingerprint: %s\n", claims.Fingerprint)
-	fmt.Printf("Request Fingerprint: %s\n", requestFingerprint)
 	// Validate Fingerprint
 	if !a.ValidateFingerprint(r, claims.Fingerprint) {
 		return nil, fmt.Errorf("invalid fingerprint")
@@ -346,12 +378,13 @@ func GetTokenFromCookie(r *http.Request) (string, error) {
 }
 
 // Helper function to extract the IP address
+// GetIPAddress returns the client IP. Forwarded headers are deliberately not read here:
+// middleware.ClientIP has already resolved them against the trusted proxy list.
 func (a *AuthHandler) GetIPAddress(r *http.Request) string {
-	// Check if the IP is set by a proxy (e.g., Nginx, Cloudflare)
-	ip := r.Header.Get("X-Forwarded-For")
-	if ip == "" {
-		// Fallback to RemoteAddr if no proxy is used
-		ip, _, _ = net.SplitHostPort(r.RemoteAddr)
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		// ClientIP stores the address without a port
+		return r.RemoteAddr
 	}
 	return ip
 }
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/secrets"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResolver(t *testing.T) *secrets.Resolver {
+	r, err := secrets.NewResolver("", nil)
+	assert.NoError(t, err)
+	return r
+}
+
+func TestLoadLayers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+addr: ":7000"
+db:
+  max_open_conns: 10
+auth:
+  token:
+    exp: 15m
+`), 0o600)
+	assert.NoError(t, err)
+
+	t.Setenv("ADDR", ":7100")
+	t.Setenv("REFRESH_TOKEN_EXP", "72h")
+
+	cfg, err := Load(context.Background(), path, newResolver(t))
+	assert.NoError(t, err)
+	assert.Equal(t, ":7100", cfg.Addr, "env overrides the file")
+	assert.Equal(t, 10, cfg.Db.MaxOpenConns, "file overrides defaults")
+	assert.Equal(t, 30, cfg.Db.MaxIdleConns, "defaults fill the rest")
+	assert.Equal(t, 15*time.Minute, cfg.Auth.Token.Exp)
+	assert.Equal(t, 72*time.Hour, cfg.Auth.RefreshToken.Exp)
+}
+
+func TestLoadResolvesSecrets(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "jwt_secret")
+	assert.NoError(t, os.WriteFile(secretPath, []byte("from-a-file\n"), 0o600))
+
+	t.Setenv("JWT_SIGNING_KEY", "file://"+secretPath)
+	t.Setenv("SHOTSEEK_TEST_REFRESH", "from-the-env")
+	t.Setenv("REFRESH_TOKEN_SECRET", "env://SHOTSEEK_TEST_REFRESH")
+
+	cfg, err := Load(context.Background(), "", newResolver(t))
+	assert.NoError(t, err)
+	assert.Equal(t, "from-a-file", cfg.Auth.Token.Secret)
+	assert.Equal(t, "from-the-env", cfg.Auth.RefreshToken.Secret)
+
+	t.Setenv("AUTH_BASIC_PASS", "env://SHOTSEEK_TEST_MISSING")
+	_, err = Load(context.Background(), "", newResolver(t))
+	assert.ErrorIs(t, err, secrets.ErrNotFound)
+}
+
+func TestLoadRenamedEnv(t *testing.T) {
+	t.Setenv("HTTPS_KEY_FILE", "old.key")
+	t.Setenv("HTTPS_CERT_FILE", "old.crt")
+	t.Setenv("HTTPS_CERT_PATH", "new.crt")
+
+	cfg, err := Load(context.Background(), "", newResolver(t))
+	assert.NoError(t, err)
+	assert.Equal(t, "old.key", cfg.HttpsKeyFile, "the old name still works")
+	assert.Equal(t, "new.crt", cfg.HttpsCertFile, "the new name wins")
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+	}{
+		{name: "unknown file key", file: "adress: \":7000\"\n"},
+		{name: "unparseable env", env: map[string]string{"DB_MAX_OPEN_CONNS": "lots"}},
+		{name: "bad duration", env: map[string]string{"JWT_EXP": "an hour"}},
+		{name: "invalid value", env: map[string]string{"TRACING_SAMPLE_RATIO": "2"}},
+		{name: "production with default secrets", env: map[string]string{"ENV": "production"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := ""
+			if tt.file != "" {
+				path = filepath.Join(t.TempDir(), "config.yaml")
+				assert.NoError(t, os.WriteFile(path, []byte(tt.file), 0o600))
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := Load(context.Background(), path, newResolver(t))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Defaults().Redacted()
+	assert.Equal(t, "[REDACTED]", cfg.Auth.Token.Secret)
+	assert.Equal(t, "[REDACTED]", cfg.Auth.RefreshToken.Secret)
+	assert.Equal(t, "[REDACTED]", cfg.Auth.Basic.Pass)
+	assert.NotContains(t, cfg.Db.Addr, "adminpassword")
+}
@@ -1,3 +1,4 @@
+// Package db seeds a development database with a realistic, reproducible dataset.
 package db
 
 import (
@@ -5,12 +6,36 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-
 	"math/rand"
+	"strings"
 
+	"github.com/lib/pq"
 	"github.com/michaelhoman/ShotSeek/internal/store"
 )
 
+// Config controls the volume of generated data. The same Seed always produces the same
+// names, locations, profiles, posts and comments; only the database generated IDs differ between runs.
+type Config struct {
+	Users    int
+	Profiles int // cinematographer profiles, one each for the first Profiles users
+	Posts    int
+	Comments int
+	Seed     int64
+	// Password is shared by every seeded user so frontend devs can log in as anyone
+	Password string
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Users:    100,
+		Profiles: 60,
+		Posts:    200,
+		Comments: 500,
+		Seed:     1,
+		Password: "password123",
+	}
+}
+
 var first_names = []string{
 	"alice", "bob", "charlie", "dave", "eve", "frank", "grace", "heidi",
 	"ivan", "judy", "karl", "laura", "mallory", "nina", "oscar", "peggy",
@@ -56,249 +81,246 @@ var last_names = []string{
 	"Griffin", "Ferguson", "Hunter", "Marsh", "Hardy", "Curtis", "Christensen", "Hintermeister",
 	"Prescott", "Homan",
 }
-var zipCodeToState = map[string]string{
-	"10001": "NY",
-	"20001": "DC",
-	"30301": "GA",
-	"94101": "CA",
-	"60601": "IL",
-	"75201": "TX",
-	"98101": "WA",
-	"85001": "AZ",
-	"33101": "FL",
-	"48201": "MI",
-	"80201": "CO",
-	"19101": "PA",
-	"90001": "CA",
-	"75204": "TX",
-	"90210": "CA",
-	"02108": "MA",
-	"19103": "PA",
-	"55101": "MN",
-	"70112": "LA",
-	"10011": "NY",
-	"20005": "DC",
-	"30303": "GA",
-	"94105": "CA",
-	"60606": "IL",
-	"75205": "TX",
-	"98105": "WA",
-	"85005": "AZ",
-	"33105": "FL",
-	"48205": "MI",
-	"80205": "CO",
-	"19105": "PA",
-	"90005": "CA",
-	"75208": "TX",
-	"90215": "CA",
-	"02118": "MA",
-	"19108": "PA",
-	"55105": "MN",
-	"70115": "LA",
-	"50125": "IA",
-	"50325": "IA",
-	"66062": "KS",
-	"66202": "KS",
-	"66030": "KS",
-	"66205": "KS",
-	"66044": "KS",
-	"66208": "KS",
-	"66210": "KS",
+
+var titles = []string{
+	"Available for documentary work this spring", "Looking for a B-cam operator",
+	"Wedding season reel is up", "Selling my old gimbal", "Best lenses for low light interviews",
+	"Need a DP for a music video", "Lighting a night exterior on a budget",
+	"Color grading workflow for log footage", "Drone shots for real estate", "Behind the scenes from last week's shoot",
+	"Renting out a full cinema package", "Tips for run and gun interviews", "Anyone shooting anamorphic lately?",
+	"Commercial shoot wrapped", "Looking for a gaffer", "How do you price corporate video?",
+}
+
+var contents = []string{
+	"Just wrapped a three day shoot and have open dates coming up. Happy to travel for the right project.",
+	"We're putting together a small crew for a two day corporate job and need another camera operator.",
+	"Finally finished cutting my reel from this season. Feedback welcome, especially on the color.",
+	"Upgraded my kit and have some gear that needs a new home. Message me for details.",
+	"Interviews in dim offices keep giving me trouble. What are you all using to keep the noise down?",
+	"Indie artist looking for someone with a strong visual style for a performance video.",
+	"Sharing the setup from a night exterior we lit with two LED panels and a lot of negative fill.",
+	"This is the node tree I use to get from camera log to a clean, consistent look across cameras.",
+	"Licensed Part 107 pilot with aerial footage of listings across the metro. Rates on request.",
+	"A few stills from set and what we learned moving a dolly through a tight hallway.",
+	"Full package with camera body, cine primes, follow focus and support available by the day or week.",
+	"Keep it light: one body, a zoom, a wireless lav and an on-camera light will cover most days.",
+}
+
+var tags = []string{
+	"Camera", "Cinema", "Lenses", "Lighting", "Drone", "Gimbal", "Documentary",
+	"Wedding", "Commercial", "Music Video", "Corporate", "Color Grading", "Audio",
+	"Gear Rental", "Hiring", "Available", "Narrative", "Real Estate", "Interview", "Anamorphic",
 }
 
-var zipCodeToCity = map[string]string{
-	"10001": "New York",
-	"20001": "Washington",
-	"30301": "Atlanta",
-	"94101": "San Francisco",
-	"60601": "Chicago",
-	"75201": "Dallas",
-	"98101": "Seattle",
-	"85001": "Phoenix",
-	"33101": "Miami",
-	"48201": "Detroit",
-	"80201": "Denver",
-	"19101": "Philadelphia",
-	"90001": "Los Angeles",
-	"75204": "Dallas",
-	"90210": "Beverly Hills",
-	"02108": "Boston",
-	"19103": "Philadelphia",
-	"55101": "Minneapolis",
-	"70112": "New Orleans",
-	"10011": "New York",
-	"20005": "Washington",
-	"30303": "Atlanta",
-	"94105": "San Francisco",
-	"60606": "Chicago",
-	"75205": "Dallas",
-	"98105": "Seattle",
-	"85005": "Phoenix",
-	"33105": "Miami",
-	"48205": "Detroit",
-	"80205": "Denver",
-	"19105": "Philadelphia",
-	"90005": "Los Angeles",
-	"75208": "Dallas",
-	"90215": "Beverly Hills",
-	"02118": "Boston",
-	"19108": "Philadelphia",
-	"55105": "Minneapolis",
-	"70115": "New Orleans",
-	"50125": "Indianola",
-	"50325": "Clive",
-	"66062": "Olathe",
-	"66202": "Mission",
-	"66030": "Gardner",
-	"66205": "Mission Hills",
-	"66044": "Lawrence",
-	"66208": "Prairie Village",
-	"66210": "Overland Park",
+var headlines = []string{
+	"Documentary DP with a drone license", "Commercial cinematographer and colorist",
+	"Narrative DP, features and shorts", "Steadicam and gimbal operator", "Music video DP with a full lighting package",
+	"Corporate and event camera operator", "Aerial cinematographer, Part 107 certified", "B-cam operator for hire",
 }
 
-// var titles = []string{
-// 	"The Power of Habit", "Embracing Minimalism", "Healthy Eating Tips",
-// 	"Travel on a Budget", "Mindfulness Meditation", "Boost Your Productivity",
-// 	"Home Office Setup", "Digital Detox", "Gardening Basics",
-// 	"DIY Home Projects", "Yoga for Beginners", "Sustainable Living",
-// 	"Mastering Time Management", "Exploring Nature", "Simple Cooking Recipes",
-// 	"Fitness at Home", "Personal Finance Tips", "Creative Writing",
-// 	"Mental Health Awareness", "Learning New Skills",
-// }
-
-// var contents = []string{
-// 	"In this post, we'll explore how to develop good habits that stick and transform your life.",
-// 	"Discover the benefits of a minimalist lifestyle and how to declutter your home and mind.",
-// 	"Learn practical tips for eating healthy on a budget without sacrificing flavor.",
-// 	"Traveling doesn't have to be expensive. Here are some tips for seeing the world on a budget.",
-// 	"Mindfulness meditation can reduce stress and improve your mental well-being. Here's how to get started.",
-// 	"Increase your productivity with these simple and effective strategies.",
-// 	"Set up the perfect home office to boost your work-from-home efficiency and comfort.",
-// 	"A digital detox can help you reconnect with the real world and improve your mental health.",
-// 	"Start your gardening journey with these basic tips for beginners.",
-// 	"Transform your home with these fun and easy DIY projects.",
-// 	"Yoga is a great way to stay fit and flexible. Here are some beginner-friendly poses to try.",
-// 	"Sustainable living is good for you and the planet. Learn how to make eco-friendly choices.",
-// 	"Master time management with these tips and get more done in less time.",
-// 	"Nature has so much to offer. Discover the benefits of spending time outdoors.",
-// 	"Whip up delicious meals with these simple and quick cooking recipes.",
-// 	"Stay fit without leaving home with these effective at-home workout routines.",
-// 	"Take control of your finances with these practical personal finance tips.",
-// 	"Unleash your creativity with these inspiring writing prompts and exercises.",
-// 	"Mental health is just as important as physical health. Learn how to take care of your mind.",
-// 	"Learning new skills can be fun and rewarding. Here are some ideas to get you started.",
-// }
-
-// var tags = []string{
-// 	"Camera", "Photo", "Video", "Package", "Mindfulness",
-// 	"Productivity", "Home Office", "Digital Detox", "Gardening", "DIY",
-// 	"Yoga", "Sustainability", "Time Management", "Nature", "Cooking",
-// 	"Fitness", "Personal Finance", "Writing", "Mental Health", "Learning",
-// }
-
-// var comments = []string{
-// 	"Great post! Thanks for sharing.",
-// 	"I completely agree with your thoughts.",
-// 	"Thanks for the tips, very helpful.",
-// 	"Interesting perspective, I hadn't considered that.",
-// 	"Thanks for sharing your experience.",
-// 	"Well written, I enjoyed reading this.",
-// 	"This is very insightful, thanks for posting.",
-// 	"Great advice, I'll definitely try that.",
-// 	"I love this, very inspirational.",
-// 	"Thanks for the information, very useful.",
-// }
-
-func Seed(store store.Storage, db *sql.DB) {
-	ctx := context.Background()
-
-	users := generateUsers(100)
-	tx, _ := db.BeginTx(ctx, nil)
-
-	for _, user := range users {
-		if err := store.Users.Create(ctx, tx, user); err != nil {
-			_ = tx.Rollback()
-			log.Println("Error creating user:", err)
-			return
+var bios = []string{
+	"Fifteen years behind the camera on everything from verite documentaries to national spots.",
+	"I own my package and travel light. Happy to work as a one person crew or lead a small team.",
+	"Started as an AC in the rental houses and moved up to shooting narrative work full time.",
+	"Known for natural light and quick setups on tight schedules.",
+	"Bilingual crew with experience on international productions and remote locations.",
+}
+
+var cameraRoles = []store.CrewRole{
+	store.RoleDirectorOfPhotography, store.RoleCameraOperator, store.RoleSteadicamOperator, store.RoleDronePilot,
+}
+
+var languages = []string{"English", "Spanish", "French", "Portuguese", "Mandarin", "German"}
+
+var unions = []string{"IATSE Local 600", "IATSE Local 52", "IATSE Local 80"}
+
+var comments = []string{
+	"Great work, the framing on the second shot is beautiful.",
+	"Sent you a message, I might be available those dates.",
+	"What lens did you use for the wide?",
+	"Thanks for sharing the setup, super helpful.",
+	"I'd be interested in renting that kit next month.",
+	"Rates in our area are pretty similar, that sounds fair.",
+	"Love the color on this.",
+	"How did you handle audio on that one?",
+	"Count me in if you still need someone.",
+	"Solid advice, I'll try that on my next interview.",
+}
+
+// Seed inserts cfg.Users activated users with locations from the bundled ZIP sample,
+// cfg.Profiles cinematographer profiles for them, then cfg.Posts tagged posts and cfg.Comments comments spread across them.
+// It expects a migrated database without a previous seed, since emails must be unique.
+func Seed(ctx context.Context, s store.Storage, db *sql.DB, cfg Config) error {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	zips, err := loadZipCodes()
+	if err != nil {
+		return err
+	}
+
+	users, locations := generateUsers(rng, cfg.Users, zips)
+	if err := setPasswords(users, cfg.Password); err != nil {
+		return err
+	}
+
+	userIDs := make([]string, 0, len(users))
+	for i, user := range users {
+		// Create commits each user on its own, so a location created for an earlier user is reused by the next
+		if err := s.Users.Create(ctx, user, locations[i]); err != nil {
+			return fmt.Errorf("creating user %s: %w", user.Email, err)
 		}
+		userIDs = append(userIDs, user.ID.String())
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE users SET is_active = true WHERE id = ANY($1)`, pq.Array(userIDs)); err != nil {
+		return fmt.Errorf("activating users: %w", err)
 	}
+	log.Printf("Seeded %d users", len(users))
 
-	tx.Commit()
-
-	// posts := generatePosts(200, users)
-	// for _, post := range posts {
-	// 	if err := store.Posts.Create(ctx, post); err != nil {
-	// 		log.Println("Error creating post:", err)
-	// 		return
-	// 	}
-	// }
-
-	// comments := generateComments(500, users, posts)
-	// for _, comment := range comments {
-	// 	if err := store.Comments.Create(ctx, comment); err != nil {
-	// 		log.Println("Error creating comment:", err)
-	// 		return
-	// 	}
-	// }
-
-	log.Println("Seeding complete")
+	profiles := generateProfiles(rng, cfg.Profiles, users)
+	for _, profile := range profiles {
+		if err := s.Profiles.Create(ctx, profile); err != nil {
+			return fmt.Errorf("creating profile: %w", err)
+		}
+	}
+	log.Printf("Seeded %d profiles", len(profiles))
+
+	posts := generatePosts(rng, cfg.Posts, users)
+	for _, post := range posts {
+		if err := s.Posts.Create(ctx, post); err != nil {
+			return fmt.Errorf("creating post: %w", err)
+		}
+	}
+	log.Printf("Seeded %d posts", len(posts))
+
+	cms := generateComments(rng, cfg.Comments, users, posts)
+	for _, comment := range cms {
+		if err := s.Comments.Create(ctx, comment); err != nil {
+			return fmt.Errorf("creating comment: %w", err)
+		}
+	}
+	log.Printf("Seeded %d comments", len(cms))
+
+	return nil
 }
 
-func generateUsers(num int) []*store.User {
+func generateUsers(rng *rand.Rand, num int, zips []zipCode) ([]*store.User, []*store.Location) {
 	users := make([]*store.User, num)
+	locations := make([]*store.Location, num)
 
 	for i := 0; i < num; i++ {
-		keys := make([]string, 0, len(zipCodeToState))
-		for k := range zipCodeToState {
-			keys = append(keys, k)
-		}
-		selectedZipCode := keys[rand.Intn(len(keys))]
-		state := zipCodeToState[selectedZipCode]
-		city := zipCodeToCity[selectedZipCode]
+		first := first_names[rng.Intn(len(first_names))]
+		last := last_names[rng.Intn(len(last_names))]
 
 		users[i] = &store.User{
-			FirstName: first_names[i%len(first_names)] + fmt.Sprintf("%d", i),
-			LastName:  last_names[i%len(last_names)] + fmt.Sprintf("%d", i),
-			Zipcode:   selectedZipCode,
-			State:     state,
-			City:      city,
-			Email:     fmt.Sprintf("%c%s%d@example.com", first_names[i%len(first_names)][0], last_names[i%len(last_names)], i),
-			// Role: store.Role{
-			// 	Name: "user",
-			// },
+			FirstName: capitalize(first),
+			LastName:  capitalize(last),
+			Email:     fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i),
 		}
+		locations[i] = zips[rng.Intn(len(zips))].location()
 	}
 
-	return users
+	return users, locations
 }
 
-// func generatePosts(num int, users []*store.User) []*store.Post {
-// 	posts := make([]*store.Post, num)
-// 	for i := 0; i < num; i++ {
-// 		user := users[rand.Intn(len(users))]
-
-// 		posts[i] = &store.Post{
-// 			UserID:  user.ID,
-// 			Title:   titles[rand.Intn(len(titles))],
-// 			Content: titles[rand.Intn(len(contents))],
-// 			Tags: []string{
-// 				tags[rand.Intn(len(tags))],
-// 				tags[rand.Intn(len(tags))],
-// 			},
-// 		}
-// 	}
-
-// 	return posts
-// }
-
-// func generateComments(num int, users []*store.User, posts []*store.Post) []*store.Comment {
-// 	cms := make([]*store.Comment, num)
-// 	for i := 0; i < num; i++ {
-// 		cms[i] = &store.Comment{
-// 			PostID:  posts[rand.Intn(len(posts))].ID,
-// 			UserID:  users[rand.Intn(len(users))].ID,
-// 			Content: comments[rand.Intn(len(comments))],
-// 		}
-// 	}
-// 	return cms
-// }
+// generateProfiles gives the first num users a profile, or every user when there are fewer
+func generateProfiles(rng *rand.Rand, num int, users []*store.User) []*store.Profile {
+	num = min(num, len(users))
+
+	profiles := make([]*store.Profile, num)
+	for i := 0; i < num; i++ {
+		rateMin := 300 + 50*rng.Intn(20)
+		profile := &store.Profile{
+			UserID:          users[i].ID,
+			Headline:        headlines[rng.Intn(len(headlines))],
+			Bio:             bios[rng.Intn(len(bios))],
+			Roles:           pick(rng, cameraRoles, 2),
+			Specialties:     pick(rng, store.Specialties, 3),
+			YearsExperience: 1 + rng.Intn(25),
+			DayRateMin:      rateMin,
+			DayRateMax:      rateMin + 50*rng.Intn(20),
+			Currency:        "USD",
+			Languages:       []string{"English"},
+			Links: store.ProfileLinks{
+				{Kind: store.LinkReel, URL: fmt.Sprintf("https://vimeo.com/shotseek%d", i)},
+			},
+		}
+		if rng.Intn(2) == 0 {
+			profile.Languages = append(profile.Languages, languages[1+rng.Intn(len(languages)-1)])
+		}
+		if rng.Intn(3) == 0 {
+			profile.Unions = []string{unions[rng.Intn(len(unions))]}
+		}
+		profiles[i] = profile
+	}
+	return profiles
+}
+
+func generatePosts(rng *rand.Rand, num int, users []*store.User) []*store.Post {
+	if len(users) == 0 {
+		return nil
+	}
+
+	posts := make([]*store.Post, num)
+	for i := 0; i < num; i++ {
+		posts[i] = &store.Post{
+			UserID:  users[rng.Intn(len(users))].ID,
+			Title:   titles[rng.Intn(len(titles))],
+			Content: contents[rng.Intn(len(contents))],
+			Tags:    pickTags(rng),
+		}
+	}
+
+	return posts
+}
+
+func generateComments(rng *rand.Rand, num int, users []*store.User, posts []*store.Post) []*store.Comment {
+	if len(users) == 0 || len(posts) == 0 {
+		return nil
+	}
+
+	cms := make([]*store.Comment, num)
+	for i := 0; i < num; i++ {
+		cms[i] = &store.Comment{
+			PostID:  posts[rng.Intn(len(posts))].ID,
+			UserID:  users[rng.Intn(len(users))].ID,
+			Content: comments[rng.Intn(len(comments))],
+		}
+	}
+	return cms
+}
+
+// pickTags returns one to three distinct tags
+func pickTags(rng *rand.Rand) []string {
+	return pick(rng, tags, 3)
+}
+
+// pick returns one to most distinct elements of from
+func pick[T any](rng *rand.Rand, from []T, most int) []T {
+	n := 1 + rng.Intn(most)
+	picked := make([]T, 0, n)
+	for _, i := range rng.Perm(len(from))[:n] {
+		picked = append(picked, from[i])
+	}
+	return picked
+}
+
+// setPasswords hashes the password once; bcrypt per user would dominate the seed time
+func setPasswords(users []*store.User, plain string) error {
+	if len(users) == 0 {
+		return nil
+	}
+	if err := users[0].Password.Set(plain); err != nil {
+		return err
+	}
+	for _, user := range users[1:] {
+		user.Password = users[0].Password
+	}
+	return nil
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
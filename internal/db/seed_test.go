@@ -0,0 +1,87 @@
+package db
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadZipCodes(t *testing.T) {
+	zips, err := loadZipCodes()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, zips)
+
+	for _, z := range zips {
+		assert.True(t, z.location().IsValid(), z.Code)
+		assert.Len(t, z.Code, 5, z.Code)
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	zips, err := loadZipCodes()
+	assert.NoError(t, err)
+
+	generate := func(seed int64) ([]*store.User, []*store.Location, []*store.Post) {
+		rng := rand.New(rand.NewSource(seed))
+		users, locations := generateUsers(rng, 20, zips)
+		for _, u := range users {
+			u.ID = uuid.New()
+		}
+		return users, locations, generatePosts(rng, 30, users)
+	}
+
+	usersA, locationsA, postsA := generate(42)
+	usersB, locationsB, postsB := generate(42)
+
+	emails := map[string]bool{}
+	for i := range usersA {
+		assert.Equal(t, usersA[i].Email, usersB[i].Email)
+		assert.Equal(t, usersA[i].FirstName, usersB[i].FirstName)
+		assert.Equal(t, locationsA[i], locationsB[i])
+		emails[usersA[i].Email] = true
+	}
+	assert.Len(t, emails, len(usersA), "emails must be unique")
+
+	for i := range postsA {
+		assert.Equal(t, postsA[i].Title, postsB[i].Title)
+		assert.Equal(t, postsA[i].Tags, postsB[i].Tags)
+		assert.NotEmpty(t, postsA[i].Tags)
+		assert.NotEqual(t, uuid.Nil, postsA[i].UserID)
+	}
+}
+
+func TestSetPasswordsSharesHash(t *testing.T) {
+	users := []*store.User{{}, {}, {}}
+	assert.NoError(t, setPasswords(users, "password123"))
+
+	for _, u := range users {
+		assert.NoError(t, u.Password.Compare("password123"))
+	}
+}
+
+func TestGenerateProfiles(t *testing.T) {
+	users := make([]*store.User, 5)
+	for i := range users {
+		users[i] = &store.User{ID: uuid.New()}
+	}
+
+	profilesA := generateProfiles(rand.New(rand.NewSource(7)), 10, users)
+	profilesB := generateProfiles(rand.New(rand.NewSource(7)), 10, users)
+	assert.Len(t, profilesA, len(users), "at most one profile per user")
+
+	for i, p := range profilesA {
+		assert.Equal(t, users[i].ID, p.UserID)
+		assert.Equal(t, p, profilesB[i])
+		assert.NotEmpty(t, p.Roles)
+		for _, r := range p.Roles {
+			assert.True(t, r.Valid(), r)
+		}
+		for _, s := range p.Specialties {
+			assert.True(t, s.Valid(), s)
+		}
+		assert.LessOrEqual(t, p.DayRateMin, p.DayRateMax)
+	}
+}
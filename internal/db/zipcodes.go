@@ -0,0 +1,73 @@
+package db
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+
+	"github.com/michaelhoman/ShotSeek/internal/store"
+)
+
+// zipcodes.csv is a small sample of US ZIP codes with approximate centroids,
+// weighted towards cities with busy production scenes
+//
+//go:embed zipcodes.csv
+var zipCodesCSV []byte
+
+type zipCode struct {
+	Code      string
+	City      string
+	State     string
+	County    string
+	Latitude  float64
+	Longitude float64
+}
+
+func (z zipCode) location() *store.Location {
+	return &store.Location{
+		City:        z.City,
+		State:       z.State,
+		County:      z.County,
+		ZIPCode:     z.Code,
+		Country:     "USA",
+		CountryCode: "USA",
+		Latitude:    z.Latitude,
+		Longitude:   z.Longitude,
+	}
+}
+
+func loadZipCodes() ([]zipCode, error) {
+	records, err := csv.NewReader(bytes.NewReader(zipCodesCSV)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading zip codes: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("reading zip codes: no rows")
+	}
+
+	zips := make([]zipCode, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if len(record) != 6 {
+			return nil, fmt.Errorf("zip codes line %d: expected 6 fields, got %d", i+2, len(record))
+		}
+		lat, err := strconv.ParseFloat(record[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("zip codes line %d: latitude: %w", i+2, err)
+		}
+		lon, err := strconv.ParseFloat(record[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("zip codes line %d: longitude: %w", i+2, err)
+		}
+		zips = append(zips, zipCode{
+			Code:      record[0],
+			City:      record[1],
+			State:     record[2],
+			County:    record[3],
+			Latitude:  lat,
+			Longitude: lon,
+		})
+	}
+	return zips, nil
+}
@@ -0,0 +1,117 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Loader overlays environment variables onto existing values. Unlike the Get* helpers it
+// leaves the destination untouched when a variable is unset and records an error, rather
+// than silently falling back, when a variable is set but cannot be parsed.
+type Loader struct {
+	errs []error
+}
+
+// Err returns every parse error seen so far, or nil
+func (l *Loader) Err() error {
+	return errors.Join(l.errs...)
+}
+
+func (l *Loader) fail(key, val, kind string, err error) {
+	l.errs = append(l.errs, fmt.Errorf("%s=%q is not a valid %s: %w", key, val, kind, err))
+}
+
+func (l *Loader) String(key string, dst *string) {
+	if val, ok := os.LookupEnv(key); ok {
+		*dst = val
+	}
+}
+
+// RenamedString reads oldKey, the name a variable went by before it became newKey, so existing
+// deployments keep working. It logs a deprecation warning when oldKey is set; newKey wins
+// when both are.
+func (l *Loader) RenamedString(oldKey, newKey string, dst *string) {
+	val, ok := os.LookupEnv(oldKey)
+	if !ok {
+		return
+	}
+	if _, ok := os.LookupEnv(newKey); ok {
+		log.Printf("warning: %s is deprecated and ignored because %s is also set", oldKey, newKey)
+		return
+	}
+	log.Printf("warning: %s is deprecated; rename it to %s", oldKey, newKey)
+	*dst = val
+}
+
+func (l *Loader) Int(key string, dst *int) {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	res, err := strconv.Atoi(val)
+	if err != nil {
+		l.fail(key, val, "integer", err)
+		return
+	}
+	*dst = res
+}
+
+func (l *Loader) Bool(key string, dst *bool) {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	res, err := strconv.ParseBool(val)
+	if err != nil {
+		l.fail(key, val, "boolean", err)
+		return
+	}
+	*dst = res
+}
+
+func (l *Loader) Float64(key string, dst *float64) {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	res, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		l.fail(key, val, "number", err)
+		return
+	}
+	*dst = res
+}
+
+// Duration accepts time.ParseDuration syntax such as "15m" or "168h"
+func (l *Loader) Duration(key string, dst *time.Duration) {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	res, err := time.ParseDuration(val)
+	if err != nil {
+		l.fail(key, val, "duration", err)
+		return
+	}
+	*dst = res
+}
+
+// StringSlice splits a comma separated value, dropping empty entries
+func (l *Loader) StringSlice(key string, dst *[]string) {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	var res []string
+	for _, part := range strings.Split(val, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			res = append(res, part)
+		}
+	}
+	*dst = res
+}
@@ -0,0 +1,374 @@
+// Package ical reads and writes the part of iCalendar (RFC 5545) that availability needs:
+// events with a start, an end and whether they block time. Recurrence rules are not expanded,
+// so a recurring event only counts for its first occurrence, and VTIMEZONE definitions are
+// ignored in favor of the IANA zone named by TZID.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // TZID names must resolve even where the host has no zoneinfo
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by every error Parse returns for malformed input
+var ErrInvalid = errors.New("invalid iCalendar data")
+
+// Event statuses, as written in the STATUS property
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event is a VEVENT. All day events start at midnight in the calendar's time zone and
+// their End is the midnight after their last day.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start, End  time.Time
+	AllDay      bool
+	Status      string    // one of the Status constants, or empty when not given
+	Transparent bool      // TRANSP:TRANSPARENT, the event does not block time
+	Stamp       time.Time // DTSTAMP; Encode uses the current time when zero
+}
+
+// Calendar is a VCALENDAR and the events in it
+type Calendar struct {
+	ProdID   string
+	Name     string         // X-WR-CALNAME
+	Location *time.Location // X-WR-TIMEZONE, used for times without a zone
+	Events   []Event
+}
+
+// maxLineLength caps an unfolded content line so a hostile file cannot exhaust memory
+const maxLineLength = 64 << 10
+
+// Parse reads a calendar. Times without a zone, and zones Parse cannot resolve, are read in
+// the calendar's X-WR-TIMEZONE or else in loc.
+func Parse(r io.Reader, loc *time.Location) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &Calendar{Location: loc}
+	var (
+		event   *Event
+		props   map[string]property // the current event's properties, read once END:VEVENT is seen
+		skip    []string            // components nested in an event, such as VALARM
+		started bool
+	)
+	for n, line := range lines {
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalid, n+1, err)
+		}
+
+		switch {
+		case len(skip) > 0:
+			switch p.name {
+			case "BEGIN":
+				skip = append(skip, p.value)
+			case "END":
+				skip = skip[:len(skip)-1]
+			}
+		case p.name == "BEGIN" && p.value == "VCALENDAR":
+			started = true
+		case !started:
+			return nil, fmt.Errorf("%w: expected BEGIN:VCALENDAR", ErrInvalid)
+		case p.name == "BEGIN" && p.value == "VEVENT":
+			event, props = &Event{}, map[string]property{}
+		case p.name == "END" && p.value == "VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("%w: line %d: END:VEVENT without BEGIN", ErrInvalid, n+1)
+			}
+			if err := event.read(props, cal.Location); err != nil {
+				return nil, fmt.Errorf("%w: event ending on line %d: %v", ErrInvalid, n+1, err)
+			}
+			cal.Events = append(cal.Events, *event)
+			event = nil
+		case p.name == "BEGIN":
+			skip = append(skip, p.value)
+		case p.name == "END" && p.value == "VCALENDAR":
+			return cal, nil
+		case event != nil:
+			props[p.name] = p
+		case p.name == "PRODID":
+			cal.ProdID = p.value
+		case p.name == "X-WR-CALNAME":
+			cal.Name = unescape(p.value)
+		case p.name == "X-WR-TIMEZONE":
+			if zone, err := time.LoadLocation(p.value); err == nil {
+				cal.Location = zone
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: missing END:VCALENDAR", ErrInvalid)
+}
+
+// unfold joins folded lines, which continue on the next line after a space or tab
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineLength)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		switch {
+		case line == "":
+		case line[0] == ' ' || line[0] == '\t':
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("%w: continuation line before any content", ErrInvalid)
+			}
+			if len(lines[len(lines)-1])+len(line) > maxLineLength {
+				return nil, fmt.Errorf("%w: line longer than %d bytes", ErrInvalid, maxLineLength)
+			}
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return lines, nil
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseLine splits a content line into its name, parameters and value. Parameter values
+// may be quoted, and quoted values may hold the ; and : that otherwise end them.
+func parseLine(line string) (property, error) {
+	var (
+		fields []string
+		start  int
+		quoted bool
+	)
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ';' || c == ':':
+			fields = append(fields, line[start:i])
+			start = i + 1
+			if c != ':' {
+				continue
+			}
+
+			p := property{name: strings.ToUpper(fields[0]), params: map[string]string{}, value: line[start:]}
+			if p.name == "" {
+				return property{}, errors.New("missing property name")
+			}
+			for _, param := range fields[1:] {
+				key, value, _ := strings.Cut(param, "=")
+				p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+			}
+			return p, nil
+		}
+	}
+	return property{}, fmt.Errorf("no value in %q", line)
+}
+
+// read fills in the event from its properties
+func (e *Event) read(props map[string]property, loc *time.Location) error {
+	start, ok := props["DTSTART"]
+	if !ok {
+		return errors.New("no DTSTART")
+	}
+	var err error
+	if e.Start, e.AllDay, err = parseTime(start, loc); err != nil {
+		return fmt.Errorf("DTSTART: %v", err)
+	}
+
+	switch {
+	case props["DTEND"].value != "":
+		if e.End, _, err = parseTime(props["DTEND"], loc); err != nil {
+			return fmt.Errorf("DTEND: %v", err)
+		}
+	case props["DURATION"].value != "":
+		d, err := parseDuration(props["DURATION"].value)
+		if err != nil {
+			return fmt.Errorf("DURATION: %v", err)
+		}
+		e.End = e.Start.Add(d)
+	case e.AllDay:
+		e.End = e.Start.AddDate(0, 0, 1)
+	default:
+		e.End = e.Start
+	}
+	if e.End.Before(e.Start) {
+		return errors.New("ends before it starts")
+	}
+
+	e.UID = props["UID"].value
+	e.Summary = unescape(props["SUMMARY"].value)
+	e.Description = unescape(props["DESCRIPTION"].value)
+	e.Status = strings.ToUpper(props["STATUS"].value)
+	e.Transparent = strings.EqualFold(props["TRANSP"].value, "TRANSPARENT")
+	if stamp, ok := props["DTSTAMP"]; ok {
+		e.Stamp, _, _ = parseTime(stamp, time.UTC)
+	}
+	return nil
+}
+
+// parseTime reads a DATE or DATE-TIME value, in UTC when it ends in Z, in its TZID when that
+// names a zone Go knows, and in loc otherwise
+func parseTime(p property, loc *time.Location) (time.Time, bool, error) {
+	if tzid := p.params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = zone
+		}
+	}
+
+	value := p.value
+	if p.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseDuration reads a DURATION value such as PT1H30M, P1D or P2W
+func parseDuration(value string) (time.Duration, error) {
+	sign := time.Duration(1)
+	switch value[0] {
+	case '-':
+		sign, value = -1, value[1:]
+	case '+':
+		value = value[1:]
+	}
+	if !strings.HasPrefix(value, "P") || len(value) < 3 {
+		return 0, fmt.Errorf("malformed duration %q", value)
+	}
+
+	var (
+		total  time.Duration
+		inTime bool
+		digits string
+	)
+	for _, c := range value[1:] {
+		switch {
+		case c >= '0' && c <= '9':
+			digits += string(c)
+			continue
+		case c == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return 0, fmt.Errorf("malformed duration %q", value)
+		}
+		digits = ""
+
+		unit := map[rune]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+		if inTime {
+			unit = map[rune]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+		}
+		size, ok := unit[c]
+		if !ok {
+			return 0, fmt.Errorf("malformed duration %q", value)
+		}
+		total += time.Duration(n) * size
+	}
+	if digits != "" {
+		return 0, fmt.Errorf("malformed duration %q", value)
+	}
+	return sign * total, nil
+}
+
+var (
+	unescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	escaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, ";", `\;`, "\r", "")
+)
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
+
+// Encode writes the calendar with CRLF line endings, folding lines longer than 75 octets.
+// Timed events are written in UTC and all day events as dates.
+func Encode(w io.Writer, cal Calendar) error {
+	bw := bufio.NewWriter(w)
+	write := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", cal.ProdID)
+	write("CALSCALE", "GREGORIAN")
+	if cal.Name != "" {
+		write("X-WR-CALNAME", escaper.Replace(cal.Name))
+	}
+
+	now := time.Now()
+	for _, e := range cal.Events {
+		stamp := e.Stamp
+		if stamp.IsZero() {
+			stamp = now
+		}
+
+		write("BEGIN", "VEVENT")
+		write("UID", e.UID)
+		write("DTSTAMP", stamp.UTC().Format("20060102T150405Z"))
+		if e.AllDay {
+			write("DTSTART;VALUE=DATE", e.Start.Format("20060102"))
+			write("DTEND;VALUE=DATE", e.End.Format("20060102"))
+		} else {
+			write("DTSTART", e.Start.UTC().Format("20060102T150405Z"))
+			write("DTEND", e.End.UTC().Format("20060102T150405Z"))
+		}
+		if e.Summary != "" {
+			write("SUMMARY", escaper.Replace(e.Summary))
+		}
+		if e.Description != "" {
+			write("DESCRIPTION", escaper.Replace(e.Description))
+		}
+		if e.Status != "" {
+			write("STATUS", e.Status)
+		}
+		if e.Transparent {
+			write("TRANSP", "TRANSPARENT")
+		} else {
+			write("TRANSP", "OPAQUE")
+		}
+		write("END", "VEVENT")
+	}
+	write("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// writeFolded writes a content line, breaking it every 75 octets without splitting a
+// UTF-8 sequence. Continuation lines start with a space, which counts toward their 75.
+func writeFolded(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line, limit = line[cut:], 74
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
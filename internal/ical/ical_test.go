@@ -0,0 +1,178 @@
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Calendar//EN\r\n" +
+	"X-WR-CALNAME:Shoots\\, 2026\r\n" +
+	"X-WR-TIMEZONE:America/Chicago\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:America/Denver\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:19701101T020000\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:one@example.com\r\n" +
+	"DTSTART;TZID=America/Denver:20261102T090000\r\n" +
+	"DTEND;TZID=America/Denver:20261102T170000\r\n" +
+	"SUMMARY:Commercial shoot\\; day one\r\n" +
+	"DESCRIPTION:Call time 8am\\nBring the long\r\n" +
+	"  lenses\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:two@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20261110\r\n" +
+	"DTEND;VALUE=DATE:20261112\r\n" +
+	"STATUS:TENTATIVE\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\n" +
+	"UID:three@example.com\n" +
+	"DTSTART:20261120T150000Z\n" +
+	"DURATION:PT1H30M\n" +
+	"TRANSP:TRANSPARENT\n" +
+	"END:VEVENT\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:four@example.com\r\n" +
+	"DTSTART:20261121T100000\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	cal, err := Parse(strings.NewReader(sample), time.UTC)
+	require.NoError(t, err)
+
+	denver, _ := time.LoadLocation("America/Denver")
+	chicago, _ := time.LoadLocation("America/Chicago")
+
+	assert.Equal(t, "-//Example//Calendar//EN", cal.ProdID)
+	assert.Equal(t, "Shoots, 2026", cal.Name)
+	require.Len(t, cal.Events, 4)
+
+	one := cal.Events[0]
+	assert.Equal(t, "one@example.com", one.UID)
+	assert.Equal(t, "Commercial shoot; day one", one.Summary)
+	assert.Equal(t, "Call time 8am\nBring the long lenses", one.Description)
+	assert.True(t, one.Start.Equal(time.Date(2026, 11, 2, 9, 0, 0, 0, denver)))
+	assert.True(t, one.End.Equal(time.Date(2026, 11, 2, 17, 0, 0, 0, denver)))
+	assert.False(t, one.AllDay)
+
+	two := cal.Events[1]
+	assert.True(t, two.AllDay)
+	assert.Equal(t, StatusTentative, two.Status)
+	assert.True(t, two.Start.Equal(time.Date(2026, 11, 10, 0, 0, 0, 0, chicago)))
+	assert.True(t, two.End.Equal(time.Date(2026, 11, 12, 0, 0, 0, 0, chicago)))
+
+	three := cal.Events[2]
+	assert.True(t, three.Transparent)
+	assert.Equal(t, 90*time.Minute, three.End.Sub(three.Start))
+	assert.Equal(t, time.UTC, three.Start.Location())
+
+	four := cal.Events[3]
+	assert.True(t, four.Start.Equal(time.Date(2026, 11, 21, 10, 0, 0, 0, chicago)), "floating times use X-WR-TIMEZONE")
+	assert.Equal(t, four.Start, four.End)
+}
+
+func TestParseRejectsMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"not a calendar", "BEGIN:VCARD\r\nEND:VCARD\r\n"},
+		{"unterminated", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n"},
+		{"no start", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:x\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"},
+		{"bad time", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:tomorrow\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"},
+		{"bad duration", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20261120T150000Z\r\nDURATION:1H\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"},
+		{"ends first", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20261120T150000Z\r\nDTEND:20261120T140000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"},
+		{"no value", "BEGIN:VCALENDAR\r\nGARBAGE\r\nEND:VCALENDAR\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.data), time.UTC)
+			assert.True(t, errors.Is(err, ErrInvalid), "got %v", err)
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"PT1H30M", 90 * time.Minute},
+		{"P1D", 24 * time.Hour},
+		{"P2W", 14 * 24 * time.Hour},
+		{"P1DT12H", 36 * time.Hour},
+		{"-PT15M", -15 * time.Minute},
+		{"+PT10S", 10 * time.Second},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.value)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	start := time.Date(2026, 11, 2, 15, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		ProdID: "-//ShotSeek//Availability//EN",
+		Name:   "Avery's availability",
+		Events: []Event{
+			{
+				UID:         "1@shotseek",
+				Summary:     "Booked",
+				Description: strings.Repeat("Long note, with commas; and semicolons. ", 5) + "Überlänge ✓",
+				Start:       start,
+				End:         start.Add(8 * time.Hour),
+				Status:      StatusConfirmed,
+			},
+			{
+				UID:         "2@shotseek",
+				Start:       time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC),
+				End:         time.Date(2026, 11, 12, 0, 0, 0, 0, time.UTC),
+				AllDay:      true,
+				Transparent: true,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, cal))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+	}
+
+	parsed, err := Parse(&buf, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, cal.Name, parsed.Name)
+	require.Len(t, parsed.Events, 2)
+	for i, want := range cal.Events {
+		got := parsed.Events[i]
+		assert.Equal(t, want.UID, got.UID)
+		assert.Equal(t, want.Summary, got.Summary)
+		assert.Equal(t, want.Description, got.Description)
+		assert.True(t, want.Start.Equal(got.Start))
+		assert.True(t, want.End.Equal(got.End))
+		assert.Equal(t, want.AllDay, got.AllDay)
+		assert.Equal(t, want.Status, got.Status)
+		assert.Equal(t, want.Transparent, got.Transparent)
+		assert.False(t, got.Stamp.IsZero())
+	}
+}
@@ -0,0 +1,93 @@
+package location
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/metrics"
+	"github.com/michaelhoman/ShotSeek/internal/store"
+	"github.com/michaelhoman/ShotSeek/internal/tracing"
+)
+
+// ErrNoResults is returned when the geocoder knows nothing about a ZIP code
+var ErrNoResults = errors.New("no location found")
+
+// Geocoder resolves a US ZIP code to a general location
+type Geocoder interface {
+	LookupZip(ctx context.Context, zip string) (*store.Location, error)
+}
+
+const (
+	nominatimBaseURL   = "https://nominatim.openstreetmap.org"
+	nominatimUserAgent = "ShotSeek/1.0 (mthomanmt@gmail.com)"
+)
+
+// NominatimGeocoder looks ZIP codes up with a search followed by a reverse lookup on the first hit
+type NominatimGeocoder struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewNominatimGeocoder() *NominatimGeocoder {
+	return &NominatimGeocoder{
+		baseURL: nominatimBaseURL,
+		client:  &http.Client{Transport: tracing.Transport(http.DefaultTransport)},
+	}
+}
+
+func (g *NominatimGeocoder) LookupZip(ctx context.Context, zip string) (*store.Location, error) {
+	params := url.Values{}
+	params.Set("postalcode", zip)
+	params.Set("country", "USA")
+	params.Set("format", "json")
+
+	var results []NominatimResult
+	if err := g.get(ctx, "search", "/search?"+params.Encode(), &results); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, ErrNoResults
+	}
+
+	params = url.Values{}
+	params.Set("lat", results[0].Lat)
+	params.Set("lon", results[0].Lon)
+	params.Set("format", "json")
+
+	var reverseResult NominatimResult
+	if err := g.get(ctx, "reverse", "/reverse?"+params.Encode(), &reverseResult); err != nil {
+		return nil, err
+	}
+
+	return LocationFromNominatim(&reverseResult)
+}
+
+// get sends one request to Nominatim and decodes the JSON response into dst
+func (g *NominatimGeocoder) get(ctx context.Context, operation, path string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	// Nominatim's usage policy requires an identifying User-Agent
+	req.Header.Set("User-Agent", nominatimUserAgent)
+
+	start := time.Now()
+	resp, err := g.client.Do(req)
+	metrics.ObserveGeocode(operation, start, err)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("nominatim %s error: %s", operation, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
@@ -35,7 +35,6 @@ type NominatimResult struct {
 
 // LocationFromNominatim converts a NominatimResult into a Location struct
 func LocationFromNominatim(n *NominatimResult) (*store.Location, error) {
-	fmt.Println("LocationFromNominatim: Converting Nominatim result to Location struct\n\n", n)
 	lat, err := strconv.ParseFloat(n.Lat, 64) // 🔧 was `result.Lat` — now fixed to `n.Lat`
 	if err != nil {
 		return nil, fmt.Errorf("invalid latitude: %w", err)
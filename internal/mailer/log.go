@@ -0,0 +1,23 @@
+package mailer
+
+import "go.uber.org/zap"
+
+// LogMailer writes rendered emails to the log instead of sending them. It is used when no
+// SendGrid API key is configured so registration still works in development.
+type LogMailer struct {
+	logger *zap.SugaredLogger
+}
+
+func NewLogMailer(logger *zap.SugaredLogger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(templateFile, first_name, last_name, email string, data any, isSandbox bool) error {
+	subject, body, err := render(templateFile, data)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Infow("email not sent, no mail provider configured", "template", templateFile, "subject", subject, "body", body)
+	return nil
+}
@@ -1,5 +1,41 @@
 package mailer
 
+import (
+	"bytes"
+	"embed"
+	"text/template"
+)
+
+const (
+	FromName               = "ShotSeek"
+	maxRetries             = 3
+	UserInvitationTemplate = "user_invitation.tmpl"
+)
+
+//go:embed templates
+var FS embed.FS
+
+// Client sends a templated email. Each template defines a "subject" and a "body" block.
 type Client interface {
 	Send(templateFile, first_name, last_name, email string, data any, isSandbox bool) error
 }
+
+// render executes the subject and body blocks of templateFile
+func render(templateFile string, data any) (string, string, error) {
+	tmpl, err := template.ParseFS(FS, "templates/"+templateFile)
+	if err != nil {
+		return "", "", err
+	}
+
+	subject := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(subject, "subject", data); err != nil {
+		return "", "", err
+	}
+
+	body := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(body, "body", data); err != nil {
+		return "", "", err
+	}
+
+	return subject.String(), body.String(), nil
+}
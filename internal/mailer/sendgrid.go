@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sendgrid/sendgrid-go"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+type SendGridMailer struct {
+	fromEmail string
+	apiKey    string
+	client    *sendgrid.Client
+}
+
+func NewSendgrid(apiKey, fromEmail string) *SendGridMailer {
+	return &SendGridMailer{
+		fromEmail: fromEmail,
+		apiKey:    apiKey,
+		client:    sendgrid.NewSendClient(apiKey),
+	}
+}
+
+// Send renders templateFile and delivers it, retrying with a linear backoff. In sandbox mode
+// SendGrid validates the message without delivering it.
+func (m *SendGridMailer) Send(templateFile, first_name, last_name, email string, data any, isSandbox bool) error {
+	subject, body, err := render(templateFile, data)
+	if err != nil {
+		return err
+	}
+
+	from := mail.NewEmail(FromName, m.fromEmail)
+	to := mail.NewEmail(strings.TrimSpace(first_name+" "+last_name), email)
+	message := mail.NewSingleEmail(from, subject, to, body, "")
+	message.SetMailSettings(&mail.MailSettings{
+		SandboxMode: &mail.Setting{Enable: &isSandbox},
+	})
+
+	var lastErr error
+	for i := 0; i < maxRetries; i++ {
+		response, err := m.client.Send(message)
+		switch {
+		case err != nil:
+			lastErr = err
+		case response.StatusCode >= 300:
+			lastErr = fmt.Errorf("sendgrid responded with status %d", response.StatusCode)
+		default:
+			return nil
+		}
+
+		time.Sleep(time.Second * time.Duration(i+1))
+	}
+
+	return fmt.Errorf("failed to send email after %d attempts: %w", maxRetries, lastErr)
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import "sync"
+
+// Swapper is a Client whose underlying client can be replaced while it is in use, so a
+// rotated SendGrid key takes effect without a restart
+type Swapper struct {
+	mu     sync.RWMutex
+	client Client
+}
+
+func NewSwapper(client Client) *Swapper {
+	return &Swapper{client: client}
+}
+
+// Swap makes client handle every Send from now on. Sends already under way finish with the old one.
+func (s *Swapper) Swap(client Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = client
+}
+
+func (s *Swapper) Send(templateFile, first_name, last_name, email string, data any, isSandbox bool) error {
+	s.mu.RLock()
+	client := s.client
+	s.mu.RUnlock()
+	return client.Send(templateFile, first_name, last_name, email, data, isSandbox)
+}
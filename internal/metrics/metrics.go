@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "shotseek"
+
+// Auth event names recorded by AuthEvent
+const (
+	AuthLogin          = "login"
+	AuthLoginFailure   = "login_failure"
+	AuthRefresh        = "refresh"
+	AuthRefreshFailure = "refresh_failure"
+)
+
+// Registry holds every ShotSeek metric; it is served by Handler on the admin listener
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by method, chi route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and chi route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being served.",
+	})
+
+	geocoderRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "geocoder",
+		Name:      "requests_total",
+		Help:      "Number of outbound Nominatim calls by operation and outcome.",
+	}, []string{"operation", "outcome"})
+
+	geocoderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "geocoder",
+		Name:      "request_duration_seconds",
+		Help:      "Outbound Nominatim call latency by operation.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2, 5, 10},
+	}, []string{"operation"})
+
+	authEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "events_total",
+		Help:      "Number of authentication events (logins, failures, refreshes).",
+	}, []string{"event"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ratelimit",
+		Name:      "rejected_total",
+		Help:      "Number of requests rejected with 429 by rate limit policy.",
+	}, []string{"policy"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		httpInFlight,
+		geocoderRequests,
+		geocoderDuration,
+		authEvents,
+		rateLimited,
+	)
+}
+
+// RegisterDB exposes the sql.DB.Stats() connection pool gauges for db under the given name
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Middleware records request counts, latencies and status codes per chi route pattern.
+// The pattern is read after the request is served, so it must be mounted on the root router.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+
+		httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ObserveGeocode records a single outbound geocoder call started at start
+func ObserveGeocode(operation string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	geocoderRequests.WithLabelValues(operation, outcome).Inc()
+	geocoderDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+// AuthEvent increments the counter for one of the Auth* event names
+func AuthEvent(event string) {
+	authEvents.WithLabelValues(event).Inc()
+}
+
+// RateLimited increments the rejection counter for the named rate limit policy
+func RateLimited(policy string) {
+	rateLimited.WithLabelValues(policy).Inc()
+}
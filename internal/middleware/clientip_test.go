@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.5"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		expectedIP   string
+	}{
+		{"direct client", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"untrusted peer cannot spoof", "203.0.113.7:5000", "1.1.1.1", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:443", "198.51.100.4", "198.51.100.4"},
+		{"client prepended value is ignored", "10.0.0.2:443", "1.1.1.1, 198.51.100.4", "198.51.100.4"},
+		{"chain of trusted proxies", "10.0.0.2:443", "198.51.100.4, 192.168.1.5, 10.1.1.1", "198.51.100.4"},
+		{"malformed hop stops the walk", "10.0.0.2:443", "198.51.100.4, garbage", "10.0.0.2"},
+		{"trusted proxy without header", "10.0.0.2:443", "", "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := ClientIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/health", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expectedIP, got)
+		})
+	}
+}
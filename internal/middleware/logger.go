@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/michaelhoman/ShotSeek/internal/tracing"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+// RequestLogger replaces chi's middleware.Logger. It stores a request-scoped zap logger
+// carrying the request ID and trace ID in the context, and writes one structured access
+// log line per request once the route pattern and any user ID are known.
+// Mount it after middleware.RequestID and tracing.Middleware.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		logger := utils.Logger.With(
+			"request_id", middleware.GetReqID(r.Context()),
+			"trace_id", tracing.TraceID(r.Context()),
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		ctx := utils.ContextWithLogger(r.Context(), logger)
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		route := ""
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			route = rctx.RoutePattern()
+		}
+
+		fields := []any{
+			"route", route,
+			"status", status,
+			"bytes", ww.BytesWritten(),
+			"duration", time.Since(start),
+			"remote_ip", r.RemoteAddr,
+			"user_agent", r.UserAgent(),
+		}
+
+		accessLog := utils.LoggerFromCtx(ctx)
+		switch {
+		case status >= http.StatusInternalServerError:
+			accessLog.Errorw("request completed", fields...)
+		case status >= http.StatusBadRequest:
+			accessLog.Warnw("request completed", fields...)
+		default:
+			accessLog.Infow("request completed", fields...)
+		}
+	})
+}
@@ -2,11 +2,11 @@ package middleware
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/michaelhoman/ShotSeek/internal/auth"
+	"github.com/michaelhoman/ShotSeek/internal/metrics"
 	"github.com/michaelhoman/ShotSeek/internal/utils"
 )
 
@@ -18,9 +18,7 @@ const userContextKey contextKey = "user"
 func JwtMiddleware(authHandler *auth.AuthHandler) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			fmt.Printf("Begin JWTMiddleware\n----\n")
 			tokenString, err := auth.ExtractJWTToken(r)
-			fmt.Printf("\nJWTMiddleware - TokenString: %s\n\n", tokenString)
 
 			ip := authHandler.GetIPAddress(r)
 			userAgent := r.UserAgent()
@@ -29,59 +27,60 @@ func JwtMiddleware(authHandler *auth.AuthHandler) func(http.Handler) http.Handle
 			var claims *auth.Claims // <-- shared claims variable
 
 			if err != nil {
-				utils.Logger.Info("JWT invalid or missing, checking refresh token...")
+				utils.LoggerFromCtx(r.Context()).Info("JWT invalid or missing, checking refresh token...")
 
 				refreshCookie, err := r.Cookie("refresh_token")
 				if err != nil {
-					utils.Logger.Warn("No refresh token found. Rejecting.")
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					utils.LoggerFromCtx(r.Context()).Warn("No refresh token found. Rejecting.")
+					utils.UnauthorizedErrorResponse(w, r, err)
 					return
 				}
 
 				refreshToken := refreshCookie.Value
 				userEmail, err := authHandler.ValidateRefreshTokenByHash(r, authHandler.HashToken(refreshToken))
 				if err != nil {
-					utils.Logger.Warn("Refresh token invalid. Rejecting.")
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					metrics.AuthEvent(metrics.AuthRefreshFailure)
+					utils.LoggerFromCtx(r.Context()).Warn("Refresh token invalid. Rejecting.")
+					utils.UnauthorizedErrorResponse(w, r, err)
 					return
 				}
 
 				// Generate new tokens
 				newAuthToken, err := authHandler.GenerateJWTWithFP(userEmail, requestFingerprint)
 				if err != nil {
-					utils.Logger.Warn("Failed to generate new JWT.")
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					utils.LoggerFromCtx(r.Context()).Warn("Failed to generate new JWT.")
+					utils.UnauthorizedErrorResponse(w, r, err)
 					return
 				}
 
 				newRefreshToken, err := authHandler.GenerateRefreshToken()
 				if err != nil {
-					utils.Logger.Warn("Failed to generate new refresh token.")
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					utils.LoggerFromCtx(r.Context()).Warn("Failed to generate new refresh token.")
+					utils.UnauthorizedErrorResponse(w, r, err)
 					return
 				}
 
 				setAuthCookies(w, newAuthToken, newRefreshToken, authHandler)
+				metrics.AuthEvent(metrics.AuthRefresh)
 
 				// Validate the newly issued token to extract claims
 				claims, err = authHandler.ValidateJWT(r, newAuthToken, requestFingerprint)
 				if err != nil {
-					utils.Logger.Warn("New JWT validation failed after refresh.")
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					utils.LoggerFromCtx(r.Context()).Warn("New JWT validation failed after refresh.")
+					utils.UnauthorizedErrorResponse(w, r, err)
 					return
 				}
 			} else {
 				// Original token was present, so validate it here
 				claims, err = authHandler.ValidateJWT(r, tokenString, requestFingerprint)
 				if err != nil {
-					utils.Logger.Warn("Invalid original JWT.")
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					utils.LoggerFromCtx(r.Context()).Warn("Invalid original JWT.")
+					utils.UnauthorizedErrorResponse(w, r, err)
 					return
 				}
 			}
 
-			fmt.Printf("JWT Fingerprint: %s\n", claims.Fingerprint)
-			fmt.Printf("Request Fingerprint: %s\n", requestFingerprint)
+			utils.AddLogFields(r.Context(), "user_id", claims.Subject)
 
 			// Store claims in context and continue
 			ctx := context.WithValue(r.Context(), userContextKey, claims)
@@ -91,6 +90,20 @@ func JwtMiddleware(authHandler *auth.AuthHandler) func(http.Handler) http.Handle
 	}
 }
 
+// GetClaimsFromCtx returns the claims stored by JwtMiddleware, or nil outside an authenticated route
+func GetClaimsFromCtx(ctx context.Context) *auth.Claims {
+	claims, _ := ctx.Value(userContextKey).(*auth.Claims)
+	return claims
+}
+
+// UserIDFromRequest returns the authenticated user's ID, or "" when the request is anonymous
+func UserIDFromRequest(r *http.Request) string {
+	if claims := GetClaimsFromCtx(r.Context()); claims != nil {
+		return claims.Subject
+	}
+	return ""
+}
+
 func setAuthCookies(w http.ResponseWriter, authToken, refreshToken string, authHandler *auth.AuthHandler) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "auth_token",
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+// Recoverer replaces chi's middleware.Recoverer so panics are logged with their stack
+// and answered with a problem details body instead of an empty 500
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+			// Let net/http abort the connection as it would without us
+			if rvr == http.ErrAbortHandler {
+				panic(rvr)
+			}
+
+			utils.LoggerFromCtx(r.Context()).Errorw("panic recovered", "panic", rvr, "stack", string(debug.Stack()))
+			if r.Header.Get("Connection") != "Upgrade" {
+				utils.InternalServerError(w, r, fmt.Errorf("panic: %v", rvr))
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
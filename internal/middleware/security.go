@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/cors"
+	"github.com/michaelhoman/ShotSeek/internal/config"
+)
+
+// apiContentSecurityPolicy suits JSON responses, which never load or embed anything
+const apiContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// SecurityHeaders sets protective headers on every response. HSTS is only sent when the server
+// itself terminates TLS, since browsers ignore it over plain HTTP and it must not leak onto a
+// development host. Routes serving HTML replace the CSP with ContentSecurityPolicy.
+func SecurityHeaders(httpsEnabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("X-Frame-Options", "DENY")
+			h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+			h.Set("Cross-Origin-Opener-Policy", "same-origin")
+			h.Set("Content-Security-Policy", apiContentSecurityPolicy)
+			if httpsEnabled {
+				h.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ContentSecurityPolicy overrides the default API policy for routes that render pages
+func ContentSecurityPolicy(policy string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Security-Policy", policy)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CORS allows the configured frontend origins to call the API with cookies. With no origins
+// configured no CORS headers are sent and browsers keep the API same-origin only.
+// A wildcard origin is refused because browsers would then send our auth cookies
+// along with requests from any site.
+func CORS(cfg config.CORSConfig) (func(http.Handler) http.Handler, error) {
+	var origins []string
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+		if strings.Contains(origin, "*") {
+			return nil, errors.New("CORS_ALLOWED_ORIGINS must list explicit origins when credentials are allowed")
+		}
+		origins = append(origins, origin)
+	}
+	if len(origins) == 0 {
+		// cors.Handler treats an empty list as "allow all", so skip it entirely
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+
+	return cors.Handler(cors.Options{
+		AllowedOrigins:   origins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key", "X-Request-Id", "If-Match", "If-None-Match"},
+		ExposedHeaders:   []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", "ETag", "Link"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}), nil
+}
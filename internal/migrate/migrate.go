@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/michaelhoman/ShotSeek/cmd/migrate/migrations"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// ErrSchemaBehind is returned by EnsureCurrent when embedded migrations have not been applied
+var ErrSchemaBehind = errors.New("database schema is behind the embedded migrations")
+
+// Runner applies the embedded migrations. Every operation holds a Postgres advisory lock,
+// so replicas started together with --migrate-on-start apply each migration exactly once.
+type Runner struct {
+	provider *goose.Provider
+}
+
+func NewRunner(db *sql.DB) (*Runner, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("creating migration lock: %w", err)
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, fmt.Errorf("loading migrations: %w", err)
+	}
+	return &Runner{provider: provider}, nil
+}
+
+// Up applies every pending migration
+func (r *Runner) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	return r.provider.Up(ctx)
+}
+
+// Down rolls back the most recent migration
+func (r *Runner) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	return r.provider.Down(ctx)
+}
+
+// Redo rolls back the most recent migration and applies it again
+func (r *Runner) Redo(ctx context.Context) ([]*goose.MigrationResult, error) {
+	down, err := r.provider.Down(ctx)
+	if err != nil {
+		return nil, err
+	}
+	up, err := r.provider.UpByOne(ctx)
+	if err != nil {
+		return []*goose.MigrationResult{down}, err
+	}
+	return []*goose.MigrationResult{down, up}, nil
+}
+
+// To migrates up or down until version is the latest applied migration
+func (r *Runner) To(ctx context.Context, version int64) ([]*goose.MigrationResult, error) {
+	current, err := r.provider.GetDBVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version >= current {
+		return r.provider.UpTo(ctx, version)
+	}
+	return r.provider.DownTo(ctx, version)
+}
+
+// Status lists every embedded migration and whether it has been applied
+func (r *Runner) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return r.provider.Status(ctx)
+}
+
+// Versions returns the latest applied and the latest embedded migration version
+func (r *Runner) Versions(ctx context.Context) (current, latest int64, err error) {
+	return r.provider.GetVersions(ctx)
+}
+
+// EnsureCurrent returns ErrSchemaBehind when any embedded migration is still pending
+func (r *Runner) EnsureCurrent(ctx context.Context) error {
+	pending, err := r.provider.HasPending(ctx)
+	if err != nil {
+		return fmt.Errorf("checking migrations: %w", err)
+	}
+	if pending {
+		current, latest, err := r.provider.GetVersions(ctx)
+		if err != nil {
+			return ErrSchemaBehind
+		}
+		return fmt.Errorf("%w: at version %d, need %d; run `go run ./cmd/migrate up` or start with --migrate-on-start", ErrSchemaBehind, current, latest)
+	}
+	return nil
+}
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/metrics"
+	"github.com/michaelhoman/ShotSeek/internal/tracing"
 )
 
 type NominatimResult struct {
@@ -39,7 +42,10 @@ func LookupByZip(zip string) (*NominatimResult, error) {
 	}
 	req.Header.Set("User-Agent", "ShotSeek/1.0 (you@example.com)")
 
-	resp, err := http.DefaultClient.Do(req)
+	start := time.Now()
+	client := &http.Client{Transport: tracing.Transport(http.DefaultTransport)}
+	resp, err := client.Do(req)
+	metrics.ObserveGeocode("search", start, err)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,148 @@
+// Package pagination implements keyset pagination for list endpoints. Clients page with an
+// opaque cursor naming the last row they saw by (created_at, id), or by (score, id) for ranked
+// results, so pages stay stable while rows are inserted and the database never has to skip
+// over an OFFSET.
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the sort key of the last row on a page. Score is only set by ranked
+// listings such as search, which order on it before the ID.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+	Score     float64
+}
+
+// Encode returns the cursor in its opaque form. Clients must treat it as a token and
+// only ever send back what they received.
+func (c Cursor) Encode() string {
+	raw := fmt.Sprintf("%d.%d", c.CreatedAt.UnixNano(), c.ID)
+	if c.Score != 0 {
+		raw += "~" + strconv.FormatFloat(c.Score, 'g', -1, 64)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode parses a cursor produced by Encode
+func Decode(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	key, score, ranked := strings.Cut(string(raw), "~")
+	var cursor Cursor
+	if ranked {
+		if cursor.Score, err = strconv.ParseFloat(score, 64); err != nil {
+			return Cursor{}, ErrInvalidCursor
+		}
+	}
+	ts, id, ok := strings.Cut(key, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || rowID < 1 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	cursor.CreatedAt = time.Unix(0, nanos).UTC()
+	cursor.ID = rowID
+	return cursor, nil
+}
+
+// Params is the page a client asked for. After is nil for the first page.
+type Params struct {
+	Limit int
+	After *Cursor
+}
+
+// FromRequest reads the limit and cursor query parameters. A missing limit means
+// DefaultLimit and anything above MaxLimit is capped rather than rejected.
+func FromRequest(r *http.Request) (Params, error) {
+	query := r.URL.Query()
+	params := Params{Limit: DefaultLimit}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return Params{}, utils.InvalidQueryParam("limit", "min", "must be a whole number of at least 1")
+		}
+		params.Limit = min(limit, MaxLimit)
+	}
+
+	if raw := query.Get("cursor"); raw != "" {
+		cursor, err := Decode(raw)
+		if err != nil {
+			return Params{}, utils.InvalidQueryParam("cursor", "cursor", "must be a next_cursor returned by a previous page")
+		}
+		params.After = &cursor
+	}
+	return params, nil
+}
+
+// Page is one page of results. Next is nil on the last page.
+type Page[T any] struct {
+	Items []T
+	Next  *Cursor
+}
+
+// NewPage builds a page from rows fetched with a limit of one more than requested;
+// the extra row only tells us that another page exists and is not returned.
+func NewPage[T any](rows []T, limit int, key func(T) Cursor) Page[T] {
+	if rows == nil {
+		rows = []T{}
+	}
+	if len(rows) <= limit {
+		return Page[T]{Items: rows}
+	}
+	rows = rows[:limit]
+	next := key(rows[limit-1])
+	return Page[T]{Items: rows, Next: &next}
+}
+
+// Write sends the page in the usual data envelope with next_cursor, and advertises the
+// following page in a Link header as well
+func Write[T any](w http.ResponseWriter, r *http.Request, page Page[T]) error {
+	return utils.JsonPageResponse(w, http.StatusOK, page.Items, SetLink(w, r, page.Next))
+}
+
+// SetLink sets the Link header for the page after next and returns its encoded cursor,
+// or "" on the last page. Handlers that wrap a page in a larger response use it directly.
+func SetLink(w http.ResponseWriter, r *http.Request, next *Cursor) string {
+	if next == nil {
+		return ""
+	}
+	cursor := next.Encode()
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, NextURL(r, cursor)))
+	return cursor
+}
+
+// NextURL is the request URL with its cursor replaced, keeping every filter the client sent
+func NextURL(r *http.Request, cursor string) string {
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
@@ -0,0 +1,110 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC), ID: 42}
+
+	decoded, err := Decode(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+}
+
+func TestRankedCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{ID: 42, Score: float64(float32(0.0607927))}
+
+	decoded, err := Decode(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor.Score, decoded.Score)
+	assert.Equal(t, cursor.ID, decoded.ID)
+
+	_, err = Decode(base64.RawURLEncoding.EncodeToString([]byte("0.42~high")))
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestDecodeRejectsGarbage(t *testing.T) {
+	for _, raw := range []string{"", "!!!", "bm90LWEtY3Vyc29y", "MTIz", "MS4w"} {
+		_, err := Decode(raw)
+		assert.ErrorIs(t, err, ErrInvalidCursor, raw)
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	valid := Cursor{CreatedAt: time.Unix(1700000000, 0), ID: 7}.Encode()
+
+	tests := []struct {
+		name      string
+		query     string
+		wantLimit int
+		wantAfter bool
+		wantField string
+	}{
+		{name: "defaults", query: "", wantLimit: DefaultLimit},
+		{name: "explicit limit", query: "limit=5", wantLimit: 5},
+		{name: "limit is capped", query: "limit=1000", wantLimit: MaxLimit},
+		{name: "cursor", query: "cursor=" + valid, wantLimit: DefaultLimit, wantAfter: true},
+		{name: "zero limit", query: "limit=0", wantField: "limit"},
+		{name: "non numeric limit", query: "limit=ten", wantField: "limit"},
+		{name: "bad cursor", query: "cursor=abc", wantField: "cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := FromRequest(httptest.NewRequest("GET", "/v1/posts?"+tt.query, nil))
+			if tt.wantField != "" {
+				var appErr *utils.AppError
+				require.True(t, errors.As(err, &appErr))
+				assert.Equal(t, utils.CodeValidationFailed, appErr.Code)
+				require.Len(t, appErr.Fields, 1)
+				assert.Equal(t, tt.wantField, appErr.Fields[0].Field)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLimit, params.Limit)
+			assert.Equal(t, tt.wantAfter, params.After != nil)
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	key := func(id int64) Cursor { return Cursor{ID: id} }
+
+	page := NewPage([]int64{3, 2, 1}, 2, key)
+	assert.Equal(t, []int64{3, 2}, page.Items)
+	require.NotNil(t, page.Next)
+	assert.Equal(t, int64(2), page.Next.ID)
+
+	page = NewPage([]int64{3, 2}, 2, key)
+	assert.Equal(t, []int64{3, 2}, page.Items)
+	assert.Nil(t, page.Next)
+
+	page = NewPage[int64](nil, 2, key)
+	assert.NotNil(t, page.Items)
+	assert.Nil(t, page.Next)
+}
+
+func TestWrite(t *testing.T) {
+	next := Cursor{CreatedAt: time.Unix(1700000000, 0), ID: 9}
+	r := httptest.NewRequest("GET", "/v1/posts?tag=a&tag=b&limit=2&cursor=old", nil)
+	w := httptest.NewRecorder()
+
+	require.NoError(t, Write(w, r, Page[int]{Items: []int{1, 2}, Next: &next}))
+	assert.JSONEq(t, `{"data":[1,2],"next_cursor":"`+next.Encode()+`"}`, w.Body.String())
+	assert.Equal(t, `</v1/posts?cursor=`+next.Encode()+`&limit=2&tag=a&tag=b>; rel="next"`, w.Header().Get("Link"))
+
+	w = httptest.NewRecorder()
+	require.NoError(t, Write(w, r, Page[int]{Items: []int{}}))
+	assert.JSONEq(t, `{"data":[],"next_cursor":null}`, w.Body.String())
+	assert.Empty(t, w.Header().Get("Link"))
+}
@@ -3,30 +3,102 @@ package postgres_db
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"sync"
 	"time"
+
+	"github.com/lib/pq"
 )
 
-func New(addr string, maxOpenConns, maxIdleConns int, maxIdleTime string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", addr)
-	if err != nil {
-		return nil, err
+func New(addr string, maxOpenConns, maxIdleConns int, maxIdleTime time.Duration) (*sql.DB, error) {
+	db, _, err := NewRotatable(addr, maxOpenConns, maxIdleConns, maxIdleTime)
+	return db, err
+}
+
+// NewRotatable is New for a pool whose address, and so its password, can be swapped later
+// through the returned Connector without reopening the pool
+func NewRotatable(addr string, maxOpenConns, maxIdleConns int, maxIdleTime time.Duration) (*sql.DB, *Connector, error) {
+	connector := &Connector{maxIdleConns: maxIdleConns}
+	if err := connector.setAddr(addr); err != nil {
+		return nil, nil, err
 	}
+	db := sql.OpenDB(connector)
 
 	db.SetMaxOpenConns(maxOpenConns)
 	db.SetMaxIdleConns(maxIdleConns)
 
-	duration, err := time.ParseDuration(maxIdleTime)
-	if err != nil {
-		return nil, err
-	}
-	db.SetConnMaxIdleTime(duration)
+	db.SetConnMaxIdleTime(maxIdleTime)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err = db.PingContext(ctx); err != nil {
-		return nil, err
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return db, connector, nil
+}
+
+// Connector opens each new connection with the current address
+type Connector struct {
+	maxIdleConns int
+
+	mu   sync.RWMutex
+	addr string
+	pq   *pq.Connector
+}
+
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.RLock()
+	connector := c.pq
+	c.mu.RUnlock()
+	return connector.Connect(ctx)
+}
+
+func (c *Connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+func (c *Connector) setAddr(addr string) error {
+	connector, err := pq.NewConnector(addr)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addr, c.pq = addr, connector
+	return nil
+}
+
+// Rotate switches db, which must have been opened with c, to addr. It first checks that addr
+// accepts a connection, so a bad password leaves the pool as it was, then closes the idle
+// connections so the pool reconnects with the new credentials. Connections busy at the time
+// keep their already authenticated session until the pool retires them. It reports whether
+// the address changed; rotating to the current address does nothing.
+func (c *Connector) Rotate(ctx context.Context, db *sql.DB, addr string) (bool, error) {
+	c.mu.RLock()
+	unchanged := addr == c.addr
+	c.mu.RUnlock()
+	if unchanged {
+		return false, nil
 	}
 
-	return db, nil
+	probe, err := pq.NewConnector(addr)
+	if err != nil {
+		return false, err
+	}
+	conn, err := probe.Connect(ctx)
+	if err != nil {
+		return false, err
+	}
+	conn.Close()
+
+	if err := c.setAddr(addr); err != nil {
+		return false, err
+	}
+	db.SetMaxIdleConns(0)
+	db.SetMaxIdleConns(c.maxIdleConns)
+	return true, nil
 }
@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+)
+
+// KeyFunc identifies the client a request is counted against. An empty string
+// means the KeyFunc does not apply to this request.
+type KeyFunc func(r *http.Request) string
+
+// ByIP keys on the client IP. It reads r.RemoteAddr, so mount it after the
+// middleware that resolves the real client address.
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if host == "" {
+		return ""
+	}
+	return "ip:" + host
+}
+
+// ByUser keys on the authenticated user returned by userID
+func ByUser(userID func(r *http.Request) string) KeyFunc {
+	return func(r *http.Request) string {
+		if id := userID(r); id != "" {
+			return "user:" + id
+		}
+		return ""
+	}
+}
+
+// FirstOf uses the first KeyFunc that returns a key, e.g. FirstOf(ByUser(...), ByIP)
+func FirstOf(funcs ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		for _, fn := range funcs {
+			if key := fn(r); key != "" {
+				return key
+			}
+		}
+		return ""
+	}
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/michaelhoman/ShotSeek/internal/metrics"
+	"github.com/michaelhoman/ShotSeek/internal/utils"
+)
+
+// Limiter applies named policies to routes. A Limiter without a store lets every request through,
+// which is how rate limiting is switched off.
+type Limiter struct {
+	store    Store
+	policies map[string]Policy
+}
+
+func NewLimiter(store Store, policies ...Policy) *Limiter {
+	l := &Limiter{store: store, policies: make(map[string]Policy, len(policies))}
+	for _, p := range policies {
+		l.policies[p.Name] = p
+	}
+	return l
+}
+
+// Middleware limits requests with the named policy, counting them against the client
+// returned by key. Requests key cannot identify are not limited. Each policy has its own
+// buckets, so a route can sit under both a broad default policy and a stricter one.
+// If the store fails the request is let through, since an outage of the limiter should
+// not become an outage of the API.
+func (l *Limiter) Middleware(policyName string, key KeyFunc) func(http.Handler) http.Handler {
+	policy, ok := l.policies[policyName]
+	if !ok {
+		panic(fmt.Sprintf("ratelimit: unknown policy %q", policyName))
+	}
+
+	return func(next http.Handler) http.Handler {
+		if l.store == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := key(r)
+			if client == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := l.store.Take(r.Context(), policy.Name+":"+client, policy)
+			if err != nil {
+				utils.LoggerFromCtx(r.Context()).Warnw("rate limit store failed, allowing request", "policy", policy.Name, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			setHeaders(w, policy, res)
+			if !res.Allowed {
+				metrics.RateLimited(policy.Name)
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				utils.WriteProblem(w, r, utils.NewAppError(http.StatusTooManyRequests, utils.CodeRateLimited,
+					fmt.Sprintf("Rate limit of %d requests per %s exceeded", policy.Limit, policy.Period)))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setHeaders writes the IETF RateLimit header fields (draft-ietf-httpapi-ratelimit-headers)
+func setHeaders(w http.ResponseWriter, policy Policy, res Result) {
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Period)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
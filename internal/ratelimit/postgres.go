@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so every API instance
+// shares the same limits. Each Take locks the bucket row for the length of a short
+// transaction and uses the database clock, so instance clock skew does not matter.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("beginning rate limit transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// A new bucket starts full; ON CONFLICT keeps an existing one untouched
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (key) DO NOTHING
+	`, key, policy.Limit)
+	if err != nil {
+		return Result{}, fmt.Errorf("creating rate limit bucket: %w", err)
+	}
+
+	var tokens float64
+	var last, now time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT tokens, updated_at, now()
+		FROM rate_limit_buckets
+		WHERE key = $1
+		FOR UPDATE
+	`, key).Scan(&tokens, &last, &now)
+	if err != nil {
+		return Result{}, fmt.Errorf("reading rate limit bucket: %w", err)
+	}
+
+	tokens, res := take(tokens, last, now, policy)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1
+	`, key, tokens, now)
+	if err != nil {
+		return Result{}, fmt.Errorf("updating rate limit bucket: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Result{}, fmt.Errorf("committing rate limit bucket: %w", err)
+	}
+	return res, nil
+}
+
+// Prune deletes buckets untouched for longer than idle. Pass at least the longest
+// policy period; a pruned bucket would have been full anyway.
+func (s *PostgresStore) Prune(ctx context.Context, idle time.Duration) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM rate_limit_buckets WHERE updated_at < now() - make_interval(secs => $1)
+	`, idle.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("pruning rate limit buckets: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported values for config.RateLimitConfig.Store
+const (
+	StoreMemory   = "memory"
+	StorePostgres = "postgres"
+)
+
+// Policy allows Limit requests per Period with bursts of up to Limit.
+// Tokens refill continuously, so a client that waits Period/Limit gets one more request.
+type Policy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+}
+
+// ParsePolicy parses a "<limit>/<period>" string such as "10/1m" or "300/1h"
+func ParsePolicy(name, spec string) (Policy, error) {
+	limitStr, periodStr, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("rate limit policy %s: %q is not in <limit>/<period> form", name, spec)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+	if err != nil || limit <= 0 {
+		return Policy{}, fmt.Errorf("rate limit policy %s: invalid limit %q", name, limitStr)
+	}
+	period, err := time.ParseDuration(strings.TrimSpace(periodStr))
+	if err != nil || period <= 0 {
+		return Policy{}, fmt.Errorf("rate limit policy %s: invalid period %q", name, periodStr)
+	}
+	return Policy{Name: name, Limit: limit, Period: period}, nil
+}
+
+// rate returns the number of tokens added per second
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // time until the bucket is full again
+	RetryAfter time.Duration // time until the next token, zero when Allowed
+}
+
+// Store keeps token buckets. Implementations must make Take atomic per key so that
+// limits hold when several API instances share the store.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// take refills a bucket that held tokens at last, then tries to spend one token at now.
+// It is shared by every Store so they agree on the bucket arithmetic.
+func take(tokens float64, last, now time.Time, p Policy) (float64, Result) {
+	elapsed := now.Sub(last).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	tokens = math.Min(float64(p.Limit), tokens+elapsed*p.rate())
+
+	res := Result{Limit: p.Limit}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - tokens) / p.rate())
+	}
+	res.Remaining = int(math.Floor(tokens))
+	res.ResetAfter = secondsToDuration((float64(p.Limit) - tokens) / p.rate())
+	return tokens, res
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
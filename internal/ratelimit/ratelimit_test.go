@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Policy
+		wantErr  bool
+	}{
+		{"10/1m", Policy{Name: "auth", Limit: 10, Period: time.Minute}, false},
+		{" 300 / 1h ", Policy{Name: "auth", Limit: 300, Period: time.Hour}, false},
+		{"10", Policy{}, true},
+		{"0/1m", Policy{}, true},
+		{"10/soon", Policy{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			policy, err := ParsePolicy("auth", tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	policy := Policy{Name: "auth", Limit: 2, Period: time.Minute}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	res, _ := store.Take(ctx, "ip:1.2.3.4", policy)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+
+	res, _ = store.Take(ctx, "ip:1.2.3.4", policy)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, time.Minute, res.ResetAfter)
+
+	res, _ = store.Take(ctx, "ip:1.2.3.4", policy)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 30*time.Second, res.RetryAfter)
+
+	// Other clients have their own bucket
+	res, _ = store.Take(ctx, "ip:5.6.7.8", policy)
+	assert.True(t, res.Allowed)
+
+	// One token refills every Period/Limit
+	now = now.Add(30 * time.Second)
+	res, _ = store.Take(ctx, "ip:1.2.3.4", policy)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+}
+
+func TestLimiterMiddleware(t *testing.T) {
+	policy := Policy{Name: "auth", Limit: 1, Period: time.Minute}
+	limiter := NewLimiter(NewMemoryStore(), policy)
+	handler := limiter.Middleware("auth", ByIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/authentication/login", nil)
+	req.RemoteAddr = "1.2.3.4:5678"
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1;w=60", w.Header().Get("RateLimit-Policy"))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+	// Without a store the limiter is disabled
+	disabled := NewLimiter(nil, policy).Middleware("auth", ByIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for range 3 {
+		w = httptest.NewRecorder()
+		disabled.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	}
+}
@@ -0,0 +1,162 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Reference schemes understood by Resolver. Values without a scheme are literals.
+const (
+	SchemeFile      = "file://" // file://.keys/private_key.pem or file:///run/secrets/db_password
+	SchemeEnv       = "env://"  // env://SENDGRID_API_KEY
+	SchemeEncrypted = "enc://"  // enc://db_password, looked up in the encrypted secrets file
+)
+
+var ErrNotFound = errors.New("secret not found")
+
+// Provider resolves secret references to their value
+type Provider interface {
+	Resolve(ctx context.Context, ref string) ([]byte, error)
+}
+
+// IsRef reports whether value is a secret reference rather than a literal
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, SchemeFile) ||
+		strings.HasPrefix(value, SchemeEnv) ||
+		strings.HasPrefix(value, SchemeEncrypted)
+}
+
+// ResolveString resolves value when it is a reference and returns literals unchanged.
+// A single trailing newline, as left by most editors and `echo`, is trimmed.
+func ResolveString(ctx context.Context, p Provider, value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	b, err := p.Resolve(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimSuffix(string(b), "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
+
+// Resolver is the default Provider. file:// and env:// references are read on every call so
+// rotated files are picked up immediately; the encrypted secrets file is cached until Reload.
+type Resolver struct {
+	encryptedFile string
+	masterKey     []byte
+
+	mu     sync.RWMutex
+	sealed map[string]string
+}
+
+// NewResolver returns a Resolver. encryptedFile may be empty, in which case enc:// references fail.
+func NewResolver(encryptedFile string, masterKey []byte) (*Resolver, error) {
+	r := &Resolver{encryptedFile: encryptedFile, masterKey: masterKey}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewResolverFromEnv configures a Resolver from SECRETS_FILE and the base64 encoded 32 byte
+// master key in SECRETS_MASTER_KEY or the file named by SECRETS_MASTER_KEY_FILE
+func NewResolverFromEnv() (*Resolver, error) {
+	file := os.Getenv("SECRETS_FILE")
+	if file == "" {
+		return NewResolver("", nil)
+	}
+
+	encoded := os.Getenv("SECRETS_MASTER_KEY")
+	if path := os.Getenv("SECRETS_MASTER_KEY_FILE"); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading secrets master key: %w", err)
+		}
+		encoded = string(b)
+	}
+	if encoded == "" {
+		return nil, errors.New("SECRETS_FILE is set but neither SECRETS_MASTER_KEY nor SECRETS_MASTER_KEY_FILE is")
+	}
+
+	key, err := DecodeKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return NewResolver(file, key)
+}
+
+// DecodeKey parses a base64 encoded master key
+func DecodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("decoding secrets master key: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secrets master key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+func (r *Resolver) Resolve(ctx context.Context, ref string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(ref, SchemeFile):
+		path := strings.TrimPrefix(ref, SchemeFile)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading secret %s: %w", ref, err)
+		}
+		return b, nil
+
+	case strings.HasPrefix(ref, SchemeEnv):
+		name := strings.TrimPrefix(ref, SchemeEnv)
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("secret %s: %w", ref, ErrNotFound)
+		}
+		return []byte(val), nil
+
+	case strings.HasPrefix(ref, SchemeEncrypted):
+		name := strings.TrimPrefix(ref, SchemeEncrypted)
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		if r.sealed == nil {
+			return nil, fmt.Errorf("secret %s: no encrypted secrets file configured", ref)
+		}
+		val, ok := r.sealed[name]
+		if !ok {
+			return nil, fmt.Errorf("secret %s: %w", ref, ErrNotFound)
+		}
+		return []byte(val), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported secret reference %q", ref)
+	}
+}
+
+// Reload re-reads and decrypts the encrypted secrets file. On failure the previous
+// secrets are kept so a bad rotation does not take the API down.
+func (r *Resolver) Reload() error {
+	if r.encryptedFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.encryptedFile)
+	if err != nil {
+		return fmt.Errorf("reading secrets file: %w", err)
+	}
+	sealed, err := Open(r.masterKey, data)
+	if err != nil {
+		return fmt.Errorf("opening secrets file %s: %w", r.encryptedFile, err)
+	}
+
+	r.mu.Lock()
+	r.sealed = sealed
+	r.mu.Unlock()
+	return nil
+}
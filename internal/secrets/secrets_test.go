@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSealOpen(t *testing.T) {
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	sealed, err := Seal(key, map[string]string{"db_password": "hunter22"})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(sealed), "hunter22")
+
+	opened, err := Open(key, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter22", opened["db_password"])
+
+	otherKey, _ := GenerateKey()
+	_, err = Open(otherKey, sealed)
+	assert.Error(t, err)
+}
+
+func TestResolver(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	key, _ := GenerateKey()
+
+	sealedPath := filepath.Join(dir, "secrets.enc")
+	writeSealed := func(secrets map[string]string) {
+		sealed, err := Seal(key, secrets)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(sealedPath, sealed, 0o600))
+	}
+	writeSealed(map[string]string{"api_key": "v1"})
+
+	filePath := filepath.Join(dir, "token")
+	assert.NoError(t, os.WriteFile(filePath, []byte("from-file\n"), 0o600))
+	t.Setenv("SECRETS_TEST_VALUE", "from-env")
+
+	r, err := NewResolver(sealedPath, key)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"plain-literal", "plain-literal"},
+		{"file://" + filePath, "from-file"},
+		{"env://SECRETS_TEST_VALUE", "from-env"},
+		{"enc://api_key", "v1"},
+	}
+	for _, tt := range tests {
+		got, err := ResolveString(ctx, r, tt.value)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, got)
+	}
+
+	_, err = ResolveString(ctx, r, "enc://missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Rotated values are only visible after Reload
+	writeSealed(map[string]string{"api_key": "v2"})
+	got, _ := ResolveString(ctx, r, "enc://api_key")
+	assert.Equal(t, "v1", got)
+	assert.NoError(t, r.Reload())
+	got, _ = ResolveString(ctx, r, "enc://api_key")
+	assert.Equal(t, "v2", got)
+
+	// A broken file keeps the previous secrets
+	assert.NoError(t, os.WriteFile(sealedPath, []byte("garbage"), 0o600))
+	assert.Error(t, r.Reload())
+	got, _ = ResolveString(ctx, r, "enc://api_key")
+	assert.Equal(t, "v2", got)
+}
@@ -0,0 +1,295 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+// ApplicationStatus tracks an application from submission to a decision. The poster
+// shortlists, rejects or hires; the applicant can withdraw; and applications still in
+// play are closed when the gig stops being open.
+type ApplicationStatus string
+
+const (
+	ApplicationSubmitted   ApplicationStatus = "submitted"
+	ApplicationShortlisted ApplicationStatus = "shortlisted"
+	ApplicationRejected    ApplicationStatus = "rejected"
+	ApplicationHired       ApplicationStatus = "hired"
+	ApplicationWithdrawn   ApplicationStatus = "withdrawn"
+	ApplicationClosed      ApplicationStatus = "closed"
+)
+
+var applicationTransitions = map[ApplicationStatus][]ApplicationStatus{
+	ApplicationSubmitted:   {ApplicationShortlisted, ApplicationRejected, ApplicationHired, ApplicationWithdrawn, ApplicationClosed},
+	ApplicationShortlisted: {ApplicationRejected, ApplicationHired, ApplicationWithdrawn, ApplicationClosed},
+	ApplicationRejected:    {ApplicationShortlisted},
+	ApplicationHired:       {ApplicationWithdrawn},
+}
+
+// CanBecome reports whether an application in status s may move to next
+func (s ApplicationStatus) CanBecome(next ApplicationStatus) bool {
+	for _, allowed := range applicationTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// open reports whether the application is still waiting on a decision
+func (s ApplicationStatus) open() bool {
+	return s == ApplicationSubmitted || s == ApplicationShortlisted
+}
+
+// applicationRanks orders a poster's applicants: hires, then the shortlist, then new
+// applications, with the ones out of the running last
+var applicationRanks = map[ApplicationStatus]int{
+	ApplicationHired:       1,
+	ApplicationShortlisted: 2,
+	ApplicationSubmitted:   3,
+	ApplicationClosed:      4,
+	ApplicationRejected:    5,
+	ApplicationWithdrawn:   6,
+}
+
+const applicationRankSQL = `CASE a.status
+	WHEN 'hired' THEN 1 WHEN 'shortlisted' THEN 2 WHEN 'submitted' THEN 3
+	WHEN 'closed' THEN 4 WHEN 'rejected' THEN 5 ELSE 6 END`
+
+// Application is a user's bid for a gig. PostTitle, PosterID and the applicant's name in User
+// are read from the gig's post and the applicant.
+type Application struct {
+	ID             int64             `json:"id"`
+	PostID         int64             `json:"post_id"`
+	UserID         uuid.UUID         `json:"user_id"`
+	Message        string            `json:"message"`
+	PortfolioLinks []string          `json:"portfolio_links"`
+	Status         ApplicationStatus `json:"status"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	PostTitle      string            `json:"post_title"`
+	PosterID       uuid.UUID         `json:"poster_id"`
+	User           User              `json:"user"`
+}
+
+// ApplicationFilter narrows a gig's applicant list
+type ApplicationFilter struct {
+	Status ApplicationStatus // empty for every status
+	Page   pagination.Params
+}
+
+type ApplicationStore struct {
+	db DBTX
+}
+
+const applicationColumns = `
+	a.id, a.post_id, a.user_id, a.message, a.portfolio_links, a.status, a.created_at, a.updated_at,
+	p.title, p.user_id, u.first_name, u.last_name
+	FROM applications a
+	JOIN posts p ON p.id = a.post_id
+	JOIN users u ON u.id = a.user_id
+	`
+
+func scanApplication(scan func(dest ...any) error) (Application, error) {
+	var a Application
+	err := scan(
+		&a.ID, &a.PostID, &a.UserID, &a.Message, pq.Array(&a.PortfolioLinks), &a.Status, &a.CreatedAt, &a.UpdatedAt,
+		&a.PostTitle, &a.PosterID, &a.User.FirstName, &a.User.LastName,
+	)
+	return a, err
+}
+
+// Create submits an application, returning ErrConflict when the user already applied to the post
+func (s *ApplicationStore) Create(ctx context.Context, application *Application) error {
+	ctx, span := startSpan(ctx, "ApplicationStore.Create")
+	defer span.End()
+
+	if application.PortfolioLinks == nil {
+		application.PortfolioLinks = []string{}
+	}
+
+	query := `
+	INSERT INTO applications (post_id, user_id, message, portfolio_links)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, status, created_at, updated_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query,
+		application.PostID, application.UserID, application.Message, pq.Array(application.PortfolioLinks),
+	).Scan(&application.ID, &application.Status, &application.CreatedAt, &application.UpdatedAt)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.Constraint == "applications_post_user_key" {
+			return ErrConflict
+		}
+		return fmt.Errorf("inserting application: %w", err)
+	}
+	return nil
+}
+
+func (s *ApplicationStore) GetByID(ctx context.Context, id int64) (*Application, error) {
+	ctx, span := startSpan(ctx, "ApplicationStore.GetByID")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	a, err := scanApplication(s.db.QueryRowContext(ctx, "SELECT"+applicationColumns+"WHERE a.id = $1", id).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListByPost returns a page of a gig's applications, ranked by status and then oldest first
+func (s *ApplicationStore) ListByPost(ctx context.Context, postID int64, filter ApplicationFilter) (pagination.Page[Application], error) {
+	ctx, span := startSpan(ctx, "ApplicationStore.ListByPost")
+	defer span.End()
+
+	query := "SELECT" + applicationColumns + "WHERE a.post_id = $1\n"
+	args := []any{postID}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf("AND a.status = $%d\n", len(args))
+	}
+	if after := filter.Page.After; after != nil {
+		args = append(args, after.Score, after.CreatedAt, after.ID)
+		query += fmt.Sprintf("AND (%s, a.created_at, a.id) > ($%d, $%d, $%d)\n", applicationRankSQL, len(args)-2, len(args)-1, len(args))
+	}
+	// One extra row tells us whether there is another page
+	args = append(args, filter.Page.Limit+1)
+	query += fmt.Sprintf("ORDER BY %s, a.created_at, a.id\nLIMIT $%d", applicationRankSQL, len(args))
+
+	return s.list(ctx, query, args, filter.Page.Limit, rankedApplicationCursor)
+}
+
+// ListByUser returns a page of the user's own applications, newest first
+func (s *ApplicationStore) ListByUser(ctx context.Context, userID uuid.UUID, page pagination.Params) (pagination.Page[Application], error) {
+	ctx, span := startSpan(ctx, "ApplicationStore.ListByUser")
+	defer span.End()
+
+	query := "SELECT" + applicationColumns + "WHERE a.user_id = $1\n"
+	args := []any{userID}
+	if page.After != nil {
+		args = append(args, page.After.CreatedAt, page.After.ID)
+		query += "AND (a.created_at, a.id) < ($2, $3)\n"
+	}
+	args = append(args, page.Limit+1)
+	query += fmt.Sprintf("ORDER BY a.created_at DESC, a.id DESC\nLIMIT $%d", len(args))
+
+	return s.list(ctx, query, args, page.Limit, applicationCursor)
+}
+
+func (s *ApplicationStore) list(ctx context.Context, query string, args []any, limit int, key func(Application) pagination.Cursor) (pagination.Page[Application], error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[Application]{}, err
+	}
+	defer rows.Close()
+
+	applications := []Application{}
+	for rows.Next() {
+		a, err := scanApplication(rows.Scan)
+		if err != nil {
+			return pagination.Page[Application]{}, err
+		}
+		applications = append(applications, a)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[Application]{}, err
+	}
+	return pagination.NewPage(applications, limit, key), nil
+}
+
+func applicationCursor(a Application) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: a.CreatedAt, ID: a.ID}
+}
+
+func rankedApplicationCursor(a Application) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: a.CreatedAt, ID: a.ID, Score: float64(applicationRanks[a.Status])}
+}
+
+// UpdateStatus moves the application to status if it is still in application.Status, returning
+// ErrInvalidTransition for a move the lifecycle does not allow and ErrEditConflict when another
+// request changed the status first
+func (s *ApplicationStore) UpdateStatus(ctx context.Context, application *Application, status ApplicationStatus) error {
+	ctx, span := startSpan(ctx, "ApplicationStore.UpdateStatus")
+	defer span.End()
+
+	if !application.Status.CanBecome(status) {
+		return ErrInvalidTransition
+	}
+
+	query := `
+	UPDATE applications
+	SET status = $1, updated_at = NOW()
+	WHERE id = $2 AND status = $3
+	RETURNING updated_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, status, application.ID, application.Status).Scan(&application.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			var exists bool
+			if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM applications WHERE id = $1)`, application.ID).Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				return ErrEditConflict
+			}
+			return ErrNotFound
+		}
+		return err
+	}
+	application.Status = status
+	return nil
+}
+
+// CloseByPostID closes every application on the post still waiting on a decision and returns them
+func (s *ApplicationStore) CloseByPostID(ctx context.Context, postID int64) ([]Application, error) {
+	ctx, span := startSpan(ctx, "ApplicationStore.CloseByPostID")
+	defer span.End()
+
+	query := `
+	UPDATE applications
+	SET status = 'closed', updated_at = NOW()
+	WHERE post_id = $1 AND status IN ('submitted', 'shortlisted')
+	RETURNING id, post_id, user_id, message, portfolio_links, status, created_at, updated_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	closed := []Application{}
+	for rows.Next() {
+		var a Application
+		err := rows.Scan(&a.ID, &a.PostID, &a.UserID, &a.Message, pq.Array(&a.PortfolioLinks), &a.Status, &a.CreatedAt, &a.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		closed = append(closed, a)
+	}
+	return closed, rows.Err()
+}
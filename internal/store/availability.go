@@ -0,0 +1,306 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// AvailabilityStatus says whether a user can take work during a block
+type AvailabilityStatus string
+
+const (
+	AvailabilityAvailable AvailabilityStatus = "available"
+	AvailabilityTentative AvailabilityStatus = "tentative" // pencilled in, may still fall through
+	AvailabilityBooked    AvailabilityStatus = "booked"
+)
+
+// AvailabilityStatuses lists every availability status
+var AvailabilityStatuses = []AvailabilityStatus{AvailabilityAvailable, AvailabilityTentative, AvailabilityBooked}
+
+func (s AvailabilityStatus) Valid() bool {
+	return slices.Contains(AvailabilityStatuses, s)
+}
+
+// AvailabilitySource says where a block came from
+type AvailabilitySource string
+
+const (
+	AvailabilityManual   AvailabilitySource = "manual"
+	AvailabilityImported AvailabilitySource = "import"  // read from an uploaded calendar, replaced by the next upload
+	AvailabilityBooking  AvailabilitySource = "booking" // kept in step with a held or accepted booking
+)
+
+// AvailabilityBlock is a stretch of a user's time, from StartsAt up to but not including EndsAt.
+// TimeZone is the IANA zone the user thinks of the block in; the instants do not depend on it.
+type AvailabilityBlock struct {
+	ID        int64              `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	Status    AvailabilityStatus `json:"status"`
+	StartsAt  time.Time          `json:"starts_at"`
+	EndsAt    time.Time          `json:"ends_at"`
+	TimeZone  string             `json:"time_zone" example:"America/Chicago"`
+	Note      string             `json:"note"`
+	Source    AvailabilitySource `json:"source"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// overlaps reports whether the block shares any time with [from, to). A zero bound is open.
+func (b AvailabilityBlock) overlaps(from, to time.Time) bool {
+	return (to.IsZero() || b.StartsAt.Before(to)) && (from.IsZero() || b.EndsAt.After(from))
+}
+
+type AvailabilityStore struct {
+	db DBTX
+}
+
+const availabilityColumns = `id, user_id, status, starts_at, ends_at, time_zone, note, source, created_at, updated_at`
+
+func scanAvailabilityBlock(scan func(dest ...any) error) (AvailabilityBlock, error) {
+	var b AvailabilityBlock
+	err := scan(&b.ID, &b.UserID, &b.Status, &b.StartsAt, &b.EndsAt, &b.TimeZone, &b.Note, &b.Source, &b.CreatedAt, &b.UpdatedAt)
+	return b, err
+}
+
+func (s *AvailabilityStore) Create(ctx context.Context, block *AvailabilityBlock) error {
+	ctx, span := startSpan(ctx, "AvailabilityStore.Create")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return s.insert(ctx, s.db, block)
+}
+
+func (s *AvailabilityStore) insert(ctx context.Context, db DBTX, block *AvailabilityBlock) error {
+	if block.Source == "" {
+		block.Source = AvailabilityManual
+	}
+
+	query := `
+	INSERT INTO availability_blocks (user_id, status, starts_at, ends_at, time_zone, note, source)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id, created_at, updated_at
+	`
+	err := db.QueryRowContext(ctx, query,
+		block.UserID, block.Status, block.StartsAt, block.EndsAt, block.TimeZone, block.Note, block.Source,
+	).Scan(&block.ID, &block.CreatedAt, &block.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting availability block: %w", err)
+	}
+	return nil
+}
+
+func (s *AvailabilityStore) GetByID(ctx context.Context, id int64) (*AvailabilityBlock, error) {
+	ctx, span := startSpan(ctx, "AvailabilityStore.GetByID")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := "SELECT " + availabilityColumns + " FROM availability_blocks WHERE id = $1"
+	b, err := scanAvailabilityBlock(s.db.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ListByUser returns the user's blocks that overlap [from, to), earliest first. A zero bound is open.
+func (s *AvailabilityStore) ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]AvailabilityBlock, error) {
+	ctx, span := startSpan(ctx, "AvailabilityStore.ListByUser")
+	defer span.End()
+
+	query := "SELECT " + availabilityColumns + " FROM availability_blocks WHERE user_id = $1\n"
+	args := []any{userID}
+	if !to.IsZero() {
+		args = append(args, to)
+		query += fmt.Sprintf("AND starts_at < $%d\n", len(args))
+	}
+	if !from.IsZero() {
+		args = append(args, from)
+		query += fmt.Sprintf("AND ends_at > $%d\n", len(args))
+	}
+	query += "ORDER BY starts_at, id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []AvailabilityBlock{}
+	for rows.Next() {
+		b, err := scanAvailabilityBlock(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+// LockCalendar holds the user's calendar until the surrounding transaction ends, so two
+// transactions booking the same cinematographer take turns: the second one sees the first's
+// blocks before it checks for an overlap.
+func (s *AvailabilityStore) LockCalendar(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := startSpan(ctx, "AvailabilityStore.LockCalendar")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var id uuid.UUID
+	err := s.db.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Update saves the block's status, times, time zone and note. Editing an imported block
+// makes it manual, so the next upload leaves it alone; other blocks keep their source.
+func (s *AvailabilityStore) Update(ctx context.Context, block *AvailabilityBlock) error {
+	ctx, span := startSpan(ctx, "AvailabilityStore.Update")
+	defer span.End()
+
+	query := `
+	UPDATE availability_blocks
+	SET status = $1, starts_at = $2, ends_at = $3, time_zone = $4, note = $5,
+		source = CASE WHEN source = 'import' THEN 'manual' ELSE source END, updated_at = NOW()
+	WHERE id = $6
+	RETURNING source, updated_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query,
+		block.Status, block.StartsAt, block.EndsAt, block.TimeZone, block.Note, block.ID,
+	).Scan(&block.Source, &block.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *AvailabilityStore) Delete(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "AvailabilityStore.Delete")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM availability_blocks WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ReplaceImported swaps the user's previously imported blocks for blocks, which are saved as imported
+func (s *AvailabilityStore) ReplaceImported(ctx context.Context, userID uuid.UUID, blocks []AvailabilityBlock) error {
+	ctx, span := startSpan(ctx, "AvailabilityStore.ReplaceImported")
+	defer span.End()
+
+	return withTx(s.db, ctx, func(tx DBTX) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		_, err := tx.ExecContext(ctx, `DELETE FROM availability_blocks WHERE user_id = $1 AND source = 'import'`, userID)
+		if err != nil {
+			return err
+		}
+		for i := range blocks {
+			blocks[i].UserID, blocks[i].Source = userID, AvailabilityImported
+			if err := s.insert(ctx, tx, &blocks[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetFeedToken gives the user a new calendar feed secret, revoking the previous one
+func (s *AvailabilityStore) SetFeedToken(ctx context.Context, userID uuid.UUID, tokenHash string) error {
+	ctx, span := startSpan(ctx, "AvailabilityStore.SetFeedToken")
+	defer span.End()
+
+	query := `
+	INSERT INTO calendar_feeds (user_id, token_hash) VALUES ($1, $2)
+	ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = NOW()
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, query, userID, tokenHash); err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrNotFound
+		}
+		return fmt.Errorf("saving calendar feed: %w", err)
+	}
+	return nil
+}
+
+// DeleteFeedToken turns the user's calendar feed off, returning ErrNotFound when it was not on
+func (s *AvailabilityStore) DeleteFeedToken(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := startSpan(ctx, "AvailabilityStore.DeleteFeedToken")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM calendar_feeds WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// UserIDByFeedToken returns whose calendar feed the token opens
+func (s *AvailabilityStore) UserIDByFeedToken(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	ctx, span := startSpan(ctx, "AvailabilityStore.UserIDByFeedToken")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var userID uuid.UUID
+	err := s.db.QueryRowContext(ctx, `SELECT user_id FROM calendar_feeds WHERE token_hash = $1`, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, ErrNotFound
+		}
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
@@ -0,0 +1,381 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+// BookingKind says how firm a producer's ask is. A request books the time once accepted; a
+// hold only pencils it in until the producer confirms it or it expires.
+type BookingKind string
+
+const (
+	BookingRequest BookingKind = "request"
+	BookingHold    BookingKind = "hold"
+)
+
+// BookingStatus tracks a booking from the producer's ask to a decision. Pending waits on the
+// cinematographer and countered on the producer; either side can accept, decline or counter
+// when it is their turn. An accepted hold is held until the producer confirms it.
+type BookingStatus string
+
+const (
+	BookingPending   BookingStatus = "pending"
+	BookingCountered BookingStatus = "countered"
+	BookingHeld      BookingStatus = "held"
+	BookingAccepted  BookingStatus = "accepted"
+	BookingDeclined  BookingStatus = "declined"
+	BookingCancelled BookingStatus = "cancelled"
+	BookingExpired   BookingStatus = "expired"
+)
+
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingPending:   {BookingCountered, BookingHeld, BookingAccepted, BookingDeclined, BookingCancelled, BookingExpired},
+	BookingCountered: {BookingPending, BookingHeld, BookingAccepted, BookingDeclined, BookingCancelled, BookingExpired},
+	BookingHeld:      {BookingAccepted, BookingCancelled, BookingExpired},
+	BookingAccepted:  {BookingCancelled},
+}
+
+// CanBecome reports whether a booking in status s may move to next
+func (s BookingStatus) CanBecome(next BookingStatus) bool {
+	for _, allowed := range bookingTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Open reports whether the booking still holds a claim on the cinematographer's time
+// without being booked: it is being negotiated or held
+func (s BookingStatus) Open() bool {
+	return s == BookingPending || s == BookingCountered || s == BookingHeld
+}
+
+// Booking is a producer's request or hold on a cinematographer's time. The dates, rate and
+// location are the latest terms; a counter replaces them. HoldRank is 1 for the first hold on
+// those dates, 2 for the second and so on, counting the cinematographer's open holds that
+// overlap it in the order they were placed, and 0 for anything that is not an open hold.
+type Booking struct {
+	ID                  int64         `json:"id"`
+	ProducerID          uuid.UUID     `json:"producer_id"`
+	CinematographerID   uuid.UUID     `json:"cinematographer_id"`
+	Kind                BookingKind   `json:"kind"`
+	Status              BookingStatus `json:"status"`
+	StartsAt            time.Time     `json:"starts_at"`
+	EndsAt              time.Time     `json:"ends_at"`
+	TimeZone            string        `json:"time_zone" example:"America/Chicago"`
+	Rate                int           `json:"rate"` // whole units of Currency for the whole booking
+	Currency            string        `json:"currency" example:"USD"`
+	LocationID          int64         `json:"location_id"`
+	Location            *Location     `json:"location"`
+	Message             string        `json:"message"`
+	ExpiresAt           *time.Time    `json:"expires_at"` // holds only
+	HoldRank            int           `json:"hold_rank"`
+	AvailabilityBlockID *int64        `json:"availability_block_id"` // on the cinematographer's calendar once held or accepted
+	Version             int           `json:"version"`
+	CreatedAt           time.Time     `json:"created_at"`
+	UpdatedAt           time.Time     `json:"updated_at"`
+	Producer            User          `json:"producer"`
+	Cinematographer     User          `json:"cinematographer"`
+}
+
+// DueToExpire reports whether the booking is an open hold whose time ran out by now
+func (b Booking) DueToExpire(now time.Time) bool {
+	return b.Kind == BookingHold && b.Status.Open() && b.ExpiresAt != nil && !b.ExpiresAt.After(now)
+}
+
+// overlaps reports whether the two bookings share any time
+func (b Booking) overlaps(other Booking) bool {
+	return b.StartsAt.Before(other.EndsAt) && other.StartsAt.Before(b.EndsAt)
+}
+
+// BookingRole picks which side of their bookings a user lists
+type BookingRole string
+
+const (
+	BookingAsProducer        BookingRole = "producer"
+	BookingAsCinematographer BookingRole = "cinematographer"
+)
+
+// BookingFilter narrows a user's bookings. Zero values match everything.
+type BookingFilter struct {
+	Role   BookingRole
+	Status BookingStatus
+	Page   pagination.Params
+}
+
+// BookingEvent is one entry in a booking's audit trail: who did what, the status it moved the
+// booking between and the terms it left. ActorID is nil for changes the system makes.
+type BookingEvent struct {
+	ID         int64         `json:"id"`
+	BookingID  int64         `json:"booking_id"`
+	ActorID    *uuid.UUID    `json:"actor_id"`
+	Action     string        `json:"action" example:"countered"`
+	FromStatus BookingStatus `json:"from_status"`
+	ToStatus   BookingStatus `json:"to_status"`
+	StartsAt   time.Time     `json:"starts_at"`
+	EndsAt     time.Time     `json:"ends_at"`
+	Rate       int           `json:"rate"`
+	Note       string        `json:"note"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type BookingStore struct {
+	db DBTX
+}
+
+const openBookingStatuses = `('pending', 'countered', 'held')`
+
+const bookingColumns = `
+	b.id, b.producer_id, b.cinematographer_id, b.kind, b.status, b.starts_at, b.ends_at, b.time_zone,
+	b.rate, b.currency, b.message, b.expires_at, b.availability_block_id, b.version, b.created_at, b.updated_at,
+	CASE WHEN b.kind = 'hold' AND b.status IN ` + openBookingStatuses + ` THEN (
+		SELECT COUNT(*) + 1 FROM bookings o
+		WHERE o.cinematographer_id = b.cinematographer_id AND o.kind = 'hold' AND o.status IN ` + openBookingStatuses + `
+			AND o.starts_at < b.ends_at AND o.ends_at > b.starts_at AND o.id < b.id
+	) ELSE 0 END,
+	l.id, COALESCE(l.street, ''), l.city, l.state, COALESCE(l.county, ''), l.zip_code,
+	COALESCE(l.country, ''), COALESCE(l.country_code, ''), l.latitude, l.longitude,
+	pu.first_name, pu.last_name, cu.first_name, cu.last_name
+	FROM bookings b
+	JOIN locations l ON l.id = b.location_id
+	JOIN users pu ON pu.id = b.producer_id
+	JOIN users cu ON cu.id = b.cinematographer_id
+	`
+
+func scanBooking(scan func(dest ...any) error) (Booking, error) {
+	b := Booking{Location: &Location{}}
+	err := scan(
+		&b.ID, &b.ProducerID, &b.CinematographerID, &b.Kind, &b.Status, &b.StartsAt, &b.EndsAt, &b.TimeZone,
+		&b.Rate, &b.Currency, &b.Message, &b.ExpiresAt, &b.AvailabilityBlockID, &b.Version, &b.CreatedAt, &b.UpdatedAt,
+		&b.HoldRank,
+		&b.Location.ID, &b.Location.Street, &b.Location.City, &b.Location.State, &b.Location.County, &b.Location.ZIPCode,
+		&b.Location.Country, &b.Location.CountryCode, &b.Location.Latitude, &b.Location.Longitude,
+		&b.Producer.FirstName, &b.Producer.LastName, &b.Cinematographer.FirstName, &b.Cinematographer.LastName,
+	)
+	b.LocationID = b.Location.ID
+	b.Producer.ID, b.Cinematographer.ID = b.ProducerID, b.CinematographerID
+	return b, err
+}
+
+// Create saves a new pending booking at the general location given
+func (s *BookingStore) Create(ctx context.Context, booking *Booking) error {
+	ctx, span := startSpan(ctx, "BookingStore.Create")
+	defer span.End()
+
+	if booking.Location == nil || !booking.Location.IsValid() {
+		return fmt.Errorf("booking location is missing required fields (city, state, or zip code)")
+	}
+
+	return withTx(s.db, ctx, func(tx DBTX) error {
+		locationID, err := NewLocationStore(tx).findOrCreate(ctx, booking.Location)
+		if err != nil {
+			return err
+		}
+		booking.LocationID, booking.Location.ID = locationID, locationID
+
+		query := `
+		INSERT INTO bookings (producer_id, cinematographer_id, kind, starts_at, ends_at, time_zone,
+			rate, currency, location_id, message, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, status, version, created_at, updated_at
+		`
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		err = tx.QueryRowContext(ctx, query,
+			booking.ProducerID, booking.CinematographerID, booking.Kind, booking.StartsAt, booking.EndsAt, booking.TimeZone,
+			booking.Rate, booking.Currency, booking.LocationID, booking.Message, booking.ExpiresAt,
+		).Scan(&booking.ID, &booking.Status, &booking.Version, &booking.CreatedAt, &booking.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("inserting booking: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *BookingStore) GetByID(ctx context.Context, id int64) (*Booking, error) {
+	ctx, span := startSpan(ctx, "BookingStore.GetByID")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	b, err := scanBooking(s.db.QueryRowContext(ctx, "SELECT"+bookingColumns+"WHERE b.id = $1", id).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ListByUser returns a page of the bookings the user sent or received, newest first
+func (s *BookingStore) ListByUser(ctx context.Context, userID uuid.UUID, filter BookingFilter) (pagination.Page[Booking], error) {
+	ctx, span := startSpan(ctx, "BookingStore.ListByUser")
+	defer span.End()
+
+	query := "SELECT" + bookingColumns
+	switch filter.Role {
+	case BookingAsProducer:
+		query += "WHERE b.producer_id = $1\n"
+	case BookingAsCinematographer:
+		query += "WHERE b.cinematographer_id = $1\n"
+	default:
+		query += "WHERE (b.producer_id = $1 OR b.cinematographer_id = $1)\n"
+	}
+	args := []any{userID}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf("AND b.status = $%d\n", len(args))
+	}
+	if after := filter.Page.After; after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		query += fmt.Sprintf("AND (b.created_at, b.id) < ($%d, $%d)\n", len(args)-1, len(args))
+	}
+	// One extra row tells us whether there is another page
+	args = append(args, filter.Page.Limit+1)
+	query += fmt.Sprintf("ORDER BY b.created_at DESC, b.id DESC\nLIMIT $%d", len(args))
+
+	bookings, err := s.list(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[Booking]{}, err
+	}
+	return pagination.NewPage(bookings, filter.Page.Limit, bookingCursor), nil
+}
+
+// ListDueToExpire returns the open holds whose expiry has passed by now, oldest first
+func (s *BookingStore) ListDueToExpire(ctx context.Context, now time.Time) ([]Booking, error) {
+	ctx, span := startSpan(ctx, "BookingStore.ListDueToExpire")
+	defer span.End()
+
+	query := "SELECT" + bookingColumns + "WHERE b.kind = 'hold' AND b.status IN " + openBookingStatuses +
+		" AND b.expires_at <= $1\nORDER BY b.expires_at, b.id"
+	return s.list(ctx, query, now)
+}
+
+func (s *BookingStore) list(ctx context.Context, query string, args ...any) ([]Booking, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []Booking{}
+	for rows.Next() {
+		b, err := scanBooking(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+func bookingCursor(b Booking) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: b.CreatedAt, ID: b.ID}
+}
+
+// Update saves the booking's status, terms, expiry and calendar block if it is still at
+// booking.Version, returning ErrEditConflict when another request changed it first. The
+// caller checks the move is allowed with CanBecome.
+func (s *BookingStore) Update(ctx context.Context, booking *Booking) error {
+	ctx, span := startSpan(ctx, "BookingStore.Update")
+	defer span.End()
+
+	query := `
+	UPDATE bookings
+	SET status = $1, starts_at = $2, ends_at = $3, time_zone = $4, rate = $5, expires_at = $6,
+		availability_block_id = $7, version = version + 1, updated_at = NOW()
+	WHERE id = $8 AND version = $9
+	RETURNING version, updated_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query,
+		booking.Status, booking.StartsAt, booking.EndsAt, booking.TimeZone, booking.Rate, booking.ExpiresAt,
+		booking.AvailabilityBlockID, booking.ID, booking.Version,
+	).Scan(&booking.Version, &booking.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			var exists bool
+			if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM bookings WHERE id = $1)`, booking.ID).Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				return ErrEditConflict
+			}
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// AddEvent appends an entry to a booking's audit trail
+func (s *BookingStore) AddEvent(ctx context.Context, event *BookingEvent) error {
+	ctx, span := startSpan(ctx, "BookingStore.AddEvent")
+	defer span.End()
+
+	query := `
+	INSERT INTO booking_events (booking_id, actor_id, action, from_status, to_status, starts_at, ends_at, rate, note)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id, created_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query,
+		event.BookingID, event.ActorID, event.Action, event.FromStatus, event.ToStatus,
+		event.StartsAt, event.EndsAt, event.Rate, event.Note,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting booking event: %w", err)
+	}
+	return nil
+}
+
+// ListEvents returns the booking's audit trail, oldest first
+func (s *BookingStore) ListEvents(ctx context.Context, bookingID int64) ([]BookingEvent, error) {
+	ctx, span := startSpan(ctx, "BookingStore.ListEvents")
+	defer span.End()
+
+	query := `
+	SELECT id, booking_id, actor_id, action, from_status, to_status, starts_at, ends_at, rate, note, created_at
+	FROM booking_events
+	WHERE booking_id = $1
+	ORDER BY id
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []BookingEvent{}
+	for rows.Next() {
+		var e BookingEvent
+		err := rows.Scan(&e.ID, &e.BookingID, &e.ActorID, &e.Action, &e.FromStatus, &e.ToStatus,
+			&e.StartsAt, &e.EndsAt, &e.Rate, &e.Note, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -3,28 +3,32 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
 )
 
 type Comment struct {
-	ID        int64  `json:"id"`
-	PostID    int64  `json:"post_id"`
-	UserID    int64  `json:"user_id"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
-	User      User   `json:"user"`
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `json:"user"`
 }
 
 type CommentsStore struct {
-	db *sql.DB
+	db DBTX
 }
 
 func (s *CommentsStore) Create(ctx context.Context, comment *Comment) error {
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
+	ctx, span := startSpan(ctx, "CommentsStore.Create")
+	defer span.End()
+
 	query := `
 INSERT INTO comments (post_id, user_id, content)
 VALUES ($1, $2, $3) RETURNING id, created_at, updated_at
@@ -32,7 +36,7 @@ VALUES ($1, $2, $3) RETURNING id, created_at, updated_at
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	err = tx.QueryRowContext(
+	return s.db.QueryRowContext(
 		ctx,
 		query,
 		comment.PostID,
@@ -43,32 +47,36 @@ VALUES ($1, $2, $3) RETURNING id, created_at, updated_at
 		&comment.CreatedAt,
 		&comment.UpdatedAt,
 	)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if err := tx.Commit(); err != nil {
-		return err
-	}
-	return nil
 }
 
-func (s *CommentsStore) GetByPostID(ctx context.Context, postID int64) ([]Comment, error) {
+// GetByPostID returns one page of a post's comments, newest first
+func (s *CommentsStore) GetByPostID(ctx context.Context, postID int64, page pagination.Params) (pagination.Page[Comment], error) {
+	ctx, span := startSpan(ctx, "CommentsStore.GetByPostID")
+	defer span.End()
+
 	query := `
 	SELECT c.id, c.post_id, c.user_id, c.content, c.created_at, c.updated_at, u.first_name, u.last_name
 	FROM comments c
 	JOIN users u ON c.user_id = u.id
 	WHERE c.post_id = $1
-	ORDER BY c.created_at DESC;
 	`
+	args := []any{postID}
+	if page.After != nil {
+		query += `AND (c.created_at, c.id) < ($2, $3)
+	`
+		args = append(args, page.After.CreatedAt, page.After.ID)
+	}
+	// One extra row tells us whether there is another page
+	query += fmt.Sprintf(`ORDER BY c.created_at DESC, c.id DESC
+	LIMIT $%d`, len(args)+1)
+	args = append(args, page.Limit+1)
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.QueryContext(ctx, query, postID)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return pagination.Page[Comment]{}, err
 	}
 	defer rows.Close()
 	comments := []Comment{}
@@ -77,14 +85,24 @@ func (s *CommentsStore) GetByPostID(ctx context.Context, postID int64) ([]Commen
 		c.User = User{}
 		err := rows.Scan(&c.ID, &c.PostID, &c.UserID, &c.Content, &c.CreatedAt, &c.UpdatedAt, &c.User.FirstName, &c.User.LastName)
 		if err != nil {
-			return nil, err
+			return pagination.Page[Comment]{}, err
 		}
 		comments = append(comments, c)
 	}
-	return comments, nil
+	if err := rows.Err(); err != nil {
+		return pagination.Page[Comment]{}, err
+	}
+	return pagination.NewPage(comments, page.Limit, commentCursor), nil
+}
+
+func commentCursor(c Comment) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
 }
 
 func (s *CommentsStore) GetByCommentID(ctx context.Context, commentID int64) (*Comment, error) {
+	ctx, span := startSpan(ctx, "CommentsStore.GetByCommentID")
+	defer span.End()
+
 	query := `
 	SELECT c.id, c.post_id, c.user_id, c.content, c.created_at, c.updated_at, u.first_name, u.last_name
 	FROM comments c
@@ -99,17 +117,20 @@ func (s *CommentsStore) GetByCommentID(ctx context.Context, commentID int64) (*C
 	comment.User = User{}
 	err := s.db.QueryRowContext(ctx, query, commentID).Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.Content, &comment.CreatedAt, &comment.UpdatedAt, &comment.User.FirstName, &comment.User.LastName)
 	if err != nil {
-		return nil, err
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
 	}
-	fmt.Println("KILL RACHEL", &comment)
 	return &comment, nil
 }
 
 func (s *CommentsStore) Update(ctx context.Context, comment *Comment) error {
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
+	ctx, span := startSpan(ctx, "CommentsStore.Update")
+	defer span.End()
+
 	query := `
 	UPDATE comments
 	SET content = $1
@@ -120,19 +141,20 @@ func (s *CommentsStore) Update(ctx context.Context, comment *Comment) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	err = tx.QueryRowContext(ctx, query, comment.Content, comment.ID).Scan(&comment.UpdatedAt)
+	err := s.db.QueryRowContext(ctx, query, comment.Content, comment.ID).Scan(&comment.UpdatedAt)
 	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if err := tx.Commit(); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
 		return err
 	}
 	return nil
 }
 
 func (s *CommentsStore) DeleteByCommentID(ctx context.Context, commentID int64) error {
+	ctx, span := startSpan(ctx, "CommentsStore.DeleteByCommentID")
+	defer span.End()
+
 	query := `
 	DELETE FROM comments
 	WHERE id = $1
@@ -156,6 +178,9 @@ func (s *CommentsStore) DeleteByCommentID(ctx context.Context, commentID int64)
 }
 
 func (s *CommentsStore) DeleteByPostID(ctx context.Context, postID int64) error {
+	ctx, span := startSpan(ctx, "CommentsStore.DeleteByPostID")
+	defer span.End()
+
 	query := `
 	DELETE FROM comments
 	WHERE post_id = $1
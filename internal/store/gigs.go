@@ -0,0 +1,349 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+var ErrInvalidTransition = errors.New("status change is not allowed")
+
+// CrewRole is the position a gig is hiring for
+type CrewRole string
+
+const (
+	RoleDirectorOfPhotography CrewRole = "director_of_photography"
+	RoleCameraOperator        CrewRole = "camera_operator"
+	RoleSteadicamOperator     CrewRole = "steadicam_operator"
+	RoleDronePilot            CrewRole = "drone_pilot"
+	RoleFirstAC               CrewRole = "first_ac"
+	RoleSecondAC              CrewRole = "second_ac"
+	RoleDIT                   CrewRole = "dit"
+	RoleGaffer                CrewRole = "gaffer"
+	RoleBestBoyElectric       CrewRole = "best_boy_electric"
+	RoleElectrician           CrewRole = "electrician"
+	RoleKeyGrip               CrewRole = "key_grip"
+	RoleGrip                  CrewRole = "grip"
+)
+
+// CrewRoles lists every role a gig can hire for
+var CrewRoles = []CrewRole{
+	RoleDirectorOfPhotography, RoleCameraOperator, RoleSteadicamOperator, RoleDronePilot,
+	RoleFirstAC, RoleSecondAC, RoleDIT, RoleGaffer, RoleBestBoyElectric, RoleElectrician,
+	RoleKeyGrip, RoleGrip,
+}
+
+func (r CrewRole) Valid() bool {
+	return slices.Contains(CrewRoles, r)
+}
+
+// UnionStatus says whether a gig is a union call
+type UnionStatus string
+
+const (
+	UnionOnly     UnionStatus = "union"
+	UnionNonUnion UnionStatus = "non_union"
+	UnionEither   UnionStatus = "either"
+)
+
+// GigStatus is where a gig is in its lifecycle. Open gigs can be filled or cancelled,
+// filled gigs can reopen when crew drop out, and cancelled gigs stay cancelled.
+type GigStatus string
+
+const (
+	GigOpen      GigStatus = "open"
+	GigFilled    GigStatus = "filled"
+	GigCancelled GigStatus = "cancelled"
+)
+
+var gigTransitions = map[GigStatus][]GigStatus{
+	GigOpen:   {GigFilled, GigCancelled},
+	GigFilled: {GigOpen, GigCancelled},
+}
+
+// CanBecome reports whether a gig in status s may move to next
+func (s GigStatus) CanBecome(next GigStatus) bool {
+	for _, allowed := range gigTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Gig is a post advertising a crew call. The post carries the title, description and tags,
+// and its version covers the gig fields too.
+type Gig struct {
+	Post
+	Role          CrewRole    `json:"role"`
+	ShootStart    Date        `json:"shoot_start" swaggertype:"string" format:"date"`
+	ShootEnd      Date        `json:"shoot_end" swaggertype:"string" format:"date"`
+	CallTime      string      `json:"call_time" example:"07:00"` // local to the location
+	DurationHours int         `json:"duration_hours"`            // per shoot day
+	DayRateMin    int         `json:"day_rate_min"`              // whole units of Currency
+	DayRateMax    int         `json:"day_rate_max"`
+	Currency      string      `json:"currency" example:"USD"`
+	Gear          []string    `json:"gear"`
+	UnionStatus   UnionStatus `json:"union_status"`
+	Status        GigStatus   `json:"status"`
+	LocationID    int64       `json:"location_id"`
+	Location      *Location   `json:"location"`
+}
+
+// GigFilter narrows a gig listing. Zero values match everything.
+type GigFilter struct {
+	Role        CrewRole
+	Status      GigStatus
+	UnionStatus UnionStatus
+	From, To    Date // gigs shooting on any day from From through To
+	MinRate     int  // gigs whose rate range reaches at least this
+	Currency    string
+	Gear        []string // gigs asking for every one of these
+	Within      *BoundingBox
+	Page        pagination.Params
+}
+
+type GigStore struct {
+	db DBTX
+}
+
+const gigColumns = `
+	p.id, p.content, p.title, p.tags, p.version, p.user_id, p.created_at, p.updated_at,
+	g.role, g.shoot_start, g.shoot_end, to_char(g.call_time, 'HH24:MI'), g.duration_hours,
+	g.day_rate_min, g.day_rate_max, g.currency, g.gear, g.union_status, g.status,
+	l.id, COALESCE(l.street, ''), l.city, l.state, COALESCE(l.county, ''), l.zip_code,
+	COALESCE(l.country, ''), COALESCE(l.country_code, ''), l.latitude, l.longitude
+	FROM gigs g
+	JOIN posts p ON p.id = g.post_id
+	JOIN locations l ON l.id = g.location_id
+	`
+
+func scanGig(scan func(dest ...any) error) (Gig, error) {
+	gig := Gig{Location: &Location{}}
+	err := scan(
+		&gig.ID, &gig.Content, &gig.Title, pq.Array(&gig.Tags), &gig.Version, &gig.UserID, &gig.CreatedAt, &gig.UpdatedAt,
+		&gig.Role, &gig.ShootStart, &gig.ShootEnd, &gig.CallTime, &gig.DurationHours,
+		&gig.DayRateMin, &gig.DayRateMax, &gig.Currency, pq.Array(&gig.Gear), &gig.UnionStatus, &gig.Status,
+		&gig.Location.ID, &gig.Location.Street, &gig.Location.City, &gig.Location.State, &gig.Location.County, &gig.Location.ZIPCode,
+		&gig.Location.Country, &gig.Location.CountryCode, &gig.Location.Latitude, &gig.Location.Longitude,
+	)
+	gig.LocationID = gig.Location.ID
+	return gig, err
+}
+
+// Create inserts the gig's post and gig row together. New gigs are always open.
+func (s *GigStore) Create(ctx context.Context, gig *Gig) error {
+	ctx, span := startSpan(ctx, "GigStore.Create")
+	defer span.End()
+
+	if gig.Location == nil || !gig.Location.IsValid() {
+		return fmt.Errorf("gig location is missing required fields (city, state, or zip code)")
+	}
+	if gig.Gear == nil {
+		gig.Gear = []string{}
+	}
+
+	return withTx(s.db, ctx, func(tx DBTX) error {
+		if err := (&PostStore{tx}).Create(ctx, &gig.Post); err != nil {
+			return fmt.Errorf("inserting post: %w", err)
+		}
+		locationID, err := NewLocationStore(tx).findOrCreate(ctx, gig.Location)
+		if err != nil {
+			return err
+		}
+		gig.LocationID, gig.Location.ID = locationID, locationID
+
+		query := `
+		INSERT INTO gigs (post_id, role, shoot_start, shoot_end, call_time, duration_hours,
+			day_rate_min, day_rate_max, currency, gear, union_status, location_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING status
+		`
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		return tx.QueryRowContext(ctx, query,
+			gig.ID, gig.Role, gig.ShootStart, gig.ShootEnd, gig.CallTime, gig.DurationHours,
+			gig.DayRateMin, gig.DayRateMax, gig.Currency, pq.Array(gig.Gear), gig.UnionStatus, gig.LocationID,
+		).Scan(&gig.Status)
+	})
+}
+
+func (s *GigStore) GetByID(ctx context.Context, id int64) (*Gig, error) {
+	ctx, span := startSpan(ctx, "GigStore.GetByID")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	gig, err := scanGig(s.db.QueryRowContext(ctx, "SELECT"+gigColumns+"WHERE g.post_id = $1", id).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &gig, nil
+}
+
+// List returns one page of gigs matching filter, most recently posted first
+func (s *GigStore) List(ctx context.Context, filter GigFilter) (pagination.Page[Gig], error) {
+	ctx, span := startSpan(ctx, "GigStore.List")
+	defer span.End()
+
+	var (
+		where []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Role != "" {
+		where = append(where, "g.role = "+arg(filter.Role))
+	}
+	if filter.Status != "" {
+		where = append(where, "g.status = "+arg(filter.Status))
+	}
+	if filter.UnionStatus != "" {
+		where = append(where, "g.union_status = "+arg(filter.UnionStatus))
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "g.shoot_end >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "g.shoot_start <= "+arg(filter.To))
+	}
+	if filter.MinRate > 0 {
+		where = append(where, "g.day_rate_max >= "+arg(filter.MinRate))
+	}
+	if filter.Currency != "" {
+		where = append(where, "g.currency = "+arg(filter.Currency))
+	}
+	if len(filter.Gear) > 0 {
+		where = append(where, "g.gear @> "+arg(pq.Array(filter.Gear)))
+	}
+	if box := filter.Within; box != nil {
+		where = append(where,
+			fmt.Sprintf("l.latitude BETWEEN %s AND %s", arg(box.MinLat), arg(box.MaxLat)),
+			fmt.Sprintf("l.longitude BETWEEN %s AND %s", arg(box.MinLon), arg(box.MaxLon)),
+		)
+	}
+	if after := filter.Page.After; after != nil {
+		where = append(where, fmt.Sprintf("(p.created_at, p.id) < (%s, %s)", arg(after.CreatedAt), arg(after.ID)))
+	}
+
+	query := "SELECT" + gigColumns
+	if len(where) > 0 {
+		query += "WHERE " + strings.Join(where, " AND ") + "\n"
+	}
+	// One extra row tells us whether there is another page
+	query += "ORDER BY p.created_at DESC, p.id DESC\nLIMIT " + arg(filter.Page.Limit+1)
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[Gig]{}, err
+	}
+	defer rows.Close()
+
+	gigs := []Gig{}
+	for rows.Next() {
+		gig, err := scanGig(rows.Scan)
+		if err != nil {
+			return pagination.Page[Gig]{}, err
+		}
+		gigs = append(gigs, gig)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[Gig]{}, err
+	}
+	return pagination.NewPage(gigs, filter.Page.Limit, gigCursor), nil
+}
+
+func gigCursor(g Gig) pagination.Cursor {
+	return postCursor(g.Post)
+}
+
+// Update saves the post and gig fields if the post is still at gig.Version, returning
+// ErrEditConflict when it has moved on. The status is changed with UpdateStatus.
+func (s *GigStore) Update(ctx context.Context, gig *Gig) error {
+	ctx, span := startSpan(ctx, "GigStore.Update")
+	defer span.End()
+
+	if gig.Location == nil || !gig.Location.IsValid() {
+		return fmt.Errorf("gig location is missing required fields (city, state, or zip code)")
+	}
+
+	return withTx(s.db, ctx, func(tx DBTX) error {
+		if err := (&PostStore{tx}).Update(ctx, &gig.Post); err != nil {
+			return err
+		}
+		locationID, err := NewLocationStore(tx).findOrCreate(ctx, gig.Location)
+		if err != nil {
+			return err
+		}
+		gig.LocationID, gig.Location.ID = locationID, locationID
+
+		query := `
+		UPDATE gigs
+		SET role = $1, shoot_start = $2, shoot_end = $3, call_time = $4, duration_hours = $5,
+			day_rate_min = $6, day_rate_max = $7, currency = $8, gear = $9, union_status = $10, location_id = $11
+		WHERE post_id = $12
+		`
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		_, err = tx.ExecContext(ctx, query,
+			gig.Role, gig.ShootStart, gig.ShootEnd, gig.CallTime, gig.DurationHours,
+			gig.DayRateMin, gig.DayRateMax, gig.Currency, pq.Array(gig.Gear), gig.UnionStatus, gig.LocationID,
+			gig.ID,
+		)
+		return err
+	})
+}
+
+// UpdateStatus moves the gig to status, checking the transition against gig.Status and the
+// post's version against gig.Version. Both are updated on success.
+func (s *GigStore) UpdateStatus(ctx context.Context, gig *Gig, status GigStatus) error {
+	ctx, span := startSpan(ctx, "GigStore.UpdateStatus")
+	defer span.End()
+
+	if !gig.Status.CanBecome(status) {
+		return ErrInvalidTransition
+	}
+
+	return withTx(s.db, ctx, func(tx DBTX) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		posts := &PostStore{tx}
+		err := tx.QueryRowContext(ctx, `
+		UPDATE posts
+		SET version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND version = $2
+		RETURNING version
+		`, gig.ID, gig.Version).Scan(&gig.Version)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return posts.missingOrConflict(ctx, gig.ID)
+			}
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE gigs SET status = $1 WHERE post_id = $2`, status, gig.ID); err != nil {
+			return err
+		}
+		gig.Status = status
+		return nil
+	})
+}
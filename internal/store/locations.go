@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -21,20 +22,19 @@ type Location struct {
 }
 
 type LocationStore struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewLocationStore(db *sql.DB) *LocationStore {
+func NewLocationStore(db DBTX) *LocationStore {
 	return &LocationStore{db: db}
 }
 
-func (s *LocationStore) Create(ctx context.Context, tx *sql.Tx, location *Location) (Location, error) {
-	fmt.Println("Create Location Started")    // Debugging line
-	fmt.Println("Location values:", location) // Debugging line
+func (s *LocationStore) Create(ctx context.Context, location *Location) (Location, error) {
+	ctx, span := startSpan(ctx, "LocationStore.Create")
+	defer span.End()
 
 	var query string
 	if location.Street == "" {
-		fmt.Println("Query is: Insert INTO locations", location.Street, location.City, location.State, location.County, location.ZIPCode, location) // Debugging line
 		query = `
         INSERT INTO locations (street, city, state, county, zip_code, country, country_code, latitude, longitude, is_precise)
         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, false)
@@ -48,11 +48,10 @@ func (s *LocationStore) Create(ctx context.Context, tx *sql.Tx, location *Locati
         `
 	}
 
-	location.Normalize()                                 // 👍 perfect place to normalize
-	fmt.Println("Normalized Location values:", location) // Debugging line
+	location.Normalize() // 👍 perfect place to normalize
 
 	var id int64
-	err := tx.QueryRowContext(ctx, query,
+	err := s.db.QueryRowContext(ctx, query,
 		location.Street,
 		location.City,
 		location.State,
@@ -64,24 +63,23 @@ func (s *LocationStore) Create(ctx context.Context, tx *sql.Tx, location *Locati
 		location.Longitude,
 	).Scan(&id)
 	if err != nil {
-		fmt.Println("Error executing query:", err) // Debugging line
 		return Location{}, fmt.Errorf("inserting location: %w", err)
 	}
 
-	fmt.Println("Location inserted with ID:", id) // Debugging line
 	location.ID = id
 	return *location, nil
 }
 
 func (s *LocationStore) Get(ctx context.Context, id int64) (Location, error) {
+	ctx, span := startSpan(ctx, "LocationStore.Get")
+	defer span.End()
+
 	query := `
 		SELECT id, street, city, state, zip_code, country, latitude, longitude
 		FROM locations
 		WHERE id = $1
 	`
 
-	fmt.Println("Get Location Started") // Debugging line
-
 	var location Location
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&location.ID,
@@ -94,8 +92,8 @@ func (s *LocationStore) Get(ctx context.Context, id int64) (Location, error) {
 		&location.Longitude,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return Location{}, fmt.Errorf("location not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			return Location{}, ErrNotFound
 		}
 		return Location{}, fmt.Errorf("getting location: %w", err)
 	}
@@ -156,9 +154,34 @@ func (s *LocationStore) Get(ctx context.Context, id int64) (Location, error) {
 // 	}
 // }
 
+// findOrCreate returns the ID of the stored location matching location, inserting it when
+// there is none. Locations with a street are matched precisely, others by city and ZIP code.
+func (s *LocationStore) findOrCreate(ctx context.Context, location *Location) (int64, error) {
+	location.Normalize()
+
+	lookup := s.GetByLocation
+	if location.Street != "" {
+		lookup = s.GetByLocationPrecise
+	}
+	existing, err := lookup(ctx, location)
+	if err == nil {
+		return existing.ID, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return 0, err
+	}
+
+	created, err := s.Create(ctx, location)
+	if err != nil {
+		return 0, fmt.Errorf("inserting location: %w", err)
+	}
+	return created.ID, nil
+}
+
 func (s *LocationStore) GetByLocationPrecise(ctx context.Context, location *Location) (Location, error) {
-	fmt.Println("Get Location By Location Started")             // Debugging line
-	fmt.Println("Querying for location with values:", location) // Debugging line
+	ctx, span := startSpan(ctx, "LocationStore.GetByLocationPrecise")
+	defer span.End()
+
 	query := `
 		SELECT id, street, city, state, zip_code, country, latitude, longitude
 		FROM locations
@@ -181,21 +204,19 @@ func (s *LocationStore) GetByLocationPrecise(ctx context.Context, location *Loca
 		&loc.Latitude,
 		&loc.Longitude,
 	)
-	fmt.Println("Query executed")     // Debugging line
-	fmt.Println("Query result:", loc) // Debugging line
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return Location{}, fmt.Errorf("location not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			return Location{}, ErrNotFound
 		}
 		return Location{}, fmt.Errorf("getting location: %w", err)
 	}
-	fmt.Println("Location found:", loc) // Debugging line
 	return loc, nil
 }
 
 func (s *LocationStore) GetByLocation(ctx context.Context, location *Location) (Location, error) {
-	fmt.Println("Get Location By Location Started")             // Debugging line
-	fmt.Println("Querying for location with values:", location) // Debugging line
+	ctx, span := startSpan(ctx, "LocationStore.GetByLocation")
+	defer span.End()
+
 	query := `
 		SELECT id, street, city, state, zip_code, country, latitude, longitude
 		FROM locations
@@ -218,15 +239,12 @@ func (s *LocationStore) GetByLocation(ctx context.Context, location *Location) (
 		&loc.Latitude,
 		&loc.Longitude,
 	)
-	fmt.Println("Query executed")     // Debugging line
-	fmt.Println("Query result:", loc) // Debugging line
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return Location{}, fmt.Errorf("location not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			return Location{}, ErrNotFound
 		}
 		return Location{}, fmt.Errorf("getting location: %w", err)
 	}
-	fmt.Println("Location found:", loc) // Debugging line
 	return loc, nil
 }
 
@@ -239,16 +257,14 @@ func (l *Location) Normalize() {
 }
 
 func (s *LocationStore) GetGeneralLocationByZip(ctx context.Context, zipCode string) (Location, error) {
-	// Start of the function, print the input
-	fmt.Println("GetGeneralLocationByZip called with zipCode:", zipCode)
+	ctx, span := startSpan(ctx, "LocationStore.GetGeneralLocationByZip")
+	defer span.End()
 
 	query := `
 		SELECT id, street, city, state, county, zip_code, country, country_code, latitude, longitude
 		FROM locations
 		WHERE zip_code = $1 AND is_precise = false
 	`
-	// Log the query for debugging
-	fmt.Println("Executing query:", query)
 
 	var loc Location
 	// Execute the query and scan the result into the Location struct
@@ -266,23 +282,13 @@ func (s *LocationStore) GetGeneralLocationByZip(ctx context.Context, zipCode str
 	)
 
 	if err != nil {
-		// Log the error in case of failure
-		fmt.Println("Error during QueryRowContext execution:", err)
-
-		// Specific handling for no rows found
-		if err == sql.ErrNoRows {
-			fmt.Println("\n\n--GetGeneralLocationByZip--\n\nNo location found for zipCode:", zipCode)
-			return Location{}, sql.ErrNoRows
+		if errors.Is(err, sql.ErrNoRows) {
+			return Location{}, ErrNotFound
 		}
 
-		// Log the error for other types of database failures
-		fmt.Println("Error executing query:", err)
 		return Location{}, fmt.Errorf("getting location: %w", err)
 	}
 
-	// Log the result for debugging
-	fmt.Println("Location found:", loc)
-
 	return loc, nil
 }
 
@@ -316,11 +322,10 @@ func (s *LocationStore) GetGeneralLocationByZip(ctx context.Context, zipCode str
 // 	return loc, nil
 // }
 
-func (s *LocationStore) DB() *sql.DB {
-	return s.db
-}
-
 func (s *LocationStore) GetLocationsByBoundingBox(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]Location, error) {
+	ctx, span := startSpan(ctx, "LocationStore.GetLocationsByBoundingBox")
+	defer span.End()
+
 	query := `
 		SELECT id, street, city, state, county, zip_code, country, country_code, latitude, longitude
 		FROM locations
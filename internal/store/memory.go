@@ -0,0 +1,957 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+// errForeignKey mirrors the Postgres foreign key violation when a row references a missing parent
+var errForeignKey = errors.New("referenced row does not exist")
+
+// memoryDB holds every table for the in-memory stores behind a single lock,
+// so cascades across tables stay consistent the way they do in Postgres.
+type memoryDB struct {
+	mu   sync.Mutex
+	txMu sync.Mutex // serializes WithTx units of work
+	now  func() time.Time
+
+	memoryTables
+}
+
+type memoryTables struct {
+	posts         map[int64]Post
+	comments      map[int64]Comment
+	gigs          map[int64]Gig // keyed by post ID; the Post and Location are joined on read
+	applications  map[int64]Application
+	notifications map[int64]Notification
+	profiles      map[uuid.UUID]Profile
+	serviceAreas  map[uuid.UUID]ServiceArea // places keep only their location ID
+	availability  map[int64]AvailabilityBlock
+	calendarFeeds map[uuid.UUID]string // token hash by user
+	bookings      map[int64]Booking    // the Location and user names are joined on read
+	bookingEvents map[int64]BookingEvent
+	reviews       map[int64]Review // the reviewer's name is joined on read
+	users         map[uuid.UUID]User
+	invitations   []memoryInvitation
+	tokens        []RefreshToken
+	locations     map[int64]memoryLocation
+
+	nextPostID         int64
+	nextCommentID      int64
+	nextLocationID     int64
+	nextApplicationID  int64
+	nextNotificationID int64
+	nextProfileID      int64
+	nextAvailabilityID int64
+	nextBookingID      int64
+	nextBookingEventID int64
+	nextReviewID       int64
+}
+
+type memoryInvitation struct {
+	token     string
+	userID    uuid.UUID
+	expiresAt time.Time
+}
+
+type memoryLocation struct {
+	Location
+	precise bool
+}
+
+// NewMemoryStorage returns a Storage kept entirely in process memory. It follows the Postgres
+// stores' error semantics and is meant for tests and running the API without a database.
+func NewMemoryStorage() Storage {
+	db := &memoryDB{
+		now: time.Now,
+		memoryTables: memoryTables{
+			posts:         map[int64]Post{},
+			comments:      map[int64]Comment{},
+			gigs:          map[int64]Gig{},
+			applications:  map[int64]Application{},
+			notifications: map[int64]Notification{},
+			profiles:      map[uuid.UUID]Profile{},
+			serviceAreas:  map[uuid.UUID]ServiceArea{},
+			availability:  map[int64]AvailabilityBlock{},
+			calendarFeeds: map[uuid.UUID]string{},
+			bookings:      map[int64]Booking{},
+			bookingEvents: map[int64]BookingEvent{},
+			reviews:       map[int64]Review{},
+			users:         map[uuid.UUID]User{},
+			locations:     map[int64]memoryLocation{},
+		},
+	}
+	s := newMemoryStorage(db)
+	s.withTx = func(ctx context.Context, fn func(Storage) error) error {
+		return db.withTx(func() error { return fn(newMemoryStorage(db)) })
+	}
+	return s
+}
+
+func newMemoryStorage(db *memoryDB) Storage {
+	return Storage{
+		Posts:         &memoryPostStore{db},
+		Users:         &memoryUserStore{db},
+		Comments:      &memoryCommentStore{db},
+		Gigs:          &memoryGigStore{db},
+		Applications:  &memoryApplicationStore{db},
+		Notifications: &memoryNotificationStore{db},
+		Profiles:      &memoryProfileStore{db},
+		ServiceAreas:  &memoryServiceAreaStore{db},
+		Availability:  &memoryAvailabilityStore{db},
+		Bookings:      &memoryBookingStore{db},
+		Reviews:       &memoryReviewStore{db},
+		Tokens:        &memoryTokenStore{db},
+		Locations:     &memoryLocationStore{db},
+	}
+}
+
+// withTx runs fn and restores every table if it fails. Units of work run one at a time,
+// but writes made outside WithTx while fn runs are lost on rollback.
+func (db *memoryDB) withTx(fn func() error) error {
+	db.txMu.Lock()
+	defer db.txMu.Unlock()
+
+	db.mu.Lock()
+	snapshot := memoryTables{
+		posts:              maps.Clone(db.posts),
+		comments:           maps.Clone(db.comments),
+		gigs:               maps.Clone(db.gigs),
+		applications:       maps.Clone(db.applications),
+		notifications:      maps.Clone(db.notifications),
+		profiles:           maps.Clone(db.profiles),
+		serviceAreas:       maps.Clone(db.serviceAreas),
+		availability:       maps.Clone(db.availability),
+		calendarFeeds:      maps.Clone(db.calendarFeeds),
+		bookings:           maps.Clone(db.bookings),
+		bookingEvents:      maps.Clone(db.bookingEvents),
+		reviews:            maps.Clone(db.reviews),
+		users:              maps.Clone(db.users),
+		invitations:        slices.Clone(db.invitations),
+		tokens:             slices.Clone(db.tokens),
+		locations:          maps.Clone(db.locations),
+		nextPostID:         db.nextPostID,
+		nextCommentID:      db.nextCommentID,
+		nextLocationID:     db.nextLocationID,
+		nextApplicationID:  db.nextApplicationID,
+		nextNotificationID: db.nextNotificationID,
+		nextProfileID:      db.nextProfileID,
+		nextAvailabilityID: db.nextAvailabilityID,
+		nextBookingID:      db.nextBookingID,
+		nextBookingEventID: db.nextBookingEventID,
+		nextReviewID:       db.nextReviewID,
+	}
+	db.mu.Unlock()
+
+	if err := fn(); err != nil {
+		db.mu.Lock()
+		db.memoryTables = snapshot
+		db.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// timestamp matches the timestamp(0) columns, which drop fractional seconds
+func (db *memoryDB) timestamp() time.Time {
+	return db.now().Truncate(time.Second)
+}
+
+func (db *memoryDB) userByEmail(email string) (User, bool) {
+	for _, u := range db.users {
+		if strings.EqualFold(u.Email, email) {
+			return u, true
+		}
+	}
+	return User{}, false
+}
+
+// deletePost removes a post and, like ON DELETE CASCADE, its gig, applications and comments
+func (db *memoryDB) deletePost(id int64) {
+	delete(db.posts, id)
+	delete(db.gigs, id)
+	maps.DeleteFunc(db.applications, func(_ int64, a Application) bool { return a.PostID == id })
+	for cid, c := range db.comments {
+		if c.PostID == id {
+			delete(db.comments, cid)
+		}
+	}
+}
+
+type memoryPostStore struct {
+	db *memoryDB
+}
+
+func (s *memoryPostStore) Create(ctx context.Context, post *Post) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[post.UserID]; !ok {
+		return fmt.Errorf("inserting post: %w", errForeignKey)
+	}
+	if post.Tags == nil {
+		post.Tags = []string{}
+	}
+
+	s.db.nextPostID++
+	post.ID = s.db.nextPostID
+	post.CreatedAt = s.db.timestamp()
+	post.UpdatedAt = post.CreatedAt
+
+	stored := *post
+	stored.Tags = slices.Clone(post.Tags)
+	stored.Version = 0
+	stored.Comments = nil
+	s.db.posts[post.ID] = stored
+	return nil
+}
+
+func (s *memoryPostStore) GetByID(ctx context.Context, postID int64) (*Post, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	post, ok := s.db.posts[postID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	post.Tags = slices.Clone(post.Tags)
+	return &post, nil
+}
+
+func (s *memoryPostStore) List(ctx context.Context, filter PostFilter) (pagination.Page[Post], error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	// direction is 1 for oldest first and -1 for newest first
+	direction := -1
+	if filter.Sort == PostSortOldest {
+		direction = 1
+	}
+
+	posts := []Post{}
+	for _, post := range s.db.posts {
+		switch {
+		case !tagsContain(post.Tags, filter.Tags),
+			filter.AuthorID != uuid.Nil && post.UserID != filter.AuthorID,
+			!filter.From.IsZero() && post.CreatedAt.Before(filter.From),
+			!filter.To.IsZero() && !post.CreatedAt.Before(filter.To),
+			filter.Page.After != nil && direction*compareCursor(postCursor(post), *filter.Page.After) <= 0:
+			continue
+		}
+		post.Tags = slices.Clone(post.Tags)
+		posts = append(posts, post)
+	}
+	slices.SortFunc(posts, func(a, b Post) int {
+		return direction * compareCursor(postCursor(a), postCursor(b))
+	})
+	return pagination.NewPage(firstN(posts, filter.Page.Limit+1), filter.Page.Limit, postCursor), nil
+}
+
+// tagsContain mirrors the Postgres array containment operator, tags @> want
+func tagsContain(tags, want []string) bool {
+	for _, tag := range want {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *memoryPostStore) Update(ctx context.Context, post *Post) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.update(post)
+}
+
+func (s *memoryPostStore) update(post *Post) error {
+	stored, ok := s.db.posts[post.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Version != post.Version {
+		return ErrEditConflict
+	}
+
+	stored.Title = post.Title
+	stored.Content = post.Content
+	stored.Tags = slices.Clone(post.Tags)
+	stored.Version++
+	stored.UpdatedAt = s.db.timestamp()
+	s.db.posts[post.ID] = stored
+
+	post.Version = stored.Version
+	return nil
+}
+
+func (s *memoryPostStore) Delete(ctx context.Context, postID int64, version int) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.posts[postID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Version != version {
+		return ErrEditConflict
+	}
+	s.db.deletePost(postID)
+	return nil
+}
+
+type memoryCommentStore struct {
+	db *memoryDB
+}
+
+func (s *memoryCommentStore) Create(ctx context.Context, comment *Comment) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.posts[comment.PostID]; !ok {
+		return fmt.Errorf("inserting comment: %w", errForeignKey)
+	}
+	if _, ok := s.db.users[comment.UserID]; !ok {
+		return fmt.Errorf("inserting comment: %w", errForeignKey)
+	}
+
+	s.db.nextCommentID++
+	comment.ID = s.db.nextCommentID
+	comment.CreatedAt = s.db.timestamp()
+	comment.UpdatedAt = comment.CreatedAt
+
+	stored := *comment
+	stored.User = User{}
+	s.db.comments[comment.ID] = stored
+	return nil
+}
+
+// withAuthor fills in the author's name the way the Postgres queries join users
+func (s *memoryCommentStore) withAuthor(c Comment) Comment {
+	author := s.db.users[c.UserID]
+	c.User = User{FirstName: author.FirstName, LastName: author.LastName}
+	return c
+}
+
+func (s *memoryCommentStore) GetByPostID(ctx context.Context, postID int64, page pagination.Params) (pagination.Page[Comment], error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	comments := []Comment{}
+	for _, c := range s.db.comments {
+		if c.PostID != postID {
+			continue
+		}
+		if page.After != nil && compareCursor(commentCursor(c), *page.After) >= 0 {
+			continue
+		}
+		comments = append(comments, s.withAuthor(c))
+	}
+	// Newest first; IDs break ties within the same second
+	slices.SortFunc(comments, func(a, b Comment) int {
+		return compareCursor(commentCursor(b), commentCursor(a))
+	})
+	return pagination.NewPage(firstN(comments, page.Limit+1), page.Limit, commentCursor), nil
+}
+
+// compareCursor orders rows the way ORDER BY created_at, id does
+func compareCursor(a, b pagination.Cursor) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+func firstN[T any](rows []T, n int) []T {
+	if len(rows) > n {
+		return rows[:n]
+	}
+	return rows
+}
+
+func (s *memoryCommentStore) GetByCommentID(ctx context.Context, commentID int64) (*Comment, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	c, ok := s.db.comments[commentID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c = s.withAuthor(c)
+	return &c, nil
+}
+
+func (s *memoryCommentStore) Update(ctx context.Context, comment *Comment) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.comments[comment.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Content = comment.Content
+	s.db.comments[comment.ID] = stored
+
+	comment.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (s *memoryCommentStore) DeleteByCommentID(ctx context.Context, commentID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.comments[commentID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.db.comments, commentID)
+	return nil
+}
+
+func (s *memoryCommentStore) DeleteByPostID(ctx context.Context, postID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for id, c := range s.db.comments {
+		if c.PostID == postID {
+			delete(s.db.comments, id)
+		}
+	}
+	return nil
+}
+
+type memoryGigStore struct {
+	db *memoryDB
+}
+
+func (s *memoryGigStore) Create(ctx context.Context, gig *Gig) error {
+	if gig.Location == nil || !gig.Location.IsValid() {
+		return fmt.Errorf("gig location is missing required fields (city, state, or zip code)")
+	}
+	if err := (&memoryPostStore{s.db}).Create(ctx, &gig.Post); err != nil {
+		return err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if gig.Gear == nil {
+		gig.Gear = []string{}
+	}
+	gig.LocationID = (&memoryLocationStore{s.db}).findOrCreate(gig.Location)
+	gig.Location.ID = gig.LocationID
+	gig.Status = GigOpen
+	s.save(*gig)
+	return nil
+}
+
+// save stores the gig fields; the post is kept in the posts table
+func (s *memoryGigStore) save(gig Gig) {
+	gig.Post = Post{ID: gig.ID}
+	gig.Gear = slices.Clone(gig.Gear)
+	gig.Location = nil
+	s.db.gigs[gig.ID] = gig
+}
+
+// load joins a stored gig with its post and location
+func (s *memoryGigStore) load(gig Gig) (Gig, bool) {
+	post, ok := s.db.posts[gig.ID]
+	if !ok {
+		return Gig{}, false
+	}
+	post.Tags = slices.Clone(post.Tags)
+	gig.Post = post
+	gig.Gear = slices.Clone(gig.Gear)
+	loc := s.db.locations[gig.LocationID].Location
+	gig.Location = &loc
+	return gig, true
+}
+
+func (s *memoryGigStore) GetByID(ctx context.Context, id int64) (*Gig, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.gigs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	gig, ok := s.load(stored)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &gig, nil
+}
+
+func (s *memoryGigStore) List(ctx context.Context, filter GigFilter) (pagination.Page[Gig], error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	gigs := []Gig{}
+	for _, stored := range s.db.gigs {
+		gig, ok := s.load(stored)
+		if !ok {
+			continue
+		}
+		switch {
+		case filter.Role != "" && gig.Role != filter.Role,
+			filter.Status != "" && gig.Status != filter.Status,
+			filter.UnionStatus != "" && gig.UnionStatus != filter.UnionStatus,
+			!filter.From.IsZero() && gig.ShootEnd.Before(filter.From.Time),
+			!filter.To.IsZero() && gig.ShootStart.After(filter.To.Time),
+			filter.MinRate > 0 && gig.DayRateMax < filter.MinRate,
+			filter.Currency != "" && gig.Currency != filter.Currency,
+			!tagsContain(gig.Gear, filter.Gear),
+			filter.Within != nil && !filter.Within.contains(*gig.Location),
+			filter.Page.After != nil && compareCursor(gigCursor(gig), *filter.Page.After) >= 0:
+			continue
+		}
+		gigs = append(gigs, gig)
+	}
+	slices.SortFunc(gigs, func(a, b Gig) int {
+		return compareCursor(gigCursor(b), gigCursor(a))
+	})
+	return pagination.NewPage(firstN(gigs, filter.Page.Limit+1), filter.Page.Limit, gigCursor), nil
+}
+
+func (s *memoryGigStore) Update(ctx context.Context, gig *Gig) error {
+	if gig.Location == nil || !gig.Location.IsValid() {
+		return fmt.Errorf("gig location is missing required fields (city, state, or zip code)")
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.gigs[gig.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if err := (&memoryPostStore{s.db}).update(&gig.Post); err != nil {
+		return err
+	}
+	gig.LocationID = (&memoryLocationStore{s.db}).findOrCreate(gig.Location)
+	gig.Location.ID = gig.LocationID
+	gig.Status = stored.Status
+	s.save(*gig)
+	return nil
+}
+
+func (s *memoryGigStore) UpdateStatus(ctx context.Context, gig *Gig, status GigStatus) error {
+	if !gig.Status.CanBecome(status) {
+		return ErrInvalidTransition
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.gigs[gig.ID]
+	post, postOK := s.db.posts[gig.ID]
+	switch {
+	case !ok || !postOK:
+		return ErrNotFound
+	case post.Version != gig.Version:
+		return ErrEditConflict
+	}
+
+	post.Version++
+	post.UpdatedAt = s.db.timestamp()
+	s.db.posts[gig.ID] = post
+	stored.Status = status
+	s.db.gigs[gig.ID] = stored
+
+	gig.Version = post.Version
+	gig.Status = status
+	return nil
+}
+
+type memoryUserStore struct {
+	db *memoryDB
+}
+
+// Create ignores tx; the memory store applies every write immediately
+func (s *memoryUserStore) Create(ctx context.Context, user *User, location *Location) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.create(user, location)
+}
+
+func (s *memoryUserStore) create(user *User, location *Location) error {
+	if location != nil && !location.IsValid() {
+		return fmt.Errorf("location provided but missing required fields (city, state, or zip code)")
+	}
+	if _, taken := s.db.userByEmail(user.Email); taken {
+		return ErrDuplicateEmail
+	}
+
+	var locationID int64
+	if location != nil {
+		locations := memoryLocationStore{s.db}
+		if existing, err := locations.getByLocation(location); err == nil {
+			locationID = existing.ID
+		} else {
+			locationID = locations.create(location).ID
+		}
+	}
+
+	user.ID = uuid.New()
+	user.CreatedAt = s.db.timestamp()
+	user.UpdatedAt = user.CreatedAt
+	user.LocationID = locationID
+	user.IsActive = false
+	user.Version = 0
+
+	stored := *user
+	stored.Location = nil
+	s.db.users[user.ID] = stored
+	return nil
+}
+
+func (s *memoryUserStore) GetByEmail(ctx context.Context, email string) (*User, error) {
+	user, err := s.GetByEmailWithPassword(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = password{}
+	return user, nil
+}
+
+func (s *memoryUserStore) GetByEmailWithPassword(ctx context.Context, email string) (*User, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	user, ok := s.db.userByEmail(email)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (s *memoryUserStore) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	// Same columns as the Postgres query
+	return &User{
+		ID:        stored.ID,
+		Email:     stored.Email,
+		FirstName: stored.FirstName,
+		LastName:  stored.LastName,
+		CreatedAt: stored.CreatedAt,
+		UpdatedAt: stored.UpdatedAt,
+		Version:   stored.Version,
+	}, nil
+}
+
+func (s *memoryUserStore) Update(ctx context.Context, user *User, location *Location) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.users[user.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Version != user.Version {
+		return ErrEditConflict
+	}
+	if other, taken := s.db.userByEmail(user.Email); taken && other.ID != user.ID {
+		return ErrDuplicateEmail
+	}
+
+	locationID := user.LocationID
+	if location != nil {
+		if existing, ok := s.db.locations[location.ID]; ok {
+			existing.Location = *location
+			s.db.locations[location.ID] = existing
+		} else {
+			s.db.nextLocationID++
+			location.ID = s.db.nextLocationID
+			s.db.locations[location.ID] = memoryLocation{Location: *location}
+		}
+		locationID = location.ID
+	}
+
+	stored.Email = user.Email
+	stored.FirstName = user.FirstName
+	stored.LastName = user.LastName
+	if user.Password.hash != nil {
+		stored.Password = user.Password
+	}
+	stored.LocationID = locationID
+	stored.Version++
+	stored.UpdatedAt = s.db.timestamp()
+	s.db.users[user.ID] = stored
+
+	user.Version = stored.Version
+	user.LocationID = locationID
+	return nil
+}
+
+// Delete removes the user and, like the ON DELETE CASCADE foreign keys, everything they own
+func (s *memoryUserStore) Delete(ctx context.Context, id uuid.UUID) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.db.users, id)
+
+	for postID, p := range s.db.posts {
+		if p.UserID == id {
+			s.db.deletePost(postID)
+		}
+	}
+	for commentID, c := range s.db.comments {
+		if c.UserID == id {
+			delete(s.db.comments, commentID)
+		}
+	}
+	maps.DeleteFunc(s.db.applications, func(_ int64, a Application) bool { return a.UserID == id })
+	maps.DeleteFunc(s.db.notifications, func(_ int64, n Notification) bool { return n.UserID == id })
+	delete(s.db.profiles, id)
+	delete(s.db.serviceAreas, id)
+	maps.DeleteFunc(s.db.availability, func(_ int64, b AvailabilityBlock) bool { return b.UserID == id })
+	delete(s.db.calendarFeeds, id)
+	maps.DeleteFunc(s.db.bookings, func(_ int64, b Booking) bool { return b.ProducerID == id || b.CinematographerID == id })
+	for eventID, e := range s.db.bookingEvents {
+		if _, ok := s.db.bookings[e.BookingID]; !ok {
+			delete(s.db.bookingEvents, eventID)
+		} else if e.ActorID != nil && *e.ActorID == id {
+			e.ActorID = nil
+			s.db.bookingEvents[eventID] = e
+		}
+	}
+	maps.DeleteFunc(s.db.reviews, func(_ int64, r Review) bool {
+		_, ok := s.db.bookings[r.BookingID]
+		return !ok || r.ReviewerID == id || r.RevieweeID == id
+	})
+	s.db.tokens = slices.DeleteFunc(s.db.tokens, func(t RefreshToken) bool { return t.UserID == id.String() })
+	s.db.invitations = slices.DeleteFunc(s.db.invitations, func(i memoryInvitation) bool { return i.userID == id })
+	return nil
+}
+
+func (s *memoryUserStore) CreateAndInvite(ctx context.Context, user *User, location *Location, token string, invitationExp time.Duration) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if err := s.create(user, location); err != nil {
+		return err
+	}
+	s.db.invitations = append(s.db.invitations, memoryInvitation{
+		token:     token,
+		userID:    user.ID,
+		expiresAt: s.db.now().Add(invitationExp),
+	})
+	return nil
+}
+
+// Activate expects the plain token; invitations store its SHA-256 hex digest
+func (s *memoryUserStore) Activate(ctx context.Context, token string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	hash := sha256.Sum256([]byte(token))
+	hashToken := hex.EncodeToString(hash[:])
+
+	now := s.db.now()
+	idx := slices.IndexFunc(s.db.invitations, func(i memoryInvitation) bool {
+		return i.token == hashToken && i.expiresAt.After(now)
+	})
+	if idx < 0 {
+		return ErrNotFound
+	}
+	userID := s.db.invitations[idx].userID
+
+	user, ok := s.db.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	user.IsActive = true
+	s.db.users[userID] = user
+
+	s.db.invitations = slices.DeleteFunc(s.db.invitations, func(i memoryInvitation) bool { return i.userID == userID })
+	return nil
+}
+
+func (s *memoryUserStore) GetHashedPassword(ctx context.Context, email string) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	user, ok := s.db.userByEmail(email)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return string(user.Password.hash), nil
+}
+
+type memoryTokenStore struct {
+	db *memoryDB
+}
+
+// UpdateRefreshToken replaces the token stored for the user's device, if any
+func (s *memoryTokenStore) UpdateRefreshToken(ctx context.Context, userID uuid.UUID, token string, storedFP string, expiresAt time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userID]; !ok {
+		return fmt.Errorf("inserting refresh token: %w", errForeignKey)
+	}
+
+	record := RefreshToken{UserID: userID.String(), TokenHash: token, StoredFP: storedFP, ExpiresAt: expiresAt}
+	for i, t := range s.db.tokens {
+		if t.UserID == record.UserID && t.StoredFP == storedFP {
+			s.db.tokens[i] = record
+			return nil
+		}
+	}
+	s.db.tokens = append(s.db.tokens, record)
+	return nil
+}
+
+func (s *memoryTokenStore) GetRefreshTokens(ctx context.Context, userID uuid.UUID) ([]*RefreshToken, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var tokens []*RefreshToken
+	for _, t := range s.db.tokens {
+		if t.UserID == userID.String() {
+			token := t
+			tokens = append(tokens, &token)
+		}
+	}
+	return tokens, nil
+}
+
+func (s *memoryTokenStore) GetByRefreshTokenHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, t := range s.db.tokens {
+		if t.TokenHash == tokenHash {
+			token := t
+			return &token, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+type memoryLocationStore struct {
+	db *memoryDB
+}
+
+// Create ignores tx; the memory store applies every write immediately
+func (s *memoryLocationStore) Create(ctx context.Context, location *Location) (Location, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.create(location), nil
+}
+
+// findOrCreate mirrors LocationStore.findOrCreate
+func (s *memoryLocationStore) findOrCreate(location *Location) int64 {
+	location.Normalize()
+	precise := location.Street != ""
+	for _, loc := range s.sorted() {
+		if loc.precise == precise &&
+			loc.Street == location.Street &&
+			loc.City == location.City &&
+			loc.State == location.State &&
+			loc.ZIPCode == location.ZIPCode &&
+			loc.Country == location.Country {
+			return loc.ID
+		}
+	}
+	return s.create(location).ID
+}
+
+func (s *memoryLocationStore) create(location *Location) Location {
+	precise := location.Street != ""
+	location.Normalize()
+
+	s.db.nextLocationID++
+	location.ID = s.db.nextLocationID
+	s.db.locations[location.ID] = memoryLocation{Location: *location, precise: precise}
+	return *location
+}
+
+func (s *memoryLocationStore) Get(ctx context.Context, id int64) (Location, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	loc, ok := s.db.locations[id]
+	if !ok {
+		return Location{}, ErrNotFound
+	}
+	return loc.Location, nil
+}
+
+func (s *memoryLocationStore) GetByLocation(ctx context.Context, location *Location) (Location, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.getByLocation(location)
+}
+
+// getByLocation matches general (non precise) locations field by field, like the Postgres query
+func (s *memoryLocationStore) getByLocation(location *Location) (Location, error) {
+	for _, loc := range s.sorted() {
+		if !loc.precise &&
+			loc.Street == location.Street &&
+			loc.City == location.City &&
+			loc.State == location.State &&
+			loc.ZIPCode == location.ZIPCode &&
+			loc.Country == location.Country {
+			return loc.Location, nil
+		}
+	}
+	return Location{}, ErrNotFound
+}
+
+func (s *memoryLocationStore) GetGeneralLocationByZip(ctx context.Context, zipCode string) (Location, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, loc := range s.sorted() {
+		if !loc.precise && loc.ZIPCode == zipCode {
+			return loc.Location, nil
+		}
+	}
+	return Location{}, ErrNotFound
+}
+
+func (s *memoryLocationStore) GetLocationsByBoundingBox(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]Location, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var locations []Location
+	for _, loc := range s.sorted() {
+		if loc.Latitude >= minLat && loc.Latitude <= maxLat && loc.Longitude >= minLon && loc.Longitude <= maxLon {
+			locations = append(locations, loc.Location)
+		}
+	}
+	return locations, nil
+}
+
+// sorted returns locations in insertion order so lookups are deterministic
+func (s *memoryLocationStore) sorted() []memoryLocation {
+	locations := make([]memoryLocation, 0, len(s.db.locations))
+	for _, loc := range s.db.locations {
+		locations = append(locations, loc)
+	}
+	slices.SortFunc(locations, func(a, b memoryLocation) int { return int(a.ID - b.ID) })
+	return locations
+}
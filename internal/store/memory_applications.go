@@ -0,0 +1,214 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+type memoryApplicationStore struct {
+	db *memoryDB
+}
+
+func (s *memoryApplicationStore) Create(ctx context.Context, application *Application) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.gigs[application.PostID]; !ok {
+		return fmt.Errorf("inserting application: %w", errForeignKey)
+	}
+	if _, ok := s.db.users[application.UserID]; !ok {
+		return fmt.Errorf("inserting application: %w", errForeignKey)
+	}
+	for _, a := range s.db.applications {
+		if a.PostID == application.PostID && a.UserID == application.UserID {
+			return ErrConflict
+		}
+	}
+	if application.PortfolioLinks == nil {
+		application.PortfolioLinks = []string{}
+	}
+
+	s.db.nextApplicationID++
+	application.ID = s.db.nextApplicationID
+	application.Status = ApplicationSubmitted
+	application.CreatedAt = s.db.timestamp()
+	application.UpdatedAt = application.CreatedAt
+
+	stored := *application
+	stored.PortfolioLinks = slices.Clone(application.PortfolioLinks)
+	stored.PostTitle, stored.PosterID, stored.User = "", uuid.Nil, User{}
+	s.db.applications[application.ID] = stored
+	return nil
+}
+
+// load joins a stored application with its post and applicant name
+func (s *memoryApplicationStore) load(a Application) Application {
+	a.PortfolioLinks = slices.Clone(a.PortfolioLinks)
+	post := s.db.posts[a.PostID]
+	a.PostTitle, a.PosterID = post.Title, post.UserID
+	applicant := s.db.users[a.UserID]
+	a.User = User{FirstName: applicant.FirstName, LastName: applicant.LastName}
+	return a
+}
+
+func (s *memoryApplicationStore) GetByID(ctx context.Context, id int64) (*Application, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.applications[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	a := s.load(stored)
+	return &a, nil
+}
+
+func (s *memoryApplicationStore) ListByPost(ctx context.Context, postID int64, filter ApplicationFilter) (pagination.Page[Application], error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	applications := []Application{}
+	for _, a := range s.db.applications {
+		switch {
+		case a.PostID != postID,
+			filter.Status != "" && a.Status != filter.Status,
+			filter.Page.After != nil && compareApplicationRank(rankedApplicationCursor(a), *filter.Page.After) <= 0:
+			continue
+		}
+		applications = append(applications, s.load(a))
+	}
+	slices.SortFunc(applications, func(a, b Application) int {
+		return compareApplicationRank(rankedApplicationCursor(a), rankedApplicationCursor(b))
+	})
+	return pagination.NewPage(firstN(applications, filter.Page.Limit+1), filter.Page.Limit, rankedApplicationCursor), nil
+}
+
+// compareApplicationRank orders applicants like ORDER BY rank, created_at, id
+func compareApplicationRank(a, b pagination.Cursor) int {
+	if c := cmp.Compare(a.Score, b.Score); c != 0 {
+		return c
+	}
+	return compareCursor(a, b)
+}
+
+func (s *memoryApplicationStore) ListByUser(ctx context.Context, userID uuid.UUID, page pagination.Params) (pagination.Page[Application], error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	applications := []Application{}
+	for _, a := range s.db.applications {
+		if a.UserID != userID || page.After != nil && compareCursor(applicationCursor(a), *page.After) >= 0 {
+			continue
+		}
+		applications = append(applications, s.load(a))
+	}
+	slices.SortFunc(applications, func(a, b Application) int {
+		return compareCursor(applicationCursor(b), applicationCursor(a))
+	})
+	return pagination.NewPage(firstN(applications, page.Limit+1), page.Limit, applicationCursor), nil
+}
+
+func (s *memoryApplicationStore) UpdateStatus(ctx context.Context, application *Application, status ApplicationStatus) error {
+	if !application.Status.CanBecome(status) {
+		return ErrInvalidTransition
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.applications[application.ID]
+	switch {
+	case !ok:
+		return ErrNotFound
+	case stored.Status != application.Status:
+		return ErrEditConflict
+	}
+
+	stored.Status = status
+	stored.UpdatedAt = s.db.timestamp()
+	s.db.applications[application.ID] = stored
+
+	application.Status = status
+	application.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (s *memoryApplicationStore) CloseByPostID(ctx context.Context, postID int64) ([]Application, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	closed := []Application{}
+	for id, a := range s.db.applications {
+		if a.PostID != postID || !a.Status.open() {
+			continue
+		}
+		a.Status = ApplicationClosed
+		a.UpdatedAt = s.db.timestamp()
+		s.db.applications[id] = a
+		a.PortfolioLinks = slices.Clone(a.PortfolioLinks)
+		closed = append(closed, a)
+	}
+	slices.SortFunc(closed, func(a, b Application) int { return cmp.Compare(a.ID, b.ID) })
+	return closed, nil
+}
+
+type memoryNotificationStore struct {
+	db *memoryDB
+}
+
+func (s *memoryNotificationStore) Create(ctx context.Context, notification *Notification) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[notification.UserID]; !ok {
+		return errForeignKey
+	}
+
+	s.db.nextNotificationID++
+	notification.ID = s.db.nextNotificationID
+	notification.CreatedAt = s.db.timestamp()
+	notification.ReadAt = nil
+	s.db.notifications[notification.ID] = *notification
+	return nil
+}
+
+func (s *memoryNotificationStore) ListByUser(ctx context.Context, userID uuid.UUID, filter NotificationFilter) (pagination.Page[Notification], error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	notifications := []Notification{}
+	for _, n := range s.db.notifications {
+		switch {
+		case n.UserID != userID,
+			filter.UnreadOnly && n.ReadAt != nil,
+			filter.Page.After != nil && compareCursor(notificationCursor(n), *filter.Page.After) >= 0:
+			continue
+		}
+		notifications = append(notifications, n)
+	}
+	slices.SortFunc(notifications, func(a, b Notification) int {
+		return compareCursor(notificationCursor(b), notificationCursor(a))
+	})
+	return pagination.NewPage(firstN(notifications, filter.Page.Limit+1), filter.Page.Limit, notificationCursor), nil
+}
+
+func (s *memoryNotificationStore) MarkRead(ctx context.Context, userID uuid.UUID, id int64) (*Notification, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	n, ok := s.db.notifications[id]
+	if !ok || n.UserID != userID {
+		return nil, ErrNotFound
+	}
+	if n.ReadAt == nil {
+		now := s.db.timestamp()
+		n.ReadAt = &now
+		s.db.notifications[id] = n
+	}
+	return &n, nil
+}
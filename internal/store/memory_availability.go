@@ -0,0 +1,172 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryAvailabilityStore struct {
+	db *memoryDB
+}
+
+// booked reports whether any of the user's booked blocks overlaps [from, to)
+func (db *memoryDB) booked(userID uuid.UUID, from, to time.Time) bool {
+	for _, b := range db.availability {
+		if b.UserID == userID && b.Status == AvailabilityBooked && b.overlaps(from, to) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memoryAvailabilityStore) Create(ctx context.Context, block *AvailabilityBlock) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.insert(block)
+}
+
+func (s *memoryAvailabilityStore) insert(block *AvailabilityBlock) error {
+	if _, ok := s.db.users[block.UserID]; !ok {
+		return fmt.Errorf("inserting availability block: %w", errForeignKey)
+	}
+	if block.Source == "" {
+		block.Source = AvailabilityManual
+	}
+
+	s.db.nextAvailabilityID++
+	block.ID = s.db.nextAvailabilityID
+	block.CreatedAt = s.db.timestamp()
+	block.UpdatedAt = block.CreatedAt
+	s.db.availability[block.ID] = *block
+	return nil
+}
+
+func (s *memoryAvailabilityStore) GetByID(ctx context.Context, id int64) (*AvailabilityBlock, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	b, ok := s.db.availability[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &b, nil
+}
+
+func (s *memoryAvailabilityStore) ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]AvailabilityBlock, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	blocks := []AvailabilityBlock{}
+	for _, b := range s.db.availability {
+		if b.UserID == userID && b.overlaps(from, to) {
+			blocks = append(blocks, b)
+		}
+	}
+	slices.SortFunc(blocks, func(a, b AvailabilityBlock) int {
+		return cmp.Or(a.StartsAt.Compare(b.StartsAt), cmp.Compare(a.ID, b.ID))
+	})
+	return blocks, nil
+}
+
+// LockCalendar only checks the user exists; transactions on the memory store already run
+// one at a time
+func (s *memoryAvailabilityStore) LockCalendar(ctx context.Context, userID uuid.UUID) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userID]; !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *memoryAvailabilityStore) Update(ctx context.Context, block *AvailabilityBlock) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.availability[block.ID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	stored.Status, stored.StartsAt, stored.EndsAt = block.Status, block.StartsAt, block.EndsAt
+	stored.TimeZone, stored.Note = block.TimeZone, block.Note
+	if stored.Source == AvailabilityImported {
+		stored.Source = AvailabilityManual
+	}
+	stored.UpdatedAt = s.db.timestamp()
+	s.db.availability[block.ID] = stored
+	block.Source, block.UpdatedAt = stored.Source, stored.UpdatedAt
+	return nil
+}
+
+func (s *memoryAvailabilityStore) Delete(ctx context.Context, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.availability[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.db.availability, id)
+	return nil
+}
+
+func (s *memoryAvailabilityStore) ReplaceImported(ctx context.Context, userID uuid.UUID, blocks []AvailabilityBlock) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userID]; !ok {
+		return fmt.Errorf("inserting availability block: %w", errForeignKey)
+	}
+	maps.DeleteFunc(s.db.availability, func(_ int64, b AvailabilityBlock) bool {
+		return b.UserID == userID && b.Source == AvailabilityImported
+	})
+	for i := range blocks {
+		blocks[i].UserID, blocks[i].Source = userID, AvailabilityImported
+		if err := s.insert(&blocks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryAvailabilityStore) SetFeedToken(ctx context.Context, userID uuid.UUID, tokenHash string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userID]; !ok {
+		return ErrNotFound
+	}
+	s.db.calendarFeeds[userID] = tokenHash
+	return nil
+}
+
+func (s *memoryAvailabilityStore) DeleteFeedToken(ctx context.Context, userID uuid.UUID) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.calendarFeeds[userID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.db.calendarFeeds, userID)
+	return nil
+}
+
+func (s *memoryAvailabilityStore) UserIDByFeedToken(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for userID, hash := range s.db.calendarFeeds {
+		if hash == tokenHash {
+			return userID, nil
+		}
+	}
+	return uuid.Nil, ErrNotFound
+}
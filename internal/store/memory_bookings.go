@@ -0,0 +1,167 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+type memoryBookingStore struct {
+	db *memoryDB
+}
+
+func (s *memoryBookingStore) Create(ctx context.Context, booking *Booking) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if booking.Location == nil || !booking.Location.IsValid() {
+		return fmt.Errorf("booking location is missing required fields (city, state, or zip code)")
+	}
+	for _, id := range []uuid.UUID{booking.ProducerID, booking.CinematographerID} {
+		if _, ok := s.db.users[id]; !ok {
+			return fmt.Errorf("inserting booking: %w", errForeignKey)
+		}
+	}
+	booking.LocationID = (&memoryLocationStore{s.db}).findOrCreate(booking.Location)
+	booking.Location.ID = booking.LocationID
+
+	s.db.nextBookingID++
+	booking.ID = s.db.nextBookingID
+	booking.Status = BookingPending
+	booking.Version = 0
+	booking.CreatedAt = s.db.timestamp()
+	booking.UpdatedAt = booking.CreatedAt
+
+	stored := *booking
+	stored.Location, stored.HoldRank = nil, 0
+	stored.Producer, stored.Cinematographer = User{}, User{}
+	s.db.bookings[booking.ID] = stored
+	return nil
+}
+
+// load joins a stored booking with its location, the parties' names and its hold rank
+func (s *memoryBookingStore) load(b Booking) Booking {
+	loc := s.db.locations[b.LocationID].Location
+	b.Location = &loc
+	producer, cinematographer := s.db.users[b.ProducerID], s.db.users[b.CinematographerID]
+	b.Producer = User{ID: b.ProducerID, FirstName: producer.FirstName, LastName: producer.LastName}
+	b.Cinematographer = User{ID: b.CinematographerID, FirstName: cinematographer.FirstName, LastName: cinematographer.LastName}
+	if b.Kind == BookingHold && b.Status.Open() {
+		b.HoldRank = 1
+		for _, o := range s.db.bookings {
+			if o.ID < b.ID && o.CinematographerID == b.CinematographerID && o.Kind == BookingHold && o.Status.Open() && o.overlaps(b) {
+				b.HoldRank++
+			}
+		}
+	}
+	return b
+}
+
+func (s *memoryBookingStore) GetByID(ctx context.Context, id int64) (*Booking, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	b, ok := s.db.bookings[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	b = s.load(b)
+	return &b, nil
+}
+
+func (s *memoryBookingStore) ListByUser(ctx context.Context, userID uuid.UUID, filter BookingFilter) (pagination.Page[Booking], error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	bookings := []Booking{}
+	for _, b := range s.db.bookings {
+		switch {
+		case filter.Role == BookingAsProducer && b.ProducerID != userID,
+			filter.Role == BookingAsCinematographer && b.CinematographerID != userID,
+			b.ProducerID != userID && b.CinematographerID != userID,
+			filter.Status != "" && b.Status != filter.Status,
+			filter.Page.After != nil && compareCursor(bookingCursor(b), *filter.Page.After) >= 0:
+			continue
+		}
+		bookings = append(bookings, s.load(b))
+	}
+	slices.SortFunc(bookings, func(a, b Booking) int {
+		return compareCursor(bookingCursor(b), bookingCursor(a))
+	})
+	return pagination.NewPage(firstN(bookings, filter.Page.Limit+1), filter.Page.Limit, bookingCursor), nil
+}
+
+func (s *memoryBookingStore) ListDueToExpire(ctx context.Context, now time.Time) ([]Booking, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	bookings := []Booking{}
+	for _, b := range s.db.bookings {
+		if b.DueToExpire(now) {
+			bookings = append(bookings, s.load(b))
+		}
+	}
+	slices.SortFunc(bookings, func(a, b Booking) int {
+		if c := a.ExpiresAt.Compare(*b.ExpiresAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return bookings, nil
+}
+
+func (s *memoryBookingStore) Update(ctx context.Context, booking *Booking) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	b, ok := s.db.bookings[booking.ID]
+	switch {
+	case !ok:
+		return ErrNotFound
+	case b.Version != booking.Version:
+		return ErrEditConflict
+	}
+
+	b.Status, b.StartsAt, b.EndsAt, b.TimeZone = booking.Status, booking.StartsAt, booking.EndsAt, booking.TimeZone
+	b.Rate, b.ExpiresAt, b.AvailabilityBlockID = booking.Rate, booking.ExpiresAt, booking.AvailabilityBlockID
+	b.Version++
+	b.UpdatedAt = s.db.timestamp()
+	s.db.bookings[b.ID] = b
+
+	booking.Version, booking.UpdatedAt = b.Version, b.UpdatedAt
+	return nil
+}
+
+func (s *memoryBookingStore) AddEvent(ctx context.Context, event *BookingEvent) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.bookings[event.BookingID]; !ok {
+		return fmt.Errorf("inserting booking event: %w", errForeignKey)
+	}
+
+	s.db.nextBookingEventID++
+	event.ID = s.db.nextBookingEventID
+	event.CreatedAt = s.db.timestamp()
+	s.db.bookingEvents[event.ID] = *event
+	return nil
+}
+
+func (s *memoryBookingStore) ListEvents(ctx context.Context, bookingID int64) ([]BookingEvent, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	events := []BookingEvent{}
+	for _, e := range s.db.bookingEvents {
+		if e.BookingID == bookingID {
+			events = append(events, e)
+		}
+	}
+	slices.SortFunc(events, func(a, b BookingEvent) int { return cmp.Compare(a.ID, b.ID) })
+	return events, nil
+}
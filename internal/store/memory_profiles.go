@@ -0,0 +1,216 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+type memoryProfileStore struct {
+	db *memoryDB
+}
+
+// save stores a copy of the profile without the fields joined from the user
+func (s *memoryProfileStore) save(profile *Profile) {
+	stored := *profile
+	stored.Roles = slices.Clone(profile.Roles)
+	stored.Specialties = slices.Clone(profile.Specialties)
+	stored.Languages = slices.Clone(profile.Languages)
+	stored.Unions = slices.Clone(profile.Unions)
+	stored.Links = slices.Clone(profile.Links)
+	stored.FirstName, stored.LastName, stored.City, stored.State, stored.Country = "", "", "", "", ""
+	stored.Latitude, stored.Longitude = 0, 0
+	stored.TravelMiles, stored.ServicePlaces = 0, nil
+	s.db.profiles[profile.UserID] = stored
+}
+
+func (s *memoryProfileStore) GetByUserID(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.profiles[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	p := s.load(stored)
+	return &p, nil
+}
+
+// load joins a stored profile with the user's name, location and rating
+func (s *memoryProfileStore) load(p Profile) Profile {
+	p.Roles = slices.Clone(p.Roles)
+	p.Specialties = slices.Clone(p.Specialties)
+	p.Languages = slices.Clone(p.Languages)
+	p.Unions = slices.Clone(p.Unions)
+	p.Links = slices.Clone(p.Links)
+
+	user := s.db.users[p.UserID]
+	p.FirstName, p.LastName = user.FirstName, user.LastName
+	if l, ok := s.db.locations[user.LocationID]; ok {
+		p.City, p.State, p.Country = l.City, l.State, l.Country
+		p.Latitude, p.Longitude = l.Latitude, l.Longitude
+	}
+	area := s.db.serviceArea(p.UserID)
+	p.TravelMiles, p.ServicePlaces = area.TravelMiles, area.Places
+	p.Rating = s.db.ratingSummary(p.UserID)
+	return p
+}
+
+// match mirrors profileMatchesSQL: how the user matches the search, preferring their home over
+// a service place, and their distance from the searched point
+func (s *memoryProfileStore) match(user User, filter ProfileFilter) (ProfileMatch, bool) {
+	home, hasHome := s.db.locations[user.LocationID]
+	homeKm := 0.0
+	if hasHome {
+		homeKm = distanceKm(filter.Latitude, filter.Longitude, home.Latitude, home.Longitude)
+	}
+
+	area := s.db.serviceArea(user.ID)
+	switch {
+	case hasHome && homeKm <= filter.RadiusMiles*KmPerMile:
+		return ProfileMatch{DistanceKm: homeKm, MatchedBy: MatchedNearby}, true
+	case hasHome && area.TravelMiles > 0 && homeKm <= float64(area.TravelMiles)*KmPerMile:
+		return ProfileMatch{DistanceKm: homeKm, MatchedBy: MatchedTravelRadius}, true
+	}
+	placeKm := math.Inf(1)
+	for _, p := range area.Places {
+		if km := distanceKm(filter.Latitude, filter.Longitude, p.Location.Latitude, p.Location.Longitude); km <= float64(p.Miles)*KmPerMile {
+			placeKm = min(placeKm, km)
+		}
+	}
+	if math.IsInf(placeKm, 1) {
+		return ProfileMatch{}, false
+	}
+	if !hasHome {
+		homeKm = placeKm
+	}
+	return ProfileMatch{DistanceKm: homeKm, MatchedBy: MatchedServiceArea}, true
+}
+
+func (s *memoryProfileStore) Search(ctx context.Context, filter ProfileFilter) (pagination.Page[ProfileMatch], error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	matches := []ProfileMatch{}
+	for _, stored := range s.db.profiles {
+		user := s.db.users[stored.UserID]
+		if !user.IsActive {
+			continue
+		}
+		m, ok := s.match(user, filter)
+		if !ok {
+			continue
+		}
+		m.Profile = s.load(stored)
+		switch {
+		case filter.Role != "" && !slices.Contains(stored.Roles, filter.Role),
+			filter.MaxRate > 0 && (stored.DayRateMin == 0 || stored.DayRateMin > filter.MaxRate),
+			filter.Currency != "" && stored.Currency != filter.Currency,
+			!filter.FreeFrom.IsZero() && !filter.FreeTo.IsZero() && s.db.booked(user.ID, filter.FreeFrom, filter.FreeTo),
+			filter.Page.After != nil && compareScore(filter.cursor(m), *filter.Page.After) <= 0:
+			continue
+		}
+		matches = append(matches, m)
+	}
+	slices.SortFunc(matches, func(a, b ProfileMatch) int {
+		return compareScore(filter.cursor(a), filter.cursor(b))
+	})
+	return pagination.NewPage(firstN(matches, filter.Page.Limit+1), filter.Page.Limit, filter.cursor), nil
+}
+
+// compareScore orders cursors like ORDER BY score, id
+func compareScore(a, b pagination.Cursor) int {
+	return cmp.Or(cmp.Compare(a.Score, b.Score), cmp.Compare(a.ID, b.ID))
+}
+
+func (s *memoryProfileStore) Create(ctx context.Context, profile *Profile) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[profile.UserID]; !ok {
+		return fmt.Errorf("inserting profile: %w", errForeignKey)
+	}
+	if _, ok := s.db.profiles[profile.UserID]; ok {
+		return ErrConflict
+	}
+
+	profile.setDefaults()
+	s.db.nextProfileID++
+	profile.ID = s.db.nextProfileID
+	profile.Version = 0
+	profile.CreatedAt = s.db.timestamp()
+	profile.UpdatedAt = profile.CreatedAt
+	s.save(profile)
+	return nil
+}
+
+func (s *memoryProfileStore) Update(ctx context.Context, profile *Profile) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	stored, ok := s.db.profiles[profile.UserID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Version != profile.Version {
+		return ErrEditConflict
+	}
+
+	profile.setDefaults()
+	profile.Version++
+	profile.ID, profile.CreatedAt = stored.ID, stored.CreatedAt
+	profile.UpdatedAt = s.db.timestamp()
+	s.save(profile)
+	return nil
+}
+
+type memoryServiceAreaStore struct {
+	db *memoryDB
+}
+
+// serviceArea resolves the user's stored service area, which is empty when they never set one
+func (db *memoryDB) serviceArea(userID uuid.UUID) ServiceArea {
+	stored := db.serviceAreas[userID]
+	area := ServiceArea{UserID: userID, TravelMiles: stored.TravelMiles, Places: []ServicePlace{}}
+	for _, p := range stored.Places {
+		l := db.locations[p.Location.ID].Location
+		area.Places = append(area.Places, ServicePlace{Name: p.Name, Miles: p.Miles, Location: &l})
+	}
+	return area
+}
+
+func (s *memoryServiceAreaStore) Get(ctx context.Context, userID uuid.UUID) (*ServiceArea, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userID]; !ok {
+		return nil, ErrNotFound
+	}
+	area := s.db.serviceArea(userID)
+	return &area, nil
+}
+
+func (s *memoryServiceAreaStore) Replace(ctx context.Context, area *ServiceArea) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[area.UserID]; !ok {
+		return ErrNotFound
+	}
+	if area.Places == nil {
+		area.Places = []ServicePlace{}
+	}
+
+	stored := ServiceArea{UserID: area.UserID, TravelMiles: area.TravelMiles}
+	for _, p := range area.Places {
+		p.Location.ID = (&memoryLocationStore{s.db}).findOrCreate(p.Location)
+		stored.Places = append(stored.Places, ServicePlace{Name: p.Name, Miles: p.Miles, Location: &Location{ID: p.Location.ID}})
+	}
+	s.db.serviceAreas[area.UserID] = stored
+	return nil
+}
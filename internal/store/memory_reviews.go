@@ -0,0 +1,151 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+type memoryReviewStore struct {
+	db *memoryDB
+}
+
+// ratingSummary averages the revealed reviews the user received as a cinematographer
+func (db *memoryDB) ratingSummary(userID uuid.UUID) RatingSummary {
+	now := db.now()
+	received := []Review{}
+	for _, r := range db.reviews {
+		if r.RevieweeID == userID && r.RevieweeRole == BookingAsCinematographer && r.Visible(now) {
+			received = append(received, r)
+		}
+	}
+	return summarize(received)
+}
+
+func (s *memoryReviewStore) Create(ctx context.Context, review *Review) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.bookings[review.BookingID]; !ok {
+		return fmt.Errorf("inserting review: %w", errForeignKey)
+	}
+	for _, id := range []uuid.UUID{review.ReviewerID, review.RevieweeID} {
+		if _, ok := s.db.users[id]; !ok {
+			return fmt.Errorf("inserting review: %w", errForeignKey)
+		}
+	}
+	var other *Review
+	for _, r := range s.db.reviews {
+		if r.BookingID != review.BookingID {
+			continue
+		}
+		if r.ReviewerID == review.ReviewerID {
+			return ErrConflict
+		}
+		other = &r
+	}
+
+	s.db.nextReviewID++
+	review.ID = s.db.nextReviewID
+	review.Response, review.RespondedAt = "", nil
+	review.VisibleAt = review.VisibleAt.Truncate(time.Second)
+	review.CreatedAt = s.db.timestamp()
+	review.UpdatedAt = review.CreatedAt
+	if other != nil {
+		// The second review reveals both
+		review.VisibleAt = minTime(review.VisibleAt, review.CreatedAt)
+		other.VisibleAt = minTime(other.VisibleAt, review.CreatedAt)
+		other.UpdatedAt = review.CreatedAt
+		s.db.reviews[other.ID] = *other
+	}
+
+	stored := *review
+	stored.Reviewer = User{}
+	s.db.reviews[review.ID] = stored
+	return nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// load joins a stored review with the reviewer's name
+func (s *memoryReviewStore) load(r Review) Review {
+	reviewer := s.db.users[r.ReviewerID]
+	r.Reviewer = User{ID: r.ReviewerID, FirstName: reviewer.FirstName, LastName: reviewer.LastName}
+	return r
+}
+
+func (s *memoryReviewStore) GetByID(ctx context.Context, id int64) (*Review, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	r, ok := s.db.reviews[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	r = s.load(r)
+	return &r, nil
+}
+
+func (s *memoryReviewStore) ListByBooking(ctx context.Context, bookingID int64) ([]Review, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	reviews := []Review{}
+	for _, r := range s.db.reviews {
+		if r.BookingID == bookingID {
+			reviews = append(reviews, s.load(r))
+		}
+	}
+	slices.SortFunc(reviews, func(a, b Review) int { return cmp.Compare(a.ID, b.ID) })
+	return reviews, nil
+}
+
+func (s *memoryReviewStore) ListReceived(ctx context.Context, userID uuid.UUID, filter ReviewFilter) (pagination.Page[Review], error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	now := s.db.now()
+	reviews := []Review{}
+	for _, r := range s.db.reviews {
+		switch {
+		case r.RevieweeID != userID, !r.Visible(now),
+			filter.Role != "" && r.RevieweeRole != filter.Role,
+			filter.Page.After != nil && compareCursor(reviewCursor(r), *filter.Page.After) >= 0:
+			continue
+		}
+		reviews = append(reviews, s.load(r))
+	}
+	slices.SortFunc(reviews, func(a, b Review) int {
+		return compareCursor(reviewCursor(b), reviewCursor(a))
+	})
+	return pagination.NewPage(firstN(reviews, filter.Page.Limit+1), filter.Page.Limit, reviewCursor), nil
+}
+
+func (s *memoryReviewStore) Respond(ctx context.Context, review *Review) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	r, ok := s.db.reviews[review.ID]
+	switch {
+	case !ok:
+		return ErrNotFound
+	case r.Response != "":
+		return ErrConflict
+	}
+
+	respondedAt := s.db.timestamp()
+	r.Response, r.RespondedAt, r.UpdatedAt = review.Response, &respondedAt, respondedAt
+	s.db.reviews[r.ID] = r
+	review.RespondedAt, review.UpdatedAt = r.RespondedAt, r.UpdatedAt
+	return nil
+}
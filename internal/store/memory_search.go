@@ -0,0 +1,260 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+// The memory store approximates Postgres full-text search closely enough for tests:
+// websearch syntax, English stop words, a crude plural stemmer, and ts_rank's default
+// weights for title (A), tags (B) and content (C). Scores differ from ts_rank but order
+// matches the same way.
+const (
+	weightTitle   = 1.0
+	weightTags    = 0.4
+	weightContent = 0.2
+
+	headlineWords = 35
+)
+
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"the": true, "to": true, "with": true,
+}
+
+// searchClause is a word or quoted phrase from the query, possibly negated with a leading -
+type searchClause struct {
+	terms   []string
+	negated bool
+}
+
+// parseWebSearch splits a query into alternatives separated by "or", each a list of clauses
+// that must all hold, like websearch_to_tsquery. Stop words are dropped.
+func parseWebSearch(query string) [][]searchClause {
+	var (
+		alternatives [][]searchClause
+		current      []searchClause
+	)
+	for len(query) > 0 {
+		query = strings.TrimLeftFunc(query, unicode.IsSpace)
+		if query == "" {
+			break
+		}
+
+		negated := strings.HasPrefix(query, "-")
+		if negated {
+			query = query[1:]
+		}
+
+		var word string
+		if strings.HasPrefix(query, `"`) {
+			phrase, rest, _ := strings.Cut(query[1:], `"`)
+			word, query = phrase, rest
+		} else {
+			end := strings.IndexFunc(query, unicode.IsSpace)
+			if end < 0 {
+				end = len(query)
+			}
+			word, query = query[:end], query[end:]
+		}
+
+		if !negated && strings.EqualFold(word, "or") {
+			if len(current) > 0 {
+				alternatives = append(alternatives, current)
+			}
+			current = nil
+			continue
+		}
+		if terms := searchTerms(word); len(terms) > 0 {
+			current = append(current, searchClause{terms: terms, negated: negated})
+		}
+	}
+	if len(current) > 0 {
+		alternatives = append(alternatives, current)
+	}
+	return alternatives
+}
+
+// searchTerms lower-cases, splits and stems text the same way for documents and queries
+func searchTerms(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if searchStopWords[word] {
+			continue
+		}
+		terms = append(terms, stemTerm(word))
+	}
+	return terms
+}
+
+func stemTerm(word string) string {
+	if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+// searchDocument is a post split into the weighted fields that make up its tsvector
+type searchDocument struct {
+	title, tags, content []string
+}
+
+func newSearchDocument(post Post) searchDocument {
+	return searchDocument{
+		title:   searchTerms(post.Title),
+		tags:    searchTerms(strings.Join(post.Tags, " ")),
+		content: searchTerms(post.Content),
+	}
+}
+
+// weight is the best weight of a field containing every term of a clause, or 0
+func (d searchDocument) weight(terms []string) float64 {
+	for _, field := range []struct {
+		terms  []string
+		weight float64
+	}{{d.title, weightTitle}, {d.tags, weightTags}, {d.content, weightContent}} {
+		if containsAll(field.terms, terms) {
+			return field.weight
+		}
+	}
+	return 0
+}
+
+func containsAll(haystack, needles []string) bool {
+	for _, n := range needles {
+		if !slices.Contains(haystack, n) {
+			return false
+		}
+	}
+	return true
+}
+
+// rank scores the document against the query, returning false when it does not match
+func (d searchDocument) rank(alternatives [][]searchClause) (float64, bool) {
+	best, matched := 0.0, false
+	for _, clauses := range alternatives {
+		score, ok := 0.0, true
+		for _, c := range clauses {
+			w := d.weight(c.terms)
+			if c.negated {
+				ok = ok && w == 0
+				continue
+			}
+			ok = ok && w > 0
+			score += w
+		}
+		if ok {
+			matched = true
+			best = max(best, score)
+		}
+	}
+	return best, matched
+}
+
+// headline excerpts content around the first match, marking matching words like ts_headline
+func headline(content string, alternatives [][]searchClause) string {
+	wanted := map[string]bool{}
+	for _, clauses := range alternatives {
+		for _, c := range clauses {
+			if c.negated {
+				continue
+			}
+			for _, t := range c.terms {
+				wanted[t] = true
+			}
+		}
+	}
+
+	matches := func(word string) bool {
+		for _, t := range searchTerms(word) {
+			if wanted[t] {
+				return true
+			}
+		}
+		return false
+	}
+
+	words := strings.Fields(content)
+	start := max(0, slices.IndexFunc(words, matches)-headlineWords/4)
+	words = words[start:min(len(words), start+headlineWords)]
+	for i, w := range words {
+		if matches(w) {
+			words[i] = highlightStart + w + highlightStop
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func (s *memoryPostStore) Search(ctx context.Context, search PostSearch) (PostSearchResults, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	alternatives := parseWebSearch(search.Query)
+	results := []PostSearchResult{}
+	counts := map[string]int{}
+	for _, post := range s.db.posts {
+		if !tagsContain(post.Tags, search.Tags) || !s.authorWithin(post, search.Within) {
+			continue
+		}
+		rank, ok := newSearchDocument(post).rank(alternatives)
+		if !ok {
+			continue
+		}
+		for _, tag := range post.Tags {
+			counts[tag]++
+		}
+
+		result := PostSearchResult{Post: post, Rank: rank}
+		if after := search.Page.After; after != nil && compareRanked(searchCursor(result), *after) <= 0 {
+			continue
+		}
+		result.Tags = slices.Clone(post.Tags)
+		result.Highlight = markHighlights(headline(post.Content, alternatives))
+		results = append(results, result)
+	}
+
+	slices.SortFunc(results, func(a, b PostSearchResult) int {
+		return compareRanked(searchCursor(a), searchCursor(b))
+	})
+
+	facets := []TagFacet{}
+	for tag, count := range counts {
+		facets = append(facets, TagFacet{Tag: tag, Count: count})
+	}
+	slices.SortFunc(facets, func(a, b TagFacet) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Tag, b.Tag)
+	})
+
+	return PostSearchResults{
+		Page: pagination.NewPage(firstN(results, search.Page.Limit+1), search.Page.Limit, searchCursor),
+		Tags: firstN(facets, MaxTagFacets),
+	}, nil
+}
+
+// compareRanked orders best match first, like ORDER BY rank DESC, id DESC
+func compareRanked(a, b pagination.Cursor) int {
+	if c := cmp.Compare(b.Score, a.Score); c != 0 {
+		return c
+	}
+	return cmp.Compare(b.ID, a.ID)
+}
+
+// authorWithin reports whether the post's author is located inside box; nil matches everyone
+func (s *memoryPostStore) authorWithin(post Post, box *BoundingBox) bool {
+	if box == nil {
+		return true
+	}
+	loc, ok := s.db.locations[s.db.users[post.UserID].LocationID]
+	return ok && box.contains(loc.Location)
+}
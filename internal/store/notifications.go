@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+// NotificationKind says what happened, so clients can pick an icon or group notifications
+type NotificationKind string
+
+const (
+	NotificationApplicationReceived  NotificationKind = "application_received"
+	NotificationApplicationStatus    NotificationKind = "application_status"
+	NotificationApplicationWithdrawn NotificationKind = "application_withdrawn"
+	NotificationBookingRequested     NotificationKind = "booking_requested"
+	NotificationBookingUpdated       NotificationKind = "booking_updated"
+	NotificationBookingExpired       NotificationKind = "booking_expired"
+	NotificationReviewReceived       NotificationKind = "review_received"
+	NotificationReviewsRevealed      NotificationKind = "reviews_revealed"
+	NotificationReviewResponse       NotificationKind = "review_response"
+)
+
+// Notification is an in-app message to a user about something that changed. Link is the
+// API path of the resource it concerns.
+type Notification struct {
+	ID        int64            `json:"id"`
+	UserID    uuid.UUID        `json:"user_id"`
+	Kind      NotificationKind `json:"kind"`
+	Message   string           `json:"message"`
+	Link      string           `json:"link"`
+	ReadAt    *time.Time       `json:"read_at"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// NotificationFilter narrows a user's notifications
+type NotificationFilter struct {
+	UnreadOnly bool
+	Page       pagination.Params
+}
+
+type NotificationStore struct {
+	db DBTX
+}
+
+func (s *NotificationStore) Create(ctx context.Context, notification *Notification) error {
+	ctx, span := startSpan(ctx, "NotificationStore.Create")
+	defer span.End()
+
+	query := `
+	INSERT INTO notifications (user_id, kind, message, link)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return s.db.QueryRowContext(ctx, query,
+		notification.UserID, notification.Kind, notification.Message, notification.Link,
+	).Scan(&notification.ID, &notification.CreatedAt)
+}
+
+// ListByUser returns a page of the user's notifications, newest first
+func (s *NotificationStore) ListByUser(ctx context.Context, userID uuid.UUID, filter NotificationFilter) (pagination.Page[Notification], error) {
+	ctx, span := startSpan(ctx, "NotificationStore.ListByUser")
+	defer span.End()
+
+	query := `
+	SELECT id, user_id, kind, message, link, read_at, created_at
+	FROM notifications
+	WHERE user_id = $1
+	`
+	args := []any{userID}
+	if filter.UnreadOnly {
+		query += "AND read_at IS NULL\n"
+	}
+	if after := filter.Page.After; after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		query += fmt.Sprintf("AND (created_at, id) < ($%d, $%d)\n", len(args)-1, len(args))
+	}
+	// One extra row tells us whether there is another page
+	args = append(args, filter.Page.Limit+1)
+	query += fmt.Sprintf("ORDER BY created_at DESC, id DESC\nLIMIT $%d", len(args))
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[Notification]{}, err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Message, &n.Link, &n.ReadAt, &n.CreatedAt); err != nil {
+			return pagination.Page[Notification]{}, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[Notification]{}, err
+	}
+	return pagination.NewPage(notifications, filter.Page.Limit, notificationCursor), nil
+}
+
+func notificationCursor(n Notification) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: n.CreatedAt, ID: n.ID}
+}
+
+// MarkRead marks one of the user's notifications read. Notifications belonging to someone
+// else are reported as ErrNotFound.
+func (s *NotificationStore) MarkRead(ctx context.Context, userID uuid.UUID, id int64) (*Notification, error) {
+	ctx, span := startSpan(ctx, "NotificationStore.MarkRead")
+	defer span.End()
+
+	query := `
+	UPDATE notifications
+	SET read_at = COALESCE(read_at, NOW())
+	WHERE id = $1 AND user_id = $2
+	RETURNING id, user_id, kind, message, link, read_at, created_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var n Notification
+	err := s.db.QueryRowContext(ctx, query, id, userID).Scan(&n.ID, &n.UserID, &n.Kind, &n.Message, &n.Link, &n.ReadAt, &n.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &n, nil
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"log"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
 )
 
 type Post struct {
@@ -16,20 +19,40 @@ type Post struct {
 	Title     string    `json:"title"`
 	Tags      []string  `json:"tags"`
 	Version   int       `json:"version"`
-	UserID    int64     `json:"user_id"`
+	UserID    uuid.UUID `json:"user_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Comments  []Comment `json:"comments"`
+	// CommentsNextCursor pages on through GET /posts/{id}/comments when Comments holds only the first page
+	CommentsNextCursor *string `json:"comments_next_cursor,omitempty"`
 }
+
+// PostSort orders a post listing. Both orders page on (created_at, id).
+type PostSort string
+
+const (
+	PostSortNewest PostSort = "-created_at"
+	PostSortOldest PostSort = "created_at"
+)
+
+// PostFilter narrows a post listing. Zero values match everything.
+type PostFilter struct {
+	Tags     []string  // posts carrying every one of these tags
+	AuthorID uuid.UUID // uuid.Nil for any author
+	From     time.Time // created at or after
+	To       time.Time // created before
+	Sort     PostSort  // PostSortNewest when empty
+	Page     pagination.Params
+}
+
 type PostStore struct {
-	db *sql.DB
+	db DBTX
 }
 
 func (s *PostStore) Create(ctx context.Context, post *Post) error {
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
+	ctx, span := startSpan(ctx, "PostStore.Create")
+	defer span.End()
+
 	query := `
 INSERT INTO posts (content, title, tags, user_id)
 VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at
@@ -37,7 +60,7 @@ VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at
 	if post.Tags == nil {
 		post.Tags = []string{}
 	}
-	err = tx.QueryRowContext(
+	return s.db.QueryRowContext(
 		ctx,
 		query,
 		post.Content,
@@ -49,27 +72,22 @@ VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at
 		&post.CreatedAt,
 		&post.UpdatedAt,
 	)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if err := tx.Commit(); err != nil {
-		return err
-	}
-	return nil
 }
 
-func (s *PostStore) Delete(ctx context.Context, postID int64) error {
+// Delete removes the post if it is still at version, returning ErrEditConflict when it has moved on
+func (s *PostStore) Delete(ctx context.Context, postID int64, version int) error {
+	ctx, span := startSpan(ctx, "PostStore.Delete")
+	defer span.End()
+
 	query := `
 	DELETE FROM posts
-	WHERE id = $1
+	WHERE id = $1 AND version = $2
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	result, err := s.db.ExecContext(ctx, query, postID)
+	result, err := s.db.ExecContext(ctx, query, postID, version)
 	if err != nil {
 		return err
 	}
@@ -78,12 +96,27 @@ func (s *PostStore) Delete(ctx context.Context, postID int64) error {
 		return err
 	}
 	if rowsAffected == 0 {
-		return ErrNotFound
+		return s.missingOrConflict(ctx, postID)
 	}
 	return nil
 }
 
+// missingOrConflict explains why a version checked write matched no row
+func (s *PostStore) missingOrConflict(ctx context.Context, postID int64) error {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM posts WHERE id = $1)`, postID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrEditConflict
+	}
+	return ErrNotFound
+}
+
 func (s *PostStore) GetByID(ctx context.Context, postID int64) (*Post, error) {
+	ctx, span := startSpan(ctx, "PostStore.GetByID")
+	defer span.End()
+
 	query := `
 	SELECT   id, content, title, tags, version, user_id, created_at, updated_at
 	FROM posts 
@@ -112,7 +145,6 @@ func (s *PostStore) GetByID(ctx context.Context, postID int64) (*Post, error) {
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			log.Printf("Error fetching post: %v", err) //TODO: CHECK LOGGING PROCEDURE Or use structured logging
 			return nil, ErrNotFound
 		default:
 			return nil, err
@@ -121,7 +153,92 @@ func (s *PostStore) GetByID(ctx context.Context, postID int64) (*Post, error) {
 	return &post, nil
 }
 
+// List returns one page of posts matching filter, without their comments
+func (s *PostStore) List(ctx context.Context, filter PostFilter) (pagination.Page[Post], error) {
+	ctx, span := startSpan(ctx, "PostStore.List")
+	defer span.End()
+
+	var (
+		where []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.Tags) > 0 {
+		where = append(where, "tags @> "+arg(pq.Array(filter.Tags)))
+	}
+	if filter.AuthorID != uuid.Nil {
+		where = append(where, "user_id = "+arg(filter.AuthorID))
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "created_at < "+arg(filter.To))
+	}
+
+	order, after := "DESC", "<"
+	if filter.Sort == PostSortOldest {
+		order, after = "ASC", ">"
+	}
+	if filter.Page.After != nil {
+		where = append(where, fmt.Sprintf("(created_at, id) %s (%s, %s)", after, arg(filter.Page.After.CreatedAt), arg(filter.Page.After.ID)))
+	}
+
+	query := `
+	SELECT id, content, title, tags, version, user_id, created_at, updated_at
+	FROM posts
+	`
+	if len(where) > 0 {
+		query += "WHERE " + strings.Join(where, " AND ") + "\n"
+	}
+	// One extra row tells us whether there is another page
+	query += fmt.Sprintf("ORDER BY created_at %s, id %s\nLIMIT %s", order, order, arg(filter.Page.Limit+1))
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[Post]{}, err
+	}
+	defer rows.Close()
+
+	posts := []Post{}
+	for rows.Next() {
+		var post Post
+		err := rows.Scan(
+			&post.ID,
+			&post.Content,
+			&post.Title,
+			pq.Array(&post.Tags),
+			&post.Version,
+			&post.UserID,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+		)
+		if err != nil {
+			return pagination.Page[Post]{}, err
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[Post]{}, err
+	}
+	return pagination.NewPage(posts, filter.Page.Limit, postCursor), nil
+}
+
+func postCursor(p Post) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
+
 func (s *PostStore) Update(ctx context.Context, post *Post) error {
+	ctx, span := startSpan(ctx, "PostStore.Update")
+	defer span.End()
+
 	query := `
 	UPDATE posts
 	SET title = $1, content = $2, tags = $3, version = version + 1, updated_at = NOW()
@@ -143,11 +260,10 @@ func (s *PostStore) Update(ctx context.Context, post *Post) error {
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return ErrNotFound
+			return s.missingOrConflict(ctx, post.ID)
 		default:
 			return err
 		}
 	}
 	return nil
-
 }
@@ -0,0 +1,466 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+// Specialty is a kind of production a cinematographer works on
+type Specialty string
+
+const (
+	SpecialtyDocumentary Specialty = "documentary"
+	SpecialtyCommercial  Specialty = "commercial"
+	SpecialtyNarrative   Specialty = "narrative"
+	SpecialtyMusicVideo  Specialty = "music_video"
+	SpecialtyCorporate   Specialty = "corporate"
+	SpecialtyEvent       Specialty = "event"
+)
+
+// Specialties lists every specialty a profile can claim
+var Specialties = []Specialty{
+	SpecialtyDocumentary, SpecialtyCommercial, SpecialtyNarrative,
+	SpecialtyMusicVideo, SpecialtyCorporate, SpecialtyEvent,
+}
+
+func (s Specialty) Valid() bool {
+	return slices.Contains(Specialties, s)
+}
+
+// LinkKind says where a profile link points
+type LinkKind string
+
+const (
+	LinkReel      LinkKind = "reel"
+	LinkWebsite   LinkKind = "website"
+	LinkInstagram LinkKind = "instagram"
+	LinkVimeo     LinkKind = "vimeo"
+	LinkYouTube   LinkKind = "youtube"
+	LinkIMDb      LinkKind = "imdb"
+	LinkLinkedIn  LinkKind = "linkedin"
+	LinkOther     LinkKind = "other"
+)
+
+// LinkKinds lists every kind of profile link
+var LinkKinds = []LinkKind{LinkReel, LinkWebsite, LinkInstagram, LinkVimeo, LinkYouTube, LinkIMDb, LinkLinkedIn, LinkOther}
+
+type ProfileLink struct {
+	Kind LinkKind `json:"kind"`
+	URL  string   `json:"url" example:"https://vimeo.com/reel"`
+}
+
+// ProfileLinks is stored as a JSON array in a jsonb column
+type ProfileLinks []ProfileLink
+
+func (l ProfileLinks) Value() (driver.Value, error) {
+	if l == nil {
+		l = ProfileLinks{}
+	}
+	return json.Marshal(l)
+}
+
+func (l *ProfileLinks) Scan(src any) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into ProfileLinks", src)
+	}
+	return json.Unmarshal(b, l)
+}
+
+// Profile is what a cinematographer shows the people hiring. FirstName, LastName, the general
+// location and the service area are read from the user; the email and street address never
+// leave the store.
+type Profile struct {
+	ID              int64          `json:"-"`
+	UserID          uuid.UUID      `json:"user_id"`
+	Headline        string         `json:"headline" example:"Documentary DP with a drone license"`
+	Bio             string         `json:"bio"`
+	Roles           []CrewRole     `json:"roles"`
+	Specialties     []Specialty    `json:"specialties"`
+	YearsExperience int            `json:"years_experience"`
+	DayRateMin      int            `json:"day_rate_min"` // whole units of Currency, 0 when not given
+	DayRateMax      int            `json:"day_rate_max"`
+	Currency        string         `json:"currency" example:"USD"`
+	Languages       []string       `json:"languages" example:"English,Spanish"`
+	Unions          []string       `json:"unions" example:"IATSE Local 600"`
+	Links           ProfileLinks   `json:"links"`
+	Version         int            `json:"version"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	FirstName       string         `json:"first_name"`
+	LastName        string         `json:"last_name"`
+	City            string         `json:"city"`
+	State           string         `json:"state"`
+	Country         string         `json:"country"`
+	Latitude        float64        `json:"-"` // of the user's location, only used to measure distance
+	Longitude       float64        `json:"-"`
+	TravelMiles     int            `json:"travel_miles"` // from the user's service area
+	ServicePlaces   []ServicePlace `json:"service_places"`
+	Rating          RatingSummary  `json:"rating"` // from producers' revealed reviews
+}
+
+// ProfileSort orders a cinematographer search. Both orders page on (score, id).
+type ProfileSort string
+
+const (
+	ProfileSortDistance ProfileSort = "distance" // nearest home first
+	ProfileSortRating   ProfileSort = "rating"   // highest average rating first, unrated profiles last
+)
+
+// How a profile came to match a search
+const (
+	MatchedNearby       = "nearby"        // the user's home is within the searched radius
+	MatchedTravelRadius = "travel_radius" // the user travels as far as the searched point
+	MatchedServiceArea  = "service_area"  // one of the user's service places covers the searched point
+)
+
+// ProfileFilter is a search for cinematographers around a point. A profile matches when the
+// user's home is within RadiusMiles of it, when it is within their travel radius, or when one of
+// their service places covers it. The other zero values match everything.
+type ProfileFilter struct {
+	Latitude, Longitude float64
+	RadiusMiles         float64
+	Role                CrewRole
+	MaxRate             int // profiles whose day rate starts at or below this; profiles without a rate never match
+	Currency            string
+	// When both are set, only users with no booked block overlapping [FreeFrom, FreeTo)
+	FreeFrom, FreeTo time.Time
+	Sort             ProfileSort // distance when empty
+	Page             pagination.Params
+}
+
+// ProfileMatch is a profile found by a search
+type ProfileMatch struct {
+	Profile
+	// From the searched point to the user's home, or to the service place that matched for a user without one
+	DistanceKm float64
+	MatchedBy  string
+}
+
+// cursor keys a match by the filter's sort order; the rating is negated so the best come first
+func (f ProfileFilter) cursor(m ProfileMatch) pagination.Cursor {
+	if f.Sort == ProfileSortRating {
+		score := 0.0
+		if m.Rating.Average != nil {
+			score = -*m.Rating.Average
+		}
+		return pagination.Cursor{ID: m.ID, Score: score}
+	}
+	return pagination.Cursor{ID: m.ID, Score: m.DistanceKm}
+}
+
+// setDefaults replaces nil lists so they are stored and written as empty arrays
+func (p *Profile) setDefaults() {
+	if p.Roles == nil {
+		p.Roles = []CrewRole{}
+	}
+	if p.Specialties == nil {
+		p.Specialties = []Specialty{}
+	}
+	if p.Languages == nil {
+		p.Languages = []string{}
+	}
+	if p.Unions == nil {
+		p.Unions = []string{}
+	}
+	if p.Links == nil {
+		p.Links = ProfileLinks{}
+	}
+}
+
+type textArrayValue[T ~string] struct {
+	values *[]T
+}
+
+// textArray reads and writes a text[] column of a string type like CrewRole,
+// which pq.Array only handles for plain strings
+func textArray[T ~string](values *[]T) textArrayValue[T] {
+	return textArrayValue[T]{values}
+}
+
+func (a textArrayValue[T]) Value() (driver.Value, error) {
+	raw := make(pq.StringArray, len(*a.values))
+	for i, v := range *a.values {
+		raw[i] = string(v)
+	}
+	return raw.Value()
+}
+
+func (a textArrayValue[T]) Scan(src any) error {
+	var raw pq.StringArray
+	if err := raw.Scan(src); err != nil {
+		return err
+	}
+	values := make([]T, len(raw))
+	for i, v := range raw {
+		values[i] = T(v)
+	}
+	*a.values = values
+	return nil
+}
+
+type ProfileStore struct {
+	db DBTX
+}
+
+const profileColumns = `
+	pr.id, pr.user_id, pr.headline, pr.bio, pr.roles, pr.specialties, pr.years_experience,
+	pr.day_rate_min, pr.day_rate_max, pr.currency, pr.languages, pr.unions, pr.links,
+	pr.version, pr.created_at, pr.updated_at,
+	u.first_name, u.last_name, COALESCE(l.city, ''), COALESCE(l.state, ''), COALESCE(l.country, ''),
+	COALESCE(l.latitude, 0), COALESCE(l.longitude, 0), u.travel_miles,
+	rs.count, rs.average, rs.professionalism, rs.technical_skill, rs.communication
+	FROM profiles pr
+	JOIN users u ON u.id = pr.user_id
+	LEFT JOIN locations l ON l.id = u.location_id` + ratingSummarySQL
+
+func scanProfile(scan func(dest ...any) error) (Profile, error) {
+	var p Profile
+	err := scan(
+		&p.ID, &p.UserID, &p.Headline, &p.Bio, textArray(&p.Roles), textArray(&p.Specialties), &p.YearsExperience,
+		&p.DayRateMin, &p.DayRateMax, &p.Currency, pq.Array(&p.Languages), pq.Array(&p.Unions), &p.Links,
+		&p.Version, &p.CreatedAt, &p.UpdatedAt,
+		&p.FirstName, &p.LastName, &p.City, &p.State, &p.Country,
+		&p.Latitude, &p.Longitude, &p.TravelMiles,
+		&p.Rating.Count, &p.Rating.Average, &p.Rating.Professionalism, &p.Rating.TechnicalSkill, &p.Rating.Communication,
+	)
+	return p, err
+}
+
+func (s *ProfileStore) GetByUserID(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	ctx, span := startSpan(ctx, "ProfileStore.GetByUserID")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	p, err := scanProfile(s.db.QueryRowContext(ctx, "SELECT"+profileColumns+"WHERE pr.user_id = $1", userID).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	profiles := []Profile{p}
+	if err := s.attachPlaces(ctx, profiles); err != nil {
+		return nil, err
+	}
+	return &profiles[0], nil
+}
+
+// profileMatchesSQL finds the users who match a search around the point in $1 and $2 within $3
+// kilometers, at most once each, preferring a match by home over one by service place. Every
+// branch is limited to a bounding box first, $4 to $7 around the radius and $8 to $11 around
+// the farthest anyone can travel, so the location index narrows the rows to measure.
+var profileMatchesSQL = `
+	WITH matches AS (
+		SELECT u.id AS user_id, 0 AS preference, ` + distanceSQL("l") + ` AS km
+		FROM users u JOIN locations l ON l.id = u.location_id
+		WHERE l.latitude BETWEEN $4 AND $5 AND l.longitude BETWEEN $6 AND $7
+			AND ` + distanceSQL("l") + ` <= $3
+		UNION ALL
+		SELECT u.id, 1, ` + distanceSQL("l") + `
+		FROM users u JOIN locations l ON l.id = u.location_id
+		WHERE u.travel_miles > 0 AND l.latitude BETWEEN $8 AND $9 AND l.longitude BETWEEN $10 AND $11
+			AND ` + distanceSQL("l") + ` <= u.travel_miles * ` + kmPerMileSQL + `
+		UNION ALL
+		SELECT sa.user_id, 2, ` + distanceSQL("sl") + `
+		FROM service_areas sa JOIN locations sl ON sl.id = sa.location_id
+		WHERE sl.latitude BETWEEN $8 AND $9 AND sl.longitude BETWEEN $10 AND $11
+			AND ` + distanceSQL("sl") + ` <= sa.miles * ` + kmPerMileSQL + `
+	),
+	best AS (
+		SELECT DISTINCT ON (m.user_id) m.user_id,
+			CASE m.preference WHEN 0 THEN 'nearby' WHEN 1 THEN 'travel_radius' ELSE 'service_area' END AS matched_by,
+			COALESCE(` + distanceSQL("hl") + `, m.km) AS distance_km
+		FROM matches m
+		JOIN users hu ON hu.id = m.user_id
+		LEFT JOIN locations hl ON hl.id = hu.location_id
+		ORDER BY m.user_id, m.preference, m.km
+	)
+	`
+
+// Search returns one page of the active users' profiles that match filter, nearest or best
+// rated first. Distances, matching and ordering all happen in the database.
+func (s *ProfileStore) Search(ctx context.Context, filter ProfileFilter) (pagination.Page[ProfileMatch], error) {
+	ctx, span := startSpan(ctx, "ProfileStore.Search")
+	defer span.End()
+
+	near := boxAround(filter.Latitude, filter.Longitude, filter.RadiusMiles)
+	far := boxAround(filter.Latitude, filter.Longitude, maxServiceMiles)
+	args := []any{
+		filter.Latitude, filter.Longitude, filter.RadiusMiles * KmPerMile,
+		near.MinLat, near.MaxLat, near.MinLon, near.MaxLon,
+		far.MinLat, far.MaxLat, far.MinLon, far.MaxLon,
+	}
+	score := "b.distance_km"
+	if filter.Sort == ProfileSortRating {
+		score = "-COALESCE(rs.average, 0)"
+	}
+
+	query := profileMatchesSQL + "SELECT b.distance_km, b.matched_by," + profileColumns + `
+	JOIN best b ON b.user_id = pr.user_id
+	WHERE u.is_active
+	`
+	if filter.Role != "" {
+		args = append(args, pq.Array([]string{string(filter.Role)}))
+		query += fmt.Sprintf("AND pr.roles @> $%d\n", len(args))
+	}
+	if filter.MaxRate > 0 {
+		args = append(args, filter.MaxRate)
+		query += fmt.Sprintf("AND pr.day_rate_min > 0 AND pr.day_rate_min <= $%d\n", len(args))
+	}
+	if filter.Currency != "" {
+		args = append(args, filter.Currency)
+		query += fmt.Sprintf("AND pr.currency = $%d\n", len(args))
+	}
+	if !filter.FreeFrom.IsZero() && !filter.FreeTo.IsZero() {
+		args = append(args, filter.FreeFrom, filter.FreeTo)
+		query += fmt.Sprintf(`AND NOT EXISTS (
+			SELECT 1 FROM availability_blocks ab
+			WHERE ab.user_id = u.id AND ab.status = 'booked' AND ab.starts_at < $%d AND ab.ends_at > $%d
+		)
+		`, len(args), len(args)-1)
+	}
+	if after := filter.Page.After; after != nil {
+		args = append(args, after.Score, after.ID)
+		query += fmt.Sprintf("AND (%s, pr.id) > ($%d::float8, $%d)\n", score, len(args)-1, len(args))
+	}
+	// One extra row tells us whether there is another page
+	args = append(args, filter.Page.Limit+1)
+	query += fmt.Sprintf("ORDER BY %s, pr.id\nLIMIT $%d", score, len(args))
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[ProfileMatch]{}, err
+	}
+	defer rows.Close()
+
+	matches := []ProfileMatch{}
+	profiles := []Profile{}
+	for rows.Next() {
+		var m ProfileMatch
+		p, err := scanProfile(func(dest ...any) error {
+			return rows.Scan(append([]any{&m.DistanceKm, &m.MatchedBy}, dest...)...)
+		})
+		if err != nil {
+			return pagination.Page[ProfileMatch]{}, err
+		}
+		matches = append(matches, m)
+		profiles = append(profiles, p)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[ProfileMatch]{}, err
+	}
+
+	// Only the page's own service places are read
+	if err := s.attachPlaces(ctx, profiles); err != nil {
+		return pagination.Page[ProfileMatch]{}, err
+	}
+	for i := range matches {
+		matches[i].Profile = profiles[i]
+	}
+	return pagination.NewPage(matches, filter.Page.Limit, filter.cursor), nil
+}
+
+// attachPlaces reads the profiles' service places in one query
+func (s *ProfileStore) attachPlaces(ctx context.Context, profiles []Profile) error {
+	userIDs := make([]uuid.UUID, len(profiles))
+	for i, p := range profiles {
+		userIDs[i] = p.UserID
+	}
+	places, err := (&ServiceAreaStore{s.db}).places(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	for i := range profiles {
+		profiles[i].ServicePlaces = places[profiles[i].UserID]
+		if profiles[i].ServicePlaces == nil {
+			profiles[i].ServicePlaces = []ServicePlace{}
+		}
+	}
+	return nil
+}
+
+// Create inserts the user's profile, returning ErrConflict when they already have one
+func (s *ProfileStore) Create(ctx context.Context, profile *Profile) error {
+	ctx, span := startSpan(ctx, "ProfileStore.Create")
+	defer span.End()
+
+	profile.setDefaults()
+
+	query := `
+	INSERT INTO profiles (user_id, headline, bio, roles, specialties, years_experience,
+		day_rate_min, day_rate_max, currency, languages, unions, links)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	RETURNING id, version, created_at, updated_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query,
+		profile.UserID, profile.Headline, profile.Bio, textArray(&profile.Roles), textArray(&profile.Specialties), profile.YearsExperience,
+		profile.DayRateMin, profile.DayRateMax, profile.Currency, pq.Array(profile.Languages), pq.Array(profile.Unions), profile.Links,
+	).Scan(&profile.ID, &profile.Version, &profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.Constraint == "profiles_pkey" {
+			return ErrConflict
+		}
+		return fmt.Errorf("inserting profile: %w", err)
+	}
+	return nil
+}
+
+// Update saves the profile if it is still at profile.Version, returning ErrEditConflict when
+// another request changed it first
+func (s *ProfileStore) Update(ctx context.Context, profile *Profile) error {
+	ctx, span := startSpan(ctx, "ProfileStore.Update")
+	defer span.End()
+
+	profile.setDefaults()
+
+	query := `
+	UPDATE profiles
+	SET headline = $1, bio = $2, roles = $3, specialties = $4, years_experience = $5,
+		day_rate_min = $6, day_rate_max = $7, currency = $8, languages = $9, unions = $10, links = $11,
+		version = version + 1, updated_at = NOW()
+	WHERE user_id = $12 AND version = $13
+	RETURNING id, version, updated_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query,
+		profile.Headline, profile.Bio, textArray(&profile.Roles), textArray(&profile.Specialties), profile.YearsExperience,
+		profile.DayRateMin, profile.DayRateMax, profile.Currency, pq.Array(profile.Languages), pq.Array(profile.Unions), profile.Links,
+		profile.UserID, profile.Version,
+	).Scan(&profile.ID, &profile.Version, &profile.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			var exists bool
+			if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM profiles WHERE user_id = $1)`, profile.UserID).Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				return ErrEditConflict
+			}
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
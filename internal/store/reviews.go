@@ -0,0 +1,284 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+// Review is what one party to a completed booking thought of the other. Rating is the overall
+// score from 1 to 5; the categories are optional, and TechnicalSkill only applies to
+// cinematographers. Reviews are double blind: nobody but the reviewer sees one before
+// VisibleAt, which starts as the end of the review period and moves up to the moment the
+// other party's review comes in.
+type Review struct {
+	ID              int64       `json:"id"`
+	BookingID       int64       `json:"booking_id"`
+	ReviewerID      uuid.UUID   `json:"reviewer_id"`
+	RevieweeID      uuid.UUID   `json:"reviewee_id"`
+	RevieweeRole    BookingRole `json:"reviewee_role"` // what the reviewee was on the booking
+	Rating          int         `json:"rating"`
+	Professionalism *int        `json:"professionalism"`
+	TechnicalSkill  *int        `json:"technical_skill"`
+	Communication   *int        `json:"communication"`
+	Body            string      `json:"body"`
+	Response        string      `json:"response"` // the reviewee's public reply
+	RespondedAt     *time.Time  `json:"responded_at"`
+	VisibleAt       time.Time   `json:"visible_at"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	Reviewer        User        `json:"reviewer"`
+}
+
+// Visible reports whether the review has been revealed by now
+func (r Review) Visible(now time.Time) bool {
+	return !r.VisibleAt.After(now)
+}
+
+// RatingSummary averages a user's revealed reviews, to two decimal places. The averages are
+// nil until there is a score to average.
+type RatingSummary struct {
+	Count           int      `json:"count"`
+	Average         *float64 `json:"average" example:"4.75"`
+	Professionalism *float64 `json:"professionalism"`
+	TechnicalSkill  *float64 `json:"technical_skill"`
+	Communication   *float64 `json:"communication"`
+}
+
+// ReviewFilter narrows the reviews a user received. Zero values match everything.
+type ReviewFilter struct {
+	Role BookingRole // what the user was on the booking
+	Page pagination.Params
+}
+
+type ReviewStore struct {
+	db DBTX
+}
+
+const reviewColumns = `
+	r.id, r.booking_id, r.reviewer_id, r.reviewee_id, r.reviewee_role, r.rating,
+	r.professionalism, r.technical_skill, r.communication, r.body, r.response, r.responded_at,
+	r.visible_at, r.created_at, r.updated_at, u.first_name, u.last_name
+	FROM reviews r
+	JOIN users u ON u.id = r.reviewer_id
+	`
+
+// ratingSummarySQL averages the revealed reviews a profile's user received as a cinematographer
+const ratingSummarySQL = `
+	LEFT JOIN LATERAL (
+		SELECT COUNT(*) AS count,
+			ROUND(AVG(rv.rating), 2)::float8 AS average,
+			ROUND(AVG(rv.professionalism), 2)::float8 AS professionalism,
+			ROUND(AVG(rv.technical_skill), 2)::float8 AS technical_skill,
+			ROUND(AVG(rv.communication), 2)::float8 AS communication
+		FROM reviews rv
+		WHERE rv.reviewee_id = pr.user_id AND rv.reviewee_role = 'cinematographer' AND rv.visible_at <= NOW()
+	) rs ON TRUE
+	`
+
+func scanReview(scan func(dest ...any) error) (Review, error) {
+	var r Review
+	err := scan(
+		&r.ID, &r.BookingID, &r.ReviewerID, &r.RevieweeID, &r.RevieweeRole, &r.Rating,
+		&r.Professionalism, &r.TechnicalSkill, &r.Communication, &r.Body, &r.Response, &r.RespondedAt,
+		&r.VisibleAt, &r.CreatedAt, &r.UpdatedAt, &r.Reviewer.FirstName, &r.Reviewer.LastName,
+	)
+	r.Reviewer.ID = r.ReviewerID
+	return r, err
+}
+
+// Create saves a review, returning ErrConflict when the reviewer already reviewed the booking.
+// When it is the second review of the booking, both are revealed at once.
+func (s *ReviewStore) Create(ctx context.Context, review *Review) error {
+	ctx, span := startSpan(ctx, "ReviewStore.Create")
+	defer span.End()
+
+	return withTx(s.db, ctx, func(tx DBTX) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		// Two reviews arriving together must each see the other
+		if _, err := tx.ExecContext(ctx, `SELECT id FROM bookings WHERE id = $1 FOR UPDATE`, review.BookingID); err != nil {
+			return err
+		}
+
+		query := `
+		INSERT INTO reviews (booking_id, reviewer_id, reviewee_id, reviewee_role, rating,
+			professionalism, technical_skill, communication, body, visible_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+		`
+		err := tx.QueryRowContext(ctx, query,
+			review.BookingID, review.ReviewerID, review.RevieweeID, review.RevieweeRole, review.Rating,
+			review.Professionalism, review.TechnicalSkill, review.Communication, review.Body, review.VisibleAt,
+		).Scan(&review.ID, &review.CreatedAt, &review.UpdatedAt)
+		if err != nil {
+			var pgErr *pq.Error
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.Constraint == "reviews_booking_reviewer_key" {
+				return ErrConflict
+			}
+			return fmt.Errorf("inserting review: %w", err)
+		}
+
+		reveal := `
+		UPDATE reviews SET visible_at = LEAST(visible_at, $2), updated_at = NOW()
+		WHERE booking_id = $1 AND (SELECT COUNT(*) FROM reviews WHERE booking_id = $1) > 1
+		RETURNING id, visible_at
+		`
+		rows, err := tx.QueryContext(ctx, reveal, review.BookingID, review.CreatedAt)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id int64
+			var visibleAt time.Time
+			if err := rows.Scan(&id, &visibleAt); err != nil {
+				return err
+			}
+			if id == review.ID {
+				review.VisibleAt = visibleAt
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (s *ReviewStore) GetByID(ctx context.Context, id int64) (*Review, error) {
+	ctx, span := startSpan(ctx, "ReviewStore.GetByID")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	r, err := scanReview(s.db.QueryRowContext(ctx, "SELECT"+reviewColumns+"WHERE r.id = $1", id).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListByBooking returns both parties' reviews of the booking, revealed or not, oldest first
+func (s *ReviewStore) ListByBooking(ctx context.Context, bookingID int64) ([]Review, error) {
+	ctx, span := startSpan(ctx, "ReviewStore.ListByBooking")
+	defer span.End()
+
+	return s.list(ctx, "SELECT"+reviewColumns+"WHERE r.booking_id = $1\nORDER BY r.id", bookingID)
+}
+
+// ListReceived returns a page of the revealed reviews the user received, newest first
+func (s *ReviewStore) ListReceived(ctx context.Context, userID uuid.UUID, filter ReviewFilter) (pagination.Page[Review], error) {
+	ctx, span := startSpan(ctx, "ReviewStore.ListReceived")
+	defer span.End()
+
+	query := "SELECT" + reviewColumns + "WHERE r.reviewee_id = $1 AND r.visible_at <= NOW()\n"
+	args := []any{userID}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		query += fmt.Sprintf("AND r.reviewee_role = $%d\n", len(args))
+	}
+	if after := filter.Page.After; after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		query += fmt.Sprintf("AND (r.created_at, r.id) < ($%d, $%d)\n", len(args)-1, len(args))
+	}
+	// One extra row tells us whether there is another page
+	args = append(args, filter.Page.Limit+1)
+	query += fmt.Sprintf("ORDER BY r.created_at DESC, r.id DESC\nLIMIT $%d", len(args))
+
+	reviews, err := s.list(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[Review]{}, err
+	}
+	return pagination.NewPage(reviews, filter.Page.Limit, reviewCursor), nil
+}
+
+func (s *ReviewStore) list(ctx context.Context, query string, args ...any) ([]Review, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []Review{}
+	for rows.Next() {
+		r, err := scanReview(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
+func reviewCursor(r Review) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: r.CreatedAt, ID: r.ID}
+}
+
+// Respond saves the reviewee's reply to review.Response. There is one reply per review:
+// ErrConflict means it already has one.
+func (s *ReviewStore) Respond(ctx context.Context, review *Review) error {
+	ctx, span := startSpan(ctx, "ReviewStore.Respond")
+	defer span.End()
+
+	query := `
+	UPDATE reviews SET response = $1, responded_at = NOW(), updated_at = NOW()
+	WHERE id = $2 AND response = ''
+	RETURNING responded_at, updated_at
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, review.Response, review.ID).Scan(&review.RespondedAt, &review.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			var exists bool
+			if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM reviews WHERE id = $1)`, review.ID).Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				return ErrConflict
+			}
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// summarize averages the reviews the way ratingSummarySQL does
+func summarize(reviews []Review) RatingSummary {
+	average := func(score func(Review) *int) *float64 {
+		total, n := 0, 0
+		for _, r := range reviews {
+			if s := score(r); s != nil {
+				total += *s
+				n++
+			}
+		}
+		if n == 0 {
+			return nil
+		}
+		avg := math.Round(float64(total)/float64(n)*100) / 100
+		return &avg
+	}
+	return RatingSummary{
+		Count:           len(reviews),
+		Average:         average(func(r Review) *int { return &r.Rating }),
+		Professionalism: average(func(r Review) *int { return r.Professionalism }),
+		TechnicalSkill:  average(func(r Review) *int { return r.TechnicalSkill }),
+		Communication:   average(func(r Review) *int { return r.Communication }),
+	}
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+)
+
+// MaxTagFacets caps how many tags a search reports counts for
+const MaxTagFacets = 20
+
+// Highlights come back from the database between these control characters, which cannot
+// appear in escaped HTML, so the snippet can be escaped before the markers become <mark> tags.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+// BoundingBox limits results to authors located inside it
+type BoundingBox struct {
+	MinLat, MaxLat, MinLon, MaxLon float64
+}
+
+func (b BoundingBox) contains(loc Location) bool {
+	return loc.Latitude >= b.MinLat && loc.Latitude <= b.MaxLat &&
+		loc.Longitude >= b.MinLon && loc.Longitude <= b.MaxLon
+}
+
+// PostSearch is a full-text query over posts' titles, tags and content
+type PostSearch struct {
+	Query  string       // web search syntax: words, "quoted phrases", or, -excluded
+	Tags   []string     // posts carrying every one of these tags
+	Within *BoundingBox // nil for anywhere
+	Page   pagination.Params
+}
+
+// PostSearchResult is a matching post with its relevance and a highlighted excerpt.
+// Highlight is HTML: the post text is escaped and matches are wrapped in <mark>.
+type PostSearchResult struct {
+	Post
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+// TagFacet counts the matching posts that carry a tag
+type TagFacet struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// PostSearchResults is one page of results, best match first, plus tag counts over every match
+type PostSearchResults struct {
+	pagination.Page[PostSearchResult]
+	Tags []TagFacet
+}
+
+func searchCursor(r PostSearchResult) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: r.CreatedAt, ID: r.ID, Score: r.Rank}
+}
+
+// markHighlights escapes a snippet and turns the highlight markers into <mark> tags
+func markHighlights(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, highlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, highlightStop, "</mark>")
+}
+
+// Search ranks posts against search.Query with ts_rank and excerpts their content with ts_headline
+func (s *PostStore) Search(ctx context.Context, search PostSearch) (PostSearchResults, error) {
+	ctx, span := startSpan(ctx, "PostStore.Search")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	page, err := s.searchPage(ctx, search)
+	if err != nil {
+		return PostSearchResults{}, err
+	}
+	tags, err := s.searchFacets(ctx, search)
+	if err != nil {
+		return PostSearchResults{}, err
+	}
+	return PostSearchResults{Page: page, Tags: tags}, nil
+}
+
+// searchFrom builds the FROM and WHERE clauses shared by the result and facet queries;
+// join is added to the FROM clause as is
+func searchFrom(search PostSearch, arg func(any) string, join string) string {
+	from := `FROM posts p
+	CROSS JOIN websearch_to_tsquery('english', ` + arg(search.Query) + `) AS q
+	` + join
+	where := []string{"p.search @@ q"}
+
+	if search.Within != nil {
+		from += `JOIN users u ON u.id = p.user_id
+	JOIN locations l ON l.id = u.location_id
+	`
+		where = append(where,
+			fmt.Sprintf("l.latitude BETWEEN %s AND %s", arg(search.Within.MinLat), arg(search.Within.MaxLat)),
+			fmt.Sprintf("l.longitude BETWEEN %s AND %s", arg(search.Within.MinLon), arg(search.Within.MaxLon)),
+		)
+	}
+	if len(search.Tags) > 0 {
+		where = append(where, "p.tags @> "+arg(pq.Array(search.Tags)))
+	}
+	return from + "WHERE " + strings.Join(where, " AND ") + "\n"
+}
+
+func (s *PostStore) searchPage(ctx context.Context, search PostSearch) (pagination.Page[PostSearchResult], error) {
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	headlineOptions := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=35, MinWords=15, MaxFragments=2", highlightStart, highlightStop)
+	query := `
+	SELECT p.id, p.content, p.title, p.tags, p.version, p.user_id, p.created_at, p.updated_at,
+		ts_rank(p.search, q) AS rank,
+		ts_headline('english', p.content, q, ` + arg(headlineOptions) + `)
+	` + searchFrom(search, arg, "")
+	if after := search.Page.After; after != nil {
+		query += fmt.Sprintf("AND (ts_rank(p.search, q), p.id) < (%s::real, %s)\n", arg(after.Score), arg(after.ID))
+	}
+	// One extra row tells us whether there is another page
+	query += "ORDER BY rank DESC, p.id DESC\nLIMIT " + arg(search.Page.Limit+1)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[PostSearchResult]{}, err
+	}
+	defer rows.Close()
+
+	results := []PostSearchResult{}
+	for rows.Next() {
+		var r PostSearchResult
+		err := rows.Scan(
+			&r.ID,
+			&r.Content,
+			&r.Title,
+			pq.Array(&r.Tags),
+			&r.Version,
+			&r.UserID,
+			&r.CreatedAt,
+			&r.UpdatedAt,
+			&r.Rank,
+			&r.Highlight,
+		)
+		if err != nil {
+			return pagination.Page[PostSearchResult]{}, err
+		}
+		r.Highlight = markHighlights(r.Highlight)
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[PostSearchResult]{}, err
+	}
+	return pagination.NewPage(results, search.Page.Limit, searchCursor), nil
+}
+
+func (s *PostStore) searchFacets(ctx context.Context, search PostSearch) ([]TagFacet, error) {
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Every tag of every match, not just the tags on this page
+	query := `
+	SELECT tag, count(*)
+	` + searchFrom(search, arg, "CROSS JOIN LATERAL unnest(p.tags) AS tag\n\t") + `GROUP BY tag
+	ORDER BY count(*) DESC, tag
+	LIMIT ` + arg(MaxTagFacets)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facets := []TagFacet{}
+	for rows.Next() {
+		var f TagFacet
+		if err := rows.Scan(&f.Tag, &f.Count); err != nil {
+			return nil, err
+		}
+		facets = append(facets, f)
+	}
+	return facets, rows.Err()
+}
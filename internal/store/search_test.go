@@ -0,0 +1,56 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWebSearch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  [][]searchClause
+	}{
+		{query: "RED Komodo", want: [][]searchClause{{{terms: []string{"red"}}, {terms: []string{"komodo"}}}}},
+		{query: "cameras in the city", want: [][]searchClause{{{terms: []string{"camera"}}, {terms: []string{"city"}}}}},
+		{query: `"kansas city" -gimbal`, want: [][]searchClause{{{terms: []string{"kansa", "city"}}, {terms: []string{"gimbal"}, negated: true}}}},
+		{query: "steadicam or gimbal", want: [][]searchClause{{{terms: []string{"steadicam"}}}, {{terms: []string{"gimbal"}}}}},
+		{query: "the or a", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseWebSearch(tt.query))
+		})
+	}
+}
+
+func TestSearchDocumentRank(t *testing.T) {
+	doc := newSearchDocument(Post{Title: "RED Komodo", Content: "Steadicam operators wanted", Tags: []string{"camera"}})
+
+	tests := []struct {
+		query     string
+		wantMatch bool
+		wantRank  float64
+	}{
+		{query: "komodo", wantMatch: true, wantRank: weightTitle},
+		{query: "komodo steadicam operator", wantMatch: true, wantRank: weightTitle + 2*weightContent},
+		{query: "camera", wantMatch: true, wantRank: weightTags},
+		{query: "komodo -steadicam", wantMatch: false},
+		{query: "alexa or komodo", wantMatch: true, wantRank: weightTitle},
+		{query: "alexa", wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rank, ok := doc.rank(parseWebSearch(tt.query))
+			assert.Equal(t, tt.wantMatch, ok)
+			assert.InDelta(t, tt.wantRank, rank, 1e-9)
+		})
+	}
+}
+
+func TestMarkHighlights(t *testing.T) {
+	snippet := "Shooting <script>x</script> on " + highlightStart + "Komodo" + highlightStop
+	assert.Equal(t, "Shooting &lt;script&gt;x&lt;/script&gt; on <mark>Komodo</mark>", markHighlights(snippet))
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ServiceArea is where a user will work: anywhere within TravelMiles of their home location,
+// plus each of Places
+type ServiceArea struct {
+	UserID      uuid.UUID      `json:"user_id"`
+	TravelMiles int            `json:"travel_miles"` // 0 when they do not travel
+	Places      []ServicePlace `json:"places"`
+}
+
+// ServicePlace is a further area a user works in, such as a metro area given by a ZIP code in it
+type ServicePlace struct {
+	Name     string    `json:"name" example:"Kansas City metro"`
+	Miles    int       `json:"miles"` // around the location, 0 for the ZIP code alone
+	Location *Location `json:"location"`
+}
+
+// maxServiceMiles is the farthest a travel radius or service place reaches, as checked by the
+// service_areas migration
+const maxServiceMiles = 500
+
+// KmPerMile converts the miles users give into the kilometers distances are measured in
+const KmPerMile = 1.609344
+
+const earthKm = 6371.0
+
+// kmPerMileSQL is KmPerMile as a literal for queries
+var kmPerMileSQL = strconv.FormatFloat(KmPerMile, 'g', -1, 64)
+
+// distanceKm is the great-circle distance between two points by the haversine formula
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthKm * math.Asin(math.Sqrt(a))
+}
+
+// distanceSQL is distanceKm from the point in $1 and $2 to a location aliased l
+func distanceSQL(l string) string {
+	return fmt.Sprintf(`(2 * %[2]g * ASIN(SQRT(
+		POWER(SIN(RADIANS(%[1]s.latitude::float8 - $1::float8) / 2), 2) +
+		COS(RADIANS($1::float8)) * COS(RADIANS(%[1]s.latitude::float8)) * POWER(SIN(RADIANS(%[1]s.longitude::float8 - $2::float8) / 2), 2)
+	)))`, l, earthKm)
+}
+
+// boxAround is the bounding box miles around a point. Everything within miles of the point
+// lies inside it, so searches filter on it before measuring exactly.
+func boxAround(lat, lon, miles float64) BoundingBox {
+	dLat := miles / 69
+	dLon := miles / (69 * math.Max(math.Cos(lat*math.Pi/180), 0.01))
+	return BoundingBox{MinLat: lat - dLat, MaxLat: lat + dLat, MinLon: lon - dLon, MaxLon: lon + dLon}
+}
+
+type ServiceAreaStore struct {
+	db DBTX
+}
+
+// Get returns the user's service area, returning ErrNotFound when the user does not exist
+func (s *ServiceAreaStore) Get(ctx context.Context, userID uuid.UUID) (*ServiceArea, error) {
+	ctx, span := startSpan(ctx, "ServiceAreaStore.Get")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	area := &ServiceArea{UserID: userID}
+	err := s.db.QueryRowContext(ctx, `SELECT travel_miles FROM users WHERE id = $1`, userID).Scan(&area.TravelMiles)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	places, err := s.places(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, err
+	}
+	area.Places = places[userID]
+	if area.Places == nil {
+		area.Places = []ServicePlace{}
+	}
+	return area, nil
+}
+
+// places returns the service places of each of the users, in the order they were given
+func (s *ServiceAreaStore) places(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]ServicePlace, error) {
+	query := `
+	SELECT sa.user_id, sa.name, sa.miles,
+		l.id, l.city, l.state, l.zip_code, COALESCE(l.country, ''), COALESCE(l.country_code, ''), l.latitude, l.longitude
+	FROM service_areas sa
+	JOIN locations l ON l.id = sa.location_id
+	WHERE sa.user_id = ANY($1)
+	ORDER BY sa.id
+	`
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	places := map[uuid.UUID][]ServicePlace{}
+	for rows.Next() {
+		var userID uuid.UUID
+		p := ServicePlace{Location: &Location{}}
+		err := rows.Scan(&userID, &p.Name, &p.Miles,
+			&p.Location.ID, &p.Location.City, &p.Location.State, &p.Location.ZIPCode, &p.Location.Country,
+			&p.Location.CountryCode, &p.Location.Latitude, &p.Location.Longitude,
+		)
+		if err != nil {
+			return nil, err
+		}
+		places[userID] = append(places[userID], p)
+	}
+	return places, rows.Err()
+}
+
+// Replace saves the user's travel radius and swaps in area.Places for their previous places,
+// returning ErrNotFound when the user does not exist
+func (s *ServiceAreaStore) Replace(ctx context.Context, area *ServiceArea) error {
+	ctx, span := startSpan(ctx, "ServiceAreaStore.Replace")
+	defer span.End()
+
+	if area.Places == nil {
+		area.Places = []ServicePlace{}
+	}
+
+	return withTx(s.db, ctx, func(tx DBTX) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, `UPDATE users SET travel_miles = $1 WHERE id = $2`, area.TravelMiles, area.UserID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM service_areas WHERE user_id = $1`, area.UserID); err != nil {
+			return err
+		}
+		for _, p := range area.Places {
+			locationID, err := NewLocationStore(tx).findOrCreate(ctx, p.Location)
+			if err != nil {
+				return err
+			}
+			p.Location.ID = locationID
+
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO service_areas (user_id, location_id, name, miles) VALUES ($1, $2, $3, $4)`,
+				area.UserID, locationID, p.Name, p.Miles,
+			)
+			if err != nil {
+				return fmt.Errorf("inserting service area: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -4,14 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"math/rand/v2"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/michaelhoman/ShotSeek/internal/pagination"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	ErrNotFound          = errors.New("resource not found")
 	ErrConflict          = errors.New("resource already exists")
+	ErrEditConflict      = errors.New("resource was modified by another request")
 	QueryTimeoutDuration = 5 * time.Second
 )
 
@@ -19,57 +26,183 @@ type Storage struct {
 	Posts interface {
 		Create(context.Context, *Post) error
 		GetByID(context.Context, int64) (*Post, error)
+		List(context.Context, PostFilter) (pagination.Page[Post], error)
+		Search(context.Context, PostSearch) (PostSearchResults, error)
 		Update(context.Context, *Post) error
-		Delete(context.Context, int64) error
+		Delete(ctx context.Context, postID int64, version int) error
 	}
 	Users interface {
-		// create(context.Context, *sql.Tx, *User) error
 		Activate(context.Context, string) error
 		GetByEmail(context.Context, string) (*User, error)
 		GetByEmailWithPassword(context.Context, string) (*User, error)
 		GetByID(context.Context, uuid.UUID) (*User, error)
-		Create(context.Context, *sql.Tx, *User, *Location) error
-		update(context.Context, *sql.Tx, *User) error
+		Create(context.Context, *User, *Location) error
 		Update(context.Context, *User, *Location) error
 		Delete(context.Context, uuid.UUID) error
 		CreateAndInvite(context.Context, *User, *Location, string, time.Duration) error
 		GetHashedPassword(context.Context, string) (string, error)
-		LocationStore() *LocationStore
 	}
 	Comments interface {
 		Create(context.Context, *Comment) error
-		GetByPostID(context.Context, int64) ([]Comment, error)
+		GetByPostID(ctx context.Context, postID int64, page pagination.Params) (pagination.Page[Comment], error)
 		GetByCommentID(context.Context, int64) (*Comment, error)
 		Update(context.Context, *Comment) error
 		DeleteByCommentID(context.Context, int64) error
 		DeleteByPostID(context.Context, int64) error
 	}
+	Gigs interface {
+		Create(context.Context, *Gig) error
+		GetByID(context.Context, int64) (*Gig, error)
+		List(context.Context, GigFilter) (pagination.Page[Gig], error)
+		Update(context.Context, *Gig) error
+		UpdateStatus(ctx context.Context, gig *Gig, status GigStatus) error
+	}
+	Applications interface {
+		Create(context.Context, *Application) error
+		GetByID(context.Context, int64) (*Application, error)
+		ListByPost(ctx context.Context, postID int64, filter ApplicationFilter) (pagination.Page[Application], error)
+		ListByUser(ctx context.Context, userID uuid.UUID, page pagination.Params) (pagination.Page[Application], error)
+		UpdateStatus(ctx context.Context, application *Application, status ApplicationStatus) error
+		CloseByPostID(ctx context.Context, postID int64) ([]Application, error)
+	}
+	Notifications interface {
+		Create(context.Context, *Notification) error
+		ListByUser(ctx context.Context, userID uuid.UUID, filter NotificationFilter) (pagination.Page[Notification], error)
+		MarkRead(ctx context.Context, userID uuid.UUID, id int64) (*Notification, error)
+	}
+	Profiles interface {
+		GetByUserID(context.Context, uuid.UUID) (*Profile, error)
+		Search(context.Context, ProfileFilter) (pagination.Page[ProfileMatch], error)
+		Create(context.Context, *Profile) error
+		Update(context.Context, *Profile) error
+	}
+	ServiceAreas interface {
+		Get(context.Context, uuid.UUID) (*ServiceArea, error)
+		Replace(context.Context, *ServiceArea) error
+	}
+	Availability interface {
+		Create(context.Context, *AvailabilityBlock) error
+		GetByID(context.Context, int64) (*AvailabilityBlock, error)
+		ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]AvailabilityBlock, error)
+		LockCalendar(ctx context.Context, userID uuid.UUID) error
+		Update(context.Context, *AvailabilityBlock) error
+		Delete(context.Context, int64) error
+		ReplaceImported(ctx context.Context, userID uuid.UUID, blocks []AvailabilityBlock) error
+		SetFeedToken(ctx context.Context, userID uuid.UUID, tokenHash string) error
+		DeleteFeedToken(context.Context, uuid.UUID) error
+		UserIDByFeedToken(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	}
+	Bookings interface {
+		Create(context.Context, *Booking) error
+		GetByID(context.Context, int64) (*Booking, error)
+		ListByUser(ctx context.Context, userID uuid.UUID, filter BookingFilter) (pagination.Page[Booking], error)
+		ListDueToExpire(ctx context.Context, now time.Time) ([]Booking, error)
+		Update(context.Context, *Booking) error
+		AddEvent(context.Context, *BookingEvent) error
+		ListEvents(ctx context.Context, bookingID int64) ([]BookingEvent, error)
+	}
+	Reviews interface {
+		Create(context.Context, *Review) error
+		GetByID(context.Context, int64) (*Review, error)
+		ListByBooking(ctx context.Context, bookingID int64) ([]Review, error)
+		ListReceived(ctx context.Context, userID uuid.UUID, filter ReviewFilter) (pagination.Page[Review], error)
+		Respond(context.Context, *Review) error
+	}
 	Tokens interface {
 		UpdateRefreshToken(ctx context.Context, userID uuid.UUID, token string, stored_fp string, expiresAt time.Time) error
 		GetRefreshTokens(ctx context.Context, userID uuid.UUID) ([]*RefreshToken, error)
 		GetByRefreshTokenHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
 	}
 	Locations interface {
-		Create(context.Context, *sql.Tx, *Location) (Location, error)
+		Create(context.Context, *Location) (Location, error)
 		Get(context.Context, int64) (Location, error)
 		GetByLocation(context.Context, *Location) (Location, error)
 		GetGeneralLocationByZip(ctx context.Context, zipCode string) (Location, error)
 		GetLocationsByBoundingBox(ctx context.Context, minLat, maxLat, minLon, maxLon float64) ([]Location, error)
 	}
+
+	// withTx runs a unit of work; nil means the stores are already bound to a transaction
+	withTx func(context.Context, func(Storage) error) error
+}
+
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so every store can run inside or outside a transaction
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
 func NewPostgresStorage(db *sql.DB) Storage {
+	s := newPostgresStorage(db)
+	s.withTx = func(ctx context.Context, fn func(Storage) error) error {
+		return retryTx(ctx, func() error {
+			return withTxOptions(db, ctx, serializable, func(tx DBTX) error {
+				return fn(newPostgresStorage(tx))
+			})
+		})
+	}
+	return s
+}
+
+func newPostgresStorage(db DBTX) Storage {
 	return Storage{
-		Posts:     &PostStore{db},
-		Users:     &UserStore{db, NewLocationStore(db)},
-		Comments:  &CommentsStore{db},
-		Tokens:    &TokenStore{db},
-		Locations: &LocationStore{db},
+		Posts:         &PostStore{db},
+		Users:         &UserStore{db},
+		Comments:      &CommentsStore{db},
+		Gigs:          &GigStore{db},
+		Applications:  &ApplicationStore{db},
+		Notifications: &NotificationStore{db},
+		Profiles:      &ProfileStore{db},
+		ServiceAreas:  &ServiceAreaStore{db},
+		Availability:  &AvailabilityStore{db},
+		Bookings:      &BookingStore{db},
+		Reviews:       &ReviewStore{db},
+		Tokens:        &TokenStore{db},
+		Locations:     &LocationStore{db},
+	}
+}
+
+// WithTx runs fn with a Storage whose stores share one SERIALIZABLE transaction, committing when
+// fn returns nil and rolling back otherwise. Serialization failures and deadlocks rerun fn from the
+// start, so it must not have side effects outside the store. Inside fn, WithTx joins the outer transaction.
+func (s Storage) WithTx(ctx context.Context, fn func(tx Storage) error) error {
+	if s.withTx == nil {
+		return fn(s)
 	}
+	return s.withTx(ctx, fn)
+}
+
+var tracer = otel.Tracer("github.com/michaelhoman/ShotSeek/internal/store")
+
+// startSpan opens a client span named after the store method, e.g. "UserStore.GetByID"
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(name),
+		),
+	)
 }
 
-func withTx(db *sql.DB, ctx context.Context, fn func(*sql.Tx) error) error {
-	tx, err := db.BeginTx(ctx, nil)
+// serializable is the isolation of Storage.WithTx units of work. Concurrent units that read
+// what the other writes fail with 40001 instead of both committing, and retryTx reruns them.
+var serializable = &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+// withTx runs fn in a new transaction at the default READ COMMITTED isolation, or directly on db
+// when it already is one
+func withTx(db DBTX, ctx context.Context, fn func(DBTX) error) error {
+	return withTxOptions(db, ctx, nil, fn)
+}
+
+// withTxOptions is withTx for a new transaction started with opts
+func withTxOptions(db DBTX, ctx context.Context, opts *sql.TxOptions, fn func(DBTX) error) error {
+	sqlDB, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := sqlDB.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -79,5 +212,32 @@ func withTx(db *sql.DB, ctx context.Context, fn func(*sql.Tx) error) error {
 	}
 
 	return tx.Commit()
+}
+
+const (
+	maxTxAttempts  = 3
+	txRetryBackoff = 20 * time.Millisecond
+)
+
+// retryTx reruns fn while it fails with a serialization failure (40001) or deadlock (40P01)
+func retryTx(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt == maxTxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		// Jitter keeps the conflicting transactions from colliding again in lockstep
+		backoff := txRetryBackoff*time.Duration(attempt) + time.Duration(rand.Int64N(int64(txRetryBackoff)))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
 
+func isRetryable(err error) bool {
+	var pgErr *pq.Error
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
 }
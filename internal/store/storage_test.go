@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTx(t *testing.T) {
+	serialization := &pq.Error{Code: "40001"}
+	deadlock := &pq.Error{Code: "40P01"}
+	uniqueViolation := &pq.Error{Code: "23505"}
+
+	tests := []struct {
+		name     string
+		errs     []error
+		wantErr  error
+		attempts int
+	}{
+		{name: "success", errs: []error{nil}, attempts: 1},
+		{name: "serialization failure then success", errs: []error{serialization, nil}, attempts: 2},
+		{name: "wrapped deadlock then success", errs: []error{fmt.Errorf("deleting post: %w", deadlock), nil}, attempts: 2},
+		{name: "gives up", errs: []error{serialization, serialization, serialization, nil}, wantErr: serialization, attempts: maxTxAttempts},
+		{name: "other errors are not retried", errs: []error{uniqueViolation, nil}, wantErr: uniqueViolation, attempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := retryTx(context.Background(), func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.attempts, attempts)
+		})
+	}
+}
+
+func TestRetryTxStopsWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := retryTx(ctx, func() error { return &pq.Error{Code: "40001"} })
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,7 +17,7 @@ type RefreshToken struct {
 }
 
 type TokenStore struct {
-	db *sql.DB
+	db DBTX
 }
 
 // func (s *TokenStore) UpdateRefreshToken(ctx context.Context, userEmail, token_hash string, expiresAt time.Time) error {
@@ -60,9 +59,9 @@ type TokenStore struct {
 // }
 
 func (s *TokenStore) UpdateRefreshToken(ctx context.Context, user_id uuid.UUID, token_hash, stored_fp string, expiresAt time.Time) error {
-	fmt.Println("******") // Debugging
-	fmt.Println("******") // Debugging
-	fmt.Println("UpdateRefreshToken called with user_id:", user_id, "token_hash:", token_hash, "stored_fp:", stored_fp, "expiresAt:", expiresAt)
+	ctx, span := startSpan(ctx, "TokenStore.UpdateRefreshToken")
+	defer span.End()
+
 	query := `
     INSERT INTO refresh_tokens (user_id, token_hash, stored_fp, expires_at)
     VALUES ($1, $2, $3, $4)
@@ -72,12 +71,8 @@ func (s *TokenStore) UpdateRefreshToken(ctx context.Context, user_id uuid.UUID,
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	fmt.Println("Executing query:", query) // Log the query
-	fmt.Printf("Inserting token for user: %s, token_hash: %s\n", user_id, token_hash)
-
 	_, err := s.db.ExecContext(ctx, query, user_id, token_hash, stored_fp, expiresAt)
 	if err != nil {
-		fmt.Println("Error inserting token:", err) // Log any errors
 		return err
 	}
 	return nil
@@ -85,6 +80,9 @@ func (s *TokenStore) UpdateRefreshToken(ctx context.Context, user_id uuid.UUID,
 
 // GetRefreshTokens retrieves all refresh tokens for a user
 func (s *TokenStore) GetRefreshTokens(ctx context.Context, userID uuid.UUID) ([]*RefreshToken, error) {
+	ctx, span := startSpan(ctx, "TokenStore.GetRefreshTokens")
+	defer span.End()
+
 	query := `
 	SELECT user_id, token_hash, stored_fp, expires_at
 	FROM refresh_tokens
@@ -93,8 +91,6 @@ func (s *TokenStore) GetRefreshTokens(ctx context.Context, userID uuid.UUID) ([]
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	fmt.Println("1* GetRefreshTokens query: ", query)
-
 	rows, err := s.db.QueryContext(
 		ctx,
 		query,
@@ -104,16 +100,11 @@ func (s *TokenStore) GetRefreshTokens(ctx context.Context, userID uuid.UUID) ([]
 		return nil, err
 	}
 
-	fmt.Println("2* GetRefreshTokens rows: ", rows)
-
-	fmt.Println()
-
 	defer rows.Close()
 
 	var refreshTokens []*RefreshToken
 
 	if err := rows.Err(); err != nil {
-		fmt.Println("No refesh tokens found")
 		return nil, err
 	}
 
@@ -135,6 +126,9 @@ func (s *TokenStore) GetRefreshTokens(ctx context.Context, userID uuid.UUID) ([]
 // GetByTokenHash retrieves a refresh token by its hash
 
 func (s *TokenStore) GetByRefreshTokenHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	ctx, span := startSpan(ctx, "TokenStore.GetByRefreshTokenHash")
+	defer span.End()
+
 	query := `
     SELECT user_id, token_hash, stored_fp, expires_at
     FROM refresh_tokens
@@ -152,8 +146,8 @@ func (s *TokenStore) GetByRefreshTokenHash(ctx context.Context, tokenHash string
 		&token.TokenHash,
 		&token.StoredFP,
 		&token.ExpiresAt); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("refresh token not found") // Graceful error handling
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
 		}
 		return nil, err
 	}
@@ -7,7 +7,6 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -53,10 +52,8 @@ type password struct {
 // }
 
 func (p *password) Set(plain string) error {
-	fmt.Println("Setting password") // TODO: Remove Debugging line
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
-	fmt.Println("Set Hash is:", hash) // TODO: Remove Debugging line
 	if err != nil {
 		return err
 	}
@@ -66,132 +63,88 @@ func (p *password) Set(plain string) error {
 }
 
 func (p *password) Compare(storedPassword string) error {
-	fmt.Println("Comparing password")             //TODO: Remove this line
-	fmt.Println("p.hash", p.hash)                 //TODO: Remove this line
-	fmt.Println("[]byte(p.hash)", storedPassword) //TODO: Remove this line
 	return bcrypt.CompareHashAndPassword(p.hash, []byte(storedPassword))
 }
 
 type UserStore struct {
-	db            *sql.DB
-	locationStore *LocationStore
+	db DBTX
 }
 
-// Satisfy the Users interface
-func (s *UserStore) LocationStore() *LocationStore {
-	return s.locationStore
-}
+// Create inserts user, reusing a matching location or inserting it first
+func (s *UserStore) Create(ctx context.Context, user *User, location *Location) error {
+	ctx, span := startSpan(ctx, "UserStore.Create")
+	defer span.End()
 
-func (s *UserStore) Create(ctx context.Context, tx *sql.Tx, user *User, location *Location) error {
-	var locationID int64 // Pointer so we can insert NULL if needed
+	if location != nil && !location.IsValid() {
+		return fmt.Errorf("location provided but missing required fields (city, state, or zip code)")
+	}
 
-	fmt.Println()
-	fmt.Println()
-	fmt.Println("Location is:", location)
+	return withTx(s.db, ctx, func(tx DBTX) error {
+		return s.create(ctx, tx, user, location)
+	})
+}
 
-	if location != nil {
-		if !location.IsValid() {
-			return fmt.Errorf("location provided but missing required fields (city, state, or zip code)")
-		}
-		// continue...
-		fmt.Println("Location is valid, proceeding with location check")
-		submittedLocation, err := s.locationStore.GetByLocation(ctx, location)
+func (s *UserStore) create(ctx context.Context, tx DBTX, user *User, location *Location) error {
+	var locationID sql.NullInt64 // NULL when the user has no location
 
+	if location != nil {
+		locations := NewLocationStore(tx)
+		submittedLocation, err := locations.GetByLocation(ctx, location)
 		if err != nil {
-			utils.Logger.Info("Error querying location: %v", err)
+			utils.LoggerFromCtx(ctx).Debugw("location not found, a new one will be created", "error", err)
 		}
 		if submittedLocation.ID != 0 {
-			fmt.Println("Location already exists, using existing location ID")
-			locationID = submittedLocation.ID
+			locationID = sql.NullInt64{Int64: submittedLocation.ID, Valid: true}
 		} else {
-			// Location does not exist, first query Nominatim API and then insert new location into locations table
-
-			fmt.Println("Location does not exist, inserting new location")
-			// locationID = submittedLocation.ID
-
-			// Debugging logs
-			log.Printf("Attempting to find location with email: %s", user.Email)
-			log.Printf("Location query with: Street=%s, City=%s, State=%s, ZipCode=%s", location.Street, location.City, location.State, location.ZIPCode)
-
-			// fmt.Printf(err)
-			//print err
-			fmt.Println("Error 12 is:", err)
-
-			newLocation, err := s.locationStore.Create(ctx, tx, location)
+			newLocation, err := locations.Create(ctx, location)
 			if err != nil {
-				log.Printf("Error inserting location: %v", err)
 				return fmt.Errorf("inserting location: %w", err)
 			}
-			locationID = newLocation.ID
-			log.Printf("Inserted new location with ID: %d", locationID)
-		}
-
-		// Debugging log before inserting user
-		log.Printf("Inserting user: %s", user.Email)
-		log.Printf("User info - First Name: %s, Last Name: %s", user.FirstName, user.LastName)
-		if locationID != 0 {
-			log.Printf("Location ID to insert: %d", locationID)
-		} else {
-			log.Printf("No location ID provided, setting to NULL")
+			locationID = sql.NullInt64{Int64: newLocation.ID, Valid: true}
 		}
+	}
 
-		newUserID := uuid.New()
-
-		// Debugging log for new user ID
-		log.Printf("New user ID generated: %s", newUserID)
-		log.Printf("Inserting user: id=%s, first_name=%s, last_name=%s, email=%s, password=%s, location_id=%d", newUserID, user.FirstName, user.LastName, user.Email, user.Password.hash, locationID)
-
-		// Insert the user with correct location_id handling
-		// userInsertQuery := `
-		// 	INSERT INTO users (id, email, password, first_name, last_name, location_id)
-		// 	VALUES ( $1, $2, $3, $4, $5, $6)
-		// 	RETURNING id, created_at
-		// 	`
-		userInsertQuery := `
-		INSERT INTO users (id, email, password, first_name, last_name, location_id)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at`
+	userInsertQuery := `
+	INSERT INTO users (id, email, password, first_name, last_name, location_id)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id, created_at`
 
-		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
-		defer cancel()
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
 
-		// Check if locationID is valid or nil before inserting
-		err = tx.QueryRowContext(
-			ctx,
-			userInsertQuery,
-			newUserID,
-			user.Email,
-			user.Password.hash, // assuming a method, or use []byte directly if applicable
-			user.FirstName,
-			user.LastName,
-			locationID, // Use NULL if no location exists, or the valid locationID
-		).Scan(&user.ID, &user.CreatedAt)
+	err := tx.QueryRowContext(
+		ctx,
+		userInsertQuery,
+		uuid.New(),
+		user.Email,
+		user.Password.hash,
+		user.FirstName,
+		user.LastName,
+		locationID, // NULL when the user has no location
+	).Scan(&user.ID, &user.CreatedAt)
 
-		if err != nil {
-			var pgErr *pq.Error
-			if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.Constraint == "users_email_key" {
-				log.Printf("Duplicate email found: %s", user.Email)
-				return ErrDuplicateEmail
-			}
-			log.Printf("Error inserting user: %v", err)
-			return fmt.Errorf("inserting user: %w", err)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.Constraint == "users_email_key" {
+			return ErrDuplicateEmail
 		}
-
-		// Success debugging
-		log.Printf("User successfully created with ID: %s at %v", user.ID, user.CreatedAt)
-
-		return nil
+		return fmt.Errorf("inserting user: %w", err)
 	}
+
+	user.LocationID = locationID.Int64
 	return nil
 }
 
 func (s *UserStore) GetByEmail(ctx context.Context, email string) (*User, error) {
-	fmt.Println("GetByEmail called with email:", email) // Debugging line
+	ctx, span := startSpan(ctx, "UserStore.GetByEmail")
+	defer span.End()
+
 	// Check if email is empty
 	query := `
 SELECT
     u.id, u.email, u.first_name, u.last_name, u.created_at, u.updated_at, u.version, u.is_active,
-    l.id AS location_id, l.street, l.city, l.state, l.zip_code, l.country, l.latitude, l.longitude
+    COALESCE(l.id, 0) AS location_id, COALESCE(l.street, ''), COALESCE(l.city, ''), COALESCE(l.state, ''),
+    COALESCE(l.zip_code, ''), COALESCE(l.country, ''), COALESCE(l.latitude, 0), COALESCE(l.longitude, 0)
 FROM users u
 LEFT JOIN locations l ON u.location_id = l.id
 WHERE u.email = $1
@@ -223,7 +176,6 @@ WHERE u.email = $1
 	)
 
 	if err != nil {
-		fmt.Println("Error occurred while querying user:", err) // Debugging line
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			return nil, ErrNotFound
@@ -231,18 +183,20 @@ WHERE u.email = $1
 			return nil, err
 		}
 	}
-	fmt.Println("User found:", user) // Debugging line
 	user.LocationID = location.ID
 	return &user, nil
 }
 
 func (s *UserStore) GetByEmailWithPassword(ctx context.Context, email string) (*User, error) {
-	fmt.Println("GetByEmail called with email:", email) // Debugging line
+	ctx, span := startSpan(ctx, "UserStore.GetByEmailWithPassword")
+	defer span.End()
+
 	// Check if email is empty
 	query := `
 SELECT
     u.id, u.email, u.password, u.first_name, u.last_name, u.created_at, u.updated_at, u.version, u.is_active,
-    l.id AS location_id, l.street, l.city, l.state, l.zip_code, l.country, l.latitude, l.longitude
+    COALESCE(l.id, 0) AS location_id, COALESCE(l.street, ''), COALESCE(l.city, ''), COALESCE(l.state, ''),
+    COALESCE(l.zip_code, ''), COALESCE(l.country, ''), COALESCE(l.latitude, 0), COALESCE(l.longitude, 0)
 FROM users u
 LEFT JOIN locations l ON u.location_id = l.id
 WHERE u.email = $1
@@ -275,7 +229,6 @@ WHERE u.email = $1
 	)
 
 	if err != nil {
-		fmt.Println("Error occurred while querying user:", err) // Debugging line
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			return nil, ErrNotFound
@@ -283,7 +236,6 @@ WHERE u.email = $1
 			return nil, err
 		}
 	}
-	fmt.Println("User found:", user) // Debugging line
 	user.LocationID = location.ID
 	return &user, nil
 }
@@ -323,6 +275,9 @@ WHERE u.email = $1
 // }
 
 func (s *UserStore) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	ctx, span := startSpan(ctx, "UserStore.GetByID")
+	defer span.End()
+
 	query := `
 SELECT id, email, first_name, last_name, created_at, updated_at, version
 FROM users
@@ -345,7 +300,6 @@ WHERE id = $1
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			log.Printf("Error fetching post: %v", err) //TODO: CHECK LOGGING PROCEDURE Or use structured logging
 			return nil, ErrNotFound
 		default:
 			return nil, err
@@ -355,12 +309,17 @@ WHERE id = $1
 }
 
 func (s *UserStore) Update(ctx context.Context, user *User, location *Location) error {
-	// Begin a transaction
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback() // Make sure to rollback in case of an error
+	ctx, span := startSpan(ctx, "UserStore.Update")
+	defer span.End()
+
+	return withTx(s.db, ctx, func(tx DBTX) error {
+		return s.update(ctx, tx, user, location)
+	})
+}
+
+func (s *UserStore) update(ctx context.Context, tx DBTX, user *User, location *Location) error {
+	// Without a location the user keeps the one they have
+	locationID := sql.NullInt64{Int64: user.LocationID, Valid: user.LocationID != 0}
 
 	// If location is provided (indicating it might be updated), update the location table
 	if location != nil {
@@ -411,46 +370,56 @@ func (s *UserStore) Update(ctx context.Context, user *User, location *Location)
 				return err
 			}
 		}
+		locationID = sql.NullInt64{Int64: location.ID, Valid: true}
 	}
 
 	// Now update the user table with the new or unchanged location ID
 	updateUserQuery := `
 	UPDATE users
-	SET email = $1, password = $2, first_name = $3, last_name = $4, location_id = $5, version = version + 1, updated_at = NOW()
+	SET email = $1, password = COALESCE($2, password), first_name = $3, last_name = $4, location_id = $5, version = version + 1, updated_at = NOW()
 	WHERE id = $6 AND version = $7
 	RETURNING version
 	`
 
-	err = tx.QueryRowContext(
+	err := tx.QueryRowContext(
 		ctx,
 		updateUserQuery,
 		user.Email,
-		user.Password,
+		user.Password.hash, // NULL keeps the stored hash when the user was loaded without it
 		user.FirstName,
 		user.LastName,
-		location.ID, // Update location_id in the users table
+		locationID,
 		user.ID,
 		user.Version,
 	).Scan(&user.Version)
 
 	if err != nil {
+		var pgErr *pq.Error
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
+			var exists bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, user.ID).Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				return ErrEditConflict
+			}
 			return ErrNotFound
+		case errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.Constraint == "users_email_key":
+			return ErrDuplicateEmail
 		default:
 			return err
 		}
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return err
-	}
-
+	user.LocationID = locationID.Int64
 	return nil
 }
 
 func (s *UserStore) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := startSpan(ctx, "UserStore.Delete")
+	defer span.End()
+
 	query := `
 DELETE FROM users
 WHERE id = $1
@@ -473,8 +442,15 @@ WHERE id = $1
 }
 
 func (s *UserStore) CreateAndInvite(ctx context.Context, user *User, location *Location, token string, invitationExp time.Duration) error {
-	return withTx(s.db, ctx, func(tx *sql.Tx) error {
-		if err := s.Create(ctx, tx, user, location); err != nil {
+	ctx, span := startSpan(ctx, "UserStore.CreateAndInvite")
+	defer span.End()
+
+	if location != nil && !location.IsValid() {
+		return fmt.Errorf("location provided but missing required fields (city, state, or zip code)")
+	}
+
+	return withTx(s.db, ctx, func(tx DBTX) error {
+		if err := s.create(ctx, tx, user, location); err != nil {
 			return err
 		}
 		err := s.createUserInvitation(ctx, tx, user.ID, invitationExp, token)
@@ -485,7 +461,7 @@ func (s *UserStore) CreateAndInvite(ctx context.Context, user *User, location *L
 	})
 }
 
-func (s *UserStore) createUserInvitation(ctx context.Context, tx *sql.Tx, userID uuid.UUID, invitationExp time.Duration, token string) error {
+func (s *UserStore) createUserInvitation(ctx context.Context, tx DBTX, userID uuid.UUID, invitationExp time.Duration, token string) error {
 	query := `
 INSERT INTO user_invitations (user_id, token, expires_at) VALUES ($1, $2,  $3)
 `
@@ -500,20 +476,23 @@ INSERT INTO user_invitations (user_id, token, expires_at) VALUES ($1, $2,  $3)
 }
 
 func (s *UserStore) Activate(ctx context.Context, token string) error {
+	ctx, span := startSpan(ctx, "UserStore.Activate")
+	defer span.End()
+
 	// find the user that this token corresponds to
 	// check if the token is expired
 	// if expired return an error
 	// if not expired
 	// activate the user
 	// delete the invitation
-	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+	return withTx(s.db, ctx, func(tx DBTX) error {
 		user, err := s.getUserFromInvitation(ctx, tx, token)
 		if err != nil {
 			return err
 		}
 		// Update user
 		user.IsActive = true
-		if err := s.update(ctx, tx, user); err != nil {
+		if err := s.activate(ctx, tx, user); err != nil {
 			return err
 		}
 		// Clean Invitations
@@ -526,9 +505,9 @@ func (s *UserStore) Activate(ctx context.Context, token string) error {
 
 }
 
-func (s *UserStore) getUserFromInvitation(ctx context.Context, tx *sql.Tx, token string) (*User, error) {
+func (s *UserStore) getUserFromInvitation(ctx context.Context, tx DBTX, token string) (*User, error) {
 	query := `
-	SELECT u.id, u.email, u.first_name, u.last_name, u.location_id, u.created_at, u.is_active
+	SELECT u.id, u.email, u.first_name, u.last_name, COALESCE(u.location_id, 0), u.created_at, u.is_active
 	FROM users u
 	JOIN user_invitations ui ON u.id = ui.user_id
 	WHERE ui.token = $1 AND ui.expires_at > $2
@@ -557,11 +536,10 @@ func (s *UserStore) getUserFromInvitation(ctx context.Context, tx *sql.Tx, token
 			return nil, err
 		}
 	}
-	fmt.Println("GetUserFromInvitation User found:", user) // Debugging line
 	return user, nil
 }
 
-func (s *UserStore) update(ctx context.Context, tx *sql.Tx, user *User) error {
+func (s *UserStore) activate(ctx context.Context, tx DBTX, user *User) error {
 	query := `
 	UPDATE users
 	SET is_active = $1
@@ -578,7 +556,8 @@ func (s *UserStore) update(ctx context.Context, tx *sql.Tx, user *User) error {
 }
 
 func (s *UserStore) GetHashedPassword(ctx context.Context, email string) (string, error) {
-	fmt.Println("GetHashedPassword called with email:", email) // Debugging line
+	ctx, span := startSpan(ctx, "UserStore.GetHashedPassword")
+	defer span.End()
 
 	query := `
 SELECT password
@@ -592,17 +571,15 @@ WHERE email = $1
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			fmt.Println("Error occurred while querying password:", err) // Debugging line
 			return "", ErrNotFound
 		default:
 			return "", err
 		}
 	}
-	fmt.Println("GetHashedPassword: Hashed Password:", passwordHash) // Debugging line
 	return passwordHash, nil
 }
 
-func (s *UserStore) deleteInvitation(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+func (s *UserStore) deleteInvitation(ctx context.Context, tx DBTX, userID uuid.UUID) error {
 	query := `
 	DELETE FROM user_invitations
 	WHERE user_id = $1
@@ -1,25 +1,57 @@
 package utils
 
 import (
+	"errors"
 	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func InternalServerError(w http.ResponseWriter, r *http.Request, err error) {
-	Logger.Errorw("internal error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
-	WriteJSONError(w, http.StatusInternalServerError, "The server encountered a problem")
+	trace.SpanFromContext(r.Context()).RecordError(err)
+	WriteProblem(w, r, WrapAppError(http.StatusInternalServerError, CodeInternal, "The server encountered a problem", err))
 }
 
+// BadRequestResponse passes AppErrors and validation errors through unchanged so their
+// codes and field details survive; any other error is reported as a generic bad request
+// and its message is only logged.
 func BadRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
-	Logger.Warnf("bad request", "method", r.Method, "path", r.URL.Path, "error", err.Error())
-	WriteJSONError(w, http.StatusBadRequest, err.Error())
+	var appErr *AppError
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &appErr) || errors.As(err, &validationErrs) {
+		WriteProblem(w, r, err)
+		return
+	}
+	WriteProblem(w, r, WrapAppError(http.StatusBadRequest, CodeBadRequest, "The request is malformed", err))
 }
 
 func NotFoundResponse(w http.ResponseWriter, r *http.Request, err error) {
-	Logger.Warnf("not found error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
-	WriteJSONError(w, http.StatusNotFound, "not found")
+	WriteProblem(w, r, WrapAppError(http.StatusNotFound, CodeNotFound, "The requested resource could not be found", err))
 }
 
 func UnauthorizedErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
-	Logger.Warnf("unauthorized error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
-	WriteJSONError(w, http.StatusUnauthorized, "TEST unauthorized")
+	WriteProblem(w, r, WrapAppError(http.StatusUnauthorized, CodeUnauthorized, "Missing or invalid credentials", err))
+}
+
+func ForbiddenResponse(w http.ResponseWriter, r *http.Request, err error) {
+	WriteProblem(w, r, WrapAppError(http.StatusForbidden, CodeForbidden, "You do not have permission to perform this action", err))
+}
+
+func ConflictResponse(w http.ResponseWriter, r *http.Request, err error) {
+	WriteProblem(w, r, WrapAppError(http.StatusConflict, CodeConflict, "The request conflicts with the current state of the resource", err))
+}
+
+func UpstreamFailureResponse(w http.ResponseWriter, r *http.Request, err error) {
+	trace.SpanFromContext(r.Context()).RecordError(err)
+	WriteProblem(w, r, WrapAppError(http.StatusBadGateway, CodeUpstreamFailure, "An upstream service failed to respond", err))
+}
+
+// NotFoundHandler and MethodNotAllowedHandler replace chi's plain text defaults
+func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	WriteProblem(w, r, NewAppError(http.StatusNotFound, CodeNotFound, "No route matches "+r.URL.Path))
+}
+
+func MethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	WriteProblem(w, r, NewAppError(http.StatusMethodNotAllowed, CodeMethodNotAllowed, r.Method+" is not supported for "+r.URL.Path))
 }
@@ -1,14 +1,45 @@
 package utils
 
-import "go.uber.org/zap"
+import (
+	"context"
+	"sync"
+
+	"github.com/michaelhoman/ShotSeek/internal/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
 
 // Logger is a global logger instance
-var Logger *zap.SugaredLogger
+var Logger *zap.SugaredLogger = zap.NewNop().Sugar()
+
+// InitLogger initializes the logger and assigns it to the global variable.
+// Production builds log JSON at info level by default; everything else logs
+// human-readable output at debug level. cfg.Level overrides the default level.
+func InitLogger(cfg config.LogConfig, environment string) error {
+	var zapCfg zap.Config
+	if environment == "production" {
+		zapCfg = zap.NewProductionConfig()
+	} else {
+		zapCfg = zap.NewDevelopmentConfig()
+	}
+
+	if cfg.Level != "" {
+		level, err := zap.ParseAtomicLevel(cfg.Level)
+		if err != nil {
+			return err
+		}
+		zapCfg.Level = level
+	}
 
-// InitLogger initializes the logger and assigns it to the global variable
-func InitLogger() {
-	logger := zap.Must(zap.NewProduction()).Sugar()
-	Logger = logger
+	logger, err := zapCfg.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return NewRedactingCore(core)
+	}))
+	if err != nil {
+		return err
+	}
+
+	Logger = logger.Sugar()
+	return nil
 }
 
 // CleanupLogger flushes logs before the program exits
@@ -17,3 +48,39 @@ func CleanupLogger() {
 		Logger.Sync()
 	}
 }
+
+type loggerCtxKey string
+
+const loggerCtx loggerCtxKey = "logger"
+
+// requestLogger is shared by pointer through the request context so fields added
+// deep in the middleware chain (e.g. user_id) also end up on the access log line
+type requestLogger struct {
+	mu     sync.RWMutex
+	logger *zap.SugaredLogger
+}
+
+// ContextWithLogger returns a copy of ctx carrying logger for the rest of the request
+func ContextWithLogger(ctx context.Context, logger *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, loggerCtx, &requestLogger{logger: logger})
+}
+
+// LoggerFromCtx returns the request-scoped logger, or the global Logger outside of a request
+func LoggerFromCtx(ctx context.Context) *zap.SugaredLogger {
+	if rl, ok := ctx.Value(loggerCtx).(*requestLogger); ok {
+		rl.mu.RLock()
+		defer rl.mu.RUnlock()
+		return rl.logger
+	}
+	return Logger
+}
+
+// AddLogFields attaches key/value pairs to the request-scoped logger in ctx.
+// It is a no-op when ctx does not carry a request logger.
+func AddLogFields(ctx context.Context, keysAndValues ...any) {
+	if rl, ok := ctx.Value(loggerCtx).(*requestLogger); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		rl.logger = rl.logger.With(keysAndValues...)
+	}
+}
@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-playground/validator/v10"
+)
+
+// ProblemContentType is the RFC 7807 media type used for every error response
+const ProblemContentType = "application/problem+json"
+
+// ErrorCode is a stable, machine readable identifier for a class of error.
+// Clients may switch on it, so existing values must never change meaning.
+type ErrorCode string
+
+const (
+	CodeBadRequest           ErrorCode = "bad_request"
+	CodeInvalidJSON          ErrorCode = "invalid_json"
+	CodeValidationFailed     ErrorCode = "validation_failed"
+	CodeUnauthorized         ErrorCode = "unauthorized"
+	CodeForbidden            ErrorCode = "forbidden"
+	CodeNotFound             ErrorCode = "not_found"
+	CodeMethodNotAllowed     ErrorCode = "method_not_allowed"
+	CodeConflict             ErrorCode = "conflict"
+	CodeDuplicateEmail       ErrorCode = "duplicate_email"
+	CodeEditConflict         ErrorCode = "edit_conflict"
+	CodePreconditionFailed   ErrorCode = "precondition_failed"
+	CodePreconditionRequired ErrorCode = "precondition_required"
+	CodeInvalidTransition    ErrorCode = "invalid_transition"
+	CodeRateLimited          ErrorCode = "rate_limited"
+	CodeUpstreamFailure      ErrorCode = "upstream_failure"
+	CodeInternal             ErrorCode = "internal_error"
+)
+
+// AppError is the typed application error rendered as problem details.
+// Detail is shown to clients; Err is the underlying cause and is only logged.
+type AppError struct {
+	Status int
+	Code   ErrorCode
+	Detail string
+	Fields []FieldError
+	// Current is the resource as it stands now, returned with conflicts so clients can merge
+	Current any
+	Err     error
+}
+
+// FieldError describes a single invalid request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Problem is the RFC 7807 response body, extended with our error code, request ID and field errors
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      ErrorCode    `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Current   any          `json:"current,omitempty"`
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Code, e.Err)
+	}
+	if e.Detail != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Detail)
+	}
+	return string(e.Code)
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// NewAppError builds an AppError with a client facing detail message
+func NewAppError(status int, code ErrorCode, detail string) *AppError {
+	return &AppError{Status: status, Code: code, Detail: detail}
+}
+
+// WrapAppError builds an AppError that keeps cause for logging
+func WrapAppError(status int, code ErrorCode, detail string, cause error) *AppError {
+	return &AppError{Status: status, Code: code, Detail: detail, Err: cause}
+}
+
+// InvalidField reports a single body field that passed its own rules but is inconsistent with another
+func InvalidField(field, rule, message string) *AppError {
+	return &AppError{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidationFailed,
+		Detail: "One or more fields are invalid",
+		Fields: []FieldError{{Field: field, Rule: rule, Message: message}},
+	}
+}
+
+// InvalidQueryParam reports a single bad query parameter the way validation failures are reported for bodies
+func InvalidQueryParam(field, rule, message string) *AppError {
+	return &AppError{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidationFailed,
+		Detail: "One or more query parameters are invalid",
+		Fields: []FieldError{{Field: field, Rule: rule, Message: message}},
+	}
+}
+
+// AsAppError converts any error into an AppError. Validation errors keep their field
+// details; anything unrecognised becomes an opaque internal error.
+func AsAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return &AppError{
+			Status: http.StatusBadRequest,
+			Code:   CodeValidationFailed,
+			Detail: "One or more fields are invalid",
+			Fields: fieldErrors(validationErrs),
+			Err:    err,
+		}
+	}
+
+	return WrapAppError(http.StatusInternalServerError, CodeInternal, "The server encountered a problem", err)
+}
+
+// WriteProblem renders err as application/problem+json, logging server errors with their cause
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := AsAppError(err)
+
+	logger := LoggerFromCtx(r.Context())
+	switch {
+	case appErr.Status >= http.StatusInternalServerError:
+		logger.Errorw("request failed", "code", appErr.Code, "status", appErr.Status, "error", err)
+	default:
+		logger.Infow("request rejected", "code", appErr.Code, "status", appErr.Status, "error", err)
+	}
+
+	problem := Problem{
+		Type:      "/problems/" + string(appErr.Code),
+		Title:     http.StatusText(appErr.Status),
+		Status:    appErr.Status,
+		Detail:    appErr.Detail,
+		Instance:  r.URL.Path,
+		Code:      appErr.Code,
+		RequestID: middleware.GetReqID(r.Context()),
+		Errors:    appErr.Fields,
+		Current:   appErr.Current,
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(appErr.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+func fieldErrors(errs validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: validationMessage(fe),
+		})
+	}
+	return fields
+}
+
+// validationMessage turns a validator rule into a sentence that does not leak Go type names
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), lengthUnit(fe))
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), lengthUnit(fe))
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", fe.Param())
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "datetime":
+		return fmt.Sprintf("must match the layout %s", fe.Param())
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	case "timezone":
+		return "must be an IANA time zone"
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
+}
+
+func lengthUnit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteProblem(t *testing.T) {
+	type payload struct {
+		Email     string `json:"email" validate:"required,email"`
+		FirstName string `json:"first_name" validate:"min=2"`
+	}
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   ErrorCode
+		expectedDetail string
+		expectedFields []FieldError
+	}{
+		{
+			name:           "validation errors expose json field names",
+			err:            Validate.Struct(payload{Email: "nope", FirstName: "J"}),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeValidationFailed,
+			expectedDetail: "One or more fields are invalid",
+			expectedFields: []FieldError{
+				{Field: "email", Rule: "email", Message: "must be a valid email address"},
+				{Field: "first_name", Rule: "min", Message: "must be at least 2 characters"},
+			},
+		},
+		{
+			name:           "app errors keep their status and code",
+			err:            NewAppError(http.StatusConflict, CodeDuplicateEmail, "A user with that email already exists"),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   CodeDuplicateEmail,
+			expectedDetail: "A user with that email already exists",
+		},
+		{
+			name:           "unknown errors do not leak their message",
+			err:            errors.New("pq: connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   CodeInternal,
+			expectedDetail: "The server encountered a problem",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/v1/auth/register", nil)
+			w := httptest.NewRecorder()
+
+			WriteProblem(w, r, tt.err)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+			var problem Problem
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+			assert.Equal(t, tt.expectedStatus, problem.Status)
+			assert.Equal(t, tt.expectedCode, problem.Code)
+			assert.Equal(t, "/problems/"+string(tt.expectedCode), problem.Type)
+			assert.Equal(t, tt.expectedDetail, problem.Detail)
+			assert.Equal(t, "/v1/auth/register", problem.Instance)
+			assert.Equal(t, tt.expectedFields, problem.Errors)
+		})
+	}
+}
+
+func TestReadJSONDoesNotLeakDecoderErrors(t *testing.T) {
+	type payload struct {
+		Rate     int       `json:"rate"`
+		StartsAt time.Time `json:"starts_at"`
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedDetail string
+		expectedFields []FieldError
+	}{
+		{"empty", "", "Request body must not be empty", nil},
+		{"malformed", `{"rate": 1`, "Request body is not valid JSON", nil},
+		{"syntax", `{"rate": x}`, "Request body is not valid JSON", nil},
+		{
+			"wrong type", `{"rate": "a lot"}`, "Request body has a field of the wrong type",
+			[]FieldError{{Field: "rate", Rule: "type", Message: "must be a whole number"}},
+		},
+		{
+			"unknown field", `{"price": 1}`, "Request body has an unknown field",
+			[]FieldError{{Field: "price", Rule: "unknown", Message: "is not a recognised field"}},
+		},
+		{"bad timestamp", `{"starts_at": "tomorrow"}`, "Request body could not be decoded", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/v1/bookings", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			err := ReadJSON(w, r, &payload{})
+			assert.Error(t, err)
+			BadRequestResponse(w, r, err)
+
+			var problem Problem
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+			assert.Equal(t, http.StatusBadRequest, problem.Status)
+			assert.Equal(t, CodeInvalidJSON, problem.Code)
+			assert.Equal(t, tt.expectedDetail, problem.Detail)
+			assert.Equal(t, tt.expectedFields, problem.Errors)
+			assert.NotContains(t, problem.Detail, "json:")
+		})
+	}
+}
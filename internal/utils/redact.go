@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeyParts marks a log field as secret when its key contains any of them
+var sensitiveKeyParts = []string{"password", "token", "secret", "authorization", "cookie", "private_key"}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// RedactEmail keeps the first character of the local part and the domain, e.g. "j***@example.com"
+func RedactEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return redacted
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// redactingCore scrubs secrets and email addresses from structured fields before they are encoded
+type redactingCore struct {
+	zapcore.Core
+}
+
+// NewRedactingCore wraps core so tokens, passwords and emails never reach the log output
+func NewRedactingCore(core zapcore.Core) zapcore.Core {
+	return &redactingCore{Core: core}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+// Check leaves the decision to the wrapped core, so a sampler underneath still drops entries,
+// but registers c in its place so the fields are redacted before they are written
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Core.Check(entry, nil) != nil {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = emailPattern.ReplaceAllStringFunc(entry.Message, RedactEmail)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		out[i] = redactField(f)
+	}
+	return out
+}
+
+// redactField scrubs emails from string, error, Stringer and reflected fields. Values that
+// marshal themselves through zapcore.ObjectMarshaler or ArrayMarshaler are written as they are.
+func redactField(f zapcore.Field) zapcore.Field {
+	key := strings.ToLower(f.Key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return zap.String(f.Key, redacted)
+		}
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		return zap.String(f.Key, emailPattern.ReplaceAllStringFunc(f.String, RedactEmail))
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok && err != nil {
+			return zap.String(f.Key, emailPattern.ReplaceAllStringFunc(err.Error(), RedactEmail))
+		}
+	case zapcore.StringerType:
+		if s, ok := stringOf(f.Interface); ok {
+			return zap.String(f.Key, emailPattern.ReplaceAllStringFunc(s, RedactEmail))
+		}
+	case zapcore.ReflectType:
+		// Encode the value the way the JSON encoder would, and only replace it when it holds
+		// an email, so other values keep their own encoding
+		raw, err := json.Marshal(f.Interface)
+		if err != nil {
+			return zap.String(f.Key, emailPattern.ReplaceAllStringFunc(fmt.Sprint(f.Interface), RedactEmail))
+		}
+		if emailPattern.Match(raw) {
+			return zap.Reflect(f.Key, json.RawMessage(emailPattern.ReplaceAllFunc(raw, func(email []byte) []byte {
+				return []byte(RedactEmail(string(email)))
+			})))
+		}
+	}
+	return f
+}
+
+// stringOf calls String on v, reporting false when v is not a Stringer or String panics, as it
+// does for a nil pointer
+func stringOf(v any) (s string, ok bool) {
+	stringer, isStringer := v.(fmt.Stringer)
+	if !isStringer {
+		return "", false
+	}
+	defer func() {
+		if recover() != nil {
+			s, ok = "", false
+		}
+	}()
+	return stringer.String(), true
+}
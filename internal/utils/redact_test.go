@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactingCore(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(NewRedactingCore(core)).Sugar()
+
+	logger.With("refresh_token", "abc123").Infow("login for jane.doe@example.com",
+		"password", "hunter22",
+		"email", "jane.doe@example.com",
+		"error", errors.New("duplicate key for jane.doe@example.com"),
+		"user_id", "0b7f0c1e",
+	)
+
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+
+	assert.Equal(t, "login for j***@example.com", entry.Message)
+	fields := entry.ContextMap()
+	assert.Equal(t, "[REDACTED]", fields["refresh_token"])
+	assert.Equal(t, "[REDACTED]", fields["password"])
+	assert.Equal(t, "j***@example.com", fields["email"])
+	assert.Equal(t, "duplicate key for j***@example.com", fields["error"])
+	assert.Equal(t, "0b7f0c1e", fields["user_id"])
+}
+
+type contact struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (c contact) String() string { return c.Name + " <" + c.Email + ">" }
+
+func TestRedactingCoreEncodedFields(t *testing.T) {
+	var out bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&out), zapcore.DebugLevel)
+	logger := zap.New(NewRedactingCore(core))
+
+	jane := contact{Name: "Jane", Email: "jane.doe@example.com"}
+	logger.Info("invited",
+		zap.Stringer("stringer", jane),
+		zap.Reflect("reflect", jane),
+		zap.Any("map", map[string]any{"to": []string{"jane.doe@example.com"}}),
+		zap.Reflect("plain", map[string]int{"count": 2}),
+	)
+
+	var fields map[string]any
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &fields))
+	assert.NotContains(t, out.String(), "jane.doe@example.com")
+	assert.Equal(t, "Jane <j***@example.com>", fields["stringer"])
+	assert.Equal(t, map[string]any{"name": "Jane", "email": "j***@example.com"}, fields["reflect"])
+	assert.Equal(t, map[string]any{"to": []any{"j***@example.com"}}, fields["map"])
+	assert.Equal(t, map[string]any{"count": 2.0}, fields["plain"])
+}
+
+func TestRedactingCoreKeepsSampling(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	sampled := zapcore.NewSamplerWithOptions(core, time.Minute, 1, 0)
+	logger := zap.New(NewRedactingCore(sampled)).Sugar()
+
+	for range 3 {
+		logger.Infow("token refreshed", "token", "abc123")
+	}
+
+	entries := logs.All()
+	assert.Len(t, entries, 1, "the sampler drops repeats")
+	assert.Equal(t, "[REDACTED]", entries[0].ContextMap()["token"])
+}
+
+func TestRedactEmail(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"jane.doe@example.com", "j***@example.com"},
+		{"x@shotseek.app", "x***@shotseek.app"},
+		{"not-an-email", "[REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RedactEmail(tt.input))
+		})
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -14,6 +17,17 @@ var Validate *validator.Validate
 
 func init() {
 	Validate = validator.New(validator.WithRequiredStructEnabled())
+	// Report the JSON field name clients sent rather than the Go struct field
+	Validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return fld.Name
+		}
+		return name
+	})
 }
 
 func WriteJSON(w http.ResponseWriter, status int, data any) error {
@@ -31,16 +45,68 @@ func ReadJSON(w http.ResponseWriter, r *http.Request, data any) error {
 	decoder := json.NewDecoder(r.Body)
 	decoder.DisallowUnknownFields() // Disallow fields not defined in the struct
 
-	// Attempt to decode the JSON body
+	// Attempt to decode the JSON body. The caller writes the response, typically via BadRequestResponse
 	if err := decoder.Decode(data); err != nil {
-		// Handle decoding errors
-		WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error decoding JSON: %s", err.Error()))
-		return err
+		return decodeError(err, maxBytes)
 	}
 
 	return nil
 }
 
+// decodeError describes why a body could not be decoded without echoing the decoder's
+// message, which names Go types and struct fields; the cause is only kept for logging
+func decodeError(err error, maxBytes int) *AppError {
+	invalid := func(detail string, fields ...FieldError) *AppError {
+		return &AppError{Status: http.StatusBadRequest, Code: CodeInvalidJSON, Detail: detail, Fields: fields, Err: err}
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, io.EOF):
+		return invalid("Request body must not be empty")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return invalid("Request body is not valid JSON")
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return invalid("Request body has a field of the wrong type",
+			FieldError{Field: typeErr.Field, Rule: "type", Message: "must be " + jsonTypeName(typeErr.Type)})
+	case errors.As(err, &maxBytesErr):
+		return invalid(fmt.Sprintf("Request body must not be larger than %d bytes", maxBytes))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return invalid("Request body has an unknown field",
+			FieldError{Field: field, Rule: "unknown", Message: "is not a recognised field"})
+	default:
+		return invalid("Request body could not be decoded")
+	}
+}
+
+// jsonTypeName names the JSON value a Go type decodes from
+func jsonTypeName(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return "an RFC 3339 timestamp"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	}
+	return "of a different type"
+}
+
 func WriteJSONError(w http.ResponseWriter, status int, message string) error {
 	type envelope struct {
 		Error string `json:"error"`
@@ -56,6 +122,19 @@ func JsonResponse(w http.ResponseWriter, status int, data any) error {
 	return WriteJSON(w, status, &envelope{Data: data})
 }
 
+// JsonPageResponse is JsonResponse for one page of a list. next_cursor is null on the last page.
+func JsonPageResponse(w http.ResponseWriter, status int, data any, nextCursor string) error {
+	type envelope struct {
+		Data       any     `json:"data"`
+		NextCursor *string `json:"next_cursor"`
+	}
+	env := envelope{Data: data}
+	if nextCursor != "" {
+		env.NextCursor = &nextCursor
+	}
+	return WriteJSON(w, status, &env)
+}
+
 // func (app *application) writeStringJSON(w http.ResponseWriter, status int, message string) error {
 // 	w.Header().Set("Content-Type", "application/json")
 // 	w.WriteHeader(status)